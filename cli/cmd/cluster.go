@@ -21,8 +21,10 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
 	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
@@ -89,6 +91,10 @@ var upCmd = &cobra.Command{
 			exit.Error(err)
 		}
 
+		if err := runPreflightChecks(clusterConfig, awsCreds, false); err != nil {
+			exit.Error(err)
+		}
+
 		_, exitCode, err := runManagerUpdateCommand("/root/install.sh", clusterConfig, awsCreds)
 		if err != nil {
 			exit.Error(err)
@@ -122,6 +128,10 @@ var updateCmd = &cobra.Command{
 			exit.Error(err)
 		}
 
+		if err := runPreflightChecks(clusterConfig, awsCreds, true); err != nil {
+			exit.Error(err)
+		}
+
 		_, exitCode, err := runManagerUpdateCommand("/root/install.sh --update", clusterConfig, awsCreds)
 		if err != nil {
 			exit.Error(err)
@@ -204,6 +214,16 @@ var infoCmd = &cobra.Command{
 		items.AddAll(infoResponse.ClusterConfig.UserFacingTable())
 
 		items.Print()
+
+		if len(infoResponse.ActiveFreezes) > 0 {
+			fmt.Println(titleStr("active freeze windows") + freezePeriodsStr(infoResponse.ActiveFreezes))
+		}
+		if len(infoResponse.UpcomingFreezes) > 0 {
+			fmt.Println(titleStr("upcoming freeze windows") + freezePeriodsStr(infoResponse.UpcomingFreezes))
+		}
+		if len(infoResponse.ImageCache) > 0 {
+			fmt.Println(titleStr("image cache") + imageCacheStr(infoResponse.ImageCache))
+		}
 	},
 }
 
@@ -258,6 +278,49 @@ var emailPrompValidation = &cr.PromptValidation{
 	},
 }
 
+func freezePeriodsStr(periods []clusterconfig.FreezePeriod) string {
+	rows := make([][]interface{}, len(periods))
+	for i := range periods {
+		deployments := "all"
+		if len(periods[i].Deployments) > 0 {
+			deployments = strings.Join(periods[i].Deployments, ", ")
+		}
+		rows[i] = []interface{}{periods[i].Name, periods[i].FormatTime(periods[i].Start), periods[i].FormatTime(periods[i].End), deployments}
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "name"},
+			{Title: "start"},
+			{Title: "end"},
+			{Title: "deployments"},
+		},
+		Rows: rows,
+	}
+	return table.MustFormat(t)
+}
+
+func imageCacheStr(imageCache []schema.NodeImageCache) string {
+	rows := make([][]interface{}, len(imageCache))
+	for i, node := range imageCache {
+		status := "ready"
+		if node.CachedImages < node.Images {
+			status = "pulling"
+		}
+		rows[i] = []interface{}{node.NodeName, fmt.Sprintf("%d/%d", node.CachedImages, node.Images), status}
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "node"},
+			{Title: "cached images"},
+			{Title: "status"},
+		},
+		Rows: rows,
+	}
+	return table.MustFormat(t)
+}
+
 func promptForEmail() {
 	if email, err := files.ReadFile(_emailPath); err == nil && email != "" {
 		return
@@ -286,6 +349,22 @@ func promptForEmail() {
 	}
 }
 
+func runPreflightChecks(clusterConfig *clusterconfig.Config, awsCreds *AWSCredentials, isUpdate bool) error {
+	client, err := aws.NewPreflightClient(awsCreds.AWSAccessKeyID, awsCreds.AWSSecretAccessKey, *clusterConfig.Region)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print("running pre-flight checks ...\n\n")
+	checks := clusterconfig.PreflightChecks(clusterConfig, client, isUpdate)
+	fmt.Println(table.MustFormat(clusterconfig.PreflightChecksTable(checks)))
+
+	if failedChecks := clusterconfig.FailedPreflightChecks(checks); len(failedChecks) > 0 {
+		return clusterconfig.ErrorPreflightChecksFailed(failedChecks)
+	}
+	return nil
+}
+
 func refreshCachedClusterConfig(awsCreds *AWSCredentials) *clusterconfig.Config {
 	accessConfig, err := getClusterAccessConfig()
 	if err != nil {
@@ -300,7 +379,7 @@ func refreshCachedClusterConfig(awsCreds *AWSCredentials) *clusterconfig.Config
 
 	mountedConfigPath := mountedClusterConfigPath(*accessConfig.ClusterName, *accessConfig.Region)
 
-	fmt.Println("fetching cluster configuration ..." + "\n")
+	fmt.Print("fetching cluster configuration ...\n\n")
 	_, exitCode, err := runManagerAccessCommand("/root/refresh.sh "+mountedConfigPath, *accessConfig, awsCreds)
 	if err != nil {
 		os.Remove(cachedConfigPath)
@@ -0,0 +1,170 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+)
+
+func init() {
+	addEnvFlag(nodesCmd)
+	clusterCmd.AddCommand(nodesCmd)
+}
+
+var nodesCmd = &cobra.Command{
+	Use:   "nodes",
+	Short: "get the capacity of the cluster's worker nodes and the api replicas running on them",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.cluster.nodes")
+
+		httpResponse, err := HTTPGet("/nodes")
+		if err != nil {
+			exit.Error(err)
+		}
+
+		var nodesResponse schema.GetNodesResponse
+		if err := json.Unmarshal(httpResponse, &nodesResponse); err != nil {
+			exit.Error(err, "/nodes", string(httpResponse))
+		}
+
+		fmt.Println(nodesStr(nodesResponse))
+	},
+}
+
+func nodesStr(nodesResponse schema.GetNodesResponse) string {
+	if len(nodesResponse.Nodes) == 0 {
+		return console.Bold("no worker nodes are running") + pendingReplicasStr(nodesResponse.PendingReplicas)
+	}
+
+	anyGPU := false
+	for _, node := range nodesResponse.Nodes {
+		if node.Allocatable.GPU > 0 {
+			anyGPU = true
+		}
+	}
+
+	rows := make([][]interface{}, len(nodesResponse.Nodes))
+	for i, node := range nodesResponse.Nodes {
+		lifecycle := "on-demand"
+		if node.Spot {
+			lifecycle = "spot"
+		}
+		created := node.Created
+		rows[i] = []interface{}{
+			node.Name,
+			node.InstanceType,
+			lifecycle,
+			node.AvailabilityZone,
+			libtime.Since(&created),
+			s.Round(float64(node.Requested.CPU.MilliValue())/1000, 1, 1) + " / " + s.Round(float64(node.Allocatable.CPU.MilliValue())/1000, 1, 1),
+			s.Round(float64(node.Requested.Mem.Value())/(1<<30), 1, 1) + " / " + s.Round(float64(node.Allocatable.Mem.Value())/(1<<30), 1, 1),
+			s.Int64(node.Requested.GPU) + " / " + s.Int64(node.Allocatable.GPU),
+			apiReplicasStr(node.APIReplicas),
+		}
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "node"},
+			{Title: "instance type"},
+			{Title: "lifecycle"},
+			{Title: "zone"},
+			{Title: "age"},
+			{Title: "cpu"},
+			{Title: "mem (Gi)"},
+			{Title: "gpu", Hidden: !anyGPU},
+			{Title: "api replicas"},
+		},
+		Rows: rows,
+	}
+
+	out := table.MustFormat(t)
+
+	var items table.KeyValuePairs
+	items.Add("headroom", resourceTotalsStr(nodesResponse.Headroom, anyGPU))
+	if nodesResponse.LargestReplica != nil {
+		items.Add("largest api replica which fits on a node", resourceTotalsStr(*nodesResponse.LargestReplica, anyGPU))
+	}
+	out += "\n\n" + items.String()
+
+	return out + pendingReplicasStr(nodesResponse.PendingReplicas)
+}
+
+func pendingReplicasStr(pendingReplicas []schema.APIReplicas) string {
+	if len(pendingReplicas) == 0 {
+		return ""
+	}
+
+	rows := make([][]interface{}, len(pendingReplicas))
+	for i, replicas := range pendingReplicas {
+		rows[i] = []interface{}{
+			replicas.AppName,
+			replicas.APIName,
+			replicas.Count,
+			resourceTotalsStr(replicas.Compute, replicas.Compute.GPU > 0),
+			replicas.Reason,
+		}
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "deployment"},
+			{Title: "api"},
+			{Title: "replicas"},
+			{Title: "compute"},
+			{Title: "reason"},
+		},
+		Rows: rows,
+	}
+	return "\n" + titleStr("pending api replicas") + table.MustFormat(t)
+}
+
+func apiReplicasStr(apiReplicas []schema.APIReplicas) string {
+	if len(apiReplicas) == 0 {
+		return "-"
+	}
+
+	strs := make([]string, len(apiReplicas))
+	for i, replicas := range apiReplicas {
+		strs[i] = replicas.AppName + "/" + replicas.APIName
+		if replicas.Count > 1 {
+			strs[i] += fmt.Sprintf(" (x%d)", replicas.Count)
+		}
+	}
+	return strings.Join(strs, ", ")
+}
+
+func resourceTotalsStr(totals schema.ResourceTotals, includeGPU bool) string {
+	str := fmt.Sprintf("cpu: %s, mem: %dMi", totals.CPU.String(), totals.Mem.Value()/(1<<20))
+	if includeGPU {
+		str += ", gpu: " + s.Int64(totals.GPU)
+	}
+	return str
+}
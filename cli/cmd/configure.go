@@ -28,10 +28,14 @@ import (
 )
 
 var flagPrint bool
+var flagAWSProfile string
+var flagAWSCredentialProcess string
 
 func init() {
 	addEnvFlag(configureCmd)
 	configureCmd.PersistentFlags().BoolVarP(&flagPrint, "print", "p", false, "print the configuration")
+	configureCmd.PersistentFlags().StringVar(&flagAWSProfile, "aws-profile", "", "use credentials from a named AWS profile (including SSO, assume role, and credential_process profiles)")
+	configureCmd.PersistentFlags().StringVar(&flagAWSCredentialProcess, "aws-credential-process", "", "use credentials from the output of a command (in the format of the AWS CLI's credential_process)")
 }
 
 var configureCmd = &cobra.Command{
@@ -61,8 +65,27 @@ var configureCmd = &cobra.Command{
 				items.Add("environment", flagEnv)
 			}
 			items.Add("cortex operator endpoint", cliEnvConfig.OperatorEndpoint)
-			items.Add("aws access key id", cliEnvConfig.AWSAccessKeyID)
-			items.Add("aws secret access key", s.MaskString(cliEnvConfig.AWSSecretAccessKey, 4))
+			items.Add("cortex cluster name", cliEnvConfig.ClusterName)
+			if cliEnvConfig.AWSProfile != nil {
+				items.Add("aws profile", *cliEnvConfig.AWSProfile)
+			}
+			if cliEnvConfig.AWSCredentialProcess != nil {
+				items.Add("aws credential process", *cliEnvConfig.AWSCredentialProcess)
+			}
+			if cliEnvConfig.AWSAccessKeyID != "" {
+				items.Add("aws access key id", cliEnvConfig.AWSAccessKeyID)
+				if cliEnvConfig.AWSKeysInKeychain {
+					items.Add("aws secret access key", "stored in os keychain")
+				} else {
+					items.Add("aws secret access key", s.MaskString(cliEnvConfig.AWSSecretAccessKey, 4))
+				}
+			}
+			if cliEnvConfig.AWSSessionToken != nil {
+				items.Add("aws session token", s.MaskString(*cliEnvConfig.AWSSessionToken, 4))
+			}
+			if cliEnvConfig.AWSRoleARN != nil {
+				items.Add("aws role arn", *cliEnvConfig.AWSRoleARN)
+			}
 
 			items.Print()
 			return
@@ -31,9 +31,11 @@ import (
 )
 
 var flagKeepCache bool
+var flagDeleteOverrideFreeze string
 
 func init() {
 	deleteCmd.PersistentFlags().BoolVarP(&flagKeepCache, "keep-cache", "c", false, "keep cached data for the deployment")
+	deleteCmd.PersistentFlags().StringVar(&flagDeleteOverrideFreeze, "override-freeze", "", "delete during a freeze window (e.g. for an emergency fix); the reason is recorded")
 	addEnvFlag(deleteCmd)
 }
 
@@ -72,6 +74,9 @@ var deleteCmd = &cobra.Command{
 			"appName":   appName,
 			"keepCache": s.Bool(flagKeepCache),
 		}
+		if flagDeleteOverrideFreeze != "" {
+			params["overrideFreeze"] = flagDeleteOverrideFreeze
+		}
 		httpResponse, err := HTTPPostJSONData("/delete", nil, params)
 		if err != nil {
 			exit.Error(err)
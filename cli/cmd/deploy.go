@@ -30,6 +30,7 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
 	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/secrets"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/lib/zip"
@@ -39,19 +40,35 @@ import (
 var MaxProjectSize = 1024 * 1024 * 50
 var flagDeployForce bool
 var flagDeployRefresh bool
+var flagDeployAllowSecrets bool
+var flagDeployOverrideFreeze string
+var flagDeployParallelism int
+var flagDeployFailFast bool
 
 func init() {
 	deployCmd.PersistentFlags().BoolVarP(&flagDeployForce, "force", "f", false, "override the in-progress deployment update")
 	deployCmd.PersistentFlags().BoolVarP(&flagDeployRefresh, "refresh", "r", false, "re-deploy all apis with cleared cache and rolling updates")
+	deployCmd.PersistentFlags().BoolVar(&flagDeployAllowSecrets, "allow-secrets", false, "deploy even if possible secrets are detected in project files")
+	deployCmd.PersistentFlags().StringVar(&flagDeployOverrideFreeze, "override-freeze", "", "deploy during a freeze window (e.g. for an emergency fix); the reason is recorded")
+	deployCmd.PersistentFlags().IntVarP(&flagDeployParallelism, "parallelism", "p", 4, "maximum number of apps to deploy at once (when deploying multiple app directories)")
+	deployCmd.PersistentFlags().BoolVar(&flagDeployFailFast, "fail-fast", false, "don't deploy any app if an app fails validation, and skip remaining apps once a deploy fails (when deploying multiple app directories)")
 	addEnvFlag(deployCmd)
 }
 
 var deployCmd = &cobra.Command{
-	Use:   "deploy",
+	Use:   "deploy [APP_DIR...]",
 	Short: "create or update a deployment",
-	Args:  cobra.NoArgs,
+	Long:  "create or update a deployment; app directories (or glob patterns) can be provided to deploy multiple apps at once",
+	Args:  cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		telemetry.EventNotify("cli.deploy")
+		if flagDeployParallelism < 1 {
+			exit.Error(ErrorInvalidParallelism(flagDeployParallelism))
+		}
+		if len(args) > 0 {
+			deployApps(args, flagDeployForce, flagDeployRefresh)
+			return
+		}
 		deploy(flagDeployForce, flagDeployRefresh)
 	},
 }
@@ -63,29 +80,77 @@ func deploy(force bool, ignoreCache bool) {
 		exit.Error(err)
 	}
 
-	params := map[string]string{
-		"force":       s.Bool(force),
-		"ignoreCache": s.Bool(ignoreCache),
+	projectPaths, err := listProjectPaths(root)
+	if err != nil {
+		exit.Error(err)
 	}
 
-	configBytes, err := ioutil.ReadFile(filepath.Join(root, "cortex.yaml"))
+	findings, skippedPaths, err := checkProjectSecrets(root, projectPaths)
 	if err != nil {
-		exit.Error(errors.Wrap(err, "cortex.yaml", cr.ErrorReadConfig().Error()))
+		exit.Error(err)
+	}
+	if len(skippedPaths) > 0 {
+		fmt.Println(skippedSecretsScanMsg(""))
+		for _, path := range skippedPaths {
+			fmt.Println("  " + path)
+		}
+		fmt.Println()
+	}
+	if len(findings) > 0 {
+		fmt.Println("warning: deploying with possible secrets in project files (--allow-secrets):")
+		for _, finding := range findings {
+			fmt.Println("  " + finding.String())
+		}
+		fmt.Println()
 	}
 
-	uploadBytes := map[string][]byte{
-		"cortex.yaml": configBytes,
+	cliEnvConfig, err := readOrConfigureCLIEnv(flagEnv)
+	if err != nil {
+		exit.Error(err)
+	}
+
+	deployResponse, err := uploadProject(cliEnvConfig, root, projectPaths, deployParams(force, ignoreCache))
+	if err != nil {
+		exit.Error(err)
+	}
+
+	msgParts := strings.Split(deployResponse.Message, "\n\n")
+	fmt.Println(console.Bold(msgParts[0]))
+	if len(msgParts) > 1 {
+		fmt.Println("\n" + strings.Join(msgParts[1:], "\n\n"))
+	}
+}
+
+func deployParams(force bool, ignoreCache bool) map[string]string {
+	params := map[string]string{
+		"force":       s.Bool(force),
+		"ignoreCache": s.Bool(ignoreCache),
+	}
+	if flagDeployOverrideFreeze != "" {
+		params["overrideFreeze"] = flagDeployOverrideFreeze
 	}
+	return params
+}
 
-	projectPaths, err := files.ListDirRecursive(root, false,
+func listProjectPaths(root string) ([]string, error) {
+	return files.ListDirRecursive(root, false,
 		files.IgnoreCortexYAML,
 		files.IgnoreCortexDebug,
 		files.IgnoreHiddenFiles,
 		files.IgnoreHiddenFolders,
 		files.IgnorePythonGeneratedFiles,
 	)
+}
+
+// uploadProject zips the project files under root, and uploads them along with root's cortex.yaml
+func uploadProject(cliEnvConfig CLIEnvConfig, root string, projectPaths []string, params map[string]string) (*schema.DeployResponse, error) {
+	configBytes, err := ioutil.ReadFile(filepath.Join(root, "cortex.yaml"))
 	if err != nil {
-		exit.Error(err)
+		return nil, errors.Wrap(err, "cortex.yaml", cr.ErrorReadConfig().Error())
+	}
+
+	uploadBytes := map[string][]byte{
+		"cortex.yaml": configBytes,
 	}
 
 	projectZipBytes, err := zip.ToMem(&zip.Input{
@@ -98,11 +163,11 @@ func deploy(force bool, ignoreCache bool) {
 	})
 
 	if err != nil {
-		exit.Error(errors.Wrap(err, "failed to zip project folder"))
+		return nil, errors.Wrap(err, "failed to zip project folder")
 	}
 
 	if len(projectZipBytes) > MaxProjectSize {
-		exit.Error(errors.New("zipped project folder exceeds " + s.Int(MaxProjectSize) + " bytes"))
+		return nil, errors.New("zipped project folder exceeds " + s.Int(MaxProjectSize) + " bytes")
 	}
 
 	uploadBytes["project.zip"] = projectZipBytes
@@ -111,19 +176,42 @@ func deploy(force bool, ignoreCache bool) {
 		Bytes: uploadBytes,
 	}
 
-	response, err := HTTPUpload("/deploy", uploadInput, params)
+	response, err := httpUploadToEnv(cliEnvConfig, "/deploy", uploadInput, params)
 	if err != nil {
-		exit.Error(err)
+		return nil, err
 	}
 
 	var deployResponse schema.DeployResponse
 	if err := json.Unmarshal(response, &deployResponse); err != nil {
-		exit.Error(err, "/deploy", string(response))
+		return nil, errors.Wrap(err, "/deploy", string(response))
 	}
 
-	msgParts := strings.Split(deployResponse.Message, "\n\n")
-	fmt.Println(console.Bold(msgParts[0]))
-	if len(msgParts) > 1 {
-		fmt.Println("\n" + strings.Join(msgParts[1:], "\n\n"))
+	return &deployResponse, nil
+}
+
+// checkProjectSecrets returns the possible secrets which are allowed via --allow-secrets (or an error if they are not allowed),
+// and the project files which were too large to scan
+func checkProjectSecrets(root string, projectPaths []string) ([]secrets.Finding, []string, error) {
+	allowList, err := secrets.ReadAllowList(filepath.Join(root, secrets.AllowListFileName))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	findings, skippedPaths, err := secrets.ScanFiles(append(projectPaths, filepath.Join(root, "cortex.yaml")), root, allowList)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(findings) > 0 && !flagDeployAllowSecrets {
+		return nil, nil, secrets.ErrorSecretsDetected(findings)
+	}
+
+	return findings, skippedPaths, nil
+}
+
+func skippedSecretsScanMsg(appDesc string) string {
+	if appDesc != "" {
+		appDesc = " in " + appDesc
 	}
+	return fmt.Sprintf("warning: these project files%s were not scanned for secrets because they are larger than %d MB:", appDesc, secrets.MaxScanFileSize/(1024*1024))
 }
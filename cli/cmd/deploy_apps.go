@@ -0,0 +1,278 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+)
+
+const (
+	appDeployStatusDeployed = "deployed"
+	appDeployStatusFailed   = "failed"
+	appDeployStatusSkipped  = "skipped"
+)
+
+type appDeploy struct {
+	Dir          string
+	AppName      string
+	ProjectPaths []string
+	Status       string
+	Message      string
+	Err          error
+}
+
+// deployApps validates every app up front, and then uploads and deploys the valid ones with bounded parallelism
+func deployApps(dirPatterns []string, force bool, ignoreCache bool) {
+	appDirs, err := expandAppDirs(dirPatterns)
+	if err != nil {
+		exit.Error(err)
+	}
+
+	apps := make([]*appDeploy, len(appDirs))
+	for i, appDir := range appDirs {
+		apps[i] = validateAppDeploy(appDir)
+	}
+	checkDuplicateAppNames(apps)
+
+	numInvalid := 0
+	for _, app := range apps {
+		if app.Err != nil {
+			numInvalid++
+		}
+	}
+	if numInvalid > 0 && flagDeployFailFast {
+		for _, app := range apps {
+			if app.Err == nil {
+				app.Status = appDeployStatusSkipped
+			}
+		}
+		printAppDeploys(apps)
+		exit.ErrorNoTelemetry(ErrorAppsFailedValidation(numInvalid, len(apps)))
+	}
+
+	params := deployParams(force, ignoreCache)
+
+	// the environment and its credentials are resolved before the apps are deployed concurrently, since resolving them may prompt
+	cliEnvConfig, err := readOrConfigureCLIEnv(flagEnv)
+	if err != nil {
+		exit.Error(err)
+	}
+	if _, err := authHeaderForEnv(cliEnvConfig); err != nil {
+		exit.Error(err)
+	}
+
+	var mutex sync.Mutex
+	stop := false
+
+	var fns []func() error
+	for i := range apps {
+		app := apps[i]
+		if app.Err != nil {
+			continue
+		}
+		fns = append(fns, func() error {
+			mutex.Lock()
+			if stop {
+				mutex.Unlock()
+				app.Status = appDeployStatusSkipped
+				return nil
+			}
+			mutex.Unlock()
+
+			deployResponse, err := uploadProject(cliEnvConfig, app.Dir, app.ProjectPaths, params)
+			if err != nil {
+				app.Status = appDeployStatusFailed
+				app.Err = err
+				if flagDeployFailFast {
+					mutex.Lock()
+					stop = true
+					mutex.Unlock()
+				}
+				return nil
+			}
+
+			app.Status = appDeployStatusDeployed
+			app.Message = strings.Split(deployResponse.Message, "\n\n")[0]
+			return nil
+		})
+	}
+
+	if len(fns) > 0 {
+		fmt.Printf("deploying %d apps (up to %d at once) ...\n\n", len(fns), flagDeployParallelism)
+		parallel.RunLimited(flagDeployParallelism, fns...)
+	}
+
+	printAppDeploys(apps)
+
+	numFailed := 0
+	for _, app := range apps {
+		if app.Status != appDeployStatusDeployed {
+			numFailed++
+		}
+	}
+	if numFailed > 0 {
+		exit.ErrorNoTelemetry(ErrorAppDeploysFailed(numFailed, len(apps)))
+	}
+	fmt.Println(console.Bold(fmt.Sprintf("all %d apps deployed", len(apps))))
+}
+
+// expandAppDirs resolves directories and glob patterns to the app directories (identified via a top-level cortex.yaml file) which they match
+func expandAppDirs(dirPatterns []string) ([]string, error) {
+	var appDirs []string
+	appDirsSet := strset.New()
+
+	for _, dirPattern := range dirPatterns {
+		matches, err := filepath.Glob(files.UserPath(dirPattern))
+		if err != nil {
+			return nil, errors.Wrap(err, dirPattern)
+		}
+
+		var patternAppDirs []string
+		for _, match := range matches {
+			if err := files.CheckFile(filepath.Join(match, "cortex.yaml")); err != nil {
+				continue
+			}
+			patternAppDirs = append(patternAppDirs, match)
+		}
+
+		if len(patternAppDirs) == 0 {
+			return nil, ErrorNoAppDirsMatch(dirPattern)
+		}
+
+		for _, appDir := range patternAppDirs {
+			if !appDirsSet.Has(appDir) {
+				appDirsSet.Add(appDir)
+				appDirs = append(appDirs, appDir)
+			}
+		}
+	}
+
+	return appDirs, nil
+}
+
+func validateAppDeploy(appDir string) *appDeploy {
+	app := &appDeploy{
+		Dir: appDir,
+	}
+
+	config, err := userconfig.ReadConfigFile(filepath.Join(appDir, "cortex.yaml"), "cortex.yaml")
+	if err != nil {
+		app.Status = appDeployStatusFailed
+		app.Err = err
+		return app
+	}
+	app.AppName = config.App.Name
+
+	app.ProjectPaths, err = listProjectPaths(appDir)
+	if err != nil {
+		app.Status = appDeployStatusFailed
+		app.Err = err
+		return app
+	}
+
+	findings, skippedPaths, err := checkProjectSecrets(appDir, app.ProjectPaths)
+	if err != nil {
+		app.Status = appDeployStatusFailed
+		app.Err = err
+		return app
+	}
+	if len(skippedPaths) > 0 {
+		fmt.Println(skippedSecretsScanMsg(relativeAppDir(appDir)))
+		for _, path := range skippedPaths {
+			fmt.Println("  " + path)
+		}
+		fmt.Println()
+	}
+	if len(findings) > 0 {
+		fmt.Printf("warning: deploying %s with possible secrets in project files (--allow-secrets):\n", relativeAppDir(appDir))
+		for _, finding := range findings {
+			fmt.Println("  " + finding.String())
+		}
+		fmt.Println()
+	}
+
+	return app
+}
+
+// two app directories with the same deployment name would overwrite each other's deploys
+func checkDuplicateAppNames(apps []*appDeploy) {
+	appDirsByName := map[string][]string{}
+	for _, app := range apps {
+		if app.Err == nil {
+			appDirsByName[app.AppName] = append(appDirsByName[app.AppName], relativeAppDir(app.Dir))
+		}
+	}
+
+	for _, app := range apps {
+		if app.Err == nil && len(appDirsByName[app.AppName]) > 1 {
+			app.Status = appDeployStatusFailed
+			app.Err = ErrorDuplicateAppNames(app.AppName, appDirsByName[app.AppName])
+		}
+	}
+}
+
+func printAppDeploys(apps []*appDeploy) {
+	rows := make([][]interface{}, len(apps))
+	for i, app := range apps {
+		message := app.Message
+		if app.Err != nil {
+			message = strings.Split(app.Err.Error(), "\n")[0]
+		}
+		rows[i] = []interface{}{relativeAppDir(app.Dir), app.AppName, app.Status, message}
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "app dir"},
+			{Title: "deployment"},
+			{Title: "status"},
+			{Title: "message", MaxWidth: 100},
+		},
+		Rows: rows,
+	}
+	fmt.Println(table.MustFormat(t))
+
+	for _, app := range apps {
+		if app.Err != nil {
+			fmt.Println()
+			errors.PrintError(app.Err, relativeAppDir(app.Dir))
+		}
+	}
+	fmt.Println()
+}
+
+func relativeAppDir(appDir string) string {
+	if cwd, err := os.Getwd(); err == nil {
+		if relDir, err := filepath.Rel(cwd, appDir); err == nil && !strings.HasPrefix(relDir, "..") {
+			return relDir
+		}
+	}
+	return appDir
+}
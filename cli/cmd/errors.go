@@ -19,6 +19,7 @@ package cmd
 import (
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
@@ -45,6 +46,19 @@ const (
 	ErrConfigCannotBeChangedOnUpdate
 	ErrDuplicateCLIEnvNames
 	ErrCLINotInAppDir
+	ErrInvalidRoleARN
+	ErrInvalidCLIEnvCredentials
+	ErrCLIEnvSecretAccessKeyMissing
+	ErrCLIEnvKeysNotInKeychain
+	ErrRetrieveAWSCredentials
+	ErrNoTestCasesForAPIs
+	ErrContractTestsFailed
+	ErrNoAppDirsMatch
+	ErrDuplicateAppNames
+	ErrAppsFailedValidation
+	ErrAppDeploysFailed
+	ErrInvalidParallelism
+	ErrCLIEnvNotConfigured
 )
 
 var errorKinds = []string{
@@ -56,9 +70,22 @@ var errorKinds = []string{
 	"err_config_cannot_be_changed_on_update",
 	"err_duplicate_cli_env_names",
 	"err_cli_not_in_app_dir",
+	"err_invalid_role_arn",
+	"err_invalid_cli_env_credentials",
+	"err_cli_env_secret_access_key_missing",
+	"err_cli_env_keys_not_in_keychain",
+	"err_retrieve_aws_credentials",
+	"err_no_test_cases_for_apis",
+	"err_contract_tests_failed",
+	"err_no_app_dirs_match",
+	"err_duplicate_app_names",
+	"err_apps_failed_validation",
+	"err_app_deploys_failed",
+	"err_invalid_parallelism",
+	"err_cli_env_not_configured",
 }
 
-var _ = [1]int{}[int(ErrCLINotInAppDir)-(len(errorKinds)-1)] // Ensure list length matches
+var _ = [1]int{}[int(ErrCLIEnvNotConfigured)-(len(errorKinds)-1)] // Ensure list length matches
 
 func (t ErrorKind) String() string {
 	return errorKinds[t]
@@ -161,3 +188,112 @@ func ErrorCliNotInAppDir() error {
 		message: "your current working directory is not in or under a cortex directory (identified via a top-level cortex.yaml file)",
 	})
 }
+
+func ErrorInvalidRoleARN(roleARN string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidRoleARN,
+		message: fmt.Sprintf("%s is not a valid IAM role ARN (e.g. arn:aws:iam::123456789012:role/cortex-user is a valid role ARN)", s.UserStr(roleARN)),
+	})
+}
+
+func ErrorInvalidCLIEnvCredentials() error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidCLIEnvCredentials,
+		message: "exactly one of aws_profile, aws_credential_process, or aws_access_key_id (with aws_secret_access_key) must be specified",
+	})
+}
+
+func ErrorCLIEnvSecretAccessKeyMissing() error {
+	return errors.WithStack(Error{
+		Kind:    ErrCLIEnvSecretAccessKeyMissing,
+		message: "aws_secret_access_key must be specified when aws_access_key_id is specified",
+	})
+}
+
+func ErrorCLIEnvKeysNotInKeychain(environment string, err error) error {
+	errMsg := ""
+	if err != nil {
+		errMsg = ": " + err.Error()
+	}
+
+	configureCmd := "cortex configure"
+	if environment != "default" {
+		configureCmd = fmt.Sprintf("cortex configure --env=%s", environment)
+	}
+
+	return errors.WithStack(Error{
+		Kind:    ErrCLIEnvKeysNotInKeychain,
+		message: fmt.Sprintf("unable to read the AWS keys for the %s environment from the OS keychain%s; run `%s` to re-enter them", environment, errMsg, configureCmd),
+	})
+}
+
+func ErrorRetrieveAWSCredentials(err error, source string, awsProfile *string) error {
+	// the first line is the most relevant (e.g. the AWS SDK adds verbose logging instructions)
+	errMsg := strings.Split(urls.TrimQueryParamsStr(err.Error()), "\n")[0]
+
+	message := fmt.Sprintf("unable to retrieve AWS credentials from %s: %s", source, errMsg)
+	if awsProfile != nil {
+		message += fmt.Sprintf("; if the profile uses AWS SSO, run `aws sso login --profile %s` to start a new session", *awsProfile)
+	}
+
+	return errors.WithStack(Error{
+		Kind:    ErrRetrieveAWSCredentials,
+		message: message,
+	})
+}
+
+func ErrorNoTestCasesForAPIs(apiNames []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrNoTestCasesForAPIs,
+		message: fmt.Sprintf("there are no test cases for %s", s.StrsOr(apiNames)),
+	})
+}
+
+func ErrorContractTestsFailed(numFailed int, numTests int) error {
+	return errors.WithStack(Error{
+		Kind:    ErrContractTestsFailed,
+		message: fmt.Sprintf("%d of %d test cases failed", numFailed, numTests),
+	})
+}
+
+func ErrorNoAppDirsMatch(dirPattern string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrNoAppDirsMatch,
+		message: fmt.Sprintf("%s does not match any cortex directories (identified via a top-level cortex.yaml file)", s.UserStr(dirPattern)),
+	})
+}
+
+func ErrorDuplicateAppNames(appName string, appDirs []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrDuplicateAppNames,
+		message: fmt.Sprintf("deployment name %s is used by multiple app directories (%s)", s.UserStr(appName), strings.Join(appDirs, ", ")),
+	})
+}
+
+func ErrorAppsFailedValidation(numInvalid int, numApps int) error {
+	return errors.WithStack(Error{
+		Kind:    ErrAppsFailedValidation,
+		message: fmt.Sprintf("%d of %d apps failed validation, so no apps were deployed (--fail-fast)", numInvalid, numApps),
+	})
+}
+
+func ErrorAppDeploysFailed(numFailed int, numApps int) error {
+	return errors.WithStack(Error{
+		Kind:    ErrAppDeploysFailed,
+		message: fmt.Sprintf("%d of %d apps were not deployed", numFailed, numApps),
+	})
+}
+
+func ErrorInvalidParallelism(parallelism int) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidParallelism,
+		message: fmt.Sprintf("--parallelism must be at least 1 (got %d)", parallelism),
+	})
+}
+
+func ErrorCLIEnvNotConfigured(environment string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrCLIEnvNotConfigured,
+		message: fmt.Sprintf("the %s environment is not configured (run `cortex configure --env %s`)", environment, environment),
+	})
+}
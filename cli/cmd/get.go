@@ -53,7 +53,7 @@ func init() {
 	getCmd.PersistentFlags().BoolVarP(&flagWatch, "watch", "w", false, "re-run the command every second")
 	getCmd.PersistentFlags().BoolVarP(&flagSummary, "summary", "s", false, "show summarized output")
 	getCmd.PersistentFlags().BoolVarP(&flagVerbose, "verbose", "v", false, "show verbose output")
-	getCmd.PersistentFlags().BoolVarP(&flagAllDeployments, "all-deployments", "a", false, "list all deployments")
+	getCmd.PersistentFlags().BoolVarP(&flagAllDeployments, "all-deployments", "a", false, "list the APIs of all deployments in the cluster, with cluster resource totals")
 }
 
 var getCmd = &cobra.Command{
@@ -108,43 +108,133 @@ func runGet(cmd *cobra.Command, args []string) (string, error) {
 }
 
 func allDeploymentsStr() (string, error) {
-	httpResponse, err := HTTPGet("/deployments", map[string]string{})
+	httpResponse, err := HTTPGet("/overview", map[string]string{})
 	if err != nil {
 		return "", err
 	}
 
-	var deploymentsRes schema.GetDeploymentsResponse
-	if err = json.Unmarshal(httpResponse, &deploymentsRes); err != nil {
+	var overviewRes schema.GetOverviewResponse
+	if err = json.Unmarshal(httpResponse, &overviewRes); err != nil {
 		return "", err
 	}
 
-	if len(deploymentsRes.Deployments) == 0 {
-		return console.Bold("no deployments found"), nil
+	if len(overviewRes.Deployments) == 0 {
+		return console.Bold("no deployments found") + "\n" + clusterResourcesStr(overviewRes.ClusterResources), nil
 	}
 
-	fullTable := table.Table{}
+	var rows [][]interface{}
+	var totalFailed int32
+	var totalStale int32
+	var anyGPU bool
+	for _, deployment := range overviewRes.Deployments {
+		for _, api := range deployment.APIs {
+			status := "-"
+			var readyUpdated, readyStale, requested, failed int32
+			var updatedAt *time.Time
+			if groupStatus := api.GroupStatus; groupStatus != nil {
+				status = groupStatus.Message()
+				readyUpdated = groupStatus.ReadyUpdated
+				readyStale = groupStatus.ReadyStale()
+				requested = groupStatus.Requested
+				failed = groupStatus.FailedUpdated
+				if groupStatus.ActiveStatus != nil {
+					updatedAt = groupStatus.ActiveStatus.Start
+				}
+			}
 
-	for deploymentIndex, deployment := range deploymentsRes.Deployments {
-		resourcesRes, err := getResourcesResponse(deployment.Name)
-		if err != nil {
-			return "", err
+			cpu, mem, gpu := "-", "-", "-"
+			if api.Compute != nil {
+				cpu = api.Compute.CPU.String()
+				if api.Compute.Mem != nil {
+					mem = api.Compute.Mem.String()
+				}
+				if api.Compute.GPU > 0 {
+					gpu = s.Int64(api.Compute.GPU)
+					anyGPU = true
+				}
+			}
+
+			rows = append(rows, []interface{}{
+				deployment.Name,
+				api.Name,
+				status,
+				readyUpdated,
+				readyStale,
+				requested,
+				failed,
+				cpu,
+				mem,
+				gpu,
+				libtime.Since(updatedAt),
+				api.Endpoint,
+			})
+
+			totalFailed += failed
+			totalStale += readyStale
 		}
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "deployment"},
+			{Title: resource.APIType.UserFacing()},
+			{Title: "status"},
+			{Title: "up-to-date"},
+			{Title: "stale", Hidden: totalStale == 0},
+			{Title: "requested"},
+			{Title: "failed", Hidden: totalFailed == 0},
+			{Title: "cpu"},
+			{Title: "mem"},
+			{Title: "gpu", Hidden: !anyGPU},
+			{Title: "last update"},
+			{Title: "endpoint"},
+		},
+		Rows: rows,
+	}
 
-		t := apiResourceTable(resourcesRes.APIGroupStatuses)
+	out, err := table.Format(t)
+	if err != nil {
+		return "", err
+	}
 
-		if deploymentIndex == 0 {
-			deploymentHeader := table.Header{Title: "deployment"}
-			fullTable.Headers = append([]table.Header{deploymentHeader}, t.Headers...)
-		}
+	return out + "\n" + clusterResourcesStr(overviewRes.ClusterResources), nil
+}
 
-		for i := range t.Rows {
-			t.Rows[i] = append([]interface{}{deployment.Name}, t.Rows[i]...)
-		}
+func clusterResourcesStr(clusterResources schema.ClusterResources) string {
+	requested := clusterResources.Requested
+	capacity := clusterResources.Capacity
 
-		fullTable.Rows = append(fullTable.Rows, t.Rows...)
+	rows := [][]interface{}{
+		{"cpu", s.Round(float64(requested.CPU.MilliValue())/1000, 1, 1), s.Round(float64(capacity.CPU.MilliValue())/1000, 1, 1), percentStr(float64(requested.CPU.MilliValue()), float64(capacity.CPU.MilliValue()))},
+		{"mem (Gi)", s.Round(float64(requested.Mem.Value())/(1<<30), 1, 1), s.Round(float64(capacity.Mem.Value())/(1<<30), 1, 1), percentStr(float64(requested.Mem.Value()), float64(capacity.Mem.Value()))},
+	}
+	if capacity.GPU > 0 || requested.GPU > 0 {
+		rows = append(rows, []interface{}{"gpu", requested.GPU, capacity.GPU, percentStr(float64(requested.GPU), float64(capacity.GPU))})
 	}
 
-	return table.Format(fullTable)
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "resource"},
+			{Title: "requested"},
+			{Title: "capacity"},
+			{Title: "utilization"},
+		},
+		Rows: rows,
+	}
+
+	nodesStr := s.Int(clusterResources.NumNodes) + " worker nodes"
+	if clusterResources.NumNodes == 1 {
+		nodesStr = "1 worker node"
+	}
+	title := titleStr(fmt.Sprintf("cluster resources (%s)", nodesStr))
+	return title + table.MustFormat(t)
+}
+
+func percentStr(numerator float64, denominator float64) string {
+	if denominator == 0 {
+		return "-"
+	}
+	return s.Round(numerator/denominator*100, 0, 0) + "%"
 }
 
 func getResourcesResponse(appName string) (*schema.GetResourcesResponse, error) {
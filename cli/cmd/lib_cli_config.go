@@ -19,10 +19,12 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"regexp"
 
 	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	"github.com/cortexlabs/cortex/pkg/lib/prompt"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	"github.com/cortexlabs/yaml"
@@ -33,11 +35,31 @@ type CLIConfig struct {
 	Environments []*CLIEnvConfig `json:"environments" yaml:"environments"`
 }
 
+// Exactly one credential source is configured per environment: AWSProfile, AWSCredentialProcess, or static keys
+// Static keys are stored in the OS keychain when it is available (in which case AWSSecretAccessKey and AWSSessionToken are empty)
 type CLIEnvConfig struct {
-	Name               string `json:"name" yaml:"name"`
-	OperatorEndpoint   string `json:"operator_endpoint" yaml:"operator_endpoint"`
-	AWSAccessKeyID     string `json:"aws_access_key_id" yaml:"aws_access_key_id"`
-	AWSSecretAccessKey string `json:"aws_secret_access_key" yaml:"aws_secret_access_key"`
+	Name                 string  `json:"name" yaml:"name"`
+	OperatorEndpoint     string  `json:"operator_endpoint" yaml:"operator_endpoint"`
+	ClusterName          string  `json:"cluster_name" yaml:"cluster_name"`
+	AWSProfile           *string `json:"aws_profile" yaml:"aws_profile,omitempty"`
+	AWSCredentialProcess *string `json:"aws_credential_process" yaml:"aws_credential_process,omitempty"`
+	AWSAccessKeyID       string  `json:"aws_access_key_id" yaml:"aws_access_key_id,omitempty"`
+	AWSSecretAccessKey   string  `json:"aws_secret_access_key" yaml:"aws_secret_access_key,omitempty"`
+	AWSSessionToken      *string `json:"aws_session_token" yaml:"aws_session_token,omitempty"`
+	AWSKeysInKeychain    bool    `json:"aws_keys_in_keychain" yaml:"aws_keys_in_keychain,omitempty"`
+	AWSRoleARN           *string `json:"aws_role_arn" yaml:"aws_role_arn,omitempty"`
+}
+
+// the cluster name which is used by `cortex cluster up` if one isn't configured (and by CLI environments which predate the cluster name)
+const _defaultClusterName = "cortex"
+
+var _roleARNRegex = regexp.MustCompile(`^arn:aws[a-z-]*:iam::[0-9]{12}:role/.+$`)
+
+func validateRoleARN(roleARN string) (string, error) {
+	if !_roleARNRegex.MatchString(roleARN) {
+		return "", ErrorInvalidRoleARN(roleARN)
+	}
+	return roleARN, nil
 }
 
 var cliConfigValidation = &cr.StructValidation{
@@ -69,16 +91,44 @@ var cliConfigValidation = &cr.StructValidation{
 								Validator: cr.GetURLValidator(false, false),
 							},
 						},
+						{
+							StructField: "ClusterName",
+							StringValidation: &cr.StringValidation{
+								Default: _defaultClusterName,
+							},
+						},
+						{
+							StructField:         "AWSProfile",
+							StringPtrValidation: &cr.StringPtrValidation{},
+						},
+						{
+							StructField:         "AWSCredentialProcess",
+							StringPtrValidation: &cr.StringPtrValidation{},
+						},
 						{
 							StructField: "AWSAccessKeyID",
 							StringValidation: &cr.StringValidation{
-								Required: true,
+								AllowEmpty: true,
 							},
 						},
 						{
 							StructField: "AWSSecretAccessKey",
 							StringValidation: &cr.StringValidation{
-								Required: true,
+								AllowEmpty: true,
+							},
+						},
+						{
+							StructField:         "AWSSessionToken",
+							StringPtrValidation: &cr.StringPtrValidation{},
+						},
+						{
+							StructField:    "AWSKeysInKeychain",
+							BoolValidation: &cr.BoolValidation{},
+						},
+						{
+							StructField: "AWSRoleARN",
+							StringPtrValidation: &cr.StringPtrValidation{
+								Validator: validateRoleARN,
 							},
 						},
 					},
@@ -93,17 +143,21 @@ func cliEnvPromptValidation(defaults *CLIEnvConfig) *cr.PromptValidation {
 		defaults = &CLIEnvConfig{}
 	}
 
-	if defaults.AWSAccessKeyID == "" && os.Getenv("AWS_ACCESS_KEY_ID") != "" {
-		defaults.AWSAccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
-	}
-	if defaults.AWSSecretAccessKey == "" && os.Getenv("AWS_SECRET_ACCESS_KEY") != "" {
-		defaults.AWSSecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if defaults.AWSProfile == nil && defaults.AWSAccessKeyID == "" && os.Getenv("AWS_PROFILE") != "" {
+		defaults.AWSProfile = pointer.String(os.Getenv("AWS_PROFILE"))
 	}
 	if defaults.OperatorEndpoint == "" && os.Getenv("CORTEX_OPERATOR_ENDPOINT") != "" {
 		defaults.OperatorEndpoint = os.Getenv("CORTEX_OPERATOR_ENDPOINT")
 	}
+	if defaults.ClusterName == "" {
+		defaults.ClusterName = _defaultClusterName
+		if os.Getenv("CORTEX_CLUSTER_NAME") != "" {
+			defaults.ClusterName = os.Getenv("CORTEX_CLUSTER_NAME")
+		}
+	}
 
 	return &cr.PromptValidation{
+		SkipPopulatedFields: true,
 		PromptItemValidations: []*cr.PromptItemValidation{
 			{
 				StructField: "OperatorEndpoint",
@@ -116,6 +170,46 @@ func cliEnvPromptValidation(defaults *CLIEnvConfig) *cr.PromptValidation {
 					Validator: cr.GetURLValidator(false, false),
 				},
 			},
+			{
+				StructField: "ClusterName",
+				PromptOpts: &prompt.Options{
+					Prompt: "cortex cluster name",
+				},
+				StringValidation: &cr.StringValidation{
+					Required: true,
+					Default:  defaults.ClusterName,
+				},
+			},
+			{
+				StructField: "AWSProfile",
+				PromptOpts: &prompt.Options{
+					Prompt: "aws profile (optional; leave blank to enter access keys)",
+				},
+				StringPtrValidation: &cr.StringPtrValidation{
+					Default: defaults.AWSProfile,
+				},
+			},
+		},
+	}
+}
+
+func cliEnvKeysPromptValidation(defaults *CLIEnvConfig) *cr.PromptValidation {
+	if defaults == nil {
+		defaults = &CLIEnvConfig{}
+	}
+
+	if defaults.AWSAccessKeyID == "" && os.Getenv("AWS_ACCESS_KEY_ID") != "" {
+		defaults.AWSAccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if defaults.AWSSecretAccessKey == "" && os.Getenv("AWS_SECRET_ACCESS_KEY") != "" {
+		defaults.AWSSecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	if defaults.AWSSessionToken == nil && os.Getenv("AWS_SESSION_TOKEN") != "" {
+		defaults.AWSSessionToken = pointer.String(os.Getenv("AWS_SESSION_TOKEN"))
+	}
+
+	return &cr.PromptValidation{
+		PromptItemValidations: []*cr.PromptItemValidation{
 			{
 				StructField: "AWSAccessKeyID",
 				PromptOpts: &prompt.Options{
@@ -138,6 +232,27 @@ func cliEnvPromptValidation(defaults *CLIEnvConfig) *cr.PromptValidation {
 					Default:  defaults.AWSSecretAccessKey,
 				},
 			},
+			{
+				StructField: "AWSSessionToken",
+				PromptOpts: &prompt.Options{
+					Prompt:      "aws session token (optional)",
+					MaskDefault: true,
+					HideTyping:  true,
+				},
+				StringPtrValidation: &cr.StringPtrValidation{
+					Default: defaults.AWSSessionToken,
+				},
+			},
+			{
+				StructField: "AWSRoleARN",
+				PromptOpts: &prompt.Options{
+					Prompt: "aws role arn to assume (optional)",
+				},
+				StringPtrValidation: &cr.StringPtrValidation{
+					Default:   defaults.AWSRoleARN,
+					Validator: validateRoleARN,
+				},
+			},
 		},
 	}
 }
@@ -208,19 +323,60 @@ func configureCLIEnv(environment string) (CLIEnvConfig, error) {
 		fmt.Println("environment: " + environment + "\n")
 	}
 
+	// show the previous keys as defaults, even if they are stored in the keychain
+	if prevCLIEnvConfig != nil && prevCLIEnvConfig.AWSKeysInKeychain {
+		if err := prevCLIEnvConfig.readKeysFromKeychain(); err != nil {
+			prevCLIEnvConfig.AWSKeysInKeychain = false
+		}
+	}
+
 	cliEnvConfig := CLIEnvConfig{
 		Name: environment,
 	}
+	if flagAWSProfile != "" {
+		cliEnvConfig.AWSProfile = pointer.String(flagAWSProfile)
+	}
+	if flagAWSCredentialProcess != "" {
+		cliEnvConfig.AWSCredentialProcess = pointer.String(flagAWSCredentialProcess)
+	}
 
-	err = cr.ReadPrompt(&cliEnvConfig, cliEnvPromptValidation(prevCLIEnvConfig))
+	promptValidation := cliEnvPromptValidation(prevCLIEnvConfig)
+	if cliEnvConfig.AWSCredentialProcess != nil {
+		promptValidation.PromptItemValidations = promptValidation.PromptItemValidations[:2] // the operator endpoint and cluster name
+	}
+	err = cr.ReadPrompt(&cliEnvConfig, promptValidation)
 	if err != nil {
 		return CLIEnvConfig{}, err
 	}
 
+	if cliEnvConfig.AWSProfile == nil && cliEnvConfig.AWSCredentialProcess == nil {
+		err = cr.ReadPrompt(&cliEnvConfig, cliEnvKeysPromptValidation(prevCLIEnvConfig))
+		if err != nil {
+			return CLIEnvConfig{}, err
+		}
+	}
+
+	if err := cliEnvConfig.validate(); err != nil {
+		return CLIEnvConfig{}, err
+	}
+
+	// the new keys are moved to the keychain (if it is available) before the CLI config is written
+	if cliEnvConfig.AWSAccessKeyID != "" {
+		cliEnvConfig.moveKeysToKeychain()
+	} else {
+		deleteKeysFromKeychain(environment)
+	}
+
 	if err := addEnvToCLIConfig(cliEnvConfig); err != nil {
 		return CLIEnvConfig{}, err
 	}
 
+	clearCachedAWSCredentials(environment)
+
+	if err := moveCLIConfigKeysToKeychain(); err != nil {
+		return CLIEnvConfig{}, err
+	}
+
 	return cliEnvConfig, nil
 }
 
@@ -254,10 +410,40 @@ func (cliConfig CLIConfig) validate() error {
 			return errors.Wrap(ErrorDuplicateCLIEnvNames(cliEnvConfig.Name), _cliConfigPath, "environments")
 		}
 		envNames.Add(cliEnvConfig.Name)
+
+		if err := cliEnvConfig.validate(); err != nil {
+			return errors.Wrap(err, _cliConfigPath, "environments", cliEnvConfig.Name)
+		}
 	}
 	return nil
 }
 
+func (cliEnvConfig *CLIEnvConfig) validate() error {
+	numCredentialSources := 0
+	if cliEnvConfig.AWSProfile != nil {
+		numCredentialSources++
+	}
+	if cliEnvConfig.AWSCredentialProcess != nil {
+		numCredentialSources++
+	}
+	if cliEnvConfig.AWSAccessKeyID != "" {
+		numCredentialSources++
+	}
+	if numCredentialSources != 1 {
+		return ErrorInvalidCLIEnvCredentials()
+	}
+
+	if cliEnvConfig.AWSAccessKeyID != "" {
+		if cliEnvConfig.AWSSecretAccessKey == "" && !cliEnvConfig.AWSKeysInKeychain {
+			return ErrorCLIEnvSecretAccessKeyMissing()
+		}
+	} else if cliEnvConfig.AWSSecretAccessKey != "" || cliEnvConfig.AWSSessionToken != nil || cliEnvConfig.AWSKeysInKeychain {
+		return ErrorInvalidCLIEnvCredentials()
+	}
+
+	return nil
+}
+
 func addEnvToCLIConfig(newCLIEnvConfig CLIEnvConfig) error {
 	cliConfig, err := readCLIConfig()
 	if err != nil {
@@ -277,6 +463,10 @@ func addEnvToCLIConfig(newCLIEnvConfig CLIEnvConfig) error {
 		cliConfig.Environments = append(cliConfig.Environments, &newCLIEnvConfig)
 	}
 
+	return writeCLIConfig(cliConfig)
+}
+
+func writeCLIConfig(cliConfig CLIConfig) error {
 	cliConfigBytes, err := yaml.Marshal(cliConfig)
 	if err != nil {
 		return errors.WithStack(err)
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+)
+
+func TestCLIEnvConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		cliEnvConfig CLIEnvConfig
+		expectedErr  ErrorKind // ErrUnknown if the config is valid
+	}{
+		{
+			name:         "profile",
+			cliEnvConfig: CLIEnvConfig{AWSProfile: pointer.String("dev")},
+		},
+		{
+			name:         "credential process",
+			cliEnvConfig: CLIEnvConfig{AWSCredentialProcess: pointer.String("get-creds")},
+		},
+		{
+			name:         "keys",
+			cliEnvConfig: CLIEnvConfig{AWSAccessKeyID: "id", AWSSecretAccessKey: "secret", AWSSessionToken: pointer.String("token")},
+		},
+		{
+			name:         "keys in keychain",
+			cliEnvConfig: CLIEnvConfig{AWSAccessKeyID: "id", AWSKeysInKeychain: true},
+		},
+		{
+			name:         "keys with role",
+			cliEnvConfig: CLIEnvConfig{AWSAccessKeyID: "id", AWSSecretAccessKey: "secret", AWSRoleARN: pointer.String("arn:aws:iam::123456789012:role/cortex")},
+		},
+		{
+			name:         "no credentials",
+			cliEnvConfig: CLIEnvConfig{},
+			expectedErr:  ErrInvalidCLIEnvCredentials,
+		},
+		{
+			name:         "profile and credential process",
+			cliEnvConfig: CLIEnvConfig{AWSProfile: pointer.String("dev"), AWSCredentialProcess: pointer.String("get-creds")},
+			expectedErr:  ErrInvalidCLIEnvCredentials,
+		},
+		{
+			name:         "profile and keys",
+			cliEnvConfig: CLIEnvConfig{AWSProfile: pointer.String("dev"), AWSAccessKeyID: "id", AWSSecretAccessKey: "secret"},
+			expectedErr:  ErrInvalidCLIEnvCredentials,
+		},
+		{
+			name:         "profile and secret access key",
+			cliEnvConfig: CLIEnvConfig{AWSProfile: pointer.String("dev"), AWSSecretAccessKey: "secret"},
+			expectedErr:  ErrInvalidCLIEnvCredentials,
+		},
+		{
+			name:         "credential process and session token",
+			cliEnvConfig: CLIEnvConfig{AWSCredentialProcess: pointer.String("get-creds"), AWSSessionToken: pointer.String("token")},
+			expectedErr:  ErrInvalidCLIEnvCredentials,
+		},
+		{
+			name:         "profile and keys in keychain",
+			cliEnvConfig: CLIEnvConfig{AWSProfile: pointer.String("dev"), AWSKeysInKeychain: true},
+			expectedErr:  ErrInvalidCLIEnvCredentials,
+		},
+		{
+			name:         "access key id without secret access key",
+			cliEnvConfig: CLIEnvConfig{AWSAccessKeyID: "id"},
+			expectedErr:  ErrCLIEnvSecretAccessKeyMissing,
+		},
+	} {
+		err := tc.cliEnvConfig.validate()
+		if tc.expectedErr == ErrUnknown {
+			require.NoError(t, err, tc.name)
+		} else {
+			require.Error(t, err, tc.name)
+			require.Equal(t, tc.expectedErr, errors.Cause(err).(Error).Kind, tc.name)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/zalando/go-keyring"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+)
+
+const _keychainService = "cortex"
+
+// the keys are stored in the OS keychain under the environment's name
+type keychainAWSKeys struct {
+	AWSAccessKeyID     string  `json:"aws_access_key_id"`
+	AWSSecretAccessKey string  `json:"aws_secret_access_key"`
+	AWSSessionToken    *string `json:"aws_session_token"`
+}
+
+// credentials are resolved once per environment, and cached (and refreshed when they expire) for the life of the process;
+// the cache is guarded by _cachedAWSCredentialsMutex since requests can be made concurrently (e.g. when deploying multiple apps)
+var _cachedAWSCredentials = map[string]*credentials.Credentials{}
+var _cachedAWSCredentialsMutex sync.Mutex
+
+// the keychain can be disabled (e.g. on headless machines where accessing it prompts for a password)
+func isKeychainDisabled() bool {
+	return strings.ToLower(os.Getenv("CORTEX_DISABLE_KEYCHAIN")) == "true"
+}
+
+// keys which were written to the CLI config file by an older CLI (or while the keychain was unavailable) are moved to the keychain by `cortex configure`
+func moveCLIConfigKeysToKeychain() error {
+	cliConfig, err := readCLIConfig()
+	if err != nil {
+		return err
+	}
+
+	if !cliConfig.moveKeysToKeychain() {
+		return nil
+	}
+
+	return writeCLIConfig(cliConfig)
+}
+
+// Returns true if any keys were moved
+func (cliConfig CLIConfig) moveKeysToKeychain() bool {
+	moved := false
+	for _, cliEnvConfig := range cliConfig.Environments {
+		if cliEnvConfig.AWSAccessKeyID != "" && cliEnvConfig.AWSSecretAccessKey != "" {
+			if cliEnvConfig.moveKeysToKeychain() {
+				moved = true
+			}
+		}
+	}
+	return moved
+}
+
+// Returns false if the keychain is not available (in which case the keys remain in the CLI config)
+func (cliEnvConfig *CLIEnvConfig) moveKeysToKeychain() bool {
+	if isKeychainDisabled() {
+		return false
+	}
+
+	keysBytes, err := json.Marshal(keychainAWSKeys{
+		AWSAccessKeyID:     cliEnvConfig.AWSAccessKeyID,
+		AWSSecretAccessKey: cliEnvConfig.AWSSecretAccessKey,
+		AWSSessionToken:    cliEnvConfig.AWSSessionToken,
+	})
+	if err != nil {
+		return false
+	}
+	if err := keyring.Set(_keychainService, cliEnvConfig.Name, string(keysBytes)); err != nil {
+		return false
+	}
+
+	cliEnvConfig.AWSSecretAccessKey = ""
+	cliEnvConfig.AWSSessionToken = nil
+	cliEnvConfig.AWSKeysInKeychain = true
+	return true
+}
+
+func (cliEnvConfig *CLIEnvConfig) readKeysFromKeychain() error {
+	keysStr, err := keyring.Get(_keychainService, cliEnvConfig.Name)
+	if err != nil {
+		return ErrorCLIEnvKeysNotInKeychain(cliEnvConfig.Name, err)
+	}
+
+	var keys keychainAWSKeys
+	if err := json.Unmarshal([]byte(keysStr), &keys); err != nil || keys.AWSAccessKeyID != cliEnvConfig.AWSAccessKeyID {
+		return ErrorCLIEnvKeysNotInKeychain(cliEnvConfig.Name, nil)
+	}
+
+	cliEnvConfig.AWSSecretAccessKey = keys.AWSSecretAccessKey
+	cliEnvConfig.AWSSessionToken = keys.AWSSessionToken
+	return nil
+}
+
+func deleteKeysFromKeychain(environment string) {
+	if isKeychainDisabled() {
+		return
+	}
+	keyring.Delete(_keychainService, environment)
+}
+
+func (cliEnvConfig CLIEnvConfig) awsCredentialSourceStr() string {
+	switch {
+	case cliEnvConfig.AWSProfile != nil:
+		return "aws profile " + *cliEnvConfig.AWSProfile
+	case cliEnvConfig.AWSCredentialProcess != nil:
+		return "aws credential process"
+	case cliEnvConfig.AWSKeysInKeychain:
+		return "aws access keys (os keychain)"
+	default:
+		return "aws access keys"
+	}
+}
+
+func (cliEnvConfig CLIEnvConfig) awsCredentials() (*credentials.Credentials, error) {
+	_cachedAWSCredentialsMutex.Lock()
+	defer _cachedAWSCredentialsMutex.Unlock()
+
+	if creds, ok := _cachedAWSCredentials[cliEnvConfig.Name]; ok {
+		return creds, nil
+	}
+
+	var creds *credentials.Credentials
+	var err error
+
+	switch {
+	case cliEnvConfig.AWSProfile != nil:
+		creds, err = aws.ProfileCredentials(*cliEnvConfig.AWSProfile)
+		if err != nil {
+			return nil, err
+		}
+	case cliEnvConfig.AWSCredentialProcess != nil:
+		creds = aws.ProcessCredentials(*cliEnvConfig.AWSCredentialProcess)
+	default:
+		if cliEnvConfig.AWSSecretAccessKey == "" && cliEnvConfig.AWSKeysInKeychain {
+			if err := cliEnvConfig.readKeysFromKeychain(); err != nil {
+				return nil, err
+			}
+		}
+		sessionToken := ""
+		if cliEnvConfig.AWSSessionToken != nil {
+			sessionToken = *cliEnvConfig.AWSSessionToken
+		}
+		creds = aws.StaticCredentials(cliEnvConfig.AWSAccessKeyID, cliEnvConfig.AWSSecretAccessKey, sessionToken)
+	}
+
+	if cliEnvConfig.AWSRoleARN != nil {
+		creds, err = aws.AssumeRoleCredentials(creds, *cliEnvConfig.AWSRoleARN, _stsSigningRegion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_cachedAWSCredentials[cliEnvConfig.Name] = creds
+	return creds, nil
+}
+
+func clearCachedAWSCredentials(environment string) {
+	_cachedAWSCredentialsMutex.Lock()
+	defer _cachedAWSCredentialsMutex.Unlock()
+	delete(_cachedAWSCredentials, environment)
+}
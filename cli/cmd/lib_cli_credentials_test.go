@@ -0,0 +1,245 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+)
+
+func setTestEnv(t *testing.T, key string, value string) func() {
+	prevValue, hadValue := os.LookupEnv(key)
+	require.NoError(t, os.Setenv(key, value))
+	return func() {
+		if hadValue {
+			os.Setenv(key, prevValue)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+// uses a temporary CLI config file and an in-memory keychain
+func setTestCLIConfig(t *testing.T, cliConfigStr string) func() {
+	keyring.MockInit()
+	restoreKeychainEnv := setTestEnv(t, "CORTEX_DISABLE_KEYCHAIN", "")
+
+	tmpDir, err := ioutil.TempDir("", "cortex-cli-config")
+	require.NoError(t, err)
+	prevCLIConfigPath := _cliConfigPath
+	_cliConfigPath = filepath.Join(tmpDir, "cli.yaml")
+	require.NoError(t, files.WriteFile([]byte(cliConfigStr), _cliConfigPath))
+
+	return func() {
+		_cliConfigPath = prevCLIConfigPath
+		os.RemoveAll(tmpDir)
+		restoreKeychainEnv()
+	}
+}
+
+func TestMoveKeysToKeychain(t *testing.T) {
+	defer setTestCLIConfig(t, "")()
+
+	cliEnvConfig := CLIEnvConfig{Name: "dev", AWSAccessKeyID: "id", AWSSecretAccessKey: "secret", AWSSessionToken: pointer.String("token")}
+	require.True(t, cliEnvConfig.moveKeysToKeychain())
+	require.Equal(t, CLIEnvConfig{Name: "dev", AWSAccessKeyID: "id", AWSKeysInKeychain: true}, cliEnvConfig)
+	require.NoError(t, cliEnvConfig.validate())
+
+	require.NoError(t, cliEnvConfig.readKeysFromKeychain())
+	require.Equal(t, "secret", cliEnvConfig.AWSSecretAccessKey)
+	require.Equal(t, pointer.String("token"), cliEnvConfig.AWSSessionToken)
+
+	// the keys in the keychain must belong to the configured access key id
+	otherCLIEnvConfig := CLIEnvConfig{Name: "dev", AWSAccessKeyID: "other-id", AWSKeysInKeychain: true}
+	err := otherCLIEnvConfig.readKeysFromKeychain()
+	require.Equal(t, ErrCLIEnvKeysNotInKeychain, errors.Cause(err).(Error).Kind)
+
+	deleteKeysFromKeychain("dev")
+	err = cliEnvConfig.readKeysFromKeychain()
+	require.Equal(t, ErrCLIEnvKeysNotInKeychain, errors.Cause(err).(Error).Kind)
+
+	defer setTestEnv(t, "CORTEX_DISABLE_KEYCHAIN", "true")()
+	cliEnvConfig = CLIEnvConfig{Name: "dev", AWSAccessKeyID: "id", AWSSecretAccessKey: "secret"}
+	require.False(t, cliEnvConfig.moveKeysToKeychain())
+	require.Equal(t, CLIEnvConfig{Name: "dev", AWSAccessKeyID: "id", AWSSecretAccessKey: "secret"}, cliEnvConfig)
+}
+
+func TestMoveCLIConfigKeysToKeychain(t *testing.T) {
+	cliConfigStr := `
+environments:
+  - name: default
+    operator_endpoint: https://operator.example.com
+    aws_access_key_id: id
+    aws_secret_access_key: secret
+  - name: dev
+    operator_endpoint: https://operator.dev.example.com
+    aws_profile: dev
+`
+	defer setTestCLIConfig(t, cliConfigStr)()
+
+	// reading the CLI config doesn't modify it
+	cliConfig, err := readCLIConfig()
+	require.NoError(t, err)
+	require.Equal(t, "secret", cliConfig.Environments[0].AWSSecretAccessKey)
+	require.False(t, cliConfig.Environments[0].AWSKeysInKeychain)
+	cliConfigBytes, err := files.ReadFileBytes(_cliConfigPath)
+	require.NoError(t, err)
+	require.Equal(t, cliConfigStr, string(cliConfigBytes))
+	_, err = keyring.Get(_keychainService, "default")
+	require.Equal(t, keyring.ErrNotFound, err)
+
+	require.NoError(t, moveCLIConfigKeysToKeychain())
+
+	cliConfig, err = readCLIConfig()
+	require.NoError(t, err)
+	require.Equal(t, CLIEnvConfig{
+		Name:              "default",
+		OperatorEndpoint:  "https://operator.example.com",
+		ClusterName:       _defaultClusterName,
+		AWSAccessKeyID:    "id",
+		AWSKeysInKeychain: true,
+	}, *cliConfig.Environments[0])
+	require.Equal(t, pointer.String("dev"), cliConfig.Environments[1].AWSProfile)
+	require.False(t, cliConfig.Environments[1].AWSKeysInKeychain)
+	cliConfigBytes, err = files.ReadFileBytes(_cliConfigPath)
+	require.NoError(t, err)
+	require.NotContains(t, string(cliConfigBytes), "secret")
+
+	require.NoError(t, cliConfig.Environments[0].readKeysFromKeychain())
+	require.Equal(t, "secret", cliConfig.Environments[0].AWSSecretAccessKey)
+
+	// once the keys have been moved, the CLI config is left as is
+	cliConfigBytes, err = files.ReadFileBytes(_cliConfigPath)
+	require.NoError(t, err)
+	require.NoError(t, moveCLIConfigKeysToKeychain())
+	updatedCLIConfigBytes, err := files.ReadFileBytes(_cliConfigPath)
+	require.NoError(t, err)
+	require.Equal(t, cliConfigBytes, updatedCLIConfigBytes)
+}
+
+func TestAWSCredentials(t *testing.T) {
+	defer setTestCLIConfig(t, "")()
+	defer func() {
+		for _, environment := range []string{"keys", "keychain", "missing-keychain", "profile", "process"} {
+			clearCachedAWSCredentials(environment)
+		}
+	}()
+
+	tmpDir, err := ioutil.TempDir("", "cortex-aws-config")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	awsConfigPath := filepath.Join(tmpDir, "config")
+	require.NoError(t, files.WriteFile([]byte("[profile dev]\n"), awsConfigPath))
+	awsCredentialsPath := filepath.Join(tmpDir, "credentials")
+	require.NoError(t, files.WriteFile([]byte("[dev]\naws_access_key_id = profile-id\naws_secret_access_key = profile-secret\n"), awsCredentialsPath))
+	defer setTestEnv(t, "AWS_CONFIG_FILE", awsConfigPath)()
+	defer setTestEnv(t, "AWS_SHARED_CREDENTIALS_FILE", awsCredentialsPath)()
+
+	keychainCLIEnvConfig := CLIEnvConfig{Name: "keychain", AWSAccessKeyID: "keychain-id", AWSSecretAccessKey: "keychain-secret"}
+	require.True(t, keychainCLIEnvConfig.moveKeysToKeychain())
+
+	for _, tc := range []struct {
+		cliEnvConfig      CLIEnvConfig
+		expectedKeyID     string
+		expectedSecretKey string
+	}{
+		{
+			cliEnvConfig:      CLIEnvConfig{Name: "keys", AWSAccessKeyID: "id", AWSSecretAccessKey: "secret"},
+			expectedKeyID:     "id",
+			expectedSecretKey: "secret",
+		},
+		{
+			cliEnvConfig:      keychainCLIEnvConfig,
+			expectedKeyID:     "keychain-id",
+			expectedSecretKey: "keychain-secret",
+		},
+		{
+			cliEnvConfig:      CLIEnvConfig{Name: "profile", AWSProfile: pointer.String("dev")},
+			expectedKeyID:     "profile-id",
+			expectedSecretKey: "profile-secret",
+		},
+		{
+			cliEnvConfig:      CLIEnvConfig{Name: "process", AWSCredentialProcess: pointer.String(`echo '{"Version": 1, "AccessKeyId": "process-id", "SecretAccessKey": "process-secret"}'`)},
+			expectedKeyID:     "process-id",
+			expectedSecretKey: "process-secret",
+		},
+	} {
+		creds, err := tc.cliEnvConfig.awsCredentials()
+		require.NoError(t, err, tc.cliEnvConfig.Name)
+		value, err := creds.Get()
+		require.NoError(t, err, tc.cliEnvConfig.Name)
+		require.Equal(t, tc.expectedKeyID, value.AccessKeyID, tc.cliEnvConfig.Name)
+		require.Equal(t, tc.expectedSecretKey, value.SecretAccessKey, tc.cliEnvConfig.Name)
+	}
+
+	_, err = CLIEnvConfig{Name: "missing-keychain", AWSAccessKeyID: "id", AWSKeysInKeychain: true}.awsCredentials()
+	require.Equal(t, ErrCLIEnvKeysNotInKeychain, errors.Cause(err).(Error).Kind)
+}
+
+func TestAWSCredentialsCache(t *testing.T) {
+	defer clearCachedAWSCredentials("process")
+
+	tmpDir, err := ioutil.TempDir("", "cortex-credential-process")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	// each run of the credential process returns a new access key id, with credentials which have already expired
+	countPath := filepath.Join(tmpDir, "count")
+	credentialProcess := fmt.Sprintf(`n=$(($(cat %s 2>/dev/null || echo 0) + 1)); echo $n > %s; `, countPath, countPath) +
+		`echo "{\"Version\": 1, \"AccessKeyId\": \"id-$n\", \"SecretAccessKey\": \"secret\", \"Expiration\": \"2000-01-01T00:00:00Z\"}"`
+	cliEnvConfig := CLIEnvConfig{Name: "process", AWSCredentialProcess: pointer.String(credentialProcess)}
+
+	creds, err := cliEnvConfig.awsCredentials()
+	require.NoError(t, err)
+
+	// the credentials are cached per environment
+	cachedCreds, err := cliEnvConfig.awsCredentials()
+	require.NoError(t, err)
+	require.True(t, creds == cachedCreds)
+
+	// and refreshed when they expire
+	value, err := cachedCreds.Get()
+	require.NoError(t, err)
+	require.Equal(t, "id-1", value.AccessKeyID)
+	value, err = cachedCreds.Get()
+	require.NoError(t, err)
+	require.Equal(t, "id-2", value.AccessKeyID)
+
+	// reconfiguring the environment clears the cache
+	clearCachedAWSCredentials("process")
+	cliEnvConfig.AWSCredentialProcess = pointer.String(`echo '{"Version": 1, "AccessKeyId": "new-id", "SecretAccessKey": "secret"}'`)
+	newCreds, err := cliEnvConfig.awsCredentials()
+	require.NoError(t, err)
+	require.False(t, newCreds == creds)
+
+	// credentials without an expiration are only retrieved once
+	value, err = newCreds.Get()
+	require.NoError(t, err)
+	require.Equal(t, "new-id", value.AccessKeyID)
+	require.False(t, newCreds.IsExpired())
+}
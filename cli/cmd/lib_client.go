@@ -19,6 +19,7 @@ package cmd
 import (
 	"bytes"
 	"crypto/tls"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -26,12 +27,15 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
 
 	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
@@ -98,6 +102,15 @@ type HTTPUploadInput struct {
 }
 
 func HTTPUpload(endpoint string, input *HTTPUploadInput, qParams ...map[string]string) ([]byte, error) {
+	cliEnvConfig, err := readOrConfigureCLIEnv(flagEnv)
+	if err != nil {
+		return nil, err
+	}
+	return httpUploadToEnv(cliEnvConfig, endpoint, input, qParams...)
+}
+
+// httpUploadToEnv uploads to the operator of an environment which has already been resolved (it is safe to call concurrently)
+func httpUploadToEnv(cliEnvConfig CLIEnvConfig, endpoint string, input *HTTPUploadInput, qParams ...map[string]string) ([]byte, error) {
 	body := new(bytes.Buffer)
 	writer := multipart.NewWriter(body)
 
@@ -123,13 +136,13 @@ func HTTPUpload(endpoint string, input *HTTPUploadInput, qParams ...map[string]s
 		return nil, errors.Wrap(err, errStrCantMakeRequest)
 	}
 
-	req, err := operatorRequest("POST", endpoint, body, qParams)
+	req, err := operatorRequestToEnv(cliEnvConfig, "POST", endpoint, body, qParams)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", writer.FormDataContentType())
-	return operatorClient.MakeRequest(req)
+	return operatorClient.makeRequestToEnv(cliEnvConfig, req)
 }
 
 func addFileToMultipart(fileName string, writer *multipart.Writer, reader io.Reader) error {
@@ -158,7 +171,7 @@ func HTTPUploadZip(endpoint string, zipInput *zip.Input, fileName string, qParam
 	return HTTPUpload(endpoint, uploadInput, qParams...)
 }
 
-func StreamLogs(appName string, resourceName string, resourceType string) error {
+func StreamLogs(appName string, resourceName string, resourceType string, filters []string, level string) error {
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt)
 
@@ -171,6 +184,12 @@ func StreamLogs(appName string, resourceName string, resourceType string) error
 	values.Set("resourceName", resourceName)
 	values.Set("resourceType", resourceType)
 	values.Set("appName", appName)
+	for _, filter := range filters {
+		values.Add("filter", filter)
+	}
+	if level != "" {
+		values.Set("level", level)
+	}
 
 	if isTelemetryEnabled() {
 		values.Set("clientID", clientID())
@@ -209,7 +228,7 @@ func StreamLogs(appName string, resourceName string, resourceType string) error
 		if err != nil || output.Error == "" {
 			return errors.New(string(bodyBytes))
 		}
-		return errors.New(output.Error)
+		return errorFromResponse(output)
 	}
 	defer connection.Close()
 
@@ -227,7 +246,7 @@ func handleConnection(connection *websocket.Conn, done chan struct{}) {
 			if err != nil {
 				exit.ErrorNoPrint(err)
 			}
-			fmt.Println(string(message))
+			fmt.Println(formatLogMessage(string(message)))
 		}
 	}()
 }
@@ -249,7 +268,10 @@ func operatorRequest(method string, endpoint string, body io.Reader, qParams []m
 	if err != nil {
 		return nil, err
 	}
+	return operatorRequestToEnv(cliEnvConfig, method, endpoint, body, qParams)
+}
 
+func operatorRequestToEnv(cliEnvConfig CLIEnvConfig, method string, endpoint string, body io.Reader, qParams []map[string]string) (*http.Request, error) {
 	req, err := http.NewRequest(method, cliEnvConfig.OperatorEndpoint+endpoint, body)
 	if err != nil {
 		return nil, errors.Wrap(err, errStrCantMakeRequest)
@@ -267,13 +289,21 @@ func operatorRequest(method string, endpoint string, body io.Reader, qParams []m
 }
 
 func (client *OperatorClient) MakeRequest(request *http.Request) ([]byte, error) {
+	cliEnvConfig, err := readOrConfigureCLIEnv(flagEnv)
+	if err != nil {
+		return nil, err
+	}
+	return client.makeRequestToEnv(cliEnvConfig, request)
+}
+
+func (client *OperatorClient) makeRequestToEnv(cliEnvConfig CLIEnvConfig, request *http.Request) ([]byte, error) {
 	if isTelemetryEnabled() {
 		values := request.URL.Query()
 		values.Set("clientID", clientID())
 		request.URL.RawQuery = values.Encode()
 	}
 
-	authHeader, err := authHeader()
+	authHeader, err := authHeaderForEnv(cliEnvConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -283,7 +313,7 @@ func (client *OperatorClient) MakeRequest(request *http.Request) ([]byte, error)
 
 	response, err := client.Do(request)
 	if err != nil {
-		return nil, ErrorFailedToConnectOperator(err, operatorEndpointOrBlank())
+		return nil, ErrorFailedToConnectOperator(err, cliEnvConfig.OperatorEndpoint)
 	}
 	defer response.Body.Close()
 
@@ -299,7 +329,7 @@ func (client *OperatorClient) MakeRequest(request *http.Request) ([]byte, error)
 			return nil, errors.New(strings.TrimSpace(string(bodyBytes)))
 		}
 
-		return nil, errors.New(output.Error)
+		return nil, errorFromResponse(output)
 	}
 
 	bodyBytes, err := ioutil.ReadAll(response.Body)
@@ -331,12 +361,47 @@ func (client *GenericClient) MakeRequest(request *http.Request) ([]byte, error)
 	return bodyBytes, nil
 }
 
+// the global STS endpoint is signed for us-east-1, and is accepted by the operator regardless of the cluster's region
+const _stsSigningRegion = "us-east-1"
+
+// The auth header contains a presigned STS GetCallerIdentity request, which the operator uses to verify the user's identity (the secret access key is not sent)
 func authHeader() (string, error) {
 	cliEnvConfig, err := readOrConfigureCLIEnv(flagEnv)
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("CortexAWS %s|%s", cliEnvConfig.AWSAccessKeyID, cliEnvConfig.AWSSecretAccessKey), err
+	return authHeaderForEnv(cliEnvConfig)
+}
+
+func authHeaderForEnv(cliEnvConfig CLIEnvConfig) (string, error) {
+	creds, err := cliEnvConfig.awsCredentials()
+	if err != nil {
+		return "", err
+	}
+	if _, err := aws.RetrieveCredentials(creds); err != nil {
+		return "", ErrorRetrieveAWSCredentials(err, cliEnvConfig.awsCredentialSourceStr(), cliEnvConfig.AWSProfile)
+	}
+
+	presignedURL, err := aws.PresignGetCallerIdentity(creds, _stsSigningRegion, cliEnvConfig.ClusterName)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to sign identity request")
+	}
+
+	return consts.AuthHeaderSignedPrefix + base64.RawURLEncoding.EncodeToString([]byte(presignedURL)), nil
+}
+
+// Config errors from the operator point to the local cortex.yaml, so the offending lines can be shown
+func errorFromResponse(response schema.ErrorResponse) error {
+	err := errors.New(response.Error)
+	if response.SourcePosition == nil {
+		return err
+	}
+
+	var sourceBytes []byte
+	if appRoot := appRootOrBlank(); appRoot != "" {
+		sourceBytes, _ = ioutil.ReadFile(filepath.Join(appRoot, response.SourcePosition.FilePath))
+	}
+	return cr.WithSourcePosition(err, *response.SourcePosition, sourceBytes)
 }
 
 // Returns empty string if not able to get operator endpoint
@@ -17,13 +17,19 @@ limitations under the License.
 package cmd
 
 import (
+	"sync"
+
 	"github.com/cortexlabs/cortex/pkg/lib/files"
 	"github.com/google/uuid"
 )
 
 var _cachedClientID string
+var _cachedClientIDMutex sync.Mutex
 
 func clientID() string {
+	_cachedClientIDMutex.Lock()
+	defer _cachedClientIDMutex.Unlock()
+
 	if _cachedClientID != "" {
 		return _cachedClientID
 	}
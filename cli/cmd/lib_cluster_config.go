@@ -28,6 +28,7 @@ import (
 	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	"github.com/cortexlabs/cortex/pkg/lib/prompt"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
@@ -140,6 +141,11 @@ func getInstallClusterConfig(awsCreds *AWSCredentials) (*clusterconfig.Config, e
 		return nil, err
 	}
 
+	// new clusters use the restricted api pod security profile unless configured otherwise
+	if clusterConfig.APIPodSecurity == nil {
+		clusterConfig.APIPodSecurity = pointer.String(clusterconfig.APIPodSecurityRestricted)
+	}
+
 	if clusterConfig.Spot != nil && *clusterConfig.Spot {
 		clusterConfig.AutoFillSpot(awsCreds.CortexAWSAccessKeyID, awsCreds.AWSSecretAccessKey)
 	}
@@ -179,6 +185,10 @@ func getClusterUpdateConfig(cachedClusterConfig *clusterconfig.Config, awsCreds
 			userClusterConfig.Bucket = cachedClusterConfig.Bucket
 		}
 
+		if userClusterConfig.APIPodSecurity == nil {
+			userClusterConfig.APIPodSecurity = cachedClusterConfig.APIPodSecurity
+		}
+
 		if userClusterConfig.InstanceType != nil && *userClusterConfig.InstanceType != *cachedClusterConfig.InstanceType {
 			return nil, ErrorConfigCannotBeChangedOnUpdate(clusterconfig.InstanceTypeKey, *cachedClusterConfig.InstanceType)
 		}
@@ -385,10 +395,29 @@ func clusterConfigConfirmaionStr(clusterConfig *clusterconfig.Config, awsCreds *
 		}
 	}
 
+	if clusterConfig.MTLS != defaultConfig.MTLS {
+		items.Add(clusterconfig.MTLSUserFacingKey, clusterConfig.MTLS)
+	}
+	if clusterConfig.APIPodSecurityMode() != clusterconfig.APIPodSecurityRestricted {
+		items.Add(clusterconfig.APIPodSecurityUserFacingKey, clusterConfig.APIPodSecurityMode())
+	}
+
 	if clusterConfig.Telemetry != defaultConfig.Telemetry {
 		items.Add(clusterconfig.TelemetryUserFacingKey, clusterConfig.Telemetry)
 	}
 
+	if clusterConfig.PythonPackageIndex != nil {
+		items.Add(clusterconfig.PythonPackageIndexUserFacingKey, clusterConfig.PythonPackageIndex.UserFacingString())
+	}
+
+	if len(clusterConfig.FreezeWindows) > 0 {
+		items.Add(clusterconfig.FreezeWindowsUserFacingKey, clusterconfig.FreezeWindowsUserFacingString(clusterConfig.FreezeWindows))
+	}
+
+	if clusterConfig.PrepullImages != defaultConfig.PrepullImages {
+		items.Add(clusterconfig.PrepullImagesUserFacingKey, s.YesNo(clusterConfig.PrepullImages))
+	}
+
 	if clusterConfig.ImagePythonServe != defaultConfig.ImagePythonServe {
 		items.Add(clusterconfig.ImagePythonServeUserFacingKey, clusterConfig.ImagePythonServe)
 	}
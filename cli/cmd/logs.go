@@ -17,20 +17,29 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/cortexlabs/cortex/pkg/lib/console"
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
 )
 
+var flagLogsFilters []string
+var flagLogsLevel string
+
 func init() {
 	addAppNameFlag(logsCmd)
 	addEnvFlag(logsCmd)
+	logsCmd.Flags().StringArrayVar(&flagLogsFilters, "filter", nil, "only show structured log records with a matching field, e.g. request_id=7f3a (can be repeated)")
+	logsCmd.Flags().StringVar(&flagLogsLevel, "level", "", "only show structured log records at this level or above (debug, info, warning, error, or critical)")
 }
 
 var logsCmd = &cobra.Command{
@@ -47,7 +56,7 @@ var logsCmd = &cobra.Command{
 			exit.Error(err)
 		}
 
-		err = StreamLogs(appName, resourceName, resource.APIType.String())
+		err = StreamLogs(appName, resourceName, resource.APIType.String(), flagLogsFilters, flagLogsLevel)
 		if err != nil {
 			// note: if modifying this string, search the codebase for it and change all occurrences
 			if strings.HasSuffix(err.Error(), "is not deployed") {
@@ -58,3 +67,64 @@ var logsCmd = &cobra.Command{
 		}
 	},
 }
+
+// fields of structured log records which are shown in the log line itself, or which are the same for all records of an api
+var _logRecordBaseFields = strset.New("timestamp", "level", "message", "exception", "api_name", "replica", "log")
+
+// formatLogMessage renders a log record: structured records are formatted like the serving containers' text logs (with any additional fields appended), and other lines are shown as-is
+func formatLogMessage(message string) string {
+	var record map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(message)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&record); err != nil || record == nil {
+		return message
+	}
+
+	recordMessage, ok := record["message"].(string)
+	if !ok {
+		if log, ok := record["log"].(string); ok {
+			return log
+		}
+		return message
+	}
+
+	var fields []string
+	if requestID, ok := record["request_id"]; ok {
+		fields = append(fields, "request_id="+logFieldStr(requestID))
+	}
+	var extraKeys []string
+	for key := range record {
+		if !_logRecordBaseFields.Has(key) && key != "request_id" {
+			extraKeys = append(extraKeys, key)
+		}
+	}
+	sort.Strings(extraKeys)
+	for _, key := range extraKeys {
+		fields = append(fields, key+"="+logFieldStr(record[key]))
+	}
+
+	line := recordMessage
+	timestamp, hasTimestamp := record["timestamp"]
+	level, hasLevel := record["level"]
+	if hasTimestamp && hasLevel {
+		line = fmt.Sprintf("%s:cortex:%s:%s", logFieldStr(timestamp), logFieldStr(level), recordMessage)
+	}
+	if len(fields) > 0 {
+		line += " (" + strings.Join(fields, ", ") + ")"
+	}
+	if exception, ok := record["exception"].(string); ok {
+		line += "\n" + exception
+	}
+	return line
+}
+
+func logFieldStr(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(jsonBytes)
+}
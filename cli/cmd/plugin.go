@@ -0,0 +1,310 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+)
+
+// plugins are executables on the PATH named cortex-<name>, which are run as `cortex <name>`
+const _pluginPrefix = "cortex-"
+
+const _pluginAnnotation = "plugin"
+
+type plugin struct {
+	Name       string
+	Path       string
+	ShadowedBy string // the path of the plugin with the same name earlier on the PATH (in which case this one is ignored)
+	Builtin    bool   // the name conflicts with a built-in command (in which case the plugin is ignored)
+}
+
+func init() {
+	addEnvFlag(pluginAuthHeaderCmd)
+	pluginCmd.AddCommand(pluginListCmd)
+	pluginCmd.AddCommand(pluginAuthHeaderCmd)
+}
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "manage cli plugins",
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the plugins on your PATH",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.plugin.list")
+		fmt.Println(pluginsStr(findPlugins()))
+	},
+}
+
+// pluginAuthHeaderCmd prints the Authorization header for requests to the operator, so that plugins don't need the credentials
+// (the header expires after 15 minutes, so long-running plugins should run it again for later requests)
+var pluginAuthHeaderCmd = &cobra.Command{
+	Use:    "auth-header",
+	Short:  "print the authorization header for requests to the operator",
+	Args:   cobra.NoArgs,
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.plugin.auth-header")
+
+		// the environment isn't configured on behalf of the plugin, since the prompts would be mixed into the header
+		cliEnvConfig, err := readCLIEnvConfig(flagEnv)
+		if err != nil {
+			exit.Error(err)
+		}
+		if cliEnvConfig == nil {
+			exit.Error(ErrorCLIEnvNotConfigured(flagEnv))
+		}
+
+		header, err := authHeaderForEnv(*cliEnvConfig)
+		if err != nil {
+			exit.Error(err)
+		}
+		fmt.Println(header)
+	},
+}
+
+func pluginsStr(plugins []*plugin) string {
+	if len(plugins) == 0 {
+		return console.Bold("no plugins were found on your PATH") + " (plugins are executables named " + _pluginPrefix + "<name>)"
+	}
+
+	rows := make([][]interface{}, len(plugins))
+	for i, p := range plugins {
+		status := "ok"
+		if p.Builtin {
+			status = "ignored (conflicts with the built-in command)"
+		} else if p.ShadowedBy != "" {
+			status = "ignored (shadowed by " + p.ShadowedBy + ")"
+		}
+		rows[i] = []interface{}{p.Name, p.Path, status}
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "plugin"},
+			{Title: "path"},
+			{Title: "status"},
+		},
+		Rows: rows,
+	}
+
+	return table.MustFormat(t)
+}
+
+// findPlugins returns all plugin executables in PATH order (including the ones which are ignored)
+func findPlugins() []*plugin {
+	builtins := map[string]bool{"help": true}
+	for _, cmd := range rootCmd.Commands() {
+		if _, ok := cmd.Annotations[_pluginAnnotation]; ok {
+			continue
+		}
+		builtins[cmd.Name()] = true
+		for _, alias := range cmd.Aliases {
+			builtins[alias] = true
+		}
+	}
+
+	var plugins []*plugin
+	pluginPaths := map[string]string{} // name -> path of the plugin which is used
+	visitedDirs := map[string]bool{}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" || visitedDirs[dir] {
+			continue
+		}
+		visitedDirs[dir] = true
+
+		fileInfos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, fileInfo := range fileInfos {
+			if !strings.HasPrefix(fileInfo.Name(), _pluginPrefix) || fileInfo.Name() == _pluginPrefix {
+				continue
+			}
+			path := filepath.Join(dir, fileInfo.Name())
+			if !isExecutable(path) {
+				continue
+			}
+
+			name := strings.TrimPrefix(fileInfo.Name(), _pluginPrefix)
+			p := &plugin{
+				Name:    name,
+				Path:    path,
+				Builtin: builtins[name],
+			}
+			if shadowedBy, ok := pluginPaths[name]; ok {
+				p.ShadowedBy = shadowedBy
+			} else {
+				pluginPaths[name] = path
+			}
+			plugins = append(plugins, p)
+		}
+	}
+
+	return plugins
+}
+
+func isExecutable(path string) bool {
+	fileInfo, err := os.Stat(path) // follows symlinks
+	if err != nil {
+		return false
+	}
+	return fileInfo.Mode().IsRegular() && fileInfo.Mode().Perm()&0111 != 0
+}
+
+// shouldAddPluginCommands returns whether the plugins need to be registered as commands, i.e. when args may run a plugin (the
+// first argument isn't a built-in command) or when generating the completion script; otherwise the PATH isn't scanned
+func shouldAddPluginCommands(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if args[0] == completionCmd.Name() {
+		return true
+	}
+	_, _, err := rootCmd.Find(args)
+	return err != nil
+}
+
+// addPluginCommands registers the plugins as commands, so that they can be run and are included in the help text and in completion
+func addPluginCommands() []*cobra.Command {
+	var pluginCmds []*cobra.Command
+	for _, p := range findPlugins() {
+		if p.Builtin || p.ShadowedBy != "" {
+			continue
+		}
+		cmd := pluginCommand(p.Name, p.Path)
+		rootCmd.AddCommand(cmd)
+		pluginCmds = append(pluginCmds, cmd)
+	}
+	return pluginCmds
+}
+
+func pluginCommand(name string, path string) *cobra.Command {
+	return &cobra.Command{
+		Use:                name,
+		Short:              "plugin (" + path + ")",
+		DisableFlagParsing: true, // all arguments (including --help) are passed to the plugin
+		Annotations:        map[string]string{_pluginAnnotation: path},
+		Run: func(cmd *cobra.Command, args []string) {
+			telemetry.Event("cli.plugin")
+			runPlugin(path, args)
+		},
+	}
+}
+
+func isPluginCommand(cmd *cobra.Command) bool {
+	_, ok := cmd.Annotations[_pluginAnnotation]
+	return ok
+}
+
+func runPlugin(path string, args []string) {
+	pluginEnv, err := pluginEnvVars(args)
+	if err != nil {
+		exit.Error(err)
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), pluginEnv...)
+
+	// interrupts are also delivered to the plugin, which decides when to exit
+	signal.Ignore(os.Interrupt)
+
+	err = cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		telemetry.Close()
+		os.Exit(exitErr.ExitCode())
+	}
+	if err != nil {
+		exit.Error(errors.Wrap(err, "unable to run plugin", path))
+	}
+
+	exit.Ok()
+}
+
+// pluginEnvVars resolves the environment (and deployment) which the plugin is run against; the --env and --deployment
+// flags are read from the plugin's arguments (and are still passed to the plugin)
+func pluginEnvVars(args []string) ([]string, error) {
+	flags := pflag.NewFlagSet("plugin", pflag.ContinueOnError)
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	flags.SetOutput(ioutil.Discard)
+	env := flags.StringP("env", "e", "default", "")
+	appName := flags.StringP("deployment", "d", "", "")
+	flags.Parse(args)
+
+	flagEnv = *env
+	flagAppName = *appName
+
+	envVars := map[string]string{
+		"CORTEX_VERSION": consts.CortexVersion,
+		"CORTEX_ENV":     flagEnv,
+	}
+
+	if cliPath, err := os.Executable(); err == nil {
+		envVars["CORTEX_CLI_PATH"] = cliPath
+	}
+
+	if appRoot := appRootOrBlank(); appRoot != "" {
+		envVars["CORTEX_APP_DIR"] = appRoot
+	}
+	if IsAppNameSpecified() {
+		appName, err := AppNameFromFlagOrConfig()
+		if err != nil {
+			return nil, err
+		}
+		envVars["CORTEX_DEPLOYMENT"] = appName
+	}
+
+	// the environment isn't configured on behalf of the plugin, since it may not need it; plugins which make requests to the
+	// operator get the authorization header from `cortex plugin auth-header` (so the credentials are only resolved when needed)
+	cliEnvConfig, err := readCLIEnvConfig(flagEnv)
+	if err != nil {
+		return nil, err
+	}
+	if cliEnvConfig != nil {
+		envVars["CORTEX_OPERATOR_ENDPOINT"] = cliEnvConfig.OperatorEndpoint
+	}
+
+	var pluginEnv []string
+	for key, value := range envVars {
+		pluginEnv = append(pluginEnv, key+"="+value)
+	}
+	return pluginEnv, nil
+}
@@ -104,16 +104,24 @@ func Execute() {
 	rootCmd.AddCommand(deployCmd)
 	rootCmd.AddCommand(getCmd)
 	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(usageCmd)
 	rootCmd.AddCommand(predictCmd)
+	rootCmd.AddCommand(testCmd)
 	rootCmd.AddCommand(deleteCmd)
 
 	rootCmd.AddCommand(clusterCmd)
 	rootCmd.AddCommand(versionCmd)
 
 	rootCmd.AddCommand(configureCmd)
+	rootCmd.AddCommand(pluginCmd)
 	rootCmd.AddCommand(completionCmd)
 
-	updateRootUsage()
+	var pluginCmds []*cobra.Command
+	if shouldAddPluginCommands(os.Args[1:]) {
+		pluginCmds = addPluginCommands()
+	}
+
+	updateRootUsage(pluginCmds)
 
 	printLeadingNewLine()
 	rootCmd.Execute()
@@ -121,7 +129,7 @@ func Execute() {
 	exit.Ok()
 }
 
-func updateRootUsage() {
+func updateRootUsage(pluginCmds []*cobra.Command) {
 	defaultUsageFunc := rootCmd.UsageFunc()
 	usage := rootCmd.UsageString()
 
@@ -134,6 +142,9 @@ func updateRootUsage() {
 		usage = strings.Replace(usage, "Available Commands:", "deployment commands:", 1)
 		usage = strings.Replace(usage, "\n  cluster", "\n\ncluster commands:\n  cluster", 1)
 		usage = strings.Replace(usage, "\n  configure", "\n\nother commands:\n  configure", 1)
+		if len(pluginCmds) > 0 {
+			usage = strings.Replace(usage, "\n  "+pluginCmds[0].Name()+" ", "\n\nplugin commands:\n  "+pluginCmds[0].Name()+" ", 1)
+		}
 		usage = strings.Replace(usage, "\n\nUse \"cortex [command] --help\" for more information about a command.", "", 1)
 
 		cmd.Print(usage)
@@ -146,6 +157,9 @@ func printLeadingNewLine() {
 	if len(os.Args) == 2 && os.Args[1] == "completion" {
 		return
 	}
+	if cmd, _, err := rootCmd.Find(os.Args[1:]); err == nil && (isPluginCommand(cmd) || cmd == pluginAuthHeaderCmd) {
+		return // plugins control their own output (and read the auth header's output)
+	}
 	fmt.Println("")
 }
 
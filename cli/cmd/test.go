@@ -0,0 +1,151 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/contracttest"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+)
+
+var flagTestAPIs []string
+var flagTestJUnitPath string
+
+func init() {
+	addAppNameFlag(testCmd)
+	addEnvFlag(testCmd)
+	testCmd.Flags().StringSliceVar(&flagTestAPIs, "api", nil, "only run the test cases of these apis (comma-separated or repeated)")
+	testCmd.Flags().StringVar(&flagTestJUnitPath, "junit", "", "write a JUnit XML report to this path")
+}
+
+var testCmd = &cobra.Command{
+	Use:   "test TEST_FILE...",
+	Short: "run contract tests against the deployed apis",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.test")
+
+		testCases, err := readTestCases(args, flagTestAPIs)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		appName, err := AppNameFromFlagOrConfig()
+		if err != nil {
+			exit.Error(err)
+		}
+
+		resourcesRes, err := getResourcesResponse(appName)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		var results []*contracttest.Result
+		numFailed := 0
+		for _, testCase := range testCases {
+			result := runTestCase(testCase, resourcesRes)
+			fmt.Println(testResultStr(result))
+			results = append(results, result)
+			if !result.Passed() {
+				numFailed++
+			}
+		}
+
+		if flagTestJUnitPath != "" {
+			junitBytes, err := contracttest.JUnitXML(appName, results)
+			if err != nil {
+				exit.Error(err)
+			}
+			if err := files.WriteFile(junitBytes, flagTestJUnitPath); err != nil {
+				exit.Error(err)
+			}
+		}
+
+		fmt.Println()
+		if numFailed > 0 {
+			exit.ErrorNoTelemetry(ErrorContractTestsFailed(numFailed, len(results)))
+		}
+		fmt.Println(console.Bold(fmt.Sprintf("all %d test cases passed", len(results))))
+	},
+}
+
+func readTestCases(testFilePaths []string, apiNames []string) ([]*contracttest.TestCase, error) {
+	apiNamesSet := strset.New(apiNames...)
+
+	var testCases []*contracttest.TestCase
+	for _, testFilePath := range testFilePaths {
+		fileTestCases, err := contracttest.ReadFile(testFilePath)
+		if err != nil {
+			return nil, err
+		}
+		for _, testCase := range fileTestCases {
+			if len(apiNames) == 0 || apiNamesSet.Has(testCase.API) {
+				testCases = append(testCases, testCase)
+			}
+		}
+	}
+
+	if len(testCases) == 0 {
+		return nil, ErrorNoTestCasesForAPIs(apiNames)
+	}
+	return testCases, nil
+}
+
+// test cases are run sequentially so that their latencies are not affected by each other
+func runTestCase(testCase *contracttest.TestCase, resourcesRes *schema.GetResourcesResponse) *contracttest.Result {
+	api := resourcesRes.Context.APIs[testCase.API]
+	if api == nil {
+		return &contracttest.Result{TestCase: testCase, Err: ErrorAPINotFound(testCase.API)}
+	}
+
+	apiGroupStatus := resourcesRes.APIGroupStatuses[testCase.API]
+	if apiGroupStatus == nil || apiGroupStatus.ActiveStatus == nil {
+		status := "not ready"
+		if apiGroupStatus != nil {
+			status = apiGroupStatus.Message()
+		}
+		return &contracttest.Result{TestCase: testCase, Err: ErrorAPINotReady(testCase.API, status)}
+	}
+
+	apiURL := urls.Join(resourcesRes.APIsBaseURL, *api.Endpoint)
+	return testCase.Run(predictClient.Client, apiURL)
+}
+
+func testResultStr(result *contracttest.Result) string {
+	name := fmt.Sprintf("%s: %s", result.TestCase.API, result.TestCase.Name)
+
+	if result.Err != nil {
+		return fmt.Sprintf("%s  %s\n      %s", console.Bold("error"), name, result.Err.Error())
+	}
+
+	latency := result.Latency.Round(time.Millisecond)
+	if len(result.Failures) == 0 {
+		return fmt.Sprintf("pass   %s (%s)", name, latency)
+	}
+	return fmt.Sprintf("%s   %s (%s)\n      %s", console.Bold("fail"), name, latency, strings.Join(result.Failures, "\n      "))
+}
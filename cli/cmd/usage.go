@@ -0,0 +1,120 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+)
+
+var flagUsageStart string
+var flagUsageEnd string
+var flagUsageGroupBy []string
+
+func init() {
+	addAppNameFlag(usageCmd)
+	addEnvFlag(usageCmd)
+	usageCmd.Flags().StringVar(&flagUsageStart, "start", "", "first day to include, e.g. 2020-01-01 (UTC; default: 6 days before the end date)")
+	usageCmd.Flags().StringVar(&flagUsageEnd, "end", "", "last day to include, e.g. 2020-01-31 (UTC; default: today)")
+	usageCmd.Flags().StringSliceVar(&flagUsageGroupBy, "group-by", []string{"consumer"}, "how to group requests: any of consumer, api, and day")
+}
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "get a deployment's request counts, errors and latency per consumer",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.usage")
+
+		appName, err := AppNameFromFlagOrConfig()
+		if err != nil {
+			exit.Error(err)
+		}
+
+		params := map[string]string{
+			"appName": appName,
+			"start":   flagUsageStart,
+			"end":     flagUsageEnd,
+			"groupBy": strings.Join(flagUsageGroupBy, ","),
+		}
+		httpResponse, err := HTTPGet("/usage", params)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		var usageResponse schema.GetUsageResponse
+		if err := json.Unmarshal(httpResponse, &usageResponse); err != nil {
+			exit.Error(err, "/usage", string(httpResponse))
+		}
+
+		fmt.Println(usageStr(appName, usageResponse))
+	},
+}
+
+func usageStr(appName string, usageResponse schema.GetUsageResponse) string {
+	rangeStr := fmt.Sprintf("%s to %s (UTC)", usageResponse.Start, usageResponse.End)
+	if len(usageResponse.Rows) == 0 {
+		return console.Bold(fmt.Sprintf("no usage was recorded for deployment %s from %s", appName, rangeStr)) +
+			"\n\nusage is recorded for deployments which configure usage.consumer_header or usage.consumer_claim"
+	}
+
+	groupBy := strset.New(usageResponse.GroupBy...)
+
+	var total schema.UsageRow
+	rows := make([][]interface{}, len(usageResponse.Rows))
+	for i, row := range usageResponse.Rows {
+		latency := "-"
+		if row.Latency != nil {
+			latency = fmt.Sprintf("%.6g ms", *row.Latency)
+		}
+		rows[i] = []interface{}{row.Consumer, row.APIName, row.Day, row.Requests, row.Code2XX, row.Code4XX, row.Code5XX, latency}
+
+		total.Requests += row.Requests
+		total.Code2XX += row.Code2XX
+		total.Code4XX += row.Code4XX
+		total.Code5XX += row.Code5XX
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "consumer", Hidden: !groupBy.Has("consumer")},
+			{Title: "api", Hidden: !groupBy.Has("api")},
+			{Title: "day", Hidden: !groupBy.Has("day")},
+			{Title: "requests"},
+			{Title: "2XX"},
+			{Title: "4XX"},
+			{Title: "5XX"},
+			{Title: "avg latency"},
+		},
+		Rows: rows,
+	}
+
+	out := console.Bold(fmt.Sprintf("usage of deployment %s from %s", appName, rangeStr)) + "\n\n"
+	out += table.MustFormat(t)
+	out += fmt.Sprintf("\n\ntotal: %d requests (%d 2XX, %d 4XX, %d 5XX)", total.Requests, total.Code2XX, total.Code4XX, total.Code5XX)
+	return out
+}
@@ -146,6 +146,8 @@ function main() {
   echo -n "￮ configuring networking "
   setup_istio
   envsubst < manifests/apis.yaml | kubectl apply -f - >/dev/null
+  # the aws vpc cni does not enforce network policies on its own (https://docs.aws.amazon.com/eks/latest/userguide/calico.html)
+  kubectl apply -f manifests/calico.yaml >/dev/null
   echo "✓"
 
   echo -n "￮ configuring autoscaling "
@@ -196,7 +198,7 @@ function main() {
   fi
 
   echo -n "￮ configuring cli "
-  python update_cli_config.py "/.cortex/cli.yaml" "$CORTEX_ENVIRONMENT" "$operator_endpoint" "$CORTEX_AWS_ACCESS_KEY_ID" "$CORTEX_AWS_SECRET_ACCESS_KEY"
+  python update_cli_config.py "/.cortex/cli.yaml" "$CORTEX_ENVIRONMENT" "$operator_endpoint" "$CORTEX_CLUSTER_NAME" "$CORTEX_AWS_ACCESS_KEY_ID" "$CORTEX_AWS_SECRET_ACCESS_KEY"
   echo "✓"
 
   echo -e "\ncortex is ready!"
@@ -280,7 +282,24 @@ function setup_istio() {
     sleep 3
   done
 
+  # the injector only runs in the labeled cortex namespace, and its policy is disabled (see istio-values.yaml), so only api pods
+  # which opt in via annotation get a sidecar (the operator, fluentd, statsd and the image downloaders/pre-pullers don't)
+  if [ "$CORTEX_MTLS" == "disabled" ]; then
+    export CORTEX_ISTIO_SIDECAR_INJECTOR_ENABLED="false"
+    kubectl label namespace cortex istio-injection- >/dev/null 2>&1 || true
+  else
+    export CORTEX_ISTIO_SIDECAR_INJECTOR_ENABLED="true"
+    kubectl label namespace cortex istio-injection=enabled --overwrite >/dev/null
+  fi
+
   envsubst < manifests/istio-values.yaml | helm template istio-manifests/istio --values - --name istio --namespace istio-system | kubectl apply -f - >/dev/null
+
+  if [ "$CORTEX_MTLS" == "disabled" ]; then
+    kubectl delete -f manifests/mtls.yaml --ignore-not-found=true >/dev/null
+  else
+    export CORTEX_ISTIO_MTLS_MODE=$(echo "$CORTEX_MTLS" | tr '[:lower:]' '[:upper:]')
+    envsubst < manifests/mtls.yaml | kubectl apply -f - >/dev/null
+  fi
 }
 
 function validate_cortex() {
@@ -23,6 +23,8 @@ var (
 	ContextCacheDir    = "/mnt/context"
 	EmptyDirMountPath  = "/mnt"
 	EmptyDirVolumeName = "mnt"
+	TmpDirMountPath    = "/tmp"
+	TmpDirVolumeName   = "tmp"
 
 	ClusterConfigPath = "/configs/cluster/cluster.yaml"
 	ClusterConfigName = "cluster-config"
@@ -35,8 +37,13 @@ var (
 	ResourceStatusesDir = "resource_statuses"
 	WorkloadSpecsDir    = "workload_specs"
 	MetadataDir         = "metadata"
+	FreezeOverridesDir  = "freeze_overrides"
+	UsageDir            = "usage"
 
 	K8sNamespace = "cortex"
 
+	AuthHeaderSignedPrefix = "CortexAWSSigned " // followed by a base64 (url encoding, no padding) presigned STS GetCallerIdentity url
+	AuthHeaderLegacyPrefix = "CortexAWS "       // deprecated: followed by "<access key id>|<secret access key>"
+
 	MaxClassesPerRequest = 20 // cloudwatch.GeMetricData can get up to 100 metrics per request, avoid multiple requests and have room for other stats
 )
@@ -27,6 +27,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/sts"
 
@@ -41,6 +42,7 @@ type Client struct {
 	S3                   *s3.S3
 	stsClient            *sts.STS
 	autoscaling          *autoscaling.AutoScaling
+	ec2                  *ec2.EC2
 	CloudWatchLogsClient *cloudwatchlogs.CloudWatchLogs
 	CloudWatchMetrics    *cloudwatch.CloudWatch
 	AccountID            string
@@ -63,6 +65,7 @@ func New(region string, bucket string, withAccountID bool) (*Client, error) {
 	sess := session.Must(session.NewSession(&aws.Config{
 		Region:     aws.String(region),
 		DisableSSL: aws.Bool(false),
+		HTTPClient: newHTTPClient(),
 	}))
 
 	bucketLocation, err := GetBucketRegion(bucket)
@@ -73,6 +76,7 @@ func New(region string, bucket string, withAccountID bool) (*Client, error) {
 	bucketSess := session.Must(session.NewSession(&aws.Config{
 		Region:     aws.String(bucketLocation),
 		DisableSSL: aws.Bool(false),
+		HTTPClient: newHTTPClient(),
 	}))
 
 	awsClient := &Client{
@@ -81,6 +85,7 @@ func New(region string, bucket string, withAccountID bool) (*Client, error) {
 		S3:                   s3.New(bucketSess),
 		stsClient:            sts.New(sess),
 		autoscaling:          autoscaling.New(sess),
+		ec2:                  ec2.New(sess),
 		CloudWatchMetrics:    cloudwatch.New(sess),
 		CloudWatchLogsClient: cloudwatchlogs.New(sess),
 	}
@@ -0,0 +1,77 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// OperationTimeout bounds each HTTP request to AWS (including reading the response), so that a hung request can't block the caller indefinitely
+var OperationTimeout = 5 * time.Minute
+
+func newHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: OperationTimeout,
+	}
+}
+
+// WithContext returns a copy of the client whose requests are canceled when ctx is done
+func (c *Client) WithContext(ctx context.Context) *Client {
+	ctxClient := *c
+	if c.S3 != nil {
+		ctxClient.S3 = &s3.S3{Client: clientWithContext(c.S3.Client, ctx)}
+	}
+	if c.stsClient != nil {
+		ctxClient.stsClient = &sts.STS{Client: clientWithContext(c.stsClient.Client, ctx)}
+	}
+	if c.autoscaling != nil {
+		ctxClient.autoscaling = &autoscaling.AutoScaling{Client: clientWithContext(c.autoscaling.Client, ctx)}
+	}
+	if c.ec2 != nil {
+		ctxClient.ec2 = &ec2.EC2{Client: clientWithContext(c.ec2.Client, ctx)}
+	}
+	if c.CloudWatchMetrics != nil {
+		ctxClient.CloudWatchMetrics = &cloudwatch.CloudWatch{Client: clientWithContext(c.CloudWatchMetrics.Client, ctx)}
+	}
+	if c.CloudWatchLogsClient != nil {
+		ctxClient.CloudWatchLogsClient = &cloudwatchlogs.CloudWatchLogs{Client: clientWithContext(c.CloudWatchLogsClient.Client, ctx)}
+	}
+	return &ctxClient
+}
+
+func clientWithContext(awsClient *client.Client, ctx context.Context) *client.Client {
+	ctxClient := *awsClient
+	ctxClient.Handlers = awsClient.Handlers.Copy()
+	ctxClient.Handlers.Validate.PushFrontNamed(request.NamedHandler{
+		Name: "cortex.SetContext",
+		Fn: func(r *request.Request) {
+			r.SetContext(ctx)
+		},
+	})
+	return &ctxClient
+}
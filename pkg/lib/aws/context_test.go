@@ -0,0 +1,120 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+// newBlockingClient returns a client for an S3 endpoint which doesn't respond until the returned function is called
+func newBlockingClient(t *testing.T, timeout time.Duration) (*Client, func()) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	closeServer := func() {
+		close(release)
+		server.Close()
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:           aws.String("us-west-2"),
+		Endpoint:         aws.String(server.URL),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("id", "secret", ""),
+		MaxRetries:       aws.Int(0),
+		HTTPClient:       &http.Client{Timeout: timeout},
+	})
+	require.NoError(t, err)
+
+	return &Client{
+		Region: "us-west-2",
+		Bucket: "bucket",
+		S3:     s3.New(sess),
+	}, closeServer
+}
+
+func TestWithContextCanceled(t *testing.T) {
+	client, closeServer := newBlockingClient(t, time.Minute)
+	defer closeServer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := client.WithContext(ctx).ReadStringFromS3("key")
+	require.Error(t, err)
+	require.True(t, errors.IsCanceled(err), err.Error())
+	require.False(t, errors.IsTimeout(err), err.Error())
+	require.True(t, time.Since(start) < 10*time.Second)
+}
+
+func TestWithContextDeadline(t *testing.T) {
+	client, closeServer := newBlockingClient(t, time.Minute)
+	defer closeServer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := client.WithContext(ctx).UploadStringToS3("value", "key")
+	require.Error(t, err)
+	require.True(t, errors.IsTimeout(err), err.Error())
+	require.False(t, errors.IsCanceled(err), err.Error())
+	require.True(t, time.Since(start) < 10*time.Second)
+}
+
+func TestOperationTimeout(t *testing.T) {
+	client, closeServer := newBlockingClient(t, 50*time.Millisecond)
+	defer closeServer()
+
+	start := time.Now()
+	_, err := client.WithContext(context.Background()).ReadStringFromS3("key")
+	require.Error(t, err)
+	require.True(t, errors.IsTimeout(err), err.Error())
+	require.True(t, time.Since(start) < 10*time.Second)
+}
+
+func TestWithContextDoesNotModifyClient(t *testing.T) {
+	client, closeServer := newBlockingClient(t, time.Minute)
+	defer closeServer()
+
+	numHandlers := client.S3.Handlers.Validate.Len()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctxClient := client.WithContext(ctx)
+
+	require.Equal(t, numHandlers, client.S3.Handlers.Validate.Len())
+	require.Equal(t, numHandlers+1, ctxClient.S3.Handlers.Validate.Len())
+	require.Equal(t, client.Bucket, ctxClient.Bucket)
+}
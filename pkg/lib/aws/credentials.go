@@ -17,7 +17,13 @@ limitations under the License.
 package aws
 
 import (
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/processcreds"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
 )
 
 func GetCredentialsFromCLIConfigFile() (string, string, error) {
@@ -37,3 +43,50 @@ func GetCredentialsFromCLIConfigFile() (string, string, error) {
 
 	return value.AccessKeyID, value.SecretAccessKey, nil
 }
+
+func StaticCredentials(accessKeyID string, secretAccessKey string, sessionToken string) *credentials.Credentials {
+	return credentials.NewStaticCredentials(accessKeyID, secretAccessKey, sessionToken)
+}
+
+// ProfileCredentials resolves credentials from a named profile in the AWS config files (~/.aws/config and ~/.aws/credentials)
+// SSO, assume role (including MFA), and credential_process profiles are supported; credentials are refreshed when they expire
+func ProfileCredentials(profile string) (*credentials.Credentials, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Profile:                 profile,
+		SharedConfigState:       session.SharedConfigEnable,
+		AssumeRoleTokenProvider: stscreds.StdinTokenProvider,
+	})
+	if err != nil {
+		return nil, ErrorInvalidProfile(profile, err)
+	}
+
+	return sess.Config.Credentials, nil
+}
+
+// ProcessCredentials runs command to retrieve credentials (see https://docs.aws.amazon.com/cli/latest/userguide/cli-configure-sourcing-external.html)
+func ProcessCredentials(command string) *credentials.Credentials {
+	return processcreds.NewCredentials(command)
+}
+
+// AssumeRoleCredentials returns temporary credentials for roleARN, which are refreshed when they expire
+func AssumeRoleCredentials(creds *credentials.Credentials, roleARN string, region string) (*credentials.Credentials, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(region),
+		DisableSSL:  aws.Bool(false),
+		Credentials: creds,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return stscreds.NewCredentials(sess, roleARN), nil
+}
+
+// RetrieveCredentials returns the current credentials (retrieving them if they have expired)
+func RetrieveCredentials(creds *credentials.Credentials) (credentials.Value, error) {
+	value, err := creds.Get()
+	if err != nil {
+		return credentials.Value{}, errors.WithStack(err)
+	}
+	return value, nil
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 )
 
@@ -100,3 +101,35 @@ func GetAvailabilityZones(accessKeyID string, secretAccessKey string, region str
 
 	return availabilityZones, nil
 }
+
+// SpotInstanceIDs returns the IDs of the given instances which are spot instances (instances which don't exist are ignored)
+func (c *Client) SpotInstanceIDs(instanceIDs []string) (strset.Set, error) {
+	spotInstanceIDs := strset.New()
+	if len(instanceIDs) == 0 {
+		return spotInstanceIDs, nil
+	}
+
+	input := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("instance-id"),
+				Values: aws.StringSlice(instanceIDs),
+			},
+		},
+	}
+	err := c.ec2.DescribeInstancesPages(input, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				if instance.InstanceId != nil && aws.StringValue(instance.InstanceLifecycle) == ec2.InstanceLifecycleTypeSpot {
+					spotInstanceIDs.Add(*instance.InstanceId)
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return spotInstanceIDs, nil
+}
@@ -36,6 +36,8 @@ const (
 	ErrInstanceTypeLimitIsZero
 	ErrNoValidSpotPrices
 	ErrReadCredentials
+	ErrInvalidSignedIdentityRequest
+	ErrInvalidProfile
 )
 
 var errorKinds = []string{
@@ -47,9 +49,11 @@ var errorKinds = []string{
 	"err_instance_type_limit_is_zero",
 	"err_no_valid_spot_prices",
 	"err_read_credentials",
+	"err_invalid_signed_identity_request",
+	"err_invalid_profile",
 }
 
-var _ = [1]int{}[int(ErrReadCredentials)-(len(errorKinds)-1)] // Ensure list length matches
+var _ = [1]int{}[int(ErrInvalidProfile)-(len(errorKinds)-1)] // Ensure list length matches
 
 func (t ErrorKind) String() string {
 	return errorKinds[t]
@@ -101,6 +105,11 @@ func IsGenericNotFoundErr(err error) bool {
 	return IsNotFoundErr(err) || IsNoSuchKeyErr(err) || IsNoSuchBucketErr(err)
 }
 
+func IsInvalidSignedIdentityRequestErr(err error) bool {
+	awsErr, ok := errors.Cause(err).(Error)
+	return ok && awsErr.Kind == ErrInvalidSignedIdentityRequest
+}
+
 func CheckErrCode(err error, errorCode string) bool {
 	awsErr, ok := errors.Cause(err).(awserr.Error)
 	if !ok {
@@ -169,3 +178,17 @@ func ErrorReadCredentials() error {
 		message: "unable to read AWS credentials from credentials file",
 	})
 }
+
+func ErrorInvalidSignedIdentityRequest(reason string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidSignedIdentityRequest,
+		message: fmt.Sprintf("invalid signed identity request: %s", reason),
+	})
+}
+
+func ErrorInvalidProfile(profile string, err error) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidProfile,
+		message: fmt.Sprintf("unable to load AWS profile %s: %s", s.UserStr(profile), err.Error()),
+	})
+}
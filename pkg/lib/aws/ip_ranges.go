@@ -0,0 +1,76 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+)
+
+const _ipRangesURL = "https://ip-ranges.amazonaws.com/ip-ranges.json"
+
+type ipRanges struct {
+	Prefixes []ipRangePrefix `json:"prefixes"`
+}
+
+type ipRangePrefix struct {
+	IPPrefix string `json:"ip_prefix"`
+	Region   string `json:"region"`
+	Service  string `json:"service"`
+}
+
+// ServiceCIDRs returns the published IPv4 CIDRs for the given AWS service (e.g. "S3" or "CLOUDWATCH")
+// If region is "", CIDRs from all regions are returned
+func ServiceCIDRs(service string, region string) ([]string, error) {
+	httpClient := http.Client{Timeout: 10 * time.Second}
+	response, err := httpClient.Get(_ipRangesURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch aws ip ranges")
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch aws ip ranges")
+	}
+
+	var ranges ipRanges
+	if err := json.Unmarshal(body, &ranges); err != nil {
+		return nil, errors.Wrap(err, "unable to parse aws ip ranges")
+	}
+
+	cidrs := strset.New()
+	for _, prefix := range ranges.Prefixes {
+		if prefix.Service != service {
+			continue
+		}
+		if region != "" && prefix.Region != region {
+			continue
+		}
+		cidrs.Add(prefix.IPPrefix)
+	}
+
+	cidrSlice := cidrs.Slice()
+	sort.Strings(cidrSlice)
+	return cidrSlice, nil
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+const (
+	_elasticIPQuotaCode = "L-0263D0A3" // EC2-VPC Elastic IPs
+	_vpcQuotaCode       = "L-F678F1CE" // VPCs per Region
+)
+
+// PreflightClient looks up the account's quotas and resource usage which are relevant to creating a cluster
+type PreflightClient struct {
+	ec2           *ec2.EC2
+	serviceQuotas *servicequotas.ServiceQuotas
+}
+
+func NewPreflightClient(accessKeyID string, secretAccessKey string, region string) (*PreflightClient, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(region),
+		DisableSSL:  aws.Bool(false),
+		Credentials: credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""),
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &PreflightClient{
+		ec2:           ec2.New(sess),
+		serviceQuotas: servicequotas.New(sess),
+	}, nil
+}
+
+func quotaMetricClass(quotaClass string, spot bool) string {
+	if spot {
+		return quotaClass + "/spot"
+	}
+	return quotaClass + "/ondemand"
+}
+
+// VCPUQuota returns the account's vCPU limit for the quota class (see InstanceQuotaClass); the second return value is false if no quota was found
+func (c *PreflightClient) VCPUQuota(quotaClass string, spot bool) (int64, bool, error) {
+	metricClass := quotaMetricClass(quotaClass, spot)
+
+	var limit int64
+	found := false
+	err := c.serviceQuotas.ListServiceQuotasPages(
+		&servicequotas.ListServiceQuotasInput{
+			ServiceCode: aws.String("ec2"),
+		},
+		func(page *servicequotas.ListServiceQuotasOutput, lastPage bool) bool {
+			if page == nil {
+				return false
+			}
+			for _, quota := range page.Quotas {
+				if quota == nil || quota.Value == nil || quota.UsageMetric == nil || len(quota.UsageMetric.MetricDimensions) == 0 {
+					continue
+				}
+
+				class, ok := quota.UsageMetric.MetricDimensions["Class"]
+				if !ok || class == nil {
+					continue
+				}
+
+				if strings.ToLower(*class) == metricClass {
+					limit = int64(*quota.Value)
+					found = true
+					return false
+				}
+			}
+			return true
+		},
+	)
+	if err != nil {
+		return 0, false, errors.WithStack(err)
+	}
+
+	return limit, found, nil
+}
+
+// RunningVCPUs returns the number of vCPUs of pending and running instances in the quota class (see InstanceQuotaClass)
+func (c *PreflightClient) RunningVCPUs(quotaClass string, spot bool) (int64, error) {
+	var vCPUs int64
+	err := c.ec2.DescribeInstancesPages(
+		&ec2.DescribeInstancesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("instance-state-name"),
+					Values: aws.StringSlice([]string{"pending", "running"}),
+				},
+			},
+		},
+		func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+			for _, reservation := range page.Reservations {
+				for _, instance := range reservation.Instances {
+					if instance.InstanceType == nil || instance.CpuOptions == nil {
+						continue
+					}
+					isSpot := instance.InstanceLifecycle != nil && *instance.InstanceLifecycle == ec2.InstanceLifecycleTypeSpot
+					if isSpot != spot {
+						continue
+					}
+					if class, ok := InstanceQuotaClass(*instance.InstanceType); !ok || class != quotaClass {
+						continue
+					}
+					vCPUs += aws.Int64Value(instance.CpuOptions.CoreCount) * aws.Int64Value(instance.CpuOptions.ThreadsPerCore)
+				}
+			}
+			return true
+		},
+	)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	return vCPUs, nil
+}
+
+// InstanceTypeAvailabilityZones returns the availability zones in which the instance type is offered
+func (c *PreflightClient) InstanceTypeAvailabilityZones(instanceType string) ([]string, error) {
+	var zones []string
+	input := &ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: aws.String(ec2.LocationTypeAvailabilityZone),
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("instance-type"),
+				Values: []*string{aws.String(instanceType)},
+			},
+		},
+	}
+
+	for {
+		output, err := c.ec2.DescribeInstanceTypeOfferings(input)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, offering := range output.InstanceTypeOfferings {
+			if offering.Location != nil {
+				zones = append(zones, *offering.Location)
+			}
+		}
+		if output.NextToken == nil || *output.NextToken == "" {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	return zones, nil
+}
+
+func (c *PreflightClient) AvailabilityZones() ([]string, error) {
+	result, err := c.ec2.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	zones := []string{}
+	for _, az := range result.AvailabilityZones {
+		if az.ZoneName != nil {
+			zones = append(zones, *az.ZoneName)
+		}
+	}
+	return zones, nil
+}
+
+func (c *PreflightClient) serviceQuota(serviceCode string, quotaCode string) (int64, error) {
+	output, err := c.serviceQuotas.GetServiceQuota(&servicequotas.GetServiceQuotaInput{
+		ServiceCode: aws.String(serviceCode),
+		QuotaCode:   aws.String(quotaCode),
+	})
+	if err == nil && output.Quota != nil && output.Quota.Value != nil {
+		return int64(*output.Quota.Value), nil
+	}
+	if err != nil && !CheckErrCode(err, servicequotas.ErrCodeNoSuchResourceException) {
+		return 0, errors.WithStack(err)
+	}
+
+	// the quota has not been modified for this account
+	defaultOutput, err := c.serviceQuotas.GetAWSDefaultServiceQuota(&servicequotas.GetAWSDefaultServiceQuotaInput{
+		ServiceCode: aws.String(serviceCode),
+		QuotaCode:   aws.String(quotaCode),
+	})
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return int64(aws.Float64Value(defaultOutput.Quota.Value)), nil
+}
+
+func (c *PreflightClient) ElasticIPQuota() (int64, error) {
+	return c.serviceQuota("ec2", _elasticIPQuotaCode)
+}
+
+func (c *PreflightClient) ElasticIPCount() (int64, error) {
+	result, err := c.ec2.DescribeAddresses(&ec2.DescribeAddressesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("domain"),
+				Values: []*string{aws.String(ec2.DomainTypeVpc)},
+			},
+		},
+	})
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return int64(len(result.Addresses)), nil
+}
+
+func (c *PreflightClient) VPCQuota() (int64, error) {
+	return c.serviceQuota("vpc", _vpcQuotaCode)
+}
+
+func (c *PreflightClient) VPCCount() (int64, error) {
+	var count int64
+	err := c.ec2.DescribeVpcsPages(&ec2.DescribeVpcsInput{}, func(page *ec2.DescribeVpcsOutput, lastPage bool) bool {
+		count += int64(len(page.Vpcs))
+		return true
+	})
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return count, nil
+}
@@ -32,16 +32,27 @@ var _instancePrefixRegex = regexp.MustCompile(`[a-zA-Z]+`)
 var _standardInstancePrefixes = strset.New("a", "c", "d", "h", "i", "m", "r", "t", "z")
 var _knownInstancePrefixes = strset.Union(_standardInstancePrefixes, strset.New("p", "g", "inf", "x", "f"))
 
-func VerifyInstanceQuota(accessKeyID, secretAccessKey, region, instanceType string) error {
+// InstanceQuotaClass returns the (lowercase) EC2 vCPU quota class of the instance type, e.g. "standard" for m5.large or "p" for p3.2xlarge
+// The second return value is false if the instance type's quota class is not known
+func InstanceQuotaClass(instanceType string) (string, bool) {
 	instancePrefix := _instancePrefixRegex.FindString(instanceType)
 
-	// Allow the instance if we don't recognize the type
 	if !_knownInstancePrefixes.Has(instancePrefix) {
-		return nil
+		return "", false
 	}
 
 	if _standardInstancePrefixes.Has(instancePrefix) {
-		instancePrefix = "standard"
+		return "standard", true
+	}
+	return instancePrefix, true
+}
+
+func VerifyInstanceQuota(accessKeyID, secretAccessKey, region, instanceType string) error {
+	instancePrefix, ok := InstanceQuotaClass(instanceType)
+
+	// Allow the instance if we don't recognize the type
+	if !ok {
+		return nil
 	}
 
 	sess, err := session.NewSession(&aws.Config{
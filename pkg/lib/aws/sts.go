@@ -17,12 +17,23 @@ limitations under the License.
 package aws
 
 import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
 )
 
 // Returns account ID, whether the credentials were valid, any other error that occurred
@@ -50,3 +61,132 @@ func AccountID(accessKeyID string, secretAccessKey string, region string) (strin
 
 	return *response.Account, true, nil
 }
+
+// SignedIdentityClusterHeader is signed into presigned GetCallerIdentity requests, so that a request is only accepted by the
+// operator of the cluster it was signed for (STS rejects the signature if the operator forwards a different cluster name)
+const SignedIdentityClusterHeader = "x-cortex-cluster"
+
+// SignedIdentityRequestExpiration is how long a presigned GetCallerIdentity request remains valid (the same as aws-iam-authenticator's tokens)
+const SignedIdentityRequestExpiration = 15 * time.Minute
+
+type CallerIdentity struct {
+	ARN       string `xml:"Arn"`
+	AccountID string `xml:"Account"`
+	UserID    string `xml:"UserId"`
+}
+
+type getCallerIdentityResponse struct {
+	Result CallerIdentity `xml:"GetCallerIdentityResult"`
+}
+
+var _stsHostRegex = regexp.MustCompile(`^sts(\.[a-z0-9-]+)?\.amazonaws\.com(\.cn)?$`)
+
+var _signedIdentityRequestParams = strset.New(
+	"Action",
+	"Version",
+	"X-Amz-Algorithm",
+	"X-Amz-Credential",
+	"X-Amz-Date",
+	"X-Amz-Expires",
+	"X-Amz-Security-Token",
+	"X-Amz-Signature",
+	"X-Amz-SignedHeaders",
+)
+
+// don't follow redirects, so that a presigned request can't be used to make the operator call other hosts
+var _signedIdentityClient = &http.Client{
+	Timeout: 10 * time.Second,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// PresignGetCallerIdentity returns a presigned STS GetCallerIdentity URL for the cluster, which proves the caller's identity without revealing their secret key
+func PresignGetCallerIdentity(creds *credentials.Credentials, region string, clusterName string) (string, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(region),
+		DisableSSL:  aws.Bool(false),
+		Credentials: creds,
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	request, _ := sts.New(sess).GetCallerIdentityRequest(&sts.GetCallerIdentityInput{})
+	request.HTTPRequest.Header.Set(SignedIdentityClusterHeader, clusterName)
+	presignedURL, err := request.Presign(SignedIdentityRequestExpiration)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return presignedURL, nil
+}
+
+// VerifyPresignedGetCallerIdentity sends a presigned GetCallerIdentity request to STS on behalf of the caller, with the cluster's name
+// Returns the caller's identity, whether the signature was valid (for this cluster), any other error that occurred
+func VerifyPresignedGetCallerIdentity(presignedURL string, clusterName string) (*CallerIdentity, bool, error) {
+	parsedURL, err := url.Parse(presignedURL)
+	if err != nil {
+		return nil, false, ErrorInvalidSignedIdentityRequest("unable to parse url")
+	}
+	if parsedURL.Scheme != "https" {
+		return nil, false, ErrorInvalidSignedIdentityRequest("url scheme must be https")
+	}
+	if !_stsHostRegex.MatchString(parsedURL.Host) {
+		return nil, false, ErrorInvalidSignedIdentityRequest(fmt.Sprintf("%s is not an STS endpoint", parsedURL.Host))
+	}
+	if parsedURL.Path != "/" && parsedURL.Path != "" {
+		return nil, false, ErrorInvalidSignedIdentityRequest("url path must be /")
+	}
+
+	query := parsedURL.Query()
+	for param, values := range query {
+		if !_signedIdentityRequestParams.Has(param) {
+			return nil, false, ErrorInvalidSignedIdentityRequest(fmt.Sprintf("unexpected query parameter %s", param))
+		}
+		if len(values) != 1 {
+			return nil, false, ErrorInvalidSignedIdentityRequest(fmt.Sprintf("query parameter %s must be specified once", param))
+		}
+	}
+	if query.Get("Action") != "GetCallerIdentity" {
+		return nil, false, ErrorInvalidSignedIdentityRequest("action must be GetCallerIdentity")
+	}
+	expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil || expires <= 0 || time.Duration(expires)*time.Second > SignedIdentityRequestExpiration {
+		return nil, false, ErrorInvalidSignedIdentityRequest(fmt.Sprintf("expiration must be at most %s", SignedIdentityRequestExpiration))
+	}
+	if !slices.HasString(strings.Split(query.Get("X-Amz-SignedHeaders"), ";"), SignedIdentityClusterHeader) {
+		return nil, false, ErrorInvalidSignedIdentityRequest(fmt.Sprintf("the %s header must be signed", SignedIdentityClusterHeader))
+	}
+
+	request, err := http.NewRequest(http.MethodGet, parsedURL.String(), nil)
+	if err != nil {
+		return nil, false, errors.WithStack(err)
+	}
+	request.Header.Set(SignedIdentityClusterHeader, clusterName)
+
+	response, err := _signedIdentityClient.Do(request)
+	if err != nil {
+		return nil, false, errors.WithStack(err)
+	}
+	defer response.Body.Close()
+
+	// STS responds with 403 for invalid, expired, or revoked credentials
+	if response.StatusCode == http.StatusForbidden {
+		return nil, false, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, false, errors.New(fmt.Sprintf("unexpected response from STS: %s", response.Status))
+	}
+
+	var identityResponse getCallerIdentityResponse
+	if err := xml.NewDecoder(response.Body).Decode(&identityResponse); err != nil {
+		return nil, false, errors.Wrap(err, "unable to parse response from STS")
+	}
+	identity := identityResponse.Result
+	if identity.ARN == "" || identity.AccountID == "" {
+		return nil, false, errors.New("unexpected response from STS: missing caller identity")
+	}
+
+	return &identity, true, nil
+}
@@ -38,6 +38,21 @@ var (
 	_maxInstancePools               = 20
 )
 
+const (
+	MTLSDisabled   = "disabled"
+	MTLSPermissive = "permissive"
+	MTLSStrict     = "strict"
+)
+
+var MTLSModes = []string{MTLSDisabled, MTLSPermissive, MTLSStrict}
+
+const (
+	APIPodSecurityRestricted   = "restricted"
+	APIPodSecurityUnrestricted = "unrestricted"
+)
+
+var APIPodSecurityModes = []string{APIPodSecurityRestricted, APIPodSecurityUnrestricted}
+
 type Config struct {
 	InstanceType           *string     `json:"instance_type" yaml:"instance_type"`
 	MinInstances           *int64      `json:"min_instances" yaml:"min_instances"`
@@ -50,6 +65,8 @@ type Config struct {
 	AvailabilityZones      []string    `json:"availability_zones" yaml:"availability_zones"`
 	Bucket                 *string     `json:"bucket" yaml:"bucket"`
 	LogGroup               string      `json:"log_group" yaml:"log_group"`
+	MTLS                   string      `json:"mtls" yaml:"mtls"`
+	APIPodSecurity         *string     `json:"api_pod_security" yaml:"api_pod_security"`
 	Telemetry              bool        `json:"telemetry" yaml:"telemetry"`
 	ImagePythonServe       string      `json:"image_python_serve" yaml:"image_python_serve"`
 	ImagePythonServeGPU    string      `json:"image_python_serve_gpu" yaml:"image_python_serve_gpu"`
@@ -70,6 +87,10 @@ type Config struct {
 	ImageIstioPilot        string      `json:"image_istio_pilot" yaml:"image_istio_pilot"`
 	ImageIstioCitadel      string      `json:"image_istio_citadel" yaml:"image_istio_citadel"`
 	ImageIstioGalley       string      `json:"image_istio_galley" yaml:"image_istio_galley"`
+
+	PythonPackageIndex *PythonPackageIndex `json:"python_package_index" yaml:"python_package_index"`
+	FreezeWindows      []*FreezeWindow     `json:"freeze_windows" yaml:"freeze_windows"`
+	PrepullImages      bool                `json:"prepull_images" yaml:"prepull_images"`
 }
 
 type SpotConfig struct {
@@ -212,6 +233,27 @@ var UserValidation = &cr.StructValidation{
 			StringValidation: &cr.StringValidation{},
 			DefaultField:     "ClusterName",
 		},
+		{
+			StructField: "MTLS",
+			StringValidation: &cr.StringValidation{
+				Default:       MTLSDisabled,
+				AllowedValues: MTLSModes,
+			},
+		},
+		{
+			StructField: "APIPodSecurity",
+			StringPtrValidation: &cr.StringPtrValidation{
+				AllowedValues: APIPodSecurityModes,
+			},
+		},
+		PythonPackageIndexValidation("PythonPackageIndex"),
+		FreezeWindowsValidation,
+		{
+			StructField: "PrepullImages",
+			BoolValidation: &cr.BoolValidation{
+				Default: true,
+			},
+		},
 		{
 			StructField: "ImagePythonServe",
 			StringValidation: &cr.StringValidation{
@@ -394,6 +436,14 @@ func (cc *Config) Validate(accessKeyID string, secretAccessKey string) error {
 		return ErrorMinInstancesGreaterThanMax(*cc.MinInstances, *cc.MaxInstances)
 	}
 
+	if err := cc.PythonPackageIndex.Validate(); err != nil {
+		return errors.Wrap(err, PythonPackageIndexKey)
+	}
+
+	if err := ValidateFreezeWindows(cc.FreezeWindows); err != nil {
+		return errors.Wrap(err, FreezeWindowsKey)
+	}
+
 	if _, ok := aws.InstanceMetadatas[*cc.Region][*cc.InstanceType]; !ok {
 		return errors.Wrap(ErrorInstanceTypeNotSupportedInRegion(*cc.InstanceType, *cc.Region), InstanceTypeKey)
 	}
@@ -868,7 +918,16 @@ func (cc *Config) UserFacingTable() table.KeyValuePairs {
 		items.Add(OnDemandBackupUserFacingKey, s.YesNo(*cc.SpotConfig.OnDemandBackup))
 	}
 	items.Add(LogGroupUserFacingKey, cc.LogGroup)
+	items.Add(MTLSUserFacingKey, cc.MTLS)
+	items.Add(APIPodSecurityUserFacingKey, cc.APIPodSecurityMode())
 	items.Add(TelemetryUserFacingKey, cc.Telemetry)
+	if cc.PythonPackageIndex != nil {
+		items.Add(PythonPackageIndexUserFacingKey, cc.PythonPackageIndex.UserFacingString())
+	}
+	if len(cc.FreezeWindows) > 0 {
+		items.Add(FreezeWindowsUserFacingKey, FreezeWindowsUserFacingString(cc.FreezeWindows))
+	}
+	items.Add(PrepullImagesUserFacingKey, s.YesNo(cc.PrepullImages))
 	items.Add(ImagePythonServeUserFacingKey, cc.ImagePythonServe)
 	items.Add(ImagePythonServeGPUUserFacingKey, cc.ImagePythonServeGPU)
 	items.Add(ImageTFServeUserFacingKey, cc.ImageTFServe)
@@ -895,3 +954,11 @@ func (cc *Config) UserFacingTable() table.KeyValuePairs {
 func (cc *Config) UserFacingString() string {
 	return cc.UserFacingTable().String()
 }
+
+// APIPodSecurityMode returns the pod security mode for APIs; clusters which were created before this setting existed are unrestricted
+func (cc *Config) APIPodSecurityMode() string {
+	if cc.APIPodSecurity == nil {
+		return APIPodSecurityUnrestricted
+	}
+	return *cc.APIPodSecurity
+}
@@ -34,7 +34,16 @@ const (
 	AvailabilityZonesKey                   = "availability_zones"
 	BucketKey                              = "bucket"
 	LogGroupKey                            = "log_group"
+	MTLSKey                                = "mtls"
+	APIPodSecurityKey                      = "api_pod_security"
 	TelemetryKey                           = "telemetry"
+	PythonPackageIndexKey                  = "python_package_index"
+	URLKey                                 = "url"
+	ExtraURLsKey                           = "extra_urls"
+	TrustedHostsKey                        = "trusted_hosts"
+	CredentialsSecretKey                   = "credentials_secret"
+	FreezeWindowsKey                       = "freeze_windows"
+	PrepullImagesKey                       = "prepull_images"
 	ImagePythonServeKey                    = "image_python_serve"
 	ImagePythonServeGPUKey                 = "image_python_serve_gpu"
 	ImageTFServeKey                        = "image_tf_serve"
@@ -73,7 +82,12 @@ const (
 	InstancePoolsUserFacingKey                       = "spot instance pools"
 	OnDemandBackupUserFacingKey                      = "on demand backup"
 	LogGroupUserFacingKey                            = "cloudwatch log group"
+	MTLSUserFacingKey                                = "mutual tls"
+	APIPodSecurityUserFacingKey                      = "api pod security"
 	TelemetryUserFacingKey                           = "telemetry"
+	PythonPackageIndexUserFacingKey                  = "python package index"
+	FreezeWindowsUserFacingKey                       = "freeze windows"
+	PrepullImagesUserFacingKey                       = "pre-pull serving images"
 	ImagePythonServeUserFacingKey                    = "python serving image"
 	ImagePythonServeGPUUserFacingKey                 = "python serving gpu image"
 	ImageTFServeUserFacingKey                        = "tensorflow serving image"
@@ -46,6 +46,14 @@ const (
 	ErrConfigCannotBeChangedOnUpdate
 	ErrInvalidAvailabilityZone
 	ErrInvalidInstanceType
+	ErrPreflightChecksFailed
+	ErrPackageIndexURLContainsCredentials
+	ErrPackageIndexURLNotSpecified
+	ErrInvalidFreezeTime
+	ErrFreezeTimeFormatMismatch
+	ErrFreezeWindowEndBeforeStart
+	ErrInvalidTimezone
+	ErrDuplicateFreezeWindowName
 )
 
 var (
@@ -68,10 +76,18 @@ var (
 		"err_config_cannot_be_changed_on_update",
 		"err_invalid_availability_zone",
 		"err_invalid_instance_type",
+		"err_preflight_checks_failed",
+		"err_package_index_url_contains_credentials",
+		"err_package_index_url_not_specified",
+		"err_invalid_freeze_time",
+		"err_freeze_time_format_mismatch",
+		"err_freeze_window_end_before_start",
+		"err_invalid_timezone",
+		"err_duplicate_freeze_window_name",
 	}
 )
 
-var _ = [1]int{}[int(ErrInvalidInstanceType)-(len(errorKinds)-1)] // Ensure list length matches
+var _ = [1]int{}[int(ErrDuplicateFreezeWindowName)-(len(errorKinds)-1)] // Ensure list length matches
 
 func (t ErrorKind) String() string {
 	return errorKinds[t]
@@ -235,3 +251,59 @@ func ErrorInvalidInstanceType(instanceType string) error {
 		message: fmt.Sprintf("%s is not a valid instance type", instanceType),
 	})
 }
+
+func ErrorPreflightChecksFailed(failedChecks []string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPreflightChecksFailed,
+		message: fmt.Sprintf("the following pre-flight checks failed: %s; please resolve them (e.g. by requesting a service quota increase in the aws console or changing your cluster configuration) and try again", s.StrsAnd(failedChecks)),
+	})
+}
+
+func ErrorPackageIndexURLContainsCredentials(indexURL string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPackageIndexURLContainsCredentials,
+		message: fmt.Sprintf("%s: credentials cannot be included in package index urls; store them in a kubernetes secret (with %s and %s keys) and reference it with %s instead", indexURL, PythonPackageIndexUsernameSecretKey, PythonPackageIndexPasswordSecretKey, CredentialsSecretKey),
+	})
+}
+
+func ErrorPackageIndexURLNotSpecified() error {
+	return errors.WithStack(Error{
+		Kind:    ErrPackageIndexURLNotSpecified,
+		message: fmt.Sprintf("at least one of %s or %s must be specified", URLKey, ExtraURLsKey),
+	})
+}
+
+func ErrorInvalidFreezeTime(str string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidFreezeTime,
+		message: fmt.Sprintf("%s is not a valid freeze time; specify a date and time (e.g. 2019-12-23 18:00), a weekday and time (e.g. Fri 18:00), or a time (e.g. 18:00)", s.UserStr(str)),
+	})
+}
+
+func ErrorFreezeTimeFormatMismatch(start string, end string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrFreezeTimeFormatMismatch,
+		message: fmt.Sprintf("start (%s) and end (%s) must have the same format (date and time, weekday and time, or time)", s.UserStr(start), s.UserStr(end)),
+	})
+}
+
+func ErrorFreezeWindowEndBeforeStart(start string, end string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrFreezeWindowEndBeforeStart,
+		message: fmt.Sprintf("end (%s) must be after start (%s)", s.UserStr(end), s.UserStr(start)),
+	})
+}
+
+func ErrorInvalidTimezone(timezone string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidTimezone,
+		message: fmt.Sprintf("%s is not a valid timezone; specify a timezone from the IANA time zone database (e.g. America/Los_Angeles or UTC)", s.UserStr(timezone)),
+	})
+}
+
+func ErrorDuplicateFreezeWindowName(name string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrDuplicateFreezeWindowName,
+		message: fmt.Sprintf("multiple freeze windows are named %s", s.UserStr(name)),
+	})
+}
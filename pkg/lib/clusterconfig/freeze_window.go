@@ -0,0 +1,369 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// Formats of a freeze window's start and end times; both must use the same format
+const (
+	FreezeDateTimeFormat = "2006-01-02 15:04" // a single window, e.g. 2019-12-23 00:00
+	FreezeWeeklyFormat   = "Mon 15:04"        // a window every week, e.g. Fri 18:00 (full weekday names are also accepted)
+	FreezeDailyFormat    = "15:04"            // a window every day, e.g. 22:00
+)
+
+type freezeRecurrence int
+
+const (
+	freezeOnce freezeRecurrence = iota
+	freezeWeekly
+	freezeDaily
+)
+
+// FreezeWindow is a period of time during which deployments can't be updated or deleted (unless the freeze is explicitly overridden)
+type FreezeWindow struct {
+	Name        string   `json:"name" yaml:"name"`
+	Start       string   `json:"start" yaml:"start"`
+	End         string   `json:"end" yaml:"end"`
+	Timezone    string   `json:"timezone" yaml:"timezone"`
+	Deployments []string `json:"deployments" yaml:"deployments"` // if empty, the window applies to all deployments
+}
+
+// FreezePeriod is a single occurrence of a freeze window
+type FreezePeriod struct {
+	Name        string    `json:"name"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Timezone    string    `json:"timezone"`
+	Deployments []string  `json:"deployments"`
+}
+
+type freezeTime struct {
+	recurrence freezeRecurrence
+	date       time.Time // only set for freezeOnce (year, month, and day in UTC, to be interpreted in the window's timezone)
+	weekday    time.Weekday
+	hour       int
+	minute     int
+}
+
+var FreezeWindowsValidation = &cr.StructFieldValidation{
+	StructField: "FreezeWindows",
+	StructListValidation: &cr.StructListValidation{
+		AllowExplicitNull: true,
+		StructValidation: &cr.StructValidation{
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Name",
+					StringValidation: &cr.StringValidation{
+						Required:                   true,
+						AlphaNumericDashUnderscore: true,
+					},
+				},
+				{
+					StructField: "Start",
+					StringValidation: &cr.StringValidation{
+						Required:  true,
+						Validator: validateFreezeTime,
+					},
+				},
+				{
+					StructField: "End",
+					StringValidation: &cr.StringValidation{
+						Required:  true,
+						Validator: validateFreezeTime,
+					},
+				},
+				{
+					StructField: "Timezone",
+					StringValidation: &cr.StringValidation{
+						Default:   "UTC",
+						Validator: validateTimezone,
+					},
+				},
+				{
+					StructField: "Deployments",
+					StringListValidation: &cr.StringListValidation{
+						AllowEmpty:   true,
+						DisallowDups: true,
+					},
+				},
+			},
+		},
+	},
+}
+
+func validateFreezeTime(str string) (string, error) {
+	if _, err := parseFreezeTime(str); err != nil {
+		return "", err
+	}
+	return str, nil
+}
+
+func validateTimezone(timezone string) (string, error) {
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return "", ErrorInvalidTimezone(timezone)
+	}
+	return timezone, nil
+}
+
+func parseFreezeTime(str string) (freezeTime, error) {
+	str = strings.TrimSpace(str)
+
+	if date, err := time.Parse(FreezeDateTimeFormat, str); err == nil {
+		return freezeTime{recurrence: freezeOnce, date: date, hour: date.Hour(), minute: date.Minute()}, nil
+	}
+
+	if clock, err := time.Parse(FreezeDailyFormat, str); err == nil {
+		return freezeTime{recurrence: freezeDaily, hour: clock.Hour(), minute: clock.Minute()}, nil
+	}
+
+	if fields := strings.Fields(str); len(fields) == 2 {
+		weekday, weekdayOK := parseWeekday(fields[0])
+		clock, err := time.Parse(FreezeDailyFormat, fields[1])
+		if weekdayOK && err == nil {
+			return freezeTime{recurrence: freezeWeekly, weekday: weekday, hour: clock.Hour(), minute: clock.Minute()}, nil
+		}
+	}
+
+	return freezeTime{}, ErrorInvalidFreezeTime(str)
+}
+
+func parseWeekday(str string) (time.Weekday, bool) {
+	str = strings.ToLower(str)
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		name := strings.ToLower(weekday.String())
+		if str == name || str == name[:3] {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
+
+func (fw *FreezeWindow) Validate() error {
+	start, err := parseFreezeTime(fw.Start)
+	if err != nil {
+		return err
+	}
+	end, err := parseFreezeTime(fw.End)
+	if err != nil {
+		return err
+	}
+	if start.recurrence != end.recurrence {
+		return ErrorFreezeTimeFormatMismatch(fw.Start, fw.End)
+	}
+	if _, err := validateTimezone(fw.Timezone); err != nil {
+		return err
+	}
+	if start.recurrence == freezeOnce {
+		location, _ := time.LoadLocation(fw.Timezone)
+		if !start.at(start.date, location).Before(end.at(end.date, location)) {
+			return ErrorFreezeWindowEndBeforeStart(fw.Start, fw.End)
+		}
+	}
+	return nil
+}
+
+func ValidateFreezeWindows(freezeWindows []*FreezeWindow) error {
+	names := strset.New()
+	for _, fw := range freezeWindows {
+		if names.Has(fw.Name) {
+			return ErrorDuplicateFreezeWindowName(fw.Name)
+		}
+		names.Add(fw.Name)
+
+		if err := fw.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// at returns the freeze time on the day of the given date
+func (ft freezeTime) at(date time.Time, location *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), ft.hour, ft.minute, 0, 0, location)
+}
+
+// periodStarting returns the period which starts at (or at the first end time after) start
+func (fw *FreezeWindow) periodStarting(start time.Time, end freezeTime, location *time.Location) FreezePeriod {
+	var endTime time.Time
+	switch end.recurrence {
+	case freezeOnce:
+		endTime = end.at(end.date, location)
+	case freezeWeekly:
+		daysUntilEnd := (int(end.weekday) - int(start.Weekday()) + 7) % 7
+		endTime = end.at(start.AddDate(0, 0, daysUntilEnd), location)
+		if !endTime.After(start) {
+			endTime = endTime.AddDate(0, 0, 7)
+		}
+	case freezeDaily:
+		endTime = end.at(start, location)
+		if !endTime.After(start) {
+			endTime = endTime.AddDate(0, 0, 1)
+		}
+	}
+
+	return FreezePeriod{
+		Name:        fw.Name,
+		Start:       start,
+		End:         endTime,
+		Timezone:    fw.Timezone,
+		Deployments: fw.Deployments,
+	}
+}
+
+// periods returns the occurrences of the window which are closest to now (including the current and the next one), sorted by start time
+func (fw *FreezeWindow) periods(now time.Time) []FreezePeriod {
+	start, err := parseFreezeTime(fw.Start)
+	if err != nil {
+		return nil
+	}
+	end, err := parseFreezeTime(fw.End)
+	if err != nil || start.recurrence != end.recurrence {
+		return nil
+	}
+	location, err := time.LoadLocation(fw.Timezone)
+	if err != nil {
+		return nil
+	}
+
+	var startTimes []time.Time
+	switch start.recurrence {
+	case freezeOnce:
+		startTimes = append(startTimes, start.at(start.date, location))
+	case freezeWeekly:
+		today := now.In(location)
+		weekStart := today.AddDate(0, 0, int(start.weekday)-int(today.Weekday()))
+		for _, weeks := range []int{-1, 0, 1} {
+			startTimes = append(startTimes, start.at(weekStart.AddDate(0, 0, 7*weeks), location))
+		}
+	case freezeDaily:
+		today := now.In(location)
+		for _, days := range []int{-1, 0, 1} {
+			startTimes = append(startTimes, start.at(today.AddDate(0, 0, days), location))
+		}
+	}
+
+	periods := make([]FreezePeriod, 0, len(startTimes))
+	for _, startTime := range startTimes {
+		periods = append(periods, fw.periodStarting(startTime, end, location))
+	}
+	return periods
+}
+
+// ActivePeriod returns the occurrence of the window which is in effect at the given time, if any
+func (fw *FreezeWindow) ActivePeriod(now time.Time) *FreezePeriod {
+	for _, period := range fw.periods(now) {
+		if period.Contains(now) {
+			return &period
+		}
+	}
+	return nil
+}
+
+// NextPeriod returns the first occurrence of the window which starts after the given time, if any
+func (fw *FreezeWindow) NextPeriod(now time.Time) *FreezePeriod {
+	for _, period := range fw.periods(now) {
+		if period.Start.After(now) {
+			return &period
+		}
+	}
+	return nil
+}
+
+// AppliesTo returns whether the window freezes the given deployment
+func (fw *FreezeWindow) AppliesTo(appName string) bool {
+	if len(fw.Deployments) == 0 {
+		return true
+	}
+	return strset.New(fw.Deployments...).Has(appName)
+}
+
+func (fw *FreezeWindow) UserFacingString() string {
+	str := fmt.Sprintf("%s: %s to %s (%s)", fw.Name, fw.Start, fw.End, fw.Timezone)
+	if len(fw.Deployments) > 0 {
+		str += " for " + s.StrsAnd(fw.Deployments)
+	}
+	return str
+}
+
+func FreezeWindowsUserFacingString(freezeWindows []*FreezeWindow) string {
+	freezeWindowStrs := make([]string, len(freezeWindows))
+	for i, fw := range freezeWindows {
+		freezeWindowStrs[i] = fw.UserFacingString()
+	}
+	return strings.Join(freezeWindowStrs, "; ")
+}
+
+func (period *FreezePeriod) Contains(t time.Time) bool {
+	return !t.Before(period.Start) && t.Before(period.End)
+}
+
+// FormatTime formats t in the timezone of the freeze window
+func (period *FreezePeriod) FormatTime(t time.Time) string {
+	if location, err := time.LoadLocation(period.Timezone); err == nil {
+		t = t.In(location)
+	}
+	return t.Format("Mon 2006-01-02 15:04 MST")
+}
+
+func (period *FreezePeriod) UserFacingString() string {
+	return fmt.Sprintf("%s (%s to %s)", period.Name, period.FormatTime(period.Start), period.FormatTime(period.End))
+}
+
+// ActiveFreezePeriods returns the freeze periods which are in effect at the given time for the given deployment (or for any deployment, if appName is empty)
+func ActiveFreezePeriods(freezeWindows []*FreezeWindow, now time.Time, appName string) []FreezePeriod {
+	var periods []FreezePeriod
+	for _, fw := range freezeWindows {
+		if appName != "" && !fw.AppliesTo(appName) {
+			continue
+		}
+		if period := fw.ActivePeriod(now); period != nil {
+			periods = append(periods, *period)
+		}
+	}
+	sortFreezePeriods(periods)
+	return periods
+}
+
+// UpcomingFreezePeriods returns the next occurrence of each freeze window which starts within the given duration
+func UpcomingFreezePeriods(freezeWindows []*FreezeWindow, now time.Time, within time.Duration) []FreezePeriod {
+	var periods []FreezePeriod
+	for _, fw := range freezeWindows {
+		if period := fw.NextPeriod(now); period != nil && period.Start.Before(now.Add(within)) {
+			periods = append(periods, *period)
+		}
+	}
+	sortFreezePeriods(periods)
+	return periods
+}
+
+func sortFreezePeriods(periods []FreezePeriod) {
+	sort.Slice(periods, func(i, j int) bool {
+		if periods[i].Start.Equal(periods[j].Start) {
+			return periods[i].Name < periods[j].Name
+		}
+		return periods[i].Start.Before(periods[j].Start)
+	})
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+func mustParseTime(t *testing.T, str string, timezone string) time.Time {
+	location, err := time.LoadLocation(timezone)
+	require.NoError(t, err)
+	parsed, err := time.ParseInLocation(FreezeDateTimeFormat, str, location)
+	require.NoError(t, err)
+	return parsed
+}
+
+func TestFreezeWindowsValidation(t *testing.T) {
+	var config Config
+	errs := cr.Struct(&config, cr.MustReadYAMLStr(`
+freeze_windows:
+  - name: holidays
+    start: 2019-12-23 00:00
+    end: 2020-01-02 09:00
+    timezone: America/New_York
+  - name: weekends
+    start: Fri 18:00
+    end: monday 06:00
+    deployments: [payments]
+  - name: nightly
+    start: 22:00
+    end: 06:00
+`), &cr.StructValidation{
+		StructFieldValidations: []*cr.StructFieldValidation{FreezeWindowsValidation},
+	})
+	require.Empty(t, errs)
+	require.Len(t, config.FreezeWindows, 3)
+	require.Equal(t, "America/New_York", config.FreezeWindows[0].Timezone)
+	require.Equal(t, "UTC", config.FreezeWindows[1].Timezone)
+	require.Equal(t, "22:00", config.FreezeWindows[2].Start)
+	require.NoError(t, ValidateFreezeWindows(config.FreezeWindows))
+
+	errs = cr.Struct(&config, cr.MustReadYAMLStr(`
+freeze_windows:
+  - name: weekends
+    start: Fri 25:00
+    end: Mon 06:00
+`), &cr.StructValidation{
+		StructFieldValidations: []*cr.StructFieldValidation{FreezeWindowsValidation},
+	})
+	require.Equal(t, ErrInvalidFreezeTime, errors.Cause(errors.FirstError(errs...)).(Error).Kind)
+
+	errs = cr.Struct(&config, cr.MustReadYAMLStr(`
+freeze_windows:
+  - name: weekends
+    start: Fri 18:00
+    end: Mon 06:00
+    timezone: Mars/Olympus_Mons
+`), &cr.StructValidation{
+		StructFieldValidations: []*cr.StructFieldValidation{FreezeWindowsValidation},
+	})
+	require.Equal(t, ErrInvalidTimezone, errors.Cause(errors.FirstError(errs...)).(Error).Kind)
+}
+
+func TestValidateFreezeWindows(t *testing.T) {
+	err := ValidateFreezeWindows([]*FreezeWindow{{Name: "a", Start: "Fri 18:00", End: "06:00", Timezone: "UTC"}})
+	require.Equal(t, ErrFreezeTimeFormatMismatch, errors.Cause(err).(Error).Kind)
+
+	err = ValidateFreezeWindows([]*FreezeWindow{{Name: "a", Start: "2019-12-23 00:00", End: "2019-12-22 00:00", Timezone: "UTC"}})
+	require.Equal(t, ErrFreezeWindowEndBeforeStart, errors.Cause(err).(Error).Kind)
+
+	err = ValidateFreezeWindows([]*FreezeWindow{
+		{Name: "a", Start: "22:00", End: "06:00", Timezone: "UTC"},
+		{Name: "a", Start: "Fri 18:00", End: "Mon 06:00", Timezone: "UTC"},
+	})
+	require.Equal(t, ErrDuplicateFreezeWindowName, errors.Cause(err).(Error).Kind)
+}
+
+func TestFreezeWindowOnce(t *testing.T) {
+	fw := &FreezeWindow{Name: "holidays", Start: "2019-12-23 00:00", End: "2020-01-02 09:00", Timezone: "America/New_York"}
+
+	require.Nil(t, fw.ActivePeriod(mustParseTime(t, "2019-12-22 23:59", "America/New_York")))
+	require.Nil(t, fw.ActivePeriod(mustParseTime(t, "2019-12-23 04:59", "UTC")))
+
+	period := fw.ActivePeriod(mustParseTime(t, "2019-12-23 05:00", "UTC"))
+	require.NotNil(t, period)
+	require.True(t, period.Start.Equal(mustParseTime(t, "2019-12-23 00:00", "America/New_York")))
+	require.True(t, period.End.Equal(mustParseTime(t, "2020-01-02 14:00", "UTC")))
+	require.Equal(t, "Thu 2020-01-02 09:00 EST", period.FormatTime(period.End))
+
+	require.Nil(t, fw.ActivePeriod(mustParseTime(t, "2020-01-02 09:00", "America/New_York")))
+
+	require.NotNil(t, fw.NextPeriod(mustParseTime(t, "2019-12-01 00:00", "UTC")))
+	require.Nil(t, fw.NextPeriod(mustParseTime(t, "2019-12-23 05:00", "UTC")))
+}
+
+func TestFreezeWindowWeekly(t *testing.T) {
+	fw := &FreezeWindow{Name: "weekends", Start: "Fri 18:00", End: "Mon 06:00", Timezone: "UTC"}
+
+	// 2019-12-20 is a Friday
+	require.Nil(t, fw.ActivePeriod(mustParseTime(t, "2019-12-20 17:59", "UTC")))
+	for _, now := range []string{"2019-12-20 18:00", "2019-12-22 12:00", "2019-12-23 05:59"} {
+		period := fw.ActivePeriod(mustParseTime(t, now, "UTC"))
+		require.NotNil(t, period, now)
+		require.True(t, period.Start.Equal(mustParseTime(t, "2019-12-20 18:00", "UTC")), now)
+		require.True(t, period.End.Equal(mustParseTime(t, "2019-12-23 06:00", "UTC")), now)
+	}
+	require.Nil(t, fw.ActivePeriod(mustParseTime(t, "2019-12-23 06:00", "UTC")))
+
+	next := fw.NextPeriod(mustParseTime(t, "2019-12-21 00:00", "UTC"))
+	require.True(t, next.Start.Equal(mustParseTime(t, "2019-12-27 18:00", "UTC")))
+	next = fw.NextPeriod(mustParseTime(t, "2019-12-23 06:00", "UTC"))
+	require.True(t, next.Start.Equal(mustParseTime(t, "2019-12-27 18:00", "UTC")))
+}
+
+func TestFreezeWindowDaily(t *testing.T) {
+	fw := &FreezeWindow{Name: "nightly", Start: "22:00", End: "06:00", Timezone: "Europe/Berlin"}
+
+	period := fw.ActivePeriod(mustParseTime(t, "2019-12-21 03:00", "Europe/Berlin"))
+	require.NotNil(t, period)
+	require.True(t, period.Start.Equal(mustParseTime(t, "2019-12-20 22:00", "Europe/Berlin")))
+	require.True(t, period.End.Equal(mustParseTime(t, "2019-12-21 06:00", "Europe/Berlin")))
+
+	require.Nil(t, fw.ActivePeriod(mustParseTime(t, "2019-12-21 12:00", "Europe/Berlin")))
+	next := fw.NextPeriod(mustParseTime(t, "2019-12-21 12:00", "Europe/Berlin"))
+	require.True(t, next.Start.Equal(mustParseTime(t, "2019-12-21 21:00", "UTC")))
+}
+
+func TestFreezePeriods(t *testing.T) {
+	freezeWindows := []*FreezeWindow{
+		{Name: "weekends", Start: "Fri 18:00", End: "Mon 06:00", Timezone: "UTC", Deployments: []string{"payments"}},
+		{Name: "holidays", Start: "2019-12-21 00:00", End: "2020-01-02 00:00", Timezone: "UTC"},
+		{Name: "launch", Start: "2020-03-01 00:00", End: "2020-03-02 00:00", Timezone: "UTC"},
+	}
+	now := mustParseTime(t, "2019-12-20 20:00", "UTC")
+
+	active := ActiveFreezePeriods(freezeWindows, now, "payments")
+	require.Len(t, active, 1)
+	require.Equal(t, "weekends", active[0].Name)
+	require.Empty(t, ActiveFreezePeriods(freezeWindows, now, "search"))
+	require.Len(t, ActiveFreezePeriods(freezeWindows, now, ""), 1)
+
+	upcoming := UpcomingFreezePeriods(freezeWindows, now, 30*24*time.Hour)
+	require.Len(t, upcoming, 2)
+	require.Equal(t, "holidays", upcoming[0].Name)
+	require.Equal(t, "weekends", upcoming[1].Name)
+}
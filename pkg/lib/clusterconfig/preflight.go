@@ -0,0 +1,317 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+)
+
+const (
+	_operatorInstanceVCPUs = 2 // the operator runs on a t3.medium (see manager/generate_eks.py)
+	_minAvailabilityZones  = 2 // eksctl requires at least two availability zones
+)
+
+type PreflightStatus string
+
+const (
+	PreflightPass PreflightStatus = "pass"
+	PreflightWarn PreflightStatus = "warning"
+	PreflightFail PreflightStatus = "fail"
+)
+
+type PreflightCheck struct {
+	Name    string
+	Status  PreflightStatus
+	Message string
+}
+
+// PreflightAWS provides the AWS lookups used by the pre-flight checks (implemented by aws.PreflightClient)
+type PreflightAWS interface {
+	VCPUQuota(quotaClass string, spot bool) (int64, bool, error)
+	RunningVCPUs(quotaClass string, spot bool) (int64, error)
+	AvailabilityZones() ([]string, error)
+	InstanceTypeAvailabilityZones(instanceType string) ([]string, error)
+	ElasticIPQuota() (int64, error)
+	ElasticIPCount() (int64, error)
+	VPCQuota() (int64, error)
+	VPCCount() (int64, error)
+}
+
+var _ PreflightAWS = (*aws.PreflightClient)(nil)
+
+// PreflightChecks checks the cluster configuration against the account's quotas, instance type offerings, and existing resources
+// When updating an existing cluster, resources which were already created for the cluster are not checked
+func PreflightChecks(cc *Config, client PreflightAWS, isUpdate bool) []PreflightCheck {
+	var checks []PreflightCheck
+
+	onDemandInstances, spotInstances := cc.maxInstancesByLifecycle()
+	minOnDemandInstances, minSpotInstances := cc.minInstancesByLifecycle()
+
+	vCPUs := instanceVCPUs(*cc.Region, *cc.InstanceType)
+	quotaClass, hasQuotaClass := aws.InstanceQuotaClass(*cc.InstanceType)
+
+	if hasQuotaClass {
+		required := onDemandInstances * vCPUs
+		minRequired := minOnDemandInstances * vCPUs
+		if !isUpdate && quotaClass == "standard" {
+			required += _operatorInstanceVCPUs
+			minRequired += _operatorInstanceVCPUs
+		}
+		if required > 0 {
+			checks = append(checks, vCPUQuotaCheck(client, quotaClass, false, required, minRequired, isUpdate))
+		}
+
+		if spotInstances > 0 {
+			checks = append(checks, vCPUQuotaCheck(client, quotaClass, true, spotInstances*vCPUs, minSpotInstances*vCPUs, isUpdate))
+		}
+	}
+
+	instanceTypes := []string{*cc.InstanceType}
+	if cc.Spot != nil && *cc.Spot && cc.SpotConfig != nil {
+		for _, instanceType := range cc.SpotConfig.InstanceDistribution {
+			if instanceType != *cc.InstanceType {
+				instanceTypes = append(instanceTypes, instanceType)
+			}
+		}
+	}
+	for i, instanceType := range instanceTypes {
+		checks = append(checks, instanceTypeOfferingCheck(client, cc.AvailabilityZones, instanceType, i == 0))
+	}
+
+	if !isUpdate {
+		// eksctl creates a new VPC with a NAT gateway (which requires an elastic IP)
+		checks = append(checks, resourceLimitCheck("elastic ips", client.ElasticIPQuota, client.ElasticIPCount, 1))
+		checks = append(checks, resourceLimitCheck("vpcs", client.VPCQuota, client.VPCCount, 1))
+	}
+
+	return checks
+}
+
+func vCPUQuotaCheck(client PreflightAWS, quotaClass string, spot bool, required int64, minRequired int64, isUpdate bool) PreflightCheck {
+	lifecycle := "on-demand"
+	if spot {
+		lifecycle = "spot"
+	}
+	check := PreflightCheck{Name: fmt.Sprintf("%s %s vcpu quota", quotaClass, lifecycle)}
+
+	quota, found, err := client.VCPUQuota(quotaClass, spot)
+	if err != nil {
+		check.Status = PreflightWarn
+		check.Message = "unable to check: " + err.Error()
+		return check
+	}
+	if !found {
+		check.Status = PreflightWarn
+		check.Message = "unable to find the quota for this instance class"
+		return check
+	}
+
+	available := quota
+	if !isUpdate {
+		running, err := client.RunningVCPUs(quotaClass, spot)
+		if err != nil {
+			check.Status = PreflightWarn
+			check.Message = "unable to check current usage: " + err.Error()
+			return check
+		}
+		available = quota - running
+	}
+
+	if available < 0 {
+		available = 0
+	}
+
+	switch {
+	case available < minRequired || available == 0:
+		check.Status = PreflightFail
+		check.Message = fmt.Sprintf("%d vcpus are required but only %d of %d are available", minRequired, available, quota)
+	case available < required:
+		check.Status = PreflightWarn
+		check.Message = fmt.Sprintf("%d vcpus are available (of %d), which is not enough to scale to max instances (%d vcpus)", available, quota, required)
+	default:
+		check.Status = PreflightPass
+		check.Message = fmt.Sprintf("%d vcpus required, %d available (of %d)", required, available, quota)
+	}
+	return check
+}
+
+func instanceTypeOfferingCheck(client PreflightAWS, configuredZones []string, instanceType string, isPrimary bool) PreflightCheck {
+	check := PreflightCheck{Name: fmt.Sprintf("%s availability", instanceType)}
+
+	offeredZones, err := client.InstanceTypeAvailabilityZones(instanceType)
+	if err != nil {
+		check.Status = PreflightWarn
+		check.Message = "unable to check: " + err.Error()
+		return check
+	}
+	offeredZoneSet := strset.New(offeredZones...)
+
+	// a missing spot instance type in the distribution reduces capacity, but doesn't prevent the cluster from being created
+	failStatus := PreflightFail
+	if !isPrimary {
+		failStatus = PreflightWarn
+	}
+
+	if len(configuredZones) > 0 {
+		var missingZones []string
+		for _, zone := range configuredZones {
+			if !offeredZoneSet.Has(zone) {
+				missingZones = append(missingZones, zone)
+			}
+		}
+		if len(missingZones) > 0 {
+			check.Status = failStatus
+			check.Message = fmt.Sprintf("not offered in %s (offered in %s)", s.StrsAnd(missingZones), zonesStr(offeredZones))
+			return check
+		}
+		check.Status = PreflightPass
+		check.Message = "offered in " + s.StrsAnd(configuredZones)
+		return check
+	}
+
+	allZones, err := client.AvailabilityZones()
+	if err != nil {
+		check.Status = PreflightWarn
+		check.Message = "unable to check: " + err.Error()
+		return check
+	}
+
+	if len(offeredZones) < _minAvailabilityZones {
+		check.Status = failStatus
+		check.Message = fmt.Sprintf("offered in %s, but at least %d availability zones are required", zonesStr(offeredZones), _minAvailabilityZones)
+		return check
+	}
+
+	if len(offeredZones) < len(allZones) {
+		check.Status = PreflightWarn
+		check.Message = fmt.Sprintf("only offered in %s; set %s to these zones to avoid placing the cluster in a zone without this instance type", zonesStr(offeredZones), AvailabilityZonesKey)
+		return check
+	}
+
+	check.Status = PreflightPass
+	check.Message = "offered in all availability zones"
+	return check
+}
+
+func resourceLimitCheck(name string, quotaFn func() (int64, error), countFn func() (int64, error), required int64) PreflightCheck {
+	check := PreflightCheck{Name: name}
+
+	quota, err := quotaFn()
+	if err != nil {
+		check.Status = PreflightWarn
+		check.Message = "unable to check: " + err.Error()
+		return check
+	}
+	count, err := countFn()
+	if err != nil {
+		check.Status = PreflightWarn
+		check.Message = "unable to check current usage: " + err.Error()
+		return check
+	}
+
+	if quota-count < required {
+		check.Status = PreflightFail
+		check.Message = fmt.Sprintf("%d required, but %d of %d are already in use", required, count, quota)
+		return check
+	}
+
+	check.Status = PreflightPass
+	check.Message = fmt.Sprintf("%d required, %d of %d in use", required, count, quota)
+	return check
+}
+
+// Returns the maximum number of on-demand and spot worker instances
+func (cc *Config) maxInstancesByLifecycle() (int64, int64) {
+	return cc.instancesByLifecycle(*cc.MaxInstances)
+}
+
+// Returns the minimum number of on-demand and spot worker instances
+func (cc *Config) minInstancesByLifecycle() (int64, int64) {
+	return cc.instancesByLifecycle(*cc.MinInstances)
+}
+
+func (cc *Config) instancesByLifecycle(numInstances int64) (int64, int64) {
+	if cc.Spot == nil || !*cc.Spot || cc.SpotConfig == nil {
+		return numInstances, 0
+	}
+
+	var baseCapacity, percentageAboveBase int64
+	if cc.SpotConfig.OnDemandBaseCapacity != nil {
+		baseCapacity = *cc.SpotConfig.OnDemandBaseCapacity
+	}
+	if cc.SpotConfig.OnDemandPercentageAboveBaseCapacity != nil {
+		percentageAboveBase = *cc.SpotConfig.OnDemandPercentageAboveBaseCapacity
+	}
+
+	if numInstances <= baseCapacity {
+		return numInstances, 0
+	}
+
+	onDemand := baseCapacity + int64(math.Ceil(float64((numInstances-baseCapacity)*percentageAboveBase)/100))
+	return onDemand, numInstances - onDemand
+}
+
+func instanceVCPUs(region string, instanceType string) int64 {
+	instanceMetadata, ok := aws.InstanceMetadatas[region][instanceType]
+	if !ok {
+		return 0
+	}
+	return instanceMetadata.CPU.Value()
+}
+
+func zonesStr(zones []string) string {
+	if len(zones) == 0 {
+		return "no availability zones"
+	}
+	sorted := append([]string{}, zones...)
+	sort.Strings(sorted)
+	return s.StrsAnd(sorted)
+}
+
+func PreflightChecksTable(checks []PreflightCheck) table.Table {
+	rows := make([][]interface{}, len(checks))
+	for i, check := range checks {
+		rows[i] = []interface{}{check.Name, string(check.Status), check.Message}
+	}
+
+	return table.Table{
+		Headers: []table.Header{
+			{Title: "check"},
+			{Title: "status"},
+			{Title: "details"},
+		},
+		Rows: rows,
+	}
+}
+
+// FailedPreflightChecks returns the names of the checks which failed
+func FailedPreflightChecks(checks []PreflightCheck) []string {
+	var failed []string
+	for _, check := range checks {
+		if check.Status == PreflightFail {
+			failed = append(failed, check.Name)
+		}
+	}
+	return failed
+}
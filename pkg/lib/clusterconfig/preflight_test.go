@@ -0,0 +1,209 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+)
+
+type fakePreflightAWS struct {
+	vCPUQuotas    map[string]int64 // "<class>/<spot>" -> quota
+	runningVCPUs  map[string]int64
+	zones         []string
+	offeredZones  map[string][]string
+	eipQuota      int64
+	eipCount      int64
+	vpcQuota      int64
+	vpcCount      int64
+	vpcQuotaError error
+}
+
+func fakeQuotaKey(quotaClass string, spot bool) string {
+	if spot {
+		return quotaClass + "/spot"
+	}
+	return quotaClass + "/ondemand"
+}
+
+func (f *fakePreflightAWS) VCPUQuota(quotaClass string, spot bool) (int64, bool, error) {
+	quota, ok := f.vCPUQuotas[fakeQuotaKey(quotaClass, spot)]
+	return quota, ok, nil
+}
+
+func (f *fakePreflightAWS) RunningVCPUs(quotaClass string, spot bool) (int64, error) {
+	return f.runningVCPUs[fakeQuotaKey(quotaClass, spot)], nil
+}
+
+func (f *fakePreflightAWS) AvailabilityZones() ([]string, error) {
+	return f.zones, nil
+}
+
+func (f *fakePreflightAWS) InstanceTypeAvailabilityZones(instanceType string) ([]string, error) {
+	return f.offeredZones[instanceType], nil
+}
+
+func (f *fakePreflightAWS) ElasticIPQuota() (int64, error) {
+	return f.eipQuota, nil
+}
+
+func (f *fakePreflightAWS) ElasticIPCount() (int64, error) {
+	return f.eipCount, nil
+}
+
+func (f *fakePreflightAWS) VPCQuota() (int64, error) {
+	return f.vpcQuota, f.vpcQuotaError
+}
+
+func (f *fakePreflightAWS) VPCCount() (int64, error) {
+	return f.vpcCount, nil
+}
+
+func newFakePreflightAWS() *fakePreflightAWS {
+	return &fakePreflightAWS{
+		vCPUQuotas:   map[string]int64{"standard/ondemand": 64, "p/ondemand": 16},
+		runningVCPUs: map[string]int64{"standard/ondemand": 8},
+		zones:        []string{"us-west-2a", "us-west-2b", "us-west-2c"},
+		offeredZones: map[string][]string{
+			"m5.large":   {"us-west-2a", "us-west-2b", "us-west-2c"},
+			"p3.2xlarge": {"us-west-2b", "us-west-2c"},
+		},
+		eipQuota: 5,
+		eipCount: 2,
+		vpcQuota: 5,
+		vpcCount: 1,
+	}
+}
+
+func preflightTestConfig(instanceType string, minInstances int64, maxInstances int64) *Config {
+	return &Config{
+		Region:       pointer.String("us-west-2"),
+		InstanceType: pointer.String(instanceType),
+		MinInstances: pointer.Int64(minInstances),
+		MaxInstances: pointer.Int64(maxInstances),
+		Spot:         pointer.Bool(false),
+	}
+}
+
+func checkStatuses(checks []PreflightCheck) map[string]PreflightStatus {
+	statuses := map[string]PreflightStatus{}
+	for _, check := range checks {
+		statuses[check.Name] = check.Status
+	}
+	return statuses
+}
+
+func TestPreflightChecksPass(t *testing.T) {
+	checks := PreflightChecks(preflightTestConfig("m5.large", 1, 5), newFakePreflightAWS(), false)
+
+	require.Equal(t, map[string]PreflightStatus{
+		"standard on-demand vcpu quota": PreflightPass,
+		"m5.large availability":         PreflightPass,
+		"elastic ips":                   PreflightPass,
+		"vpcs":                          PreflightPass,
+	}, checkStatuses(checks))
+	require.Empty(t, FailedPreflightChecks(checks))
+}
+
+func TestPreflightChecksVCPUQuota(t *testing.T) {
+	client := newFakePreflightAWS()
+
+	// 2 operator vcpus + 8 running vcpus + 2*10 worker vcpus fits in 64, but 2*30 doesn't
+	checks := PreflightChecks(preflightTestConfig("m5.large", 10, 30), client, false)
+	require.Equal(t, PreflightWarn, checkStatuses(checks)["standard on-demand vcpu quota"])
+
+	checks = PreflightChecks(preflightTestConfig("m5.large", 30, 30), client, false)
+	require.Equal(t, PreflightFail, checkStatuses(checks)["standard on-demand vcpu quota"])
+	require.Equal(t, []string{"standard on-demand vcpu quota"}, FailedPreflightChecks(checks))
+
+	// running instances are not subtracted on update, since they may belong to the cluster
+	checks = PreflightChecks(preflightTestConfig("m5.large", 30, 30), client, true)
+	require.Equal(t, PreflightPass, checkStatuses(checks)["standard on-demand vcpu quota"])
+	require.NotContains(t, checkStatuses(checks), "vpcs")
+
+	// no spot quota is checked for on-demand clusters
+	checks = PreflightChecks(preflightTestConfig("p3.2xlarge", 1, 2), client, false)
+	require.Equal(t, PreflightPass, checkStatuses(checks)["p on-demand vcpu quota"])
+	require.NotContains(t, checkStatuses(checks), "p spot vcpu quota")
+}
+
+func TestPreflightChecksSpot(t *testing.T) {
+	client := newFakePreflightAWS()
+
+	cc := preflightTestConfig("m5.large", 1, 10)
+	cc.Spot = pointer.Bool(true)
+	cc.SpotConfig = &SpotConfig{
+		InstanceDistribution:                []string{"m5.large", "p3.2xlarge"},
+		OnDemandBaseCapacity:                pointer.Int64(0),
+		OnDemandPercentageAboveBaseCapacity: pointer.Int64(0),
+	}
+
+	onDemand, spot := cc.maxInstancesByLifecycle()
+	require.Equal(t, int64(0), onDemand)
+	require.Equal(t, int64(10), spot)
+
+	checks := PreflightChecks(cc, client, false)
+	statuses := checkStatuses(checks)
+	require.Equal(t, PreflightPass, statuses["standard on-demand vcpu quota"]) // operator node only
+	require.Equal(t, PreflightWarn, statuses["standard spot vcpu quota"])      // quota not found
+	require.Equal(t, PreflightWarn, statuses["p3.2xlarge availability"])       // not offered in every zone
+
+	cc.SpotConfig.OnDemandBaseCapacity = pointer.Int64(2)
+	cc.SpotConfig.OnDemandPercentageAboveBaseCapacity = pointer.Int64(50)
+	onDemand, spot = cc.maxInstancesByLifecycle()
+	require.Equal(t, int64(6), onDemand)
+	require.Equal(t, int64(4), spot)
+}
+
+func TestPreflightChecksAvailabilityZones(t *testing.T) {
+	client := newFakePreflightAWS()
+
+	cc := preflightTestConfig("p3.2xlarge", 1, 1)
+	checks := PreflightChecks(cc, client, false)
+	require.Equal(t, PreflightWarn, checkStatuses(checks)["p3.2xlarge availability"])
+
+	cc.AvailabilityZones = []string{"us-west-2a", "us-west-2b"}
+	checks = PreflightChecks(cc, client, false)
+	require.Equal(t, PreflightFail, checkStatuses(checks)["p3.2xlarge availability"])
+
+	cc.AvailabilityZones = []string{"us-west-2b", "us-west-2c"}
+	checks = PreflightChecks(cc, client, false)
+	require.Equal(t, PreflightPass, checkStatuses(checks)["p3.2xlarge availability"])
+
+	client.offeredZones["p3.2xlarge"] = []string{"us-west-2c"}
+	cc.AvailabilityZones = nil
+	checks = PreflightChecks(cc, client, false)
+	require.Equal(t, PreflightFail, checkStatuses(checks)["p3.2xlarge availability"])
+}
+
+func TestPreflightChecksResourceLimits(t *testing.T) {
+	client := newFakePreflightAWS()
+	client.eipCount = 5
+
+	checks := PreflightChecks(preflightTestConfig("m5.large", 1, 5), client, false)
+	require.Equal(t, PreflightFail, checkStatuses(checks)["elastic ips"])
+
+	// lookup errors don't block cluster creation
+	client.vpcQuotaError = errors.New("access denied")
+	checks = PreflightChecks(preflightTestConfig("m5.large", 1, 5), client, false)
+	require.Equal(t, PreflightWarn, checkStatuses(checks)["vpcs"])
+	require.Equal(t, []string{"elastic ips"}, FailedPreflightChecks(checks))
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"net/url"
+	"strings"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+)
+
+// Keys of the Kubernetes secret referenced by credentials_secret
+const (
+	PythonPackageIndexUsernameSecretKey = "username"
+	PythonPackageIndexPasswordSecretKey = "password"
+)
+
+// PythonPackageIndex configures where pip installs requirements.txt from; credentials are never stored in the config, only the name of the Kubernetes secret which holds them
+type PythonPackageIndex struct {
+	URL               *string  `json:"url" yaml:"url"`
+	ExtraURLs         []string `json:"extra_urls" yaml:"extra_urls"`
+	TrustedHosts      []string `json:"trusted_hosts" yaml:"trusted_hosts"`
+	CredentialsSecret *string  `json:"credentials_secret" yaml:"credentials_secret"`
+}
+
+func PythonPackageIndexValidation(structField string) *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: structField,
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "URL",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Validator: validatePackageIndexURL,
+					},
+				},
+				{
+					StructField: "ExtraURLs",
+					StringListValidation: &cr.StringListValidation{
+						AllowEmpty:   true,
+						DisallowDups: true,
+						Validator: func(indexURLs []string) ([]string, error) {
+							for _, indexURL := range indexURLs {
+								if _, err := validatePackageIndexURL(indexURL); err != nil {
+									return nil, err
+								}
+							}
+							return indexURLs, nil
+						},
+					},
+				},
+				{
+					StructField: "TrustedHosts",
+					StringListValidation: &cr.StringListValidation{
+						AllowEmpty:   true,
+						DisallowDups: true,
+					},
+				},
+				{
+					StructField: "CredentialsSecret",
+					StringPtrValidation: &cr.StringPtrValidation{
+						DNS1123: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func validatePackageIndexURL(indexURL string) (string, error) {
+	parsed, err := urls.Parse(indexURL)
+	if err != nil {
+		return "", err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", urls.ErrorInvalidURL(indexURL)
+	}
+	if parsed.User != nil {
+		return "", ErrorPackageIndexURLContainsCredentials(urlWithoutCredentials(parsed))
+	}
+	return indexURL, nil
+}
+
+func urlWithoutCredentials(parsed *url.URL) string {
+	withoutCredentials := *parsed
+	withoutCredentials.User = nil
+	return withoutCredentials.String()
+}
+
+func (index *PythonPackageIndex) Validate() error {
+	if index == nil {
+		return nil
+	}
+	if index.URL == nil && len(index.ExtraURLs) == 0 {
+		return ErrorPackageIndexURLNotSpecified()
+	}
+	return nil
+}
+
+// URLs returns the index URLs, starting with the primary index (if specified)
+func (index *PythonPackageIndex) URLs() []string {
+	var indexURLs []string
+	if index.URL != nil {
+		indexURLs = append(indexURLs, *index.URL)
+	}
+	return append(indexURLs, index.ExtraURLs...)
+}
+
+func (index *PythonPackageIndex) UserFacingString() string {
+	if index == nil {
+		return ""
+	}
+	str := strings.Join(index.URLs(), ", ")
+	if index.CredentialsSecret != nil {
+		str += " (credentials from secret " + *index.CredentialsSecret + ")"
+	}
+	return str
+}
@@ -0,0 +1,307 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configreader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+type SourcePosition struct {
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+func (position SourcePosition) String() string {
+	if position.FilePath == "" {
+		return fmt.Sprintf("line %d, column %d", position.Line, position.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", position.FilePath, position.Line, position.Column)
+}
+
+// YAMLPositions maps key paths (e.g. ["2", "compute", "cpu"]) to their location in a YAML file
+type YAMLPositions struct {
+	filePath     string
+	sourceBytes  []byte
+	positions    map[string]SourcePosition
+	keyPositions map[string]SourcePosition // positions of the keys themselves (rather than their values)
+}
+
+func ReadYAMLPositions(filePath string, yamlBytes []byte) (*YAMLPositions, error) {
+	positions := &YAMLPositions{
+		filePath:     filePath,
+		sourceBytes:  yamlBytes,
+		positions:    map[string]SourcePosition{},
+		keyPositions: map[string]SourcePosition{},
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(yamlBytes, &root); err != nil {
+		return nil, ErrorInvalidYAML(err)
+	}
+
+	positions.add(nil, &root)
+	return positions, nil
+}
+
+func keyPathStr(keyPath []string) string {
+	return strings.Join(keyPath, "\x00")
+}
+
+func (positions *YAMLPositions) position(node *yaml.Node) SourcePosition {
+	return SourcePosition{
+		FilePath: positions.filePath,
+		Line:     node.Line,
+		Column:   node.Column,
+	}
+}
+
+func (positions *YAMLPositions) add(keyPath []string, node *yaml.Node) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			positions.add(keyPath, child)
+		}
+	case yaml.AliasNode:
+		if node.Alias != nil {
+			positions.add(keyPath, node.Alias)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			childPath := append(append([]string{}, keyPath...), keyNode.Value)
+			positions.keyPositions[keyPathStr(childPath)] = positions.position(keyNode)
+			// point to scalar values (e.g. an invalid cpu request), and to the key for everything else
+			if valueNode.Kind == yaml.ScalarNode && valueNode.Value != "" {
+				positions.positions[keyPathStr(childPath)] = positions.position(valueNode)
+			} else {
+				positions.positions[keyPathStr(childPath)] = positions.position(keyNode)
+			}
+			positions.add(childPath, valueNode)
+		}
+	case yaml.SequenceNode:
+		for i, itemNode := range node.Content {
+			childPath := append(append([]string{}, keyPath...), s.Int(i))
+			positions.positions[keyPathStr(childPath)] = positions.position(itemNode)
+			positions.add(childPath, itemNode)
+		}
+	}
+}
+
+// Lookup returns the position of the deepest key in keyPath which exists in the file (nil if none exist)
+func (positions *YAMLPositions) Lookup(keyPath ...string) *SourcePosition {
+	if positions == nil {
+		return nil
+	}
+	for n := len(keyPath); n > 0; n-- {
+		if position, ok := positions.positions[keyPathStr(keyPath[:n])]; ok {
+			return &position
+		}
+	}
+	return nil
+}
+
+// Attach adds the source position of the error's key path (prefixed with keyPathPrefix) to err
+func (positions *YAMLPositions) Attach(err error, keyPathPrefix ...string) error {
+	if positions == nil || err == nil || GetSourcePosition(err) != nil {
+		return err
+	}
+
+	keyPath := append(append([]string{}, keyPathPrefix...), KeyPath(err)...)
+	position := positions.Lookup(keyPath...)
+	if keyPosition, ok := positions.keyPositions[keyPathStr(keyPath)]; ok && pointsToKey(err) {
+		position = &keyPosition
+	}
+	if position == nil {
+		return err
+	}
+
+	return WithSourcePosition(err, *position, positions.sourceBytes)
+}
+
+func (positions *YAMLPositions) AttachAll(errs []error, keyPathPrefix ...string) []error {
+	if !errors.HasErrors(errs) {
+		return errs
+	}
+	attachedErrs := make([]error, len(errs))
+	for i, err := range errs {
+		attachedErrs[i] = positions.Attach(err, keyPathPrefix...)
+	}
+	return attachedErrs
+}
+
+// keyError records the config key which an error belongs to; its message is unchanged
+type keyError struct {
+	error
+	key        string
+	pointToKey bool // e.g. for unsupported keys, point to the key rather than its value
+}
+
+func (err *keyError) Cause() error {
+	return err.error
+}
+
+// WithKey records that err belongs to key (without changing the error message, see KeyPath())
+func WithKey(err error, key string) error {
+	if err == nil {
+		return nil
+	}
+	return &keyError{error: err, key: key}
+}
+
+func withKeyItself(err error, key string) error {
+	if err == nil {
+		return nil
+	}
+	return &keyError{error: err, key: key, pointToKey: true}
+}
+
+func WithIndex(err error, index int) error {
+	return WithKey(err, s.Int(index))
+}
+
+func withKeyAll(errs []error, key string) []error {
+	if !errors.HasErrors(errs) {
+		return errs
+	}
+	keyedErrs := make([]error, len(errs))
+	for i, err := range errs {
+		keyedErrs[i] = WithKey(err, key)
+	}
+	return keyedErrs
+}
+
+func withIndexAll(errs []error, index int) []error {
+	return withKeyAll(errs, s.Int(index))
+}
+
+// KeyPath returns the keys recorded with WithKey(), from outermost to innermost
+func KeyPath(err error) []string {
+	var keyPath []string
+	for err != nil {
+		if keyErr, ok := err.(*keyError); ok {
+			keyPath = append(keyPath, keyErr.key)
+		}
+		causer, ok := err.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		err = causer.Cause()
+	}
+	return keyPath
+}
+
+// pointsToKey returns whether the innermost key recorded with WithKey() should point to the key itself
+func pointsToKey(err error) bool {
+	pointToKey := false
+	for err != nil {
+		if keyErr, ok := err.(*keyError); ok {
+			pointToKey = keyErr.pointToKey
+		}
+		causer, ok := err.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		err = causer.Cause()
+	}
+	return pointToKey
+}
+
+type sourcePositionError struct {
+	error
+	position SourcePosition
+	snippet  string
+}
+
+func (err *sourcePositionError) Cause() error {
+	return err.error
+}
+
+// ErrorDetails is printed below the error message by errors.PrintError()
+func (err *sourcePositionError) ErrorDetails() string {
+	return err.snippet
+}
+
+// WithSourcePosition attaches a source position to err; if sourceBytes is provided, the offending line will be rendered when the error is printed
+func WithSourcePosition(err error, position SourcePosition, sourceBytes []byte) error {
+	if err == nil {
+		return nil
+	}
+	return &sourcePositionError{
+		error:    err,
+		position: position,
+		snippet:  SourceSnippet(sourceBytes, position),
+	}
+}
+
+func GetSourcePosition(err error) *SourcePosition {
+	for err != nil {
+		if positionErr, ok := err.(*sourcePositionError); ok {
+			position := positionErr.position
+			return &position
+		}
+		causer, ok := err.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		err = causer.Cause()
+	}
+	return nil
+}
+
+// SourceSnippet renders the line at position (and the line before it), with a caret under the column
+func SourceSnippet(sourceBytes []byte, position SourcePosition) string {
+	lines := strings.Split(string(sourceBytes), "\n")
+	if len(sourceBytes) == 0 || position.Line < 1 || position.Line > len(lines) {
+		return "--> " + position.String()
+	}
+
+	lineNumWidth := len(strconv.Itoa(position.Line))
+	gutter := strings.Repeat(" ", lineNumWidth)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s--> %s\n", gutter, position.String()))
+	sb.WriteString(fmt.Sprintf("%s |\n", gutter))
+	if position.Line > 1 {
+		sb.WriteString(fmt.Sprintf("%*d | %s\n", lineNumWidth, position.Line-1, strings.TrimRight(lines[position.Line-2], "\r")))
+	}
+	line := strings.TrimRight(lines[position.Line-1], "\r")
+	sb.WriteString(fmt.Sprintf("%*d | %s\n", lineNumWidth, position.Line, line))
+
+	// preserve tabs so that the caret lines up
+	var caretPadding strings.Builder
+	for i, char := range line {
+		if i >= position.Column-1 {
+			break
+		}
+		if char == '\t' {
+			caretPadding.WriteRune('\t')
+		} else {
+			caretPadding.WriteRune(' ')
+		}
+	}
+	sb.WriteString(fmt.Sprintf("%s | %s^", gutter, caretPadding.String()))
+
+	return sb.String()
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configreader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+)
+
+type positionTestItem struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type positionTestConfig struct {
+	Items []*positionTestItem `json:"items"`
+}
+
+var positionTestValidation = &StructValidation{
+	StructFieldValidations: []*StructFieldValidation{
+		{
+			StructField: "Items",
+			StructListValidation: &StructListValidation{
+				StructValidation: &StructValidation{
+					StructFieldValidations: []*StructFieldValidation{
+						{
+							StructField:      "Name",
+							StringValidation: &StringValidation{Required: true},
+						},
+						{
+							StructField: "Count",
+							Int64Validation: &Int64Validation{
+								GreaterThan: pointer.Int64(0),
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func parsePositionTestConfig(t *testing.T, yamlStr string) error {
+	var config positionTestConfig
+	errs := Struct(&config, MustReadYAMLStr(yamlStr), positionTestValidation)
+	require.Len(t, errs, 1)
+
+	positions, err := ReadYAMLPositions("config.yaml", []byte(yamlStr))
+	require.NoError(t, err)
+	return positions.Attach(errs[0])
+}
+
+func TestSourcePosition(t *testing.T) {
+	yamlStr := `items:
+  - name: a
+    count: 1
+  - name: b
+    count: -1
+`
+	err := parsePositionTestConfig(t, yamlStr)
+	require.Equal(t, []string{"items", "1", "count"}, KeyPath(err))
+	require.Equal(t, &SourcePosition{FilePath: "config.yaml", Line: 5, Column: 12}, GetSourcePosition(err))
+	require.Equal(t, "items: index 1: count: -1 must be greater than 0", err.Error())
+
+	snippet := " --> config.yaml:5:12\n" +
+		"  |\n" +
+		"4 |   - name: b\n" +
+		"5 |     count: -1\n" +
+		"  |            ^"
+	require.Equal(t, snippet, SourceSnippet([]byte(yamlStr), *GetSourcePosition(err)))
+	require.Equal(t, snippet, errors.ErrorDetails(err))
+}
+
+func TestSourcePositionUnsupportedKey(t *testing.T) {
+	err := parsePositionTestConfig(t, "items:\n  - name: a\n    count: 1\n    typo: 1\n")
+	require.Equal(t, &SourcePosition{FilePath: "config.yaml", Line: 4, Column: 5}, GetSourcePosition(err))
+}
+
+func TestSourcePositionMissingKey(t *testing.T) {
+	// points to the closest parent which is defined
+	err := parsePositionTestConfig(t, "items:\n  - count: 1\n")
+	require.Equal(t, &SourcePosition{FilePath: "config.yaml", Line: 2, Column: 5}, GetSourcePosition(err))
+}
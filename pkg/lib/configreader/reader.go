@@ -338,8 +338,8 @@ func Struct(dest interface{}, inter interface{}, v *StructValidation) []error {
 			exit.Panic("Undefined or unsupported validation type")
 		}
 
-		allErrs, _ = errors.AddError(allErrs, err)
-		allErrs, _ = errors.AddErrors(allErrs, errs)
+		allErrs, _ = errors.AddError(allErrs, WithKey(err, key))
+		allErrs, _ = errors.AddErrors(allErrs, withKeyAll(errs, key))
 		if errors.HasErrors(allErrs) {
 			if v.ShortCircuit {
 				return allErrs
@@ -352,7 +352,7 @@ func Struct(dest interface{}, inter interface{}, v *StructValidation) []error {
 		} else {
 			err = setField(val, dest, structFieldValidation.StructField)
 		}
-		if allErrs, ok = errors.AddError(allErrs, err, key); ok {
+		if allErrs, ok = errors.AddError(allErrs, WithKey(err, key), key); ok {
 			if v.ShortCircuit {
 				return allErrs
 			}
@@ -362,7 +362,7 @@ func Struct(dest interface{}, inter interface{}, v *StructValidation) []error {
 	if !v.AllowExtraFields {
 		extraFields := slices.SubtractStrSlice(maps.InterfaceMapKeys(interMap), allowedFields)
 		for _, extraField := range extraFields {
-			allErrs = append(allErrs, ErrorUnsupportedKey(extraField))
+			allErrs = append(allErrs, withKeyItself(ErrorUnsupportedKey(extraField), extraField))
 		}
 	}
 	if errors.HasErrors(allErrs) {
@@ -393,7 +393,7 @@ func StructList(dest interface{}, inter interface{}, v *StructListValidation) (i
 		val := reflect.New(reflect.ValueOf(dest).Type().Elem().Elem()).Interface()
 		subErrs := Struct(val, interItem, v.StructValidation)
 		var ok bool
-		if errs, ok = errors.AddErrors(errs, subErrs, s.Index(i)); ok {
+		if errs, ok = errors.AddErrors(errs, withIndexAll(subErrs, i), s.Index(i)); ok {
 			if v.ShortCircuit {
 				return nil, errs
 			}
@@ -439,13 +439,13 @@ func InterfaceStruct(inter interface{}, v *InterfaceStructValidation) (interface
 
 	typeStr, err := StringFromInterfaceMap(v.TypeKey, interMap, typeStrValidation)
 	if err != nil {
-		return nil, []error{err}
+		return nil, []error{WithKey(err, v.TypeKey)}
 	}
 	var typeObj interface{}
 	if v.Parser != nil {
 		typeObj, err = v.Parser(typeStr)
 		if err != nil {
-			return nil, []error{errors.Wrap(err, v.TypeKey)}
+			return nil, []error{WithKey(errors.Wrap(err, v.TypeKey), v.TypeKey)}
 		}
 	}
 
@@ -475,7 +475,7 @@ func InterfaceStruct(inter interface{}, v *InterfaceStructValidation) (interface
 			for typeObj := range v.ParsedInterfaceStructTypes {
 				validTypeObjs = append(validTypeObjs, typeObj)
 			}
-			return nil, []error{errors.Wrap(ErrorInvalidInterface(typeStr, validTypeObjs...), v.TypeKey)}
+			return nil, []error{WithKey(errors.Wrap(ErrorInvalidInterface(typeStr, validTypeObjs...), v.TypeKey), v.TypeKey)}
 		}
 	}
 
@@ -512,7 +512,7 @@ func InterfaceStructList(dest interface{}, inter interface{}, v *InterfaceStruct
 	for i, interItem := range interSlice {
 		val, subErrs := InterfaceStruct(interItem, v.InterfaceStructValidation)
 		var ok bool
-		if errs, ok = errors.AddErrors(errs, subErrs, s.Index(i)); ok {
+		if errs, ok = errors.AddErrors(errs, withIndexAll(subErrs, i), s.Index(i)); ok {
 			if v.ShortCircuit {
 				return nil, errs
 			}
@@ -879,13 +879,21 @@ func ReadEnvVar(envVarName string) *string {
 //
 
 func ParseYAMLFile(dest interface{}, validation *StructValidation, filePath string) []error {
-	fileInterface, err := ReadYAMLFile(filePath)
+	fileBytes, err := files.ReadFileBytes(filePath)
 	if err != nil {
 		return []error{err}
 	}
 
+	fileInterface, err := ReadYAMLBytes(fileBytes)
+	if err != nil {
+		return []error{errors.Wrap(err, filePath)}
+	}
+
 	errs := Struct(dest, fileInterface, validation)
 	if errors.HasErrors(errs) {
+		if positions, err := ReadYAMLPositions(filePath, fileBytes); err == nil {
+			errs = positions.AttachAll(errs)
+		}
 		return errors.WrapAll(errs, filePath)
 	}
 
@@ -0,0 +1,197 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contracttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/cast"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+const _maxValueStrLen = 200
+
+// Check returns a description of each expectation which the response does not meet
+func (testCase *TestCase) Check(statusCode int, body []byte, latency time.Duration) []string {
+	var failures []string
+	expect := testCase.Expect
+
+	if statusCode != expect.StatusCode {
+		failure := fmt.Sprintf("%s: expected %d, got %d", StatusCodeKey, expect.StatusCode, statusCode)
+		if bodyStr := strings.TrimSpace(string(body)); bodyStr != "" {
+			failure += fmt.Sprintf(" (response: %s)", truncate(bodyStr))
+		}
+		failures = append(failures, failure)
+	}
+
+	if expect.MaxLatency != nil && latency > *expect.MaxLatency {
+		failures = append(failures, fmt.Sprintf("latency: expected at most %s, got %s", *expect.MaxLatency, latency.Round(time.Millisecond)))
+	}
+
+	if len(expect.JSON) == 0 {
+		return failures
+	}
+
+	var response interface{}
+	if err := libjson.DecodeWithNumber(body, &response); err != nil {
+		return append(failures, fmt.Sprintf("the response is not valid json: %s", truncate(strings.TrimSpace(string(body)))))
+	}
+
+	for _, assertion := range expect.JSON {
+		failures = append(failures, assertion.check(response)...)
+	}
+
+	return failures
+}
+
+func (assertion *JSONAssertion) check(response interface{}) []string {
+	value, ok := assertion.Path.Lookup(response)
+	if !ok {
+		return []string{fmt.Sprintf("%s: not found in the response", assertion.Path)}
+	}
+
+	var failures []string
+
+	if assertion.Type != nil && !isJSONType(value, *assertion.Type) {
+		failures = append(failures, fmt.Sprintf("%s: expected type %s, got %s (%s)", assertion.Path, *assertion.Type, jsonType(value), jsonStr(value)))
+	}
+
+	if assertion.Equals != nil && !reflect.DeepEqual(normalize(assertion.Equals), normalize(value)) {
+		failures = append(failures, fmt.Sprintf("%s: expected %s, got %s", assertion.Path, jsonStr(assertion.Equals), jsonStr(value)))
+	}
+
+	if assertion.Min != nil || assertion.Max != nil {
+		number, ok := value.(json.Number)
+		if !ok {
+			return append(failures, fmt.Sprintf("%s: expected a number, got %s (%s)", assertion.Path, jsonType(value), jsonStr(value)))
+		}
+		numberFloat, _ := number.Float64()
+		if assertion.Min != nil && numberFloat < *assertion.Min {
+			failures = append(failures, fmt.Sprintf("%s: expected at least %s, got %s", assertion.Path, floatStr(*assertion.Min), number))
+		}
+		if assertion.Max != nil && numberFloat > *assertion.Max {
+			failures = append(failures, fmt.Sprintf("%s: expected at most %s, got %s", assertion.Path, floatStr(*assertion.Max), number))
+		}
+	}
+
+	return failures
+}
+
+// jsonType returns the type of a value decoded from json (with numbers decoded as json.Number)
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func isJSONType(value interface{}, typeStr string) bool {
+	if typeStr == "integer" {
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		numberFloat, err := number.Float64()
+		return err == nil && numberFloat == math.Trunc(numberFloat)
+	}
+	return jsonType(value) == typeStr
+}
+
+// stringKeys converts the maps in a value parsed from YAML to maps with string keys, so that it can be encoded as json
+func stringKeys(value interface{}) interface{} {
+	switch casted := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(casted))
+		for k, v := range casted {
+			converted[fmt.Sprint(k)] = stringKeys(v)
+		}
+		return converted
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(casted))
+		for k, v := range casted {
+			converted[k] = stringKeys(v)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(casted))
+		for i, v := range casted {
+			converted[i] = stringKeys(v)
+		}
+		return converted
+	}
+	return value
+}
+
+// normalize converts a value parsed from YAML or decoded from json so that equal values are deeply equal (e.g. 1 and 1.0)
+func normalize(value interface{}) interface{} {
+	value = stringKeys(value)
+	switch casted := value.(type) {
+	case map[string]interface{}:
+		for k, v := range casted {
+			casted[k] = normalize(v)
+		}
+		return casted
+	case []interface{}:
+		for i, v := range casted {
+			casted[i] = normalize(v)
+		}
+		return casted
+	case string, bool, nil:
+		return casted
+	}
+	if number, ok := cast.InterfaceToFloat64(value); ok {
+		return number
+	}
+	return value
+}
+
+func jsonStr(value interface{}) string {
+	jsonBytes, err := json.Marshal(stringKeys(value))
+	if err != nil {
+		return truncate(s.UserStr(value))
+	}
+	return truncate(string(jsonBytes))
+}
+
+func truncate(str string) string {
+	if len(str) > _maxValueStrLen {
+		return str[:_maxValueStrLen] + "..."
+	}
+	return str
+}
+
+func floatStr(val float64) string {
+	return strconv.FormatFloat(val, 'g', -1, 64)
+}
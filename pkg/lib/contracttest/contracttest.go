@@ -0,0 +1,369 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contracttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/cast"
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// TestCase is a request to an API, and the expectations for its response
+type TestCase struct {
+	Name        string            `json:"name" yaml:"name"`
+	API         string            `json:"api" yaml:"api"`
+	Headers     map[string]string `json:"headers" yaml:"headers"`
+	Payload     interface{}       `json:"payload" yaml:"payload"`
+	PayloadFile *string           `json:"payload_file" yaml:"payload_file"`
+	Expect      *Expectations     `json:"expect" yaml:"expect"`
+
+	FilePath     string `json:"file_path" yaml:"-"`
+	headerValues map[string]string // Headers with environment variables substituted (so that e.g. tokens aren't part of the test case)
+	payloadBytes []byte
+}
+
+type Expectations struct {
+	StatusCode int              `json:"status_code" yaml:"status_code"`
+	MaxLatency *time.Duration   `json:"max_latency" yaml:"max_latency"`
+	JSON       []*JSONAssertion `json:"json" yaml:"json"`
+}
+
+// JSONAssertion checks the value at Path in the response body
+type JSONAssertion struct {
+	Path   *JSONPath   `json:"path" yaml:"path"`
+	Equals interface{} `json:"equals" yaml:"equals"`
+	Type   *string     `json:"type" yaml:"type"`
+	Min    *float64    `json:"min" yaml:"min"`
+	Max    *float64    `json:"max" yaml:"max"`
+}
+
+// JSON types which can be asserted with the type key ("integer" is a number without a fractional part)
+var JSONTypes = []string{"string", "number", "integer", "boolean", "array", "object", "null"}
+
+var testCaseValidation = &cr.StructValidation{
+	StructFieldValidations: []*cr.StructFieldValidation{
+		{
+			StructField: "Name",
+			StringValidation: &cr.StringValidation{
+				Required: true,
+			},
+		},
+		{
+			StructField: "API",
+			StringValidation: &cr.StringValidation{
+				Required: true,
+			},
+		},
+		{
+			StructField: "Headers",
+			StringMapValidation: &cr.StringMapValidation{
+				AllowExplicitNull: true,
+				AllowEmpty:        true,
+			},
+		},
+		{
+			StructField: "Payload",
+			InterfaceValidation: &cr.InterfaceValidation{
+				AllowExplicitNull:    true,
+				AllowCortexResources: true,
+			},
+		},
+		{
+			StructField:         "PayloadFile",
+			StringPtrValidation: &cr.StringPtrValidation{},
+		},
+		{
+			StructField: "Expect",
+			StructValidation: &cr.StructValidation{
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "StatusCode",
+						IntValidation: &cr.IntValidation{
+							Default:              http.StatusOK,
+							GreaterThanOrEqualTo: pointer.Int(100),
+							LessThan:             pointer.Int(600),
+						},
+					},
+					{
+						StructField:         "MaxLatency",
+						StringPtrValidation: &cr.StringPtrValidation{},
+						Parser:              parseMaxLatency,
+					},
+					{
+						StructField: "JSON",
+						StructListValidation: &cr.StructListValidation{
+							TreatNullAsEmpty: true,
+							StructValidation: jsonAssertionValidation,
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+var jsonAssertionValidation = &cr.StructValidation{
+	StructFieldValidations: []*cr.StructFieldValidation{
+		{
+			StructField: "Path",
+			StringValidation: &cr.StringValidation{
+				Required: true,
+			},
+			Parser: func(str string) (interface{}, error) {
+				return ParseJSONPath(str)
+			},
+		},
+		{
+			StructField: "Equals",
+			InterfaceValidation: &cr.InterfaceValidation{
+				AllowCortexResources: true,
+			},
+		},
+		{
+			StructField: "Type",
+			StringPtrValidation: &cr.StringPtrValidation{
+				AllowedValues: JSONTypes,
+			},
+		},
+		{
+			StructField:          "Min",
+			Float64PtrValidation: &cr.Float64PtrValidation{},
+		},
+		{
+			StructField:          "Max",
+			Float64PtrValidation: &cr.Float64PtrValidation{},
+		},
+	},
+}
+
+func parseMaxLatency(str string) (interface{}, error) {
+	maxLatency, err := time.ParseDuration(str)
+	if err != nil {
+		return nil, errors.Wrap(err, "durations look like 500ms or 2s")
+	}
+	if maxLatency <= 0 {
+		return nil, cr.ErrorMustBeGreaterThan(str, 0)
+	}
+	return maxLatency, nil
+}
+
+// ReadFile reads the test cases in a YAML file; payload files are relative to the directory of the file
+func ReadFile(filePath string) ([]*TestCase, error) {
+	fileBytes, err := files.ReadFileBytes(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return New(filePath, fileBytes)
+}
+
+func New(filePath string, fileBytes []byte) ([]*TestCase, error) {
+	data, err := cr.ReadYAMLBytes(fileBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, filePath)
+	}
+	if data == nil {
+		return nil, ErrorNoTestCases(filePath)
+	}
+
+	dataSlice, ok := cast.InterfaceToInterfaceSlice(data)
+	if !ok {
+		return nil, errors.Wrap(cr.ErrorInvalidPrimitiveType(data, cr.PrimTypeList), filePath)
+	}
+	if len(dataSlice) == 0 {
+		return nil, ErrorNoTestCases(filePath)
+	}
+
+	// positions are best-effort; errors are still reported without them
+	positions, _ := cr.ReadYAMLPositions(filePath, fileBytes)
+
+	testCases := make([]*TestCase, len(dataSlice))
+	names := map[string]bool{}
+	for i, item := range dataSlice {
+		testCase := &TestCase{}
+		if errs := cr.Struct(testCase, item, testCaseValidation); errors.HasErrors(errs) {
+			name := ""
+			if itemMap, ok := cast.InterfaceToStrInterfaceMap(item); ok {
+				name, _ = itemMap[NameKey].(string)
+			}
+			return nil, positions.Attach(errors.Wrap(errors.FirstError(errs...), identify(filePath, name, i)), s.Int(i))
+		}
+		testCase.FilePath = filePath
+
+		if err := testCase.validate(filepath.Dir(filePath)); err != nil {
+			return nil, positions.Attach(errors.Wrap(err, identify(filePath, testCase.Name, i)), s.Int(i))
+		}
+
+		if names[testCase.Name] {
+			return nil, positions.Attach(errors.Wrap(cr.WithKey(ErrorDuplicateTestCaseName(testCase.Name), NameKey), filePath), s.Int(i))
+		}
+		names[testCase.Name] = true
+
+		testCases[i] = testCase
+	}
+
+	return testCases, nil
+}
+
+func identify(filePath string, name string, index int) string {
+	if name != "" {
+		return filePath + ": test case: " + name
+	}
+	return filePath + ": test case at " + s.Index(index)
+}
+
+// withKeyPath records the key path of err (for its source position), and prefixes its message with the keys
+func withKeyPath(err error, keyPath ...string) error {
+	err = errors.Wrap(err, keyPath...)
+	for i := len(keyPath) - 1; i >= 0; i-- {
+		err = cr.WithKey(err, keyPath[i])
+	}
+	return err
+}
+
+func (testCase *TestCase) validate(baseDir string) error {
+	headerNames := make([]string, 0, len(testCase.Headers))
+	for headerName := range testCase.Headers {
+		headerNames = append(headerNames, headerName)
+	}
+	sort.Strings(headerNames)
+
+	testCase.headerValues = make(map[string]string, len(testCase.Headers))
+	for _, headerName := range headerNames {
+		headerValue, err := expandEnvVars(testCase.Headers[headerName])
+		if err != nil {
+			return withKeyPath(err, HeadersKey, headerName)
+		}
+		testCase.headerValues[headerName] = headerValue
+	}
+
+	if testCase.Payload != nil && testCase.PayloadFile != nil {
+		return withKeyPath(ErrorPayloadAndPayloadFileBothSpecified(), PayloadFileKey)
+	}
+
+	if testCase.PayloadFile != nil {
+		payloadPath := *testCase.PayloadFile
+		if !filepath.IsAbs(payloadPath) {
+			payloadPath = filepath.Join(baseDir, payloadPath)
+		}
+		payloadBytes, err := files.ReadFileBytesErrPath(payloadPath, *testCase.PayloadFile)
+		if err != nil {
+			return withKeyPath(err, PayloadFileKey)
+		}
+		if !json.Valid(payloadBytes) {
+			return withKeyPath(ErrorPayloadFileNotJSON(*testCase.PayloadFile), PayloadFileKey)
+		}
+		testCase.payloadBytes = payloadBytes
+	} else if testCase.Payload != nil {
+		payloadBytes, err := json.Marshal(stringKeys(testCase.Payload))
+		if err != nil {
+			return withKeyPath(err, PayloadKey)
+		}
+		testCase.payloadBytes = payloadBytes
+	}
+
+	for i, assertion := range testCase.Expect.JSON {
+		if err := assertion.validate(); err != nil {
+			return errors.Wrap(cr.WithKey(cr.WithKey(cr.WithIndex(err, i), JSONKey), ExpectKey), ExpectKey, JSONKey, s.Index(i))
+		}
+	}
+
+	return nil
+}
+
+// expandEnvVars substitutes $VAR and ${VAR} with the values of environment variables ($$ is a literal $)
+func expandEnvVars(str string) (string, error) {
+	var err error
+	expanded := os.Expand(str, func(envVarName string) string {
+		if envVarName == "$" {
+			return "$"
+		}
+		value, ok := os.LookupEnv(envVarName)
+		if !ok && err == nil {
+			err = ErrorEnvVarNotSet(envVarName)
+		}
+		return value
+	})
+	return expanded, err
+}
+
+func (assertion *JSONAssertion) validate() error {
+	if assertion.Equals == nil && assertion.Type == nil && assertion.Min == nil && assertion.Max == nil {
+		return withKeyPath(ErrorNoAssertions(), PathKey)
+	}
+	if assertion.Min != nil && assertion.Max != nil && *assertion.Min > *assertion.Max {
+		return withKeyPath(ErrorMinGreaterThanMax(*assertion.Min, *assertion.Max), MinKey)
+	}
+	return nil
+}
+
+// Result is the outcome of running a test case
+type Result struct {
+	TestCase   *TestCase
+	StatusCode int
+	Latency    time.Duration
+	Failures   []string // assertions which did not hold
+	Err        error    // set if the request could not be made (e.g. because the API is not ready)
+}
+
+func (result *Result) Passed() bool {
+	return result.Err == nil && len(result.Failures) == 0
+}
+
+// Run sends the test case's payload to apiURL, and checks the response against the test case's expectations
+func (testCase *TestCase) Run(client *http.Client, apiURL string) *Result {
+	result := &Result{TestCase: testCase}
+
+	request, err := http.NewRequest(http.MethodPost, apiURL, bytes.NewReader(testCase.payloadBytes))
+	if err != nil {
+		result.Err = errors.Wrap(err, "unable to make request")
+		return result
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for headerName, headerValue := range testCase.headerValues {
+		request.Header.Set(headerName, headerValue)
+	}
+
+	start := time.Now()
+	response, err := client.Do(request)
+	if err != nil {
+		result.Err = errors.Wrap(err, "failed to connect to "+apiURL)
+		return result
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Err = errors.Wrap(err, "unable to read the response")
+		return result
+	}
+
+	result.StatusCode = response.StatusCode
+	result.Failures = testCase.Check(response.StatusCode, body, result.Latency)
+	return result
+}
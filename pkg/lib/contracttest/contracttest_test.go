@@ -0,0 +1,316 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contracttest
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+const _testFile = `
+- name: setosa
+  api: iris
+  payload:
+    sepal_length: 5.2
+    tags: [a, b]
+  expect:
+    max_latency: 500ms
+    json:
+      - path: label
+        equals: setosa
+      - path: probabilities[0]
+        type: number
+        min: 0
+        max: 1
+
+- name: bad-input
+  api: iris
+  payload_file: samples/bad.json
+  expect:
+    status_code: 400
+`
+
+func TestNew(t *testing.T) {
+	dir, err := ioutil.TempDir("", "contracttest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "samples"), os.ModePerm))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "samples", "bad.json"), []byte(`{"sepal_length": "x"}`), 0644))
+	testFilePath := filepath.Join(dir, "tests.yaml")
+	require.NoError(t, ioutil.WriteFile(testFilePath, []byte(_testFile), 0644))
+
+	testCases, err := ReadFile(testFilePath)
+	require.NoError(t, err)
+	require.Len(t, testCases, 2)
+
+	require.Equal(t, "setosa", testCases[0].Name)
+	require.Equal(t, "iris", testCases[0].API)
+	require.Equal(t, http.StatusOK, testCases[0].Expect.StatusCode)
+	require.Equal(t, 500*time.Millisecond, *testCases[0].Expect.MaxLatency)
+	require.Len(t, testCases[0].Expect.JSON, 2)
+	require.Equal(t, "probabilities[0]", testCases[0].Expect.JSON[1].Path.String())
+	require.Equal(t, "number", *testCases[0].Expect.JSON[1].Type)
+	require.JSONEq(t, `{"sepal_length": 5.2, "tags": ["a", "b"]}`, string(testCases[0].payloadBytes))
+
+	require.Equal(t, http.StatusBadRequest, testCases[1].Expect.StatusCode)
+	require.Nil(t, testCases[1].Expect.MaxLatency)
+	require.JSONEq(t, `{"sepal_length": "x"}`, string(testCases[1].payloadBytes))
+}
+
+func TestNewErrors(t *testing.T) {
+	for _, c := range []struct {
+		yaml     string
+		errStr   string
+		position string
+	}{
+		{
+			yaml:   "",
+			errStr: "no test cases are defined",
+		},
+		{
+			yaml:     "- name: a\n  api: iris\n  expect:\n    max_latency: fast\n",
+			errStr:   "tests.yaml: test case: a: expect: max_latency",
+			position: "tests.yaml:4:18",
+		},
+		{
+			yaml:     "- name: a\n  api: iris\n  expect:\n    json:\n      - path: label\n        type: text\n",
+			errStr:   "tests.yaml: test case: a: expect: json: index 0: type",
+			position: "tests.yaml:6:15",
+		},
+		{
+			yaml:     "- name: a\n  api: iris\n  expect:\n    json:\n      - path: label\n",
+			errStr:   "tests.yaml: test case: a: expect: json: index 0: path: at least one of equals, type, min or max must be specified",
+			position: "tests.yaml:5:15",
+		},
+		{
+			yaml:     "- name: a\n  api: iris\n  expect:\n    json:\n      - path: label\n        min: 1\n        max: 0\n",
+			errStr:   "min (1) cannot be greater than max (0)",
+			position: "tests.yaml:6:14",
+		},
+		{
+			yaml:     "- name: a\n  api: iris\n  expect:\n    json:\n      - path: a..b\n        type: string\n",
+			errStr:   "is not a valid json path",
+			position: "tests.yaml:5:15",
+		},
+		{
+			yaml:     "- name: a\n  api: iris\n  payload: {}\n  payload_file: sample.json\n",
+			errStr:   "only one of payload and payload_file can be specified",
+			position: "tests.yaml:4:17",
+		},
+		{
+			yaml:     "- name: a\n  api: iris\n- name: a\n  api: iris\n",
+			errStr:   "test case name \"a\" is used more than once",
+			position: "tests.yaml:3:9",
+		},
+		{
+			yaml:     "- name: a\n  api: iris\n  headers:\n    Authorization: Bearer ${CORTEX_CONTRACT_TEST_UNSET}\n",
+			errStr:   "tests.yaml: test case: a: headers: Authorization: environment variable \"CORTEX_CONTRACT_TEST_UNSET\" is not set",
+			position: "tests.yaml:4:20",
+		},
+		{
+			yaml:     "- api: iris\n",
+			errStr:   "tests.yaml: test case at index 0: name",
+			position: "tests.yaml:1:3",
+		},
+	} {
+		_, err := New("tests.yaml", []byte(c.yaml))
+		require.Error(t, err, c.yaml)
+		require.Contains(t, err.Error(), c.errStr, c.yaml)
+		if c.position != "" {
+			position := cr.GetSourcePosition(err)
+			require.NotNil(t, position, c.yaml)
+			require.Equal(t, c.position, position.String(), c.yaml)
+		}
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	require.NoError(t, os.Setenv("CORTEX_CONTRACT_TEST_TOKEN", "secret-token"))
+	defer os.Unsetenv("CORTEX_CONTRACT_TEST_TOKEN")
+
+	var requestHeaders http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestHeaders = r.Header
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`"Origin authentication failed."`))
+			return
+		}
+		w.Write([]byte(`{"label": "setosa"}`))
+	}))
+	defer server.Close()
+
+	testCases := mustNew(t, `
+- name: authenticated
+  api: iris
+  headers:
+    Authorization: Bearer ${CORTEX_CONTRACT_TEST_TOKEN}
+    X-Request-Tag: $$CORTEX_CONTRACT_TEST_TOKEN
+  payload: {sepal_length: 5.2}
+- name: unauthenticated
+  api: iris
+  payload: {sepal_length: 5.2}
+  expect:
+    status_code: 401
+`)
+
+	// the token is only substituted in the request
+	require.Equal(t, map[string]string{
+		"Authorization": "Bearer ${CORTEX_CONTRACT_TEST_TOKEN}",
+		"X-Request-Tag": "$$CORTEX_CONTRACT_TEST_TOKEN",
+	}, testCases[0].Headers)
+
+	result := testCases[0].Run(http.DefaultClient, server.URL)
+	require.True(t, result.Passed(), result.Failures)
+	require.Equal(t, "Bearer secret-token", requestHeaders.Get("Authorization"))
+	require.Equal(t, "$CORTEX_CONTRACT_TEST_TOKEN", requestHeaders.Get("X-Request-Tag"))
+	require.Equal(t, "application/json", requestHeaders.Get("Content-Type"))
+
+	result = testCases[1].Run(http.DefaultClient, server.URL)
+	require.True(t, result.Passed(), result.Failures)
+	require.Empty(t, requestHeaders.Get("Authorization"))
+}
+
+func mustNew(t *testing.T, yaml string) []*TestCase {
+	testCases, err := New("tests.yaml", []byte(yaml))
+	require.NoError(t, err)
+	return testCases
+}
+
+func TestCheck(t *testing.T) {
+	testCase := mustNew(t, `
+- name: a
+  api: iris
+  expect:
+    max_latency: 100ms
+    json:
+      - path: label
+        equals: setosa
+      - path: scores
+        equals: {a: 1, b: [0.5, true]}
+      - path: probabilities[0]
+        type: number
+        min: 0
+        max: 1
+      - path: count
+        type: integer
+`)[0]
+
+	require.Empty(t, testCase.Check(200, []byte(`{"label": "setosa", "scores": {"a": 1.0, "b": [0.5, true]}, "probabilities": [0.3], "count": 2}`), 10*time.Millisecond))
+
+	failures := testCase.Check(500, []byte(`{"label": "virginica", "scores": {"a": 2, "b": [0.5, true]}, "probabilities": [1.3], "count": 2.5}`), 150*time.Millisecond)
+	require.Equal(t, []string{
+		`status_code: expected 200, got 500 (response: {"label": "virginica", "scores": {"a": 2, "b": [0.5, true]}, "probabilities": [1.3], "count": 2.5})`,
+		"latency: expected at most 100ms, got 150ms",
+		`label: expected "setosa", got "virginica"`,
+		`scores: expected {"a":1,"b":[0.5,true]}, got {"a":2,"b":[0.5,true]}`,
+		"probabilities[0]: expected at most 1, got 1.3",
+		"count: expected type integer, got number (2.5)",
+	}, failures)
+
+	failures = testCase.Check(200, []byte(`{"label": "setosa", "scores": {"a": 1, "b": [0.5, true]}, "probabilities": ["high"]}`), 0)
+	require.Equal(t, []string{
+		`probabilities[0]: expected type number, got string ("high")`,
+		`probabilities[0]: expected a number, got string ("high")`,
+		"count: not found in the response",
+	}, failures)
+
+	failures = testCase.Check(200, []byte("internal error"), 0)
+	require.Equal(t, []string{"the response is not valid json: internal error"}, failures)
+}
+
+func TestRunAndJUnitXML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "application/json" || !strings.Contains(string(body), `"sepal_length":5.2`) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "invalid payload"}`))
+			return
+		}
+		w.Write([]byte(`{"label": "setosa"}`))
+	}))
+	defer server.Close()
+
+	testCases := mustNew(t, `
+- name: setosa
+  api: iris
+  payload: {sepal_length: 5.2}
+  expect:
+    json:
+      - path: label
+        equals: setosa
+- name: virginica
+  api: iris
+  payload: {sepal_length: 5.2}
+  expect:
+    json:
+      - path: label
+        equals: virginica
+- name: no-payload
+  api: iris-v2
+`)
+
+	var results []*Result
+	for _, testCase := range testCases {
+		results = append(results, testCase.Run(http.DefaultClient, server.URL))
+	}
+	results = append(results, &Result{TestCase: testCases[2], Err: errors.New("api \"iris-v3\" not found")})
+
+	require.True(t, results[0].Passed())
+	require.Equal(t, 200, results[0].StatusCode)
+	require.False(t, results[1].Passed())
+	require.Equal(t, []string{`label: expected "virginica", got "setosa"`}, results[1].Failures)
+	require.False(t, results[2].Passed())
+	require.Equal(t, 400, results[2].StatusCode)
+	require.False(t, results[3].Passed())
+
+	closedServer := httptest.NewServer(http.NotFoundHandler())
+	closedServer.Close()
+	result := testCases[0].Run(http.DefaultClient, closedServer.URL)
+	require.Error(t, result.Err)
+	require.False(t, result.Passed())
+
+	junitBytes, err := JUnitXML("iris-app", results)
+	require.NoError(t, err)
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(junitBytes, &report))
+	require.Equal(t, 4, report.Tests)
+	require.Equal(t, 2, report.Failures)
+	require.Equal(t, 1, report.Errors)
+	require.Len(t, report.Suites, 2)
+	require.Equal(t, "iris", report.Suites[0].Name)
+	require.Equal(t, 2, report.Suites[0].Tests)
+	require.Equal(t, "iris-v2", report.Suites[1].Name)
+	require.Equal(t, "iris-app.iris", report.Suites[0].TestCases[0].ClassName)
+	require.Nil(t, report.Suites[0].TestCases[0].Failure)
+	require.Equal(t, `label: expected "virginica", got "setosa"`, report.Suites[0].TestCases[1].Failure.Message)
+	require.Contains(t, report.Suites[1].TestCases[0].Failure.Text, "status_code: expected 200, got 400")
+	require.Equal(t, `api "iris-v3" not found`, report.Suites[1].TestCases[1].Error.Message)
+}
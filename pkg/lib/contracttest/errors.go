@@ -0,0 +1,151 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contracttest
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+type ErrorKind int
+
+const (
+	ErrUnknown ErrorKind = iota
+	ErrNoTestCases
+	ErrDuplicateTestCaseName
+	ErrPayloadAndPayloadFileBothSpecified
+	ErrPayloadFileNotJSON
+	ErrInvalidJSONPath
+	ErrMinGreaterThanMax
+	ErrNoAssertions
+	ErrEnvVarNotSet
+)
+
+var errorKinds = []string{
+	"err_unknown",
+	"err_no_test_cases",
+	"err_duplicate_test_case_name",
+	"err_payload_and_payload_file_both_specified",
+	"err_payload_file_not_json",
+	"err_invalid_json_path",
+	"err_min_greater_than_max",
+	"err_no_assertions",
+	"err_env_var_not_set",
+}
+
+var _ = [1]int{}[int(ErrEnvVarNotSet)-(len(errorKinds)-1)] // Ensure list length matches
+
+func (t ErrorKind) String() string {
+	return errorKinds[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t ErrorKind) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *ErrorKind) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(errorKinds); i++ {
+		if enum == errorKinds[i] {
+			*t = ErrorKind(i)
+			return nil
+		}
+	}
+
+	*t = ErrUnknown
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *ErrorKind) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t ErrorKind) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+type Error struct {
+	Kind    ErrorKind
+	message string
+}
+
+func (e Error) Error() string {
+	return e.message
+}
+
+func ErrorNoTestCases(filePath string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrNoTestCases,
+		message: fmt.Sprintf("%s: no test cases are defined", filePath),
+	})
+}
+
+func ErrorDuplicateTestCaseName(name string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrDuplicateTestCaseName,
+		message: fmt.Sprintf("test case name %s is used more than once", s.UserStr(name)),
+	})
+}
+
+func ErrorPayloadAndPayloadFileBothSpecified() error {
+	return errors.WithStack(Error{
+		Kind:    ErrPayloadAndPayloadFileBothSpecified,
+		message: fmt.Sprintf("only one of %s and %s can be specified", PayloadKey, PayloadFileKey),
+	})
+}
+
+func ErrorPayloadFileNotJSON(filePath string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPayloadFileNotJSON,
+		message: fmt.Sprintf("%s does not contain valid json", filePath),
+	})
+}
+
+func ErrorInvalidJSONPath(path string, reason string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidJSONPath,
+		message: fmt.Sprintf("%s is not a valid json path (%s); paths look like predictions[0].label or $.scores[\"class a\"]", s.UserStr(path), reason),
+	})
+}
+
+func ErrorMinGreaterThanMax(min float64, max float64) error {
+	return errors.WithStack(Error{
+		Kind:    ErrMinGreaterThanMax,
+		message: fmt.Sprintf("%s (%s) cannot be greater than %s (%s)", MinKey, floatStr(min), MaxKey, floatStr(max)),
+	})
+}
+
+func ErrorNoAssertions() error {
+	return errors.WithStack(Error{
+		Kind:    ErrNoAssertions,
+		message: fmt.Sprintf("at least one of %s, %s, %s or %s must be specified", EqualsKey, TypeKey, MinKey, MaxKey),
+	})
+}
+
+func ErrorEnvVarNotSet(envVarName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrEnvVarNotSet,
+		message: fmt.Sprintf("environment variable %s is not set", s.UserStr(envVarName)),
+	})
+}
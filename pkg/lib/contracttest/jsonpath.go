@@ -0,0 +1,125 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contracttest
+
+import (
+	"strconv"
+	"strings"
+)
+
+// pathElement is either the key of a json object or the index of a json array
+type pathElement struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// JSONPath locates a value in a json document, e.g. predictions[0].label or $.scores["class a"] ("$" refers to the whole document)
+type JSONPath struct {
+	str      string
+	elements []pathElement
+}
+
+func ParseJSONPath(str string) (*JSONPath, error) {
+	path := &JSONPath{str: str}
+
+	remaining := strings.TrimSpace(str)
+	if remaining == "" {
+		return nil, ErrorInvalidJSONPath(str, "it is empty")
+	}
+
+	if strings.HasPrefix(remaining, "$") {
+		remaining = remaining[1:]
+	} else if !strings.HasPrefix(remaining, "[") {
+		remaining = "." + remaining
+	}
+
+	for remaining != "" {
+		switch remaining[0] {
+		case '.':
+			remaining = remaining[1:]
+			end := strings.IndexAny(remaining, ".[]")
+			if end == -1 {
+				end = len(remaining)
+			} else if remaining[end] == ']' {
+				return nil, ErrorInvalidJSONPath(str, "it contains an unmatched ]")
+			}
+			key := remaining[:end]
+			if key == "" {
+				return nil, ErrorInvalidJSONPath(str, "it contains an empty key")
+			}
+			path.elements = append(path.elements, pathElement{key: key})
+			remaining = remaining[end:]
+		case '[':
+			if len(remaining) > 1 && (remaining[1] == '"' || remaining[1] == '\'') {
+				// quoted keys can contain any characters other than their quote
+				closingQuote := strings.IndexByte(remaining[2:], remaining[1])
+				if closingQuote == -1 {
+					return nil, ErrorInvalidJSONPath(str, "it contains an unclosed quote")
+				}
+				end := 2 + closingQuote + 1
+				if end >= len(remaining) || remaining[end] != ']' {
+					return nil, ErrorInvalidJSONPath(str, "quoted keys must be followed by ]")
+				}
+				path.elements = append(path.elements, pathElement{key: remaining[2 : end-1]})
+				remaining = remaining[end+1:]
+				continue
+			}
+
+			end := strings.Index(remaining, "]")
+			if end == -1 {
+				return nil, ErrorInvalidJSONPath(str, "it contains an unclosed [")
+			}
+			index, err := strconv.Atoi(strings.TrimSpace(remaining[1:end]))
+			if err != nil || index < 0 {
+				return nil, ErrorInvalidJSONPath(str, "array indices must be non-negative integers")
+			}
+			path.elements = append(path.elements, pathElement{index: index, isIndex: true})
+			remaining = remaining[end+1:]
+		default:
+			return nil, ErrorInvalidJSONPath(str, "keys must be separated by . or enclosed in brackets")
+		}
+	}
+
+	return path, nil
+}
+
+func (path *JSONPath) String() string {
+	return path.str
+}
+
+// Lookup returns the value at path in obj (which is a decoded json document), and whether it exists
+func (path *JSONPath) Lookup(obj interface{}) (interface{}, bool) {
+	for _, element := range path.elements {
+		if element.isIndex {
+			array, ok := obj.([]interface{})
+			if !ok || element.index >= len(array) {
+				return nil, false
+			}
+			obj = array[element.index]
+		} else {
+			object, ok := obj.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if obj, ok = object[element.key]; !ok {
+				return nil, false
+			}
+		}
+	}
+	return obj, true
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contracttest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+)
+
+func TestJSONPathLookup(t *testing.T) {
+	var response interface{}
+	require.NoError(t, libjson.DecodeWithNumber([]byte(`{"predictions": [{"label": "setosa", "scores": {"class a": 0.9, "a.b": 1}}], "count": 1}`), &response))
+
+	for pathStr, expected := range map[string]interface{}{
+		"count":                            "1",
+		"$.count":                          "1",
+		"predictions[0].label":             "setosa",
+		"$.predictions[0].label":           "setosa",
+		`predictions[0].scores["class a"]`: "0.9",
+		`predictions[0].scores['a.b']`:     "1",
+		`$["predictions"][ 0 ]["label"]`:   "setosa",
+	} {
+		path, err := ParseJSONPath(pathStr)
+		require.NoError(t, err, pathStr)
+		value, ok := path.Lookup(response)
+		require.True(t, ok, pathStr)
+		require.Equal(t, expected, valueStr(value), pathStr)
+	}
+
+	path, err := ParseJSONPath("$")
+	require.NoError(t, err)
+	value, ok := path.Lookup(response)
+	require.True(t, ok)
+	require.Equal(t, response, value)
+
+	for _, pathStr := range []string{"missing", "predictions[1]", "predictions.label", "count[0]", "predictions[0].label.length"} {
+		path, err := ParseJSONPath(pathStr)
+		require.NoError(t, err, pathStr)
+		_, ok := path.Lookup(response)
+		require.False(t, ok, pathStr)
+	}
+}
+
+func TestParseJSONPathErrors(t *testing.T) {
+	for _, pathStr := range []string{"", "a..b", "a.", "a[0", "a[-1]", "a[b]", `a["b]`, `a["b"c]`, "$a", "a]"} {
+		_, err := ParseJSONPath(pathStr)
+		require.Error(t, err, pathStr)
+	}
+}
+
+// valueStr renders a decoded json value for comparison (numbers are json.Numbers)
+func valueStr(value interface{}) interface{} {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	return jsonStr(value)
+}
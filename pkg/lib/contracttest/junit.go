@@ -0,0 +1,116 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contracttest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Errors   int               `xml:"errors,attr"`
+	Time     string            `xml:"time,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Errors    int              `xml:"errors,attr"`
+	Time      string           `xml:"time,attr"`
+	TestCases []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnitXML renders results as a JUnit XML report, with a test suite per API; deploymentName names the report
+func JUnitXML(deploymentName string, results []*Result) ([]byte, error) {
+	report := &junitTestSuites{Name: deploymentName}
+	suites := map[string]*junitTestSuite{}
+	var totalTime time.Duration
+	suiteTimes := map[string]time.Duration{}
+
+	for _, result := range results {
+		apiName := result.TestCase.API
+		suite, ok := suites[apiName]
+		if !ok {
+			suite = &junitTestSuite{Name: apiName}
+			suites[apiName] = suite
+		}
+
+		testCase := &junitTestCase{
+			Name:      result.TestCase.Name,
+			ClassName: deploymentName + "." + apiName,
+			Time:      junitSeconds(result.Latency),
+			File:      result.TestCase.FilePath,
+		}
+		if result.Err != nil {
+			testCase.Error = &junitProblem{Message: result.Err.Error(), Text: result.Err.Error()}
+			suite.Errors++
+			report.Errors++
+		} else if len(result.Failures) > 0 {
+			testCase.Failure = &junitProblem{Message: result.Failures[0], Text: strings.Join(result.Failures, "\n")}
+			suite.Failures++
+			report.Failures++
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+		report.Tests++
+		suiteTimes[apiName] += result.Latency
+		totalTime += result.Latency
+	}
+
+	for apiName, suite := range suites {
+		suite.Time = junitSeconds(suiteTimes[apiName])
+		report.Suites = append(report.Suites, suite)
+	}
+	sort.Slice(report.Suites, func(i, j int) bool {
+		return report.Suites[i].Name < report.Suites[j].Name
+	})
+	report.Time = junitSeconds(totalTime)
+
+	xmlBytes, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(xmlBytes, '\n')...), nil
+}
+
+func junitSeconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
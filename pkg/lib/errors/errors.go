@@ -17,6 +17,7 @@ limitations under the License.
 package errors
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -143,9 +144,65 @@ func MergeErrItems(items ...interface{}) error {
 func PrintError(err error, strs ...string) {
 	wrappedErr := Wrap(err, strs...)
 	fmt.Println("error:", wrappedErr.Error())
+	if details := ErrorDetails(wrappedErr); details != "" {
+		fmt.Println(details)
+	}
 	// PrintStacktrace(wrappedErr)
 }
 
+// ErrorDetails returns the additional details (e.g. the offending lines of a config file) of the first error in the chain which provides them
+func ErrorDetails(err error) string {
+	for err != nil {
+		if detailer, ok := err.(interface{ ErrorDetails() string }); ok {
+			return detailer.ErrorDetails()
+		}
+		causer, ok := err.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		err = causer.Cause()
+	}
+	return ""
+}
+
+// IsCanceled returns whether err was caused by a canceled context
+func IsCanceled(err error) bool {
+	return anyCause(err, func(err error) bool {
+		return err == context.Canceled
+	})
+}
+
+// IsTimeout returns whether err was caused by an exceeded deadline (of a context, or e.g. of an HTTP client)
+func IsTimeout(err error) bool {
+	return anyCause(err, func(err error) bool {
+		if err == context.DeadlineExceeded {
+			return true
+		}
+		timeoutErr, ok := err.(interface{ Timeout() bool })
+		return ok && timeoutErr.Timeout()
+	})
+}
+
+// anyCause walks the chain of wrapped errors (including the original errors of AWS SDK errors), and returns whether fn returns true for any of them
+func anyCause(err error, fn func(error) bool) bool {
+	for err != nil {
+		if fn(err) {
+			return true
+		}
+		switch casted := err.(type) {
+		case interface{ Unwrap() error }:
+			err = casted.Unwrap()
+		case interface{ Cause() error }:
+			err = casted.Cause()
+		case interface{ OrigErr() error }:
+			err = casted.OrigErr()
+		default:
+			return false
+		}
+	}
+	return false
+}
+
 func PrintStacktrace(err error) {
 	fmt.Printf("%+v\n", err)
 }
@@ -0,0 +1,51 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package errors
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/require"
+)
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string { return "i/o timeout" }
+func (timeoutErr) Timeout() bool { return true }
+
+func TestIsCanceled(t *testing.T) {
+	require.False(t, IsCanceled(nil))
+	require.False(t, IsCanceled(New("error")))
+	require.True(t, IsCanceled(context.Canceled))
+	require.True(t, IsCanceled(Wrap(context.Canceled, "app", "api")))
+	require.True(t, IsCanceled(&url.Error{Op: "Get", URL: "https://example.com", Err: Wrap(context.Canceled)}))
+	require.True(t, IsCanceled(Wrap(awserr.New("RequestCanceled", "request context canceled", context.Canceled), "key")))
+	require.False(t, IsCanceled(context.DeadlineExceeded))
+}
+
+func TestIsTimeout(t *testing.T) {
+	require.False(t, IsTimeout(nil))
+	require.False(t, IsTimeout(New("error")))
+	require.True(t, IsTimeout(context.DeadlineExceeded))
+	require.True(t, IsTimeout(Wrap(context.DeadlineExceeded, "app")))
+	require.True(t, IsTimeout(Wrap(&url.Error{Op: "Get", URL: "https://example.com", Err: timeoutErr{}})))
+	require.True(t, IsTimeout(Wrap(awserr.New("RequestError", "send request failed", &url.Error{Op: "Get", URL: "https://example.com", Err: timeoutErr{}}), "key")))
+	require.False(t, IsTimeout(context.Canceled))
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+// Istio 1.4 configures peer authentication via authentication.istio.io/v1alpha1 Policy resources
+// (this is what later Istio versions call PeerAuthentication); the same resource's origins configure
+// request (end-user) authentication, which later Istio versions call RequestAuthentication
+
+var (
+	authenticationPolicyTypeMeta = kmeta.TypeMeta{
+		APIVersion: "v1alpha1",
+		Kind:       "Policy",
+	}
+
+	authenticationPolicyGVR = kschema.GroupVersionResource{
+		Group:    "authentication.istio.io",
+		Version:  "v1alpha1",
+		Resource: "policies",
+	}
+
+	authenticationPolicyGVK = kschema.GroupVersionKind{
+		Group:   "authentication.istio.io",
+		Version: "v1alpha1",
+		Kind:    "Policy",
+	}
+)
+
+type AuthenticationPolicySpec struct {
+	Name        string
+	Namespace   string
+	ServiceName string
+	MTLSMode    string   // "STRICT" or "PERMISSIVE"
+	JWT         *JWTSpec // if set, requests must carry a valid JWT from this issuer
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+type JWTSpec struct {
+	Issuer    string
+	JWKSURI   string
+	Audiences []string
+}
+
+func AuthenticationPolicy(spec *AuthenticationPolicySpec) *kunstructured.Unstructured {
+	policyConfig := &kunstructured.Unstructured{}
+	policyConfig.SetGroupVersionKind(authenticationPolicyGVK)
+	policyConfig.SetName(spec.Name)
+	policyConfig.SetNamespace(spec.Namespace)
+	policyConfig.Object["metadata"] = map[string]interface{}{
+		"name":        spec.Name,
+		"namespace":   spec.Namespace,
+		"labels":      spec.Labels,
+		"annotations": spec.Annotations,
+	}
+
+	policySpec := map[string]interface{}{
+		"targets": []map[string]interface{}{
+			{
+				"name": spec.ServiceName,
+			},
+		},
+		"peers": []map[string]interface{}{
+			{
+				"mtls": map[string]interface{}{
+					"mode": spec.MTLSMode,
+				},
+			},
+		},
+	}
+
+	if spec.JWT != nil {
+		jwt := map[string]interface{}{
+			"issuer":  spec.JWT.Issuer,
+			"jwksUri": spec.JWT.JWKSURI,
+		}
+		if len(spec.JWT.Audiences) > 0 {
+			jwt["audiences"] = spec.JWT.Audiences
+		}
+		policySpec["origins"] = []map[string]interface{}{
+			{
+				"jwt": jwt,
+			},
+		}
+		policySpec["principalBinding"] = "USE_ORIGIN"
+	}
+
+	policyConfig.Object["spec"] = policySpec
+
+	return policyConfig
+}
+
+func (c *Client) CreateAuthenticationPolicy(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	policy, err := c.dynamicClient.
+		Resource(authenticationPolicyGVR).
+		Namespace(spec.GetNamespace()).
+		Create(spec, kmeta.CreateOptions{
+			TypeMeta: authenticationPolicyTypeMeta,
+		})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return policy, nil
+}
+
+func (c *Client) updateAuthenticationPolicy(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	policy, err := c.dynamicClient.
+		Resource(authenticationPolicyGVR).
+		Namespace(spec.GetNamespace()).
+		Update(spec, kmeta.UpdateOptions{
+			TypeMeta: authenticationPolicyTypeMeta,
+		})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return policy, nil
+}
+
+func (c *Client) ApplyAuthenticationPolicy(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	existing, err := c.GetAuthenticationPolicy(spec.GetName(), spec.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateAuthenticationPolicy(spec)
+	}
+	spec.SetResourceVersion(existing.GetResourceVersion())
+	return c.updateAuthenticationPolicy(spec)
+}
+
+func (c *Client) GetAuthenticationPolicy(name, namespace string) (*kunstructured.Unstructured, error) {
+	policy, err := c.dynamicClient.Resource(authenticationPolicyGVR).Namespace(namespace).Get(name, kmeta.GetOptions{
+		TypeMeta: authenticationPolicyTypeMeta,
+	})
+
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return policy, nil
+}
+
+func (c *Client) DeleteAuthenticationPolicy(name, namespace string) (bool, error) {
+	err := c.dynamicClient.Resource(authenticationPolicyGVR).Namespace(namespace).Delete(name, &kmeta.DeleteOptions{
+		TypeMeta: authenticationPolicyTypeMeta,
+	})
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (c *Client) ListAuthenticationPolicies(namespace string, opts *kmeta.ListOptions) ([]kunstructured.Unstructured, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+
+	policyList, err := c.dynamicClient.Resource(authenticationPolicyGVR).Namespace(namespace).List(*opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range policyList.Items {
+		policyList.Items[i].SetGroupVersionKind(authenticationPolicyGVK)
+	}
+	return policyList.Items, nil
+}
+
+func (c *Client) ListAuthenticationPoliciesByLabels(namespace string, labels map[string]string) ([]kunstructured.Unstructured, error) {
+	opts := &kmeta.ListOptions{
+		LabelSelector: LabelSelector(labels),
+	}
+	return c.ListAuthenticationPolicies(namespace, opts)
+}
+
+func (c *Client) ListAuthenticationPoliciesByLabel(namespace string, labelKey string, labelValue string) ([]kunstructured.Unstructured, error) {
+	return c.ListAuthenticationPoliciesByLabels(namespace, map[string]string{labelKey: labelValue})
+}
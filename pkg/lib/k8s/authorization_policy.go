@@ -0,0 +1,199 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"sort"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+var (
+	authorizationPolicyTypeMeta = kmeta.TypeMeta{
+		APIVersion: "v1beta1",
+		Kind:       "AuthorizationPolicy",
+	}
+
+	authorizationPolicyGVR = kschema.GroupVersionResource{
+		Group:    "security.istio.io",
+		Version:  "v1beta1",
+		Resource: "authorizationpolicies",
+	}
+
+	authorizationPolicyGVK = kschema.GroupVersionKind{
+		Group:   "security.istio.io",
+		Version: "v1beta1",
+		Kind:    "AuthorizationPolicy",
+	}
+)
+
+// AuthorizationPolicySpec allows requests to the selected pods only if they were authenticated with a JWT from one of
+// the request principals (e.g. "<issuer>/*") and carry all of the required claims
+type AuthorizationPolicySpec struct {
+	Name              string
+	Namespace         string
+	Selector          map[string]string
+	RequestPrincipals []string
+	RequiredClaims    map[string]string // claim -> value
+	Labels            map[string]string
+	Annotations       map[string]string
+}
+
+func AuthorizationPolicy(spec *AuthorizationPolicySpec) *kunstructured.Unstructured {
+	policyConfig := &kunstructured.Unstructured{}
+	policyConfig.SetGroupVersionKind(authorizationPolicyGVK)
+	policyConfig.SetName(spec.Name)
+	policyConfig.SetNamespace(spec.Namespace)
+	policyConfig.Object["metadata"] = map[string]interface{}{
+		"name":        spec.Name,
+		"namespace":   spec.Namespace,
+		"labels":      spec.Labels,
+		"annotations": spec.Annotations,
+	}
+
+	claims := make([]string, 0, len(spec.RequiredClaims))
+	for claim := range spec.RequiredClaims {
+		claims = append(claims, claim)
+	}
+	sort.Strings(claims)
+
+	conditions := make([]map[string]interface{}, len(claims))
+	for i, claim := range claims {
+		conditions[i] = map[string]interface{}{
+			"key":    "request.auth.claims[" + claim + "]",
+			"values": []string{spec.RequiredClaims[claim]},
+		}
+	}
+
+	rule := map[string]interface{}{
+		"from": []map[string]interface{}{
+			{
+				"source": map[string]interface{}{
+					"requestPrincipals": spec.RequestPrincipals,
+				},
+			},
+		},
+	}
+	if len(conditions) > 0 {
+		rule["when"] = conditions
+	}
+
+	policyConfig.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": spec.Selector,
+		},
+		"rules": []map[string]interface{}{rule},
+	}
+
+	return policyConfig
+}
+
+func (c *Client) CreateAuthorizationPolicy(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	policy, err := c.dynamicClient.
+		Resource(authorizationPolicyGVR).
+		Namespace(spec.GetNamespace()).
+		Create(spec, kmeta.CreateOptions{
+			TypeMeta: authorizationPolicyTypeMeta,
+		})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return policy, nil
+}
+
+func (c *Client) updateAuthorizationPolicy(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	policy, err := c.dynamicClient.
+		Resource(authorizationPolicyGVR).
+		Namespace(spec.GetNamespace()).
+		Update(spec, kmeta.UpdateOptions{
+			TypeMeta: authorizationPolicyTypeMeta,
+		})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return policy, nil
+}
+
+func (c *Client) ApplyAuthorizationPolicy(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	existing, err := c.GetAuthorizationPolicy(spec.GetName(), spec.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateAuthorizationPolicy(spec)
+	}
+	spec.SetResourceVersion(existing.GetResourceVersion())
+	return c.updateAuthorizationPolicy(spec)
+}
+
+func (c *Client) GetAuthorizationPolicy(name, namespace string) (*kunstructured.Unstructured, error) {
+	policy, err := c.dynamicClient.Resource(authorizationPolicyGVR).Namespace(namespace).Get(name, kmeta.GetOptions{
+		TypeMeta: authorizationPolicyTypeMeta,
+	})
+
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return policy, nil
+}
+
+func (c *Client) DeleteAuthorizationPolicy(name, namespace string) (bool, error) {
+	err := c.dynamicClient.Resource(authorizationPolicyGVR).Namespace(namespace).Delete(name, &kmeta.DeleteOptions{
+		TypeMeta: authorizationPolicyTypeMeta,
+	})
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (c *Client) ListAuthorizationPolicies(namespace string, opts *kmeta.ListOptions) ([]kunstructured.Unstructured, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+
+	policyList, err := c.dynamicClient.Resource(authorizationPolicyGVR).Namespace(namespace).List(*opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range policyList.Items {
+		policyList.Items[i].SetGroupVersionKind(authorizationPolicyGVK)
+	}
+	return policyList.Items, nil
+}
+
+func (c *Client) ListAuthorizationPoliciesByLabels(namespace string, labels map[string]string) ([]kunstructured.Unstructured, error) {
+	opts := &kmeta.ListOptions{
+		LabelSelector: LabelSelector(labels),
+	}
+	return c.ListAuthorizationPolicies(namespace, opts)
+}
+
+func (c *Client) ListAuthorizationPoliciesByLabel(namespace string, labelKey string, labelValue string) ([]kunstructured.Unstructured, error) {
+	return c.ListAuthorizationPoliciesByLabels(namespace, map[string]string{labelKey: labelValue})
+}
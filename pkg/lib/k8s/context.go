@@ -0,0 +1,139 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	kclientrest "k8s.io/client-go/rest"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+// OperationTimeout bounds each request to the Kubernetes API, so that a hung request can't block the caller indefinitely
+var OperationTimeout = time.Minute
+
+// WithContext returns a copy of the client whose requests are canceled when ctx is done
+// (the client-go version used here doesn't accept contexts, so they are applied to the underlying HTTP requests)
+// Building a copy is relatively expensive, so if ctx was returned by ContextWithClientCache (e.g. for an operator request or
+// a cron run), the copy is built once and reused for the rest of ctx's lifetime
+func (c *Client) WithContext(ctx context.Context) (*Client, error) {
+	cache, ok := ctx.Value(clientCacheKey{}).(*clientCache)
+	if !ok || cache.done != ctx.Done() {
+		// ctx isn't cached, or was derived from a cached context with a shorter deadline
+		return c.newContextClient(ctx)
+	}
+
+	cache.Lock()
+	defer cache.Unlock()
+	if ctxClient, ok := cache.clients[c]; ok {
+		return ctxClient, nil
+	}
+	ctxClient, err := c.newContextClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cache.clients[c] = ctxClient
+	return ctxClient, nil
+}
+
+type clientCacheKey struct{}
+
+type clientCache struct {
+	sync.Mutex
+	done    <-chan struct{}
+	clients map[*Client]*Client // base client -> copy for the context
+}
+
+// ContextWithClientCache returns a copy of ctx in which the clients returned by WithContext are cached
+func ContextWithClientCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, clientCacheKey{}, &clientCache{
+		done:    ctx.Done(),
+		clients: map[*Client]*Client{},
+	})
+}
+
+func (c *Client) newContextClient(ctx context.Context) (*Client, error) {
+	ctxRestConfig := &kclientrest.Config{
+		Host:          c.RestConfig.Host,
+		APIPath:       c.RestConfig.APIPath,
+		ContentConfig: c.RestConfig.ContentConfig,
+		UserAgent:     c.RestConfig.UserAgent,
+		QPS:           c.RestConfig.QPS,
+		Burst:         c.RestConfig.Burst,
+		Timeout:       c.RestConfig.Timeout,
+		// authentication and TLS are handled by the shared transport (which also reuses its connections)
+		Transport: &contextTransport{
+			ctx:  ctx,
+			base: c.transport,
+		},
+	}
+
+	ctxClient := &Client{
+		RestConfig: c.RestConfig,
+		Namespace:  c.Namespace,
+		transport:  c.transport,
+	}
+	if err := ctxClient.initClients(ctxRestConfig); err != nil {
+		return nil, err
+	}
+	return ctxClient, nil
+}
+
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// keep the request's own context (which enforces the client's timeout), and also cancel it when t.ctx is done
+	reqCtx, cancel := context.WithCancel(req.Context())
+	go func() {
+		select {
+		case <-t.ctx.Done():
+			cancel()
+		case <-reqCtx.Done():
+		}
+	}()
+
+	response, err := t.base.RoundTrip(req.WithContext(reqCtx))
+	if err != nil {
+		cancel()
+		if ctxErr := t.ctx.Err(); ctxErr != nil {
+			return nil, errors.Wrap(ctxErr, err.Error())
+		}
+		return nil, err
+	}
+
+	response.Body = &cancelOnCloseBody{ReadCloser: response.Body, cancel: cancel}
+	return response, nil
+}
+
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
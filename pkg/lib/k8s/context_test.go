@@ -0,0 +1,153 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	kclientrest "k8s.io/client-go/rest"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+// newBlockingClient returns a client for an API server which doesn't respond until the returned function is called
+func newBlockingClient(t *testing.T, timeout time.Duration) (*Client, func()) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	closeServer := func() {
+		close(release)
+		server.Close()
+	}
+
+	client, err := NewFromRestConfig("default", &kclientrest.Config{
+		Host:    server.URL,
+		Timeout: timeout,
+	})
+	require.NoError(t, err)
+	return client, closeServer
+}
+
+func TestWithContextCanceled(t *testing.T) {
+	client, closeServer := newBlockingClient(t, time.Minute)
+	defer closeServer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	ctxClient, err := client.WithContext(ctx)
+	require.NoError(t, err)
+	_, err = ctxClient.GetConfigMap("test")
+	require.Error(t, err)
+	require.True(t, errors.IsCanceled(err), err.Error())
+	require.False(t, errors.IsTimeout(err), err.Error())
+	require.True(t, time.Since(start) < 10*time.Second)
+}
+
+func TestWithContextDeadline(t *testing.T) {
+	client, closeServer := newBlockingClient(t, time.Minute)
+	defer closeServer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	ctxClient, err := client.WithContext(ctx)
+	require.NoError(t, err)
+	_, err = ctxClient.ListPods(nil)
+	require.Error(t, err)
+	require.True(t, errors.IsTimeout(err), err.Error())
+	require.False(t, errors.IsCanceled(err), err.Error())
+	require.True(t, time.Since(start) < 10*time.Second)
+}
+
+func TestOperationTimeout(t *testing.T) {
+	client, closeServer := newBlockingClient(t, 50*time.Millisecond)
+	defer closeServer()
+
+	start := time.Now()
+	ctxClient, err := client.WithContext(context.Background())
+	require.NoError(t, err)
+	_, err = ctxClient.GetConfigMap("test")
+	require.Error(t, err)
+	require.True(t, errors.IsTimeout(err), err.Error())
+	require.True(t, time.Since(start) < 10*time.Second)
+
+	// the client-level timeout also applies without a context
+	_, err = client.GetConfigMap("test")
+	require.Error(t, err)
+	require.True(t, errors.IsTimeout(err), err.Error())
+}
+
+func TestWithContextSucceeds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "test"}, "data": {"key": "value"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewFromRestConfig("default", &kclientrest.Config{Host: server.URL})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctxClient, err := client.WithContext(ctx)
+	require.NoError(t, err)
+	data, err := ctxClient.GetConfigMapData("test")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"key": "value"}, data)
+}
+
+func TestWithContextClientCache(t *testing.T) {
+	client, err := NewFromRestConfig("default", &kclientrest.Config{Host: "http://localhost"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// without a cache, a client is built for each call
+	ctxClient1, err := client.WithContext(ctx)
+	require.NoError(t, err)
+	ctxClient2, err := client.WithContext(ctx)
+	require.NoError(t, err)
+	require.True(t, ctxClient1 != ctxClient2)
+
+	cachedCtx := ContextWithClientCache(ctx)
+	ctxClient1, err = client.WithContext(cachedCtx)
+	require.NoError(t, err)
+	ctxClient2, err = client.WithContext(cachedCtx)
+	require.NoError(t, err)
+	require.True(t, ctxClient1 == ctxClient2)
+
+	// contexts which are derived from the cached context with a different deadline get their own client
+	timeoutCtx, timeoutCancel := context.WithTimeout(cachedCtx, time.Minute)
+	defer timeoutCancel()
+	ctxClient3, err := client.WithContext(timeoutCtx)
+	require.NoError(t, err)
+	require.True(t, ctxClient1 != ctxClient3)
+}
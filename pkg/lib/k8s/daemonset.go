@@ -0,0 +1,132 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+var daemonSetTypeMeta = kmeta.TypeMeta{
+	APIVersion: "apps/v1",
+	Kind:       "DaemonSet",
+}
+
+type DaemonSetSpec struct {
+	Name        string
+	Namespace   string
+	PodSpec     PodSpec
+	Selector    map[string]string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+func DaemonSet(spec *DaemonSetSpec) *kapps.DaemonSet {
+	if spec.Namespace == "" {
+		spec.Namespace = "default"
+	}
+	if spec.PodSpec.Namespace == "" {
+		spec.PodSpec.Namespace = spec.Namespace
+	}
+	if spec.PodSpec.Name == "" {
+		spec.PodSpec.Name = spec.Name
+	}
+	if spec.Selector == nil {
+		spec.Selector = spec.PodSpec.Labels
+	}
+
+	daemonSet := &kapps.DaemonSet{
+		TypeMeta: daemonSetTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Namespace:   spec.Namespace,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		Spec: kapps.DaemonSetSpec{
+			Template: kcore.PodTemplateSpec{
+				ObjectMeta: kmeta.ObjectMeta{
+					Name:        spec.PodSpec.Name,
+					Namespace:   spec.PodSpec.Namespace,
+					Labels:      spec.PodSpec.Labels,
+					Annotations: spec.PodSpec.Annotations,
+				},
+				Spec: spec.PodSpec.K8sPodSpec,
+			},
+			Selector: &kmeta.LabelSelector{
+				MatchLabels: spec.Selector,
+			},
+		},
+	}
+	return daemonSet
+}
+
+func (c *Client) CreateDaemonSet(daemonSet *kapps.DaemonSet) (*kapps.DaemonSet, error) {
+	daemonSet.TypeMeta = daemonSetTypeMeta
+	daemonSet, err := c.daemonSetClient.Create(daemonSet)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return daemonSet, nil
+}
+
+func (c *Client) updateDaemonSet(daemonSet *kapps.DaemonSet) (*kapps.DaemonSet, error) {
+	daemonSet.TypeMeta = daemonSetTypeMeta
+	daemonSet, err := c.daemonSetClient.Update(daemonSet)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return daemonSet, nil
+}
+
+func (c *Client) ApplyDaemonSet(daemonSet *kapps.DaemonSet) (*kapps.DaemonSet, error) {
+	existing, err := c.GetDaemonSet(daemonSet.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateDaemonSet(daemonSet)
+	}
+	return c.updateDaemonSet(daemonSet)
+}
+
+func (c *Client) GetDaemonSet(name string) (*kapps.DaemonSet, error) {
+	daemonSet, err := c.daemonSetClient.Get(name, kmeta.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	daemonSet.TypeMeta = daemonSetTypeMeta
+	return daemonSet, nil
+}
+
+func (c *Client) DeleteDaemonSet(name string) (bool, error) {
+	err := c.daemonSetClient.Delete(name, deleteOpts)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+var (
+	destinationRuleTypeMeta = kmeta.TypeMeta{
+		APIVersion: "v1alpha3",
+		Kind:       "DestinationRule",
+	}
+
+	destinationRuleGVR = kschema.GroupVersionResource{
+		Group:    "networking.istio.io",
+		Version:  "v1alpha3",
+		Resource: "destinationrules",
+	}
+
+	destinationRuleGVK = kschema.GroupVersionKind{
+		Group:   "networking.istio.io",
+		Version: "v1alpha3",
+		Kind:    "DestinationRule",
+	}
+)
+
+type DestinationRuleSpec struct {
+	Name        string
+	Namespace   string
+	Host        string
+	TLSMode     string // e.g. "ISTIO_MUTUAL" or "DISABLE"
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+func DestinationRule(spec *DestinationRuleSpec) *kunstructured.Unstructured {
+	destinationRuleConfig := &kunstructured.Unstructured{}
+	destinationRuleConfig.SetGroupVersionKind(destinationRuleGVK)
+	destinationRuleConfig.SetName(spec.Name)
+	destinationRuleConfig.SetNamespace(spec.Namespace)
+	destinationRuleConfig.Object["metadata"] = map[string]interface{}{
+		"name":        spec.Name,
+		"namespace":   spec.Namespace,
+		"labels":      spec.Labels,
+		"annotations": spec.Annotations,
+	}
+
+	destinationRuleConfig.Object["spec"] = map[string]interface{}{
+		"host": spec.Host,
+		"trafficPolicy": map[string]interface{}{
+			"tls": map[string]interface{}{
+				"mode": spec.TLSMode,
+			},
+		},
+	}
+
+	return destinationRuleConfig
+}
+
+func (c *Client) CreateDestinationRule(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	destinationRule, err := c.dynamicClient.
+		Resource(destinationRuleGVR).
+		Namespace(spec.GetNamespace()).
+		Create(spec, kmeta.CreateOptions{
+			TypeMeta: destinationRuleTypeMeta,
+		})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return destinationRule, nil
+}
+
+func (c *Client) updateDestinationRule(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	destinationRule, err := c.dynamicClient.
+		Resource(destinationRuleGVR).
+		Namespace(spec.GetNamespace()).
+		Update(spec, kmeta.UpdateOptions{
+			TypeMeta: destinationRuleTypeMeta,
+		})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return destinationRule, nil
+}
+
+func (c *Client) ApplyDestinationRule(spec *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	existing, err := c.GetDestinationRule(spec.GetName(), spec.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateDestinationRule(spec)
+	}
+	spec.SetResourceVersion(existing.GetResourceVersion())
+	return c.updateDestinationRule(spec)
+}
+
+func (c *Client) GetDestinationRule(name, namespace string) (*kunstructured.Unstructured, error) {
+	destinationRule, err := c.dynamicClient.Resource(destinationRuleGVR).Namespace(namespace).Get(name, kmeta.GetOptions{
+		TypeMeta: destinationRuleTypeMeta,
+	})
+
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return destinationRule, nil
+}
+
+func (c *Client) DeleteDestinationRule(name, namespace string) (bool, error) {
+	err := c.dynamicClient.Resource(destinationRuleGVR).Namespace(namespace).Delete(name, &kmeta.DeleteOptions{
+		TypeMeta: destinationRuleTypeMeta,
+	})
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (c *Client) ListDestinationRules(namespace string, opts *kmeta.ListOptions) ([]kunstructured.Unstructured, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+
+	drList, err := c.dynamicClient.Resource(destinationRuleGVR).Namespace(namespace).List(*opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range drList.Items {
+		drList.Items[i].SetGroupVersionKind(destinationRuleGVK)
+	}
+	return drList.Items, nil
+}
+
+func (c *Client) ListDestinationRulesByLabels(namespace string, labels map[string]string) ([]kunstructured.Unstructured, error) {
+	opts := &kmeta.ListOptions{
+		LabelSelector: LabelSelector(labels),
+	}
+	return c.ListDestinationRules(namespace, opts)
+}
+
+func (c *Client) ListDestinationRulesByLabel(namespace string, labelKey string, labelValue string) ([]kunstructured.Unstructured, error) {
+	return c.ListDestinationRulesByLabels(namespace, map[string]string{labelKey: labelValue})
+}
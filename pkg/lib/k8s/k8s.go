@@ -17,6 +17,7 @@ limitations under the License.
 package k8s
 
 import (
+	"net/http"
 	"path"
 	"regexp"
 	"strings"
@@ -30,6 +31,7 @@ import (
 	kclientbatch "k8s.io/client-go/kubernetes/typed/batch/v1"
 	kclientcore "k8s.io/client-go/kubernetes/typed/core/v1"
 	kclientextensions "k8s.io/client-go/kubernetes/typed/extensions/v1beta1"
+	kclientnetworking "k8s.io/client-go/kubernetes/typed/networking/v1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	kclientrest "k8s.io/client-go/rest"
 	kclientcmd "k8s.io/client-go/tools/clientcmd"
@@ -47,57 +49,90 @@ var (
 )
 
 type Client struct {
-	RestConfig       *kclientrest.Config
-	clientset        *kclientset.Clientset
-	dynamicClient    kclientdynamic.Interface
-	podClient        kclientcore.PodInterface
-	nodeClient       kclientcore.NodeInterface
-	serviceClient    kclientcore.ServiceInterface
-	configMapClient  kclientcore.ConfigMapInterface
-	deploymentClient kclientapps.DeploymentInterface
-	jobClient        kclientbatch.JobInterface
-	ingressClient    kclientextensions.IngressInterface
-	hpaClient        kclientautoscaling.HorizontalPodAutoscalerInterface
-	Namespace        string
+	RestConfig          *kclientrest.Config
+	transport           http.RoundTripper
+	clientset           *kclientset.Clientset
+	dynamicClient       kclientdynamic.Interface
+	podClient           kclientcore.PodInterface
+	nodeClient          kclientcore.NodeInterface
+	serviceClient       kclientcore.ServiceInterface
+	configMapClient     kclientcore.ConfigMapInterface
+	secretClient        kclientcore.SecretInterface
+	deploymentClient    kclientapps.DeploymentInterface
+	daemonSetClient     kclientapps.DaemonSetInterface
+	jobClient           kclientbatch.JobInterface
+	ingressClient       kclientextensions.IngressInterface
+	hpaClient           kclientautoscaling.HorizontalPodAutoscalerInterface
+	networkPolicyClient kclientnetworking.NetworkPolicyInterface
+	Namespace           string
 }
 
 func New(namespace string, inCluster bool) (*Client, error) {
+	var restConfig *kclientrest.Config
 	var err error
-	client := &Client{
-		Namespace: namespace,
-	}
 	if inCluster {
-		client.RestConfig, err = kclientrest.InClusterConfig()
+		restConfig, err = kclientrest.InClusterConfig()
 	} else {
 		kubeConfig := path.Join(home, ".kube", "config")
-		client.RestConfig, err = kclientcmd.BuildConfigFromFlags("", kubeConfig)
+		restConfig, err = kclientcmd.BuildConfigFromFlags("", kubeConfig)
 	}
 
 	if err != nil {
 		return nil, errors.Wrap(err, "kubeconfig")
 	}
 
-	client.clientset, err = kclientset.NewForConfig(client.RestConfig)
-	if err != nil {
-		return nil, errors.Wrap(err, "kubeconfig")
+	return NewFromRestConfig(namespace, restConfig)
+}
+
+func NewFromRestConfig(namespace string, restConfig *kclientrest.Config) (*Client, error) {
+	var err error
+	client := &Client{
+		RestConfig: restConfig,
+		Namespace:  namespace,
 	}
 
-	client.dynamicClient, err = kclientdynamic.NewForConfig(client.RestConfig)
+	if client.RestConfig.Timeout == 0 {
+		client.RestConfig.Timeout = OperationTimeout
+	}
+
+	client.transport, err = kclientrest.TransportFor(client.RestConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "kubeconfig")
 	}
 
-	client.podClient = client.clientset.CoreV1().Pods(namespace)
-	client.nodeClient = client.clientset.CoreV1().Nodes()
-	client.serviceClient = client.clientset.CoreV1().Services(namespace)
-	client.configMapClient = client.clientset.CoreV1().ConfigMaps(namespace)
-	client.deploymentClient = client.clientset.AppsV1().Deployments(namespace)
-	client.jobClient = client.clientset.BatchV1().Jobs(namespace)
-	client.ingressClient = client.clientset.ExtensionsV1beta1().Ingresses(namespace)
-	client.hpaClient = client.clientset.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace)
+	if err := client.initClients(client.RestConfig); err != nil {
+		return nil, err
+	}
 	return client, nil
 }
 
+func (c *Client) initClients(restConfig *kclientrest.Config) error {
+	var err error
+
+	c.clientset, err = kclientset.NewForConfig(restConfig)
+	if err != nil {
+		return errors.Wrap(err, "kubeconfig")
+	}
+
+	c.dynamicClient, err = kclientdynamic.NewForConfig(restConfig)
+	if err != nil {
+		return errors.Wrap(err, "kubeconfig")
+	}
+
+	c.podClient = c.clientset.CoreV1().Pods(c.Namespace)
+	c.nodeClient = c.clientset.CoreV1().Nodes()
+	c.serviceClient = c.clientset.CoreV1().Services(c.Namespace)
+	c.configMapClient = c.clientset.CoreV1().ConfigMaps(c.Namespace)
+	c.secretClient = c.clientset.CoreV1().Secrets(c.Namespace)
+	c.deploymentClient = c.clientset.AppsV1().Deployments(c.Namespace)
+	c.daemonSetClient = c.clientset.AppsV1().DaemonSets(c.Namespace)
+	c.jobClient = c.clientset.BatchV1().Jobs(c.Namespace)
+	c.ingressClient = c.clientset.ExtensionsV1beta1().Ingresses(c.Namespace)
+	c.hpaClient = c.clientset.AutoscalingV2beta2().HorizontalPodAutoscalers(c.Namespace)
+	c.networkPolicyClient = c.clientset.NetworkingV1().NetworkPolicies(c.Namespace)
+	return nil
+}
+
 // ValidName ensures name contains only lower case alphanumeric, '-', or '.'
 func ValidName(name string) string {
 	re := regexp.MustCompile(`[^a-zA-Z0-9\-\.]`)
@@ -0,0 +1,146 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	knetworking "k8s.io/api/networking/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+var networkPolicyTypeMeta = kmeta.TypeMeta{
+	APIVersion: "networking.k8s.io/v1",
+	Kind:       "NetworkPolicy",
+}
+
+type NetworkPolicySpec struct {
+	Name        string
+	Namespace   string
+	PodSelector map[string]string
+	Ingress     []knetworking.NetworkPolicyIngressRule
+	Egress      []knetworking.NetworkPolicyEgressRule
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// NetworkPolicy restricts both ingress and egress of the selected pods (an empty rule list denies all traffic in that direction)
+func NetworkPolicy(spec *NetworkPolicySpec) *knetworking.NetworkPolicy {
+	if spec.Namespace == "" {
+		spec.Namespace = "default"
+	}
+	networkPolicy := &knetworking.NetworkPolicy{
+		TypeMeta: networkPolicyTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Namespace:   spec.Namespace,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		Spec: knetworking.NetworkPolicySpec{
+			PodSelector: kmeta.LabelSelector{
+				MatchLabels: spec.PodSelector,
+			},
+			Ingress: spec.Ingress,
+			Egress:  spec.Egress,
+			PolicyTypes: []knetworking.PolicyType{
+				knetworking.PolicyTypeIngress,
+				knetworking.PolicyTypeEgress,
+			},
+		},
+	}
+	return networkPolicy
+}
+
+func (c *Client) CreateNetworkPolicy(networkPolicy *knetworking.NetworkPolicy) (*knetworking.NetworkPolicy, error) {
+	networkPolicy.TypeMeta = networkPolicyTypeMeta
+	networkPolicy, err := c.networkPolicyClient.Create(networkPolicy)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return networkPolicy, nil
+}
+
+func (c *Client) updateNetworkPolicy(networkPolicy *knetworking.NetworkPolicy) (*knetworking.NetworkPolicy, error) {
+	networkPolicy.TypeMeta = networkPolicyTypeMeta
+	networkPolicy, err := c.networkPolicyClient.Update(networkPolicy)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return networkPolicy, nil
+}
+
+func (c *Client) ApplyNetworkPolicy(networkPolicy *knetworking.NetworkPolicy) (*knetworking.NetworkPolicy, error) {
+	existing, err := c.GetNetworkPolicy(networkPolicy.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateNetworkPolicy(networkPolicy)
+	}
+	networkPolicy.ResourceVersion = existing.ResourceVersion
+	return c.updateNetworkPolicy(networkPolicy)
+}
+
+func (c *Client) GetNetworkPolicy(name string) (*knetworking.NetworkPolicy, error) {
+	networkPolicy, err := c.networkPolicyClient.Get(name, kmeta.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	networkPolicy.TypeMeta = networkPolicyTypeMeta
+	return networkPolicy, nil
+}
+
+func (c *Client) DeleteNetworkPolicy(name string) (bool, error) {
+	err := c.networkPolicyClient.Delete(name, deleteOpts)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (c *Client) ListNetworkPolicies(opts *kmeta.ListOptions) ([]knetworking.NetworkPolicy, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+	networkPolicyList, err := c.networkPolicyClient.List(*opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range networkPolicyList.Items {
+		networkPolicyList.Items[i].TypeMeta = networkPolicyTypeMeta
+	}
+	return networkPolicyList.Items, nil
+}
+
+func (c *Client) ListNetworkPoliciesByLabels(labels map[string]string) ([]knetworking.NetworkPolicy, error) {
+	opts := &kmeta.ListOptions{
+		LabelSelector: LabelSelector(labels),
+	}
+	return c.ListNetworkPolicies(opts)
+}
+
+func (c *Client) ListNetworkPoliciesByLabel(labelKey string, labelValue string) ([]knetworking.NetworkPolicy, error) {
+	return c.ListNetworkPoliciesByLabels(map[string]string{labelKey: labelValue})
+}
@@ -18,6 +18,7 @@ package k8s
 
 import (
 	"regexp"
+	"strings"
 	"time"
 
 	kcore "k8s.io/api/core/v1"
@@ -33,7 +34,13 @@ var podTypeMeta = kmeta.TypeMeta{
 	Kind:       "Pod",
 }
 
-const ReasonEvicted = "Evicted"
+const (
+	ReasonEvicted                    = "Evicted"
+	ReasonCreateContainerConfigError = "CreateContainerConfigError" // e.g. a referenced secret or secret key does not exist
+)
+
+// containers which exit because of a configuration error (e.g. their packages can't be installed) write a message with this prefix to their termination log (/dev/termination-log)
+const ConfigErrorTerminationMessagePrefix = "configuration error: "
 
 type PodStatus string
 
@@ -47,6 +54,7 @@ const (
 	PodStatusFailed       PodStatus = "Failed"
 	PodStatusKilled       PodStatus = "Killed"
 	PodStatusKilledOOM    PodStatus = "Out of Memory"
+	PodStatusConfigError  PodStatus = "Config Error"
 )
 
 var killStatuses = map[int32]bool{
@@ -142,6 +150,9 @@ func GetPodStatus(pod *kcore.Pod) PodStatus {
 
 	switch pod.Status.Phase {
 	case kcore.PodPending:
+		if hasContainerConfigError(pod.Status.InitContainerStatuses) || hasContainerConfigError(pod.Status.ContainerStatuses) {
+			return PodStatusConfigError
+		}
 		pendingPodStatus := PodStatusFromContainerStatuses(pod.Status.InitContainerStatuses)
 		if pendingPodStatus == PodStatusRunning {
 			return PodStatusInitializing
@@ -150,6 +161,9 @@ func GetPodStatus(pod *kcore.Pod) PodStatus {
 	case kcore.PodSucceeded:
 		return PodStatusSucceeded
 	case kcore.PodFailed:
+		if hasContainerConfigError(pod.Status.ContainerStatuses) {
+			return PodStatusConfigError
+		}
 		if pod.Status.Reason == ReasonEvicted && evictedMemoryMessageRegex.MatchString(pod.Status.Message) {
 			return PodStatusKilledOOM
 		}
@@ -178,12 +192,35 @@ func GetPodStatus(pod *kcore.Pod) PodStatus {
 		if pod.ObjectMeta.DeletionTimestamp != nil {
 			return PodStatusTerminating
 		}
+		if hasContainerConfigError(pod.Status.ContainerStatuses) {
+			return PodStatusConfigError
+		}
 		return PodStatusFromContainerStatuses(pod.Status.ContainerStatuses)
 	default:
 		return PodStatusUnknown
 	}
 }
 
+func hasContainerConfigError(containerStatuses []kcore.ContainerStatus) bool {
+	for _, containerStatus := range containerStatuses {
+		if containerStatus.State.Waiting != nil && containerStatus.State.Waiting.Reason == ReasonCreateContainerConfigError {
+			return true
+		}
+		if isConfigErrorTermination(containerStatus.State.Terminated) {
+			return true
+		}
+		// the container is waiting to be restarted (e.g. in CrashLoopBackOff) after exiting because of a configuration error
+		if containerStatus.State.Running == nil && isConfigErrorTermination(containerStatus.LastTerminationState.Terminated) {
+			return true
+		}
+	}
+	return false
+}
+
+func isConfigErrorTermination(terminated *kcore.ContainerStateTerminated) bool {
+	return terminated != nil && terminated.ExitCode != 0 && strings.HasPrefix(terminated.Message, ConfigErrorTerminationMessagePrefix)
+}
+
 func PodStatusFromContainerStatuses(containerStatuses []kcore.ContainerStatus) PodStatus {
 	numContainers := len(containerStatuses)
 	numWaiting := 0
@@ -282,6 +319,19 @@ func (c *Client) PodExists(name string) (bool, error) {
 	return pod != nil, nil
 }
 
+// GetPodLastLogLine returns the most recent log line of a container in the pod ("" if the container hasn't logged anything)
+func (c *Client) GetPodLastLogLine(podName string, containerName string) (string, error) {
+	tailLines := int64(1)
+	logBytes, err := c.podClient.GetLogs(podName, &kcore.PodLogOptions{
+		Container: containerName,
+		TailLines: &tailLines,
+	}).DoRaw()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return strings.TrimSpace(string(logBytes)), nil
+}
+
 func (c *Client) ListPods(opts *kmeta.ListOptions) ([]kcore.Pod, error) {
 	if opts == nil {
 		opts = &kmeta.ListOptions{}
@@ -296,6 +346,41 @@ func (c *Client) ListPods(opts *kmeta.ListOptions) ([]kcore.Pod, error) {
 	return podList.Items, nil
 }
 
+// ListPodsInAllNamespaces lists pods across all namespaces (the client's namespace is ignored)
+func (c *Client) ListPodsInAllNamespaces(opts *kmeta.ListOptions) ([]kcore.Pod, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+	podList, err := c.clientset.CoreV1().Pods(kcore.NamespaceAll).List(*opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range podList.Items {
+		podList.Items[i].TypeMeta = podTypeMeta
+	}
+	return podList.Items, nil
+}
+
+// PodRequests returns the effective resource requests of a pod (the greater of the sum of its containers' requests and any single init container's requests)
+func PodRequests(pod *kcore.Pod) kcore.ResourceList {
+	requests := kcore.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		for name, quantity := range container.Resources.Requests {
+			total := requests[name]
+			total.Add(quantity)
+			requests[name] = total
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if total, ok := requests[name]; !ok || quantity.Cmp(total) > 0 {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	return requests
+}
+
 func (c *Client) ListPodsByLabels(labels map[string]string) ([]kcore.Pod, error) {
 	opts := &kmeta.ListOptions{
 		LabelSelector: LabelSelector(labels),
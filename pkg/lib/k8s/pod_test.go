@@ -0,0 +1,159 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestGetPodStatusConfigError(t *testing.T) {
+	configErrorTermination := &kcore.ContainerStateTerminated{
+		ExitCode: 1,
+		Message:  ConfigErrorTerminationMessagePrefix + "failed to install the packages in requirements.txt",
+	}
+	failedTermination := &kcore.ContainerStateTerminated{ExitCode: 1}
+	crashLoopBackOff := kcore.ContainerState{Waiting: &kcore.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}
+
+	for _, tc := range []struct {
+		name            string
+		phase           kcore.PodPhase
+		containerStatus kcore.ContainerStatus
+		expected        PodStatus
+	}{
+		{
+			name:            "missing secret",
+			phase:           kcore.PodPending,
+			containerStatus: kcore.ContainerStatus{State: kcore.ContainerState{Waiting: &kcore.ContainerStateWaiting{Reason: ReasonCreateContainerConfigError}}},
+			expected:        PodStatusConfigError,
+		},
+		{
+			name:            "configuration error",
+			phase:           kcore.PodRunning,
+			containerStatus: kcore.ContainerStatus{State: kcore.ContainerState{Terminated: configErrorTermination}},
+			expected:        PodStatusConfigError,
+		},
+		{
+			name:            "restarting after a configuration error",
+			phase:           kcore.PodRunning,
+			containerStatus: kcore.ContainerStatus{State: crashLoopBackOff, LastTerminationState: kcore.ContainerState{Terminated: configErrorTermination}, RestartCount: 1},
+			expected:        PodStatusConfigError,
+		},
+		{
+			// the configuration error was fixed (restarted containers are counted by their last termination, as before)
+			name:            "running after a configuration error",
+			phase:           kcore.PodRunning,
+			containerStatus: kcore.ContainerStatus{State: kcore.ContainerState{Running: &kcore.ContainerStateRunning{}}, LastTerminationState: kcore.ContainerState{Terminated: configErrorTermination}, RestartCount: 1},
+			expected:        PodStatusFailed,
+		},
+		{
+			name:            "failed with a configuration error",
+			phase:           kcore.PodFailed,
+			containerStatus: kcore.ContainerStatus{State: kcore.ContainerState{Terminated: configErrorTermination}},
+			expected:        PodStatusConfigError,
+		},
+		{
+			name:            "restarting after an error",
+			phase:           kcore.PodRunning,
+			containerStatus: kcore.ContainerStatus{State: crashLoopBackOff, LastTerminationState: kcore.ContainerState{Terminated: failedTermination}, RestartCount: 1},
+			expected:        PodStatusFailed,
+		},
+		{
+			name:  "error with a termination message",
+			phase: kcore.PodRunning,
+			containerStatus: kcore.ContainerStatus{State: kcore.ContainerState{Terminated: &kcore.ContainerStateTerminated{
+				ExitCode: 1,
+				Message:  "Traceback (most recent call last): ...",
+			}}},
+			expected: PodStatusFailed,
+		},
+	} {
+		pod := &kcore.Pod{
+			Status: kcore.PodStatus{
+				Phase:             tc.phase,
+				ContainerStatuses: []kcore.ContainerStatus{tc.containerStatus},
+			},
+		}
+		require.Equal(t, tc.expected, GetPodStatus(pod), tc.name)
+	}
+}
+
+func testContainer(cpu string, mem string) kcore.Container {
+	requests := kcore.ResourceList{}
+	if cpu != "" {
+		requests[kcore.ResourceCPU] = kresource.MustParse(cpu)
+	}
+	if mem != "" {
+		requests[kcore.ResourceMemory] = kresource.MustParse(mem)
+	}
+	return kcore.Container{Resources: kcore.ResourceRequirements{Requests: requests}}
+}
+
+func TestPodRequests(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		containers     []kcore.Container
+		initContainers []kcore.Container
+		expectedCPU    string
+		expectedMem    string
+	}{
+		{
+			name:        "containers are summed",
+			containers:  []kcore.Container{testContainer("1", "1Gi"), testContainer("100m", "128Mi")},
+			expectedCPU: "1100m",
+			expectedMem: "1152Mi",
+		},
+		{
+			name:           "init containers take the max rather than the sum",
+			containers:     []kcore.Container{testContainer("500m", "512Mi")},
+			initContainers: []kcore.Container{testContainer("2", "256Mi"), testContainer("1", "1Gi")},
+			expectedCPU:    "2",
+			expectedMem:    "1Gi",
+		},
+		{
+			name:           "init containers which request less than the containers",
+			containers:     []kcore.Container{testContainer("1", "1Gi"), testContainer("1", "1Gi")},
+			initContainers: []kcore.Container{testContainer("1500m", "1536Mi")},
+			expectedCPU:    "2",
+			expectedMem:    "2Gi",
+		},
+		{
+			name:           "resources which are only requested by init containers",
+			containers:     []kcore.Container{testContainer("1", "")},
+			initContainers: []kcore.Container{testContainer("", "1Gi"), testContainer("", "2Gi")},
+			expectedCPU:    "1",
+			expectedMem:    "2Gi",
+		},
+	} {
+		pod := &kcore.Pod{
+			Spec: kcore.PodSpec{
+				Containers:     tc.containers,
+				InitContainers: tc.initContainers,
+			},
+		}
+		requests := PodRequests(pod)
+		require.Len(t, requests, 2, tc.name)
+		cpu, mem := requests[kcore.ResourceCPU], requests[kcore.ResourceMemory]
+		require.Zero(t, cpu.Cmp(kresource.MustParse(tc.expectedCPU)), tc.name)
+		require.Zero(t, mem.Cmp(kresource.MustParse(tc.expectedMem)), tc.name)
+	}
+
+	require.Empty(t, PodRequests(&kcore.Pod{}))
+}
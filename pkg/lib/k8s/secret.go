@@ -0,0 +1,57 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	kcore "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+var secretTypeMeta = kmeta.TypeMeta{
+	APIVersion: "v1",
+	Kind:       "Secret",
+}
+
+func (c *Client) GetSecret(name string) (*kcore.Secret, error) {
+	secret, err := c.secretClient.Get(name, kmeta.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	secret.TypeMeta = secretTypeMeta
+	return secret, nil
+}
+
+// SecretKeyEnvVar returns an environment variable which is populated from a key of a secret
+func SecretKeyEnvVar(envVarName string, secretName string, secretKey string) kcore.EnvVar {
+	return kcore.EnvVar{
+		Name: envVarName,
+		ValueFrom: &kcore.EnvVarSource{
+			SecretKeyRef: &kcore.SecretKeySelector{
+				LocalObjectReference: kcore.LocalObjectReference{
+					Name: secretName,
+				},
+				Key: secretKey,
+			},
+		},
+	}
+}
@@ -48,6 +48,26 @@ func Run(fns ...func() error) []error {
 	return errors
 }
 
+// RunLimited is like Run, but runs at most limit functions at once
+func RunLimited(limit int, fns ...func() error) []error {
+	if limit <= 0 || limit >= len(fns) {
+		return Run(fns...)
+	}
+
+	semaphore := make(chan struct{}, limit)
+	limitedFns := make([]func() error, len(fns))
+	for i := range fns {
+		fn := fns[i]
+		limitedFns[i] = func() error {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			return fn()
+		}
+	}
+
+	return Run(limitedFns...)
+}
+
 func RunFirstErr(fns ...func() error) error {
 	errs := Run(fns...)
 	return errors.FirstError(errs...)
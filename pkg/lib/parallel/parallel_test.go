@@ -16,6 +16,82 @@ limitations under the License.
 
 package parallel
 
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// limitedFns returns functions which record the maximum number of them which run at once; function i returns an error if i is odd
+func limitedFns(n int) ([]func() error, func() int) {
+	var mutex sync.Mutex
+	running := 0
+	maxRunning := 0
+
+	fns := make([]func() error, n)
+	for i := range fns {
+		i := i
+		fns[i] = func() error {
+			mutex.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mutex.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+
+			if i%2 == 1 {
+				return fmt.Errorf("error %d", i)
+			}
+			return nil
+		}
+	}
+
+	return fns, func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return maxRunning
+	}
+}
+
+func TestRunLimited(t *testing.T) {
+	fns, maxRunning := limitedFns(10)
+	errs := RunLimited(3, fns...)
+	require.Len(t, errs, 10)
+	for i, err := range errs {
+		if i%2 == 1 {
+			require.EqualError(t, err, fmt.Sprintf("error %d", i))
+		} else {
+			require.NoError(t, err)
+		}
+	}
+	require.True(t, maxRunning() <= 3, "%d functions ran at once", maxRunning())
+	require.True(t, maxRunning() > 1, "functions didn't run in parallel")
+
+	fns, maxRunning = limitedFns(10)
+	RunLimited(1, fns...)
+	require.Equal(t, 1, maxRunning())
+
+	// a limit which is not positive, or which is at least the number of functions, doesn't limit them
+	fns, maxRunning = limitedFns(5)
+	require.Len(t, RunLimited(0, fns...), 5)
+	require.Equal(t, 5, maxRunning())
+
+	fns, maxRunning = limitedFns(5)
+	require.Len(t, RunLimited(10, fns...), 5)
+	require.Equal(t, 5, maxRunning())
+
+	require.Nil(t, RunLimited(2))
+}
+
 //
 // These tests must be run and verified manually:
 // go test github.com/cortexlabs/cortex/pkg/lib/parallel -run TestRunInParallel -v
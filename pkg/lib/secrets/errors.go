@@ -0,0 +1,105 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+type ErrorKind int
+
+const (
+	ErrUnknown ErrorKind = iota
+	ErrSecretsDetected
+	ErrInvalidAllowListEntry
+)
+
+var errorKinds = []string{
+	"err_unknown",
+	"err_secrets_detected",
+	"err_invalid_allow_list_entry",
+}
+
+var _ = [1]int{}[int(ErrInvalidAllowListEntry)-(len(errorKinds)-1)] // Ensure list length matches
+
+func (t ErrorKind) String() string {
+	return errorKinds[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t ErrorKind) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *ErrorKind) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(errorKinds); i++ {
+		if enum == errorKinds[i] {
+			*t = ErrorKind(i)
+			return nil
+		}
+	}
+
+	*t = ErrUnknown
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *ErrorKind) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t ErrorKind) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+type Error struct {
+	Kind    ErrorKind
+	message string
+}
+
+func (e Error) Error() string {
+	return e.message
+}
+
+func ErrorSecretsDetected(findings []Finding) error {
+	findingStrs := make([]string, len(findings))
+	for i, finding := range findings {
+		findingStrs[i] = "  " + finding.String()
+	}
+
+	message := "possible secrets were found in your project files:\n" + strings.Join(findingStrs, "\n")
+	message += fmt.Sprintf("\n\nremove them before deploying, add false positives to %s (one <path>[:<line>] per line, paths may contain wildcards), or run with --allow-secrets to deploy anyway", AllowListFileName)
+
+	return errors.WithStack(Error{
+		Kind:    ErrSecretsDetected,
+		message: message,
+	})
+}
+
+func ErrorInvalidAllowListEntry(entry string, lineNum int) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidAllowListEntry,
+		message: fmt.Sprintf("%s:%d: invalid entry \"%s\" (expected <path>[:<line>])", AllowListFileName, lineNum, entry),
+	})
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+)
+
+// AllowListFileName is the name of the allow-list file in the project root (hidden files are not uploaded)
+const AllowListFileName = ".cortex-allow-secrets"
+
+// InlineAllowComment can be added to a line to suppress findings on that line
+const InlineAllowComment = "cortex:allow-secret"
+
+// MaxScanFileSize is the size of the largest file which is scanned (larger files are skipped)
+const MaxScanFileSize = 5 * 1024 * 1024
+
+const (
+	_minEntropyLength  = 20
+	_minEntropy        = 4.0
+	_binarySniffLength = 8000
+)
+
+type pattern struct {
+	kind  string
+	regex *regexp.Regexp
+}
+
+var _patterns = []pattern{
+	{"aws access key id", regexp.MustCompile(`\b(AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA)[A-Z0-9]{16}\b`)},
+	{"aws secret access key", regexp.MustCompile(`(?i)aws_?secret_?(access_?)?key\S{0,3}\s*[:=]\s*["']?[A-Za-z0-9/+=]{40}\b`)},
+	{"private key", regexp.MustCompile(`-----BEGIN ((RSA|DSA|EC|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`)},
+	{"github token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{"slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{"stripe key", regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{24,}\b`)},
+	{"google api key", regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{"json web token", regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}\b`)},
+}
+
+// High-entropy strings are only flagged in config files, where they are likely to be credentials rather than data
+var _configFileExts = map[string]bool{
+	".env":        true,
+	".yaml":       true,
+	".yml":        true,
+	".json":       true,
+	".ini":        true,
+	".cfg":        true,
+	".conf":       true,
+	".toml":       true,
+	".properties": true,
+}
+
+var _assignmentRegex = regexp.MustCompile(`[A-Za-z0-9_\-"']+\s*[:=]\s*(.+)$`)
+var _entropyTokenRegex = regexp.MustCompile(`[A-Za-z0-9+=_\-]{20,}`)
+
+type Finding struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Kind string `json:"kind"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d: %s", f.Path, f.Line, f.Kind)
+}
+
+type allowListEntry struct {
+	pathPattern string
+	line        int // 0 means any line
+}
+
+type AllowList struct {
+	entries []allowListEntry
+}
+
+// ReadAllowList parses the allow-list file at path; a missing file results in an empty allow-list
+func ReadAllowList(path string) (*AllowList, error) {
+	allowList := &AllowList{}
+	if !files.IsFile(path) {
+		return allowList, nil
+	}
+
+	content, err := files.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entry := allowListEntry{pathPattern: line}
+		if colonIndex := strings.LastIndex(line, ":"); colonIndex != -1 {
+			lineNum, err := strconv.Atoi(line[colonIndex+1:])
+			if err != nil || lineNum < 1 {
+				return nil, ErrorInvalidAllowListEntry(line, i+1)
+			}
+			entry = allowListEntry{pathPattern: line[:colonIndex], line: lineNum}
+		}
+		if _, err := filepath.Match(entry.pathPattern, ""); err != nil {
+			return nil, ErrorInvalidAllowListEntry(line, i+1)
+		}
+
+		allowList.entries = append(allowList.entries, entry)
+	}
+
+	return allowList, nil
+}
+
+func (allowList *AllowList) Allows(finding Finding) bool {
+	if allowList == nil {
+		return false
+	}
+	for _, entry := range allowList.entries {
+		if entry.line != 0 && entry.line != finding.Line {
+			continue
+		}
+		if matched, _ := filepath.Match(entry.pathPattern, finding.Path); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanFiles scans the given files for secrets, and also returns the files which were too large to scan; paths in
+// findings and skipped files are relative to baseDir
+func ScanFiles(paths []string, baseDir string, allowList *AllowList) ([]Finding, []string, error) {
+	var findings []Finding
+	var skippedPaths []string
+
+	for _, path := range paths {
+		fileInfo, err := os.Stat(path)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, files.UserPath(path))
+		}
+		if fileInfo.IsDir() {
+			continue
+		}
+
+		relPath := files.TrimDirPrefix(path, baseDir)
+		if fileInfo.Size() > MaxScanFileSize {
+			skippedPaths = append(skippedPaths, relPath)
+			continue
+		}
+
+		content, err := files.ReadFileBytes(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, finding := range ScanBytes(relPath, content) {
+			if !allowList.Allows(finding) {
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	sort.Strings(skippedPaths)
+
+	return findings, skippedPaths, nil
+}
+
+// ScanBytes scans a file's contents for secrets (binary files are skipped)
+func ScanBytes(path string, content []byte) []Finding {
+	if isBinary(content) {
+		return nil
+	}
+
+	isConfigFile := _configFileExts[strings.ToLower(filepath.Ext(path))] || strings.HasSuffix(strings.ToLower(filepath.Base(path)), "env")
+
+	var findings []Finding
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), MaxScanFileSize)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if strings.Contains(line, InlineAllowComment) {
+			continue
+		}
+
+		if kind := scanLine(line, isConfigFile); kind != "" {
+			findings = append(findings, Finding{Path: path, Line: lineNum, Kind: kind})
+		}
+	}
+
+	return findings
+}
+
+func scanLine(line string, isConfigFile bool) string {
+	for _, p := range _patterns {
+		if p.regex.MatchString(line) {
+			return p.kind
+		}
+	}
+
+	if !isConfigFile {
+		return ""
+	}
+
+	match := _assignmentRegex.FindStringSubmatch(line)
+	if match == nil {
+		return ""
+	}
+	for _, token := range _entropyTokenRegex.FindAllString(match[1], -1) {
+		if isHighEntropy(token) {
+			return "high-entropy string"
+		}
+	}
+
+	return ""
+}
+
+func isHighEntropy(token string) bool {
+	if len(token) < _minEntropyLength {
+		return false
+	}
+
+	hasLetter := strings.IndexFunc(token, func(r rune) bool { return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') }) != -1
+	hasDigit := strings.IndexFunc(token, func(r rune) bool { return r >= '0' && r <= '9' }) != -1
+	if !hasLetter || !hasDigit {
+		return false
+	}
+
+	return shannonEntropy(token) >= _minEntropy
+}
+
+func shannonEntropy(str string) float64 {
+	counts := map[rune]int{}
+	for _, r := range str {
+		counts[r]++
+	}
+
+	var entropy float64
+	length := float64(len(str))
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+func isBinary(content []byte) bool {
+	sniffLength := len(content)
+	if sniffLength > _binarySniffLength {
+		sniffLength = _binarySniffLength
+	}
+	return bytes.IndexByte(content[:sniffLength], 0) != -1
+}
@@ -39,6 +39,7 @@ type Context struct {
 	APIs              APIs                          `json:"apis"`
 	ProjectID         string                        `json:"project_id"`
 	ProjectKey        string                        `json:"project_key"`
+	HasRequirements   bool                          `json:"has_requirements"` // the project has a requirements.txt file
 }
 
 type Resource interface {
@@ -17,6 +17,8 @@ limitations under the License.
 package resource
 
 import (
+	"fmt"
+
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 )
 
@@ -33,8 +35,15 @@ type APIStatus struct {
 	InitReplicas         int32 `json:"init_replicas"`
 	TargetCPUUtilization int32 `json:"target_cpu_utilization"`
 	ReplicaCounts        `json:"replica_counts"`
-	PodStatuses          []k8s.PodStatus `json:"pod_statuses"`
-	Code                 StatusCode      `json:"status_code"`
+	PodStatuses          []k8s.PodStatus   `json:"pod_statuses"`
+	DownloadProgress     *DownloadProgress `json:"download_progress"` // Progress of the downloader init container of an initializing replica, if any
+	Code                 StatusCode        `json:"status_code"`
+}
+
+type DownloadProgress struct {
+	ItemName        string `json:"item_name"`
+	DownloadedBytes int64  `json:"downloaded_bytes"`
+	TotalBytes      int64  `json:"total_bytes"`
 }
 
 type ReplicaCounts struct {
@@ -47,9 +56,10 @@ type ReplicaCounts struct {
 
 // There is one APIGroupStatus per API name/endpoint
 type APIGroupStatus struct {
-	APIName              string     `json:"api_name"`
-	ActiveStatus         *APIStatus `json:"active_status"` // The most recently ready API status, or the ctx API status if it's ready
-	Code                 StatusCode `json:"status_code"`
+	APIName              string            `json:"api_name"`
+	ActiveStatus         *APIStatus        `json:"active_status"` // The most recently ready API status, or the ctx API status if it's ready
+	DownloadProgress     *DownloadProgress `json:"download_progress"`
+	Code                 StatusCode        `json:"status_code"`
 	GroupedReplicaCounts `json:"grouped_replica_counts"`
 }
 
@@ -110,6 +120,7 @@ const (
 	StatusParentFailed
 	StatusParentKilled
 	StatusKilledOOM
+	StatusConfigError
 
 	// Data statuses
 	StatusRunning
@@ -134,6 +145,7 @@ var statusCodes = []string{
 	"status_parent_failed",
 	"status_parent_killed",
 	"status_killed_oom",
+	"status_config_error",
 
 	"status_running",
 	"status_succeeded",
@@ -158,6 +170,7 @@ var statusCodeMessages = []string{
 	"upstream error",        // StatusParentFailed
 	"upstream termination",  // StatusParentKilled
 	"error (out of memory)", // StatusKilledOOM
+	"error (misconfigured)", // StatusConfigError
 
 	"running",    // StatusRunning
 	"ready",      // StatusSucceeded
@@ -182,6 +195,7 @@ var statusSortBuckets = []int{
 	2, // StatusParentFailed
 	2, // StatusParentKilled
 	1, // StatusKilledOOM
+	1, // StatusConfigError
 
 	3, // StatusRunning
 	0, // StatusSucceeded
@@ -221,11 +235,41 @@ func (status *DataStatus) Message() string {
 }
 
 func (status *APIStatus) Message() string {
-	return status.Code.Message()
+	return apiStatusMessage(status.Code, status.DownloadProgress)
 }
 
 func (status *APIGroupStatus) Message() string {
-	return status.Code.Message()
+	return apiStatusMessage(status.Code, status.DownloadProgress)
+}
+
+func apiStatusMessage(code StatusCode, downloadProgress *DownloadProgress) string {
+	if downloadProgress != nil && (code == StatusUpdating || code == StatusPending) {
+		return downloadProgress.String()
+	}
+	return code.Message()
+}
+
+// e.g. "downloading the model (3.2/8.0 GB)"
+func (progress *DownloadProgress) String() string {
+	units := []struct {
+		name    string
+		divisor int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+	}
+
+	sizeStr := fmt.Sprintf("%d/%d B", progress.DownloadedBytes, progress.TotalBytes)
+	for _, unit := range units {
+		if progress.TotalBytes >= unit.divisor {
+			divisor := float64(unit.divisor)
+			sizeStr = fmt.Sprintf("%.1f/%.1f %s", float64(progress.DownloadedBytes)/divisor, float64(progress.TotalBytes)/divisor, unit.name)
+			break
+		}
+	}
+
+	return fmt.Sprintf("downloading %s (%s)", progress.ItemName, sizeStr)
 }
 
 // MarshalText satisfies TextMarshaler
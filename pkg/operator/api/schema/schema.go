@@ -19,14 +19,27 @@ package schema
 import (
 	"time"
 
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+
 	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
 	"github.com/cortexlabs/cortex/pkg/operator/api/context"
 	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
 )
 
 type InfoResponse struct {
 	MaskedAWSAccessKeyID string                        `json:"masked_aws_access_key_id"`
 	ClusterConfig        *clusterconfig.InternalConfig `json:"cluster_config"`
+	ActiveFreezes        []clusterconfig.FreezePeriod  `json:"active_freezes"`
+	UpcomingFreezes      []clusterconfig.FreezePeriod  `json:"upcoming_freezes"`
+	ImageCache           []NodeImageCache              `json:"image_cache"`
+}
+
+type NodeImageCache struct {
+	NodeName     string `json:"node_name"`
+	Images       int    `json:"images"`
+	CachedImages int    `json:"cached_images"`
 }
 
 type DeployResponse struct {
@@ -40,7 +53,8 @@ type DeleteResponse struct {
 }
 
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error          string             `json:"error"`
+	SourcePosition *cr.SourcePosition `json:"source_position,omitempty"`
 }
 
 type GetResourcesResponse struct {
@@ -61,6 +75,65 @@ type GetDeploymentsResponse struct {
 	Deployments []Deployment `json:"deployments"`
 }
 
+type GetOverviewResponse struct {
+	Deployments      []DeploymentOverview `json:"deployments"`
+	ClusterResources ClusterResources     `json:"cluster_resources"`
+	APIsBaseURL      string               `json:"apis_base_url"`
+}
+
+type DeploymentOverview struct {
+	Deployment
+	APIs []APIOverview `json:"apis"`
+}
+
+type APIOverview struct {
+	Name        string                   `json:"name"`
+	GroupStatus *resource.APIGroupStatus `json:"group_status"`
+	Compute     *userconfig.APICompute   `json:"compute"`
+	Endpoint    string                   `json:"endpoint"`
+}
+
+// Resource totals across the cluster's worker nodes
+type ClusterResources struct {
+	NumNodes  int            `json:"num_nodes"`
+	Requested ResourceTotals `json:"requested"` // Requests of all pods scheduled on worker nodes (including system pods)
+	Capacity  ResourceTotals `json:"capacity"`  // Allocatable resources of worker nodes
+}
+
+type ResourceTotals struct {
+	CPU kresource.Quantity `json:"cpu"`
+	Mem kresource.Quantity `json:"mem"`
+	GPU int64              `json:"gpu"`
+}
+
+type GetNodesResponse struct {
+	Nodes           []NodeInfo      `json:"nodes"`
+	PendingReplicas []APIReplicas   `json:"pending_replicas"` // API replicas which haven't been scheduled on a node
+	Headroom        ResourceTotals  `json:"headroom"`         // Allocatable resources of worker nodes which haven't been requested
+	LargestReplica  *ResourceTotals `json:"largest_replica"`  // Compute of the largest API replica which would fit on a node (nil if there are no worker nodes)
+}
+
+type NodeInfo struct {
+	Name             string         `json:"name"`
+	InstanceType     string         `json:"instance_type"`
+	Spot             bool           `json:"spot"`
+	AvailabilityZone string         `json:"availability_zone"`
+	Created          time.Time      `json:"created"`
+	Requested        ResourceTotals `json:"requested"`   // Requests of all pods scheduled on the node (including system pods)
+	Allocatable      ResourceTotals `json:"allocatable"` // Allocatable resources of the node
+	LargestReplica   ResourceTotals `json:"largest_replica"`
+	APIReplicas      []APIReplicas  `json:"api_replicas"`
+}
+
+// The replicas of an API on a node (or pending)
+type APIReplicas struct {
+	AppName string         `json:"app_name"`
+	APIName string         `json:"api_name"`
+	Count   int            `json:"count"`
+	Compute ResourceTotals `json:"compute"`          // Compute of each replica
+	Reason  string         `json:"reason,omitempty"` // Why the replicas haven't been scheduled (only for pending replicas)
+}
+
 type FeatureSignature struct {
 	Shape []interface{} `json:"shape"`
 	Type  string        `json:"type"`
@@ -70,3 +143,22 @@ type APISummary struct {
 	Message        string                      `json:"message"`
 	ModelSignature map[string]FeatureSignature `json:"model_signature"`
 }
+
+type GetUsageResponse struct {
+	Start   string     `json:"start"` // YYYY-MM-DD (UTC)
+	End     string     `json:"end"`   // YYYY-MM-DD (UTC), inclusive
+	GroupBy []string   `json:"group_by"`
+	Rows    []UsageRow `json:"rows"`
+}
+
+// UsageRow holds the usage of a group of requests; the fields which weren't grouped by are empty
+type UsageRow struct {
+	Consumer string   `json:"consumer,omitempty"`
+	APIName  string   `json:"api_name,omitempty"`
+	Day      string   `json:"day,omitempty"`
+	Requests int      `json:"requests"`
+	Code2XX  int      `json:"code_2xx"`
+	Code4XX  int      `json:"code_4xx"`
+	Code5XX  int      `json:"code_5xx"`
+	Latency  *float64 `json:"latency"` // average, in milliseconds
+}
@@ -18,12 +18,15 @@ package userconfig
 
 import (
 	"fmt"
+	"net"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
 	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
@@ -37,10 +40,14 @@ type APIs []*API
 
 type API struct {
 	ResourceFields
-	Endpoint  *string     `json:"endpoint" yaml:"endpoint"`
-	Predictor *Predictor  `json:"predictor" yaml:"predictor"`
-	Tracker   *Tracker    `json:"tracker" yaml:"tracker"`
-	Compute   *APICompute `json:"compute" yaml:"compute"`
+	Endpoint           *string                           `json:"endpoint" yaml:"endpoint"`
+	Predictor          *Predictor                        `json:"predictor" yaml:"predictor"`
+	Tracker            *Tracker                          `json:"tracker" yaml:"tracker"`
+	Compute            *APICompute                       `json:"compute" yaml:"compute"`
+	Egress             []string                          `json:"egress" yaml:"egress"`
+	SecurityOptOuts    []string                          `json:"security_opt_outs" yaml:"security_opt_outs"`
+	PythonPackageIndex *clusterconfig.PythonPackageIndex `json:"python_package_index" yaml:"python_package_index"`
+	JWTAuth            *JWTAuth                          `json:"jwt_auth" yaml:"jwt_auth"`
 }
 
 type Tracker struct {
@@ -49,13 +56,15 @@ type Tracker struct {
 }
 
 type Predictor struct {
-	Type         PredictorType          `json:"type" yaml:"type"`
-	Path         string                 `json:"path" yaml:"path"`
-	Model        *string                `json:"model" yaml:"model"`
-	PythonPath   *string                `json:"python_path" yaml:"python_path"`
-	Config       map[string]interface{} `json:"config" yaml:"config"`
-	Env          map[string]string      `json:"env" yaml:"env"`
-	SignatureKey *string                `json:"signature_key" yaml:"signature_key"`
+	Type          PredictorType          `json:"type" yaml:"type"`
+	Path          string                 `json:"path" yaml:"path"`
+	Model         *string                `json:"model" yaml:"model"`
+	ModelManifest *string                `json:"model_manifest" yaml:"model_manifest"`
+	PythonPath    *string                `json:"python_path" yaml:"python_path"`
+	Config        map[string]interface{} `json:"config" yaml:"config"`
+	Env           map[string]string      `json:"env" yaml:"env"`
+	SignatureKey  *string                `json:"signature_key" yaml:"signature_key"`
+	Runtime       *PredictorRuntime      `json:"runtime" yaml:"runtime"`
 }
 
 var predictorValidation = &cr.StructFieldValidation{
@@ -85,6 +94,12 @@ var predictorValidation = &cr.StructFieldValidation{
 					Validator: cr.S3PathValidator(),
 				},
 			},
+			{
+				StructField: "ModelManifest",
+				StringPtrValidation: &cr.StringPtrValidation{
+					Validator: cr.S3PathValidator(),
+				},
+			},
 			{
 				StructField: "PythonPath",
 				StringPtrValidation: &cr.StringPtrValidation{
@@ -111,6 +126,7 @@ var predictorValidation = &cr.StructFieldValidation{
 				StructField:         "SignatureKey",
 				StringPtrValidation: &cr.StringPtrValidation{},
 			},
+			predictorRuntimeValidation,
 		},
 	},
 }
@@ -157,12 +173,58 @@ var apiValidation = &cr.StructValidation{
 				},
 			},
 		},
+		{
+			StructField: "Egress",
+			StringListValidation: &cr.StringListValidation{
+				Default:      []string{},
+				AllowEmpty:   true,
+				DisallowDups: true,
+				Validator:    validateEgressDestinations,
+			},
+		},
+		{
+			StructField: "SecurityOptOuts",
+			StringListValidation: &cr.StringListValidation{
+				Default:      []string{},
+				AllowEmpty:   true,
+				DisallowDups: true,
+				Validator:    validateSecurityOptOuts,
+			},
+		},
+		clusterconfig.PythonPackageIndexValidation("PythonPackageIndex"),
+		jwtAuthValidation,
 		predictorValidation,
 		apiComputeFieldValidation,
 		typeFieldValidation,
 	},
 }
 
+// InstanceMetadataCIDR is always blocked for API pods, regardless of the egress allow-list
+const InstanceMetadataCIDR = "169.254.169.254/32"
+
+var _hostnameRegex = regexp.MustCompile(`^([a-zA-Z0-9]([-a-zA-Z0-9]*[a-zA-Z0-9])?\.)+[a-zA-Z]([-a-zA-Z0-9]*[a-zA-Z0-9])?$`)
+
+func validateEgressDestinations(destinations []string) ([]string, error) {
+	for _, destination := range destinations {
+		if _, ipNet, err := net.ParseCIDR(destination); err == nil {
+			if ipNet.IP.To4() == nil || ipNet.String() == InstanceMetadataCIDR {
+				return nil, ErrorInvalidEgressDestination(destination)
+			}
+			continue
+		}
+		if !_hostnameRegex.MatchString(destination) {
+			return nil, ErrorInvalidEgressDestination(destination)
+		}
+	}
+	return destinations, nil
+}
+
+// IsEgressCIDR returns true if the egress destination is a CIDR block (as opposed to a hostname)
+func IsEgressCIDR(destination string) bool {
+	_, _, err := net.ParseCIDR(destination)
+	return err == nil
+}
+
 // IsValidTensorFlowS3Directory checks that the path contains a valid S3 directory for TensorFlow models
 // Must contain the following structure:
 // - 1523423423/ (version prefix, usually a timestamp)
@@ -235,7 +297,7 @@ func ValidatePythonPath(path string, projectFileMap map[string][]byte) error {
 		}
 	}
 	if !validPythonPath {
-		return errors.Wrap(ErrorImplDoesNotExist(path), PythonPathKey)
+		return cr.WithKey(errors.Wrap(ErrorImplDoesNotExist(path), PythonPathKey), PythonPathKey)
 	}
 	return nil
 }
@@ -256,6 +318,49 @@ func (api *API) UserConfigStr() string {
 		sb.WriteString(fmt.Sprintf("%s:\n", TrackerKey))
 		sb.WriteString(s.Indent(api.Tracker.UserConfigStr(), "  "))
 	}
+	if len(api.Egress) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", EgressKey))
+		for _, destination := range api.Egress {
+			sb.WriteString(fmt.Sprintf("  - %s\n", destination))
+		}
+	}
+	if len(api.SecurityOptOuts) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", SecurityOptOutsKey))
+		for _, optOut := range api.SecurityOptOuts {
+			sb.WriteString(fmt.Sprintf("  - %s\n", optOut))
+		}
+	}
+	if api.PythonPackageIndex != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", PythonPackageIndexKey))
+		sb.WriteString(s.Indent(pythonPackageIndexUserConfigStr(api.PythonPackageIndex), "  "))
+	}
+	if api.JWTAuth != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", JWTAuthKey))
+		sb.WriteString(s.Indent(api.JWTAuth.UserConfigStr(), "  "))
+	}
+	return sb.String()
+}
+
+func pythonPackageIndexUserConfigStr(index *clusterconfig.PythonPackageIndex) string {
+	var sb strings.Builder
+	if index.URL != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", clusterconfig.URLKey, *index.URL))
+	}
+	if len(index.ExtraURLs) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", clusterconfig.ExtraURLsKey))
+		for _, extraURL := range index.ExtraURLs {
+			sb.WriteString(fmt.Sprintf("  - %s\n", extraURL))
+		}
+	}
+	if len(index.TrustedHosts) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", clusterconfig.TrustedHostsKey))
+		for _, host := range index.TrustedHosts {
+			sb.WriteString(fmt.Sprintf("  - %s\n", host))
+		}
+	}
+	if index.CredentialsSecret != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", clusterconfig.CredentialsSecretKey, *index.CredentialsSecret))
+	}
 	return sb.String()
 }
 
@@ -278,7 +383,7 @@ func (apis APIs) Validate(deploymentName string, projectFileMap map[string][]byt
 	endpoints := map[string]string{} // endpoint -> API name
 	for _, api := range apis {
 		if dupAPIName, ok := endpoints[*api.Endpoint]; ok {
-			return ErrorDuplicateEndpoints(*api.Endpoint, dupAPIName, api.Name)
+			return AttachSourcePosition(api, ErrorDuplicateEndpoints(*api.Endpoint, dupAPIName, api.Name), EndpointKey)
 		}
 		endpoints[*api.Endpoint] = api.Name
 	}
@@ -290,7 +395,7 @@ func (apis APIs) Validate(deploymentName string, projectFileMap map[string][]byt
 
 	dups := FindDuplicateResourceName(resources...)
 	if len(dups) > 0 {
-		return ErrorDuplicateResourceName(dups...)
+		return AttachSourcePosition(dups[len(dups)-1], ErrorDuplicateResourceName(dups...))
 	}
 
 	return nil
@@ -303,6 +408,9 @@ func (predictor *Predictor) UserConfigStr() string {
 	if predictor.Model != nil {
 		sb.WriteString(fmt.Sprintf("%s: %s\n", ModelKey, *predictor.Model))
 	}
+	if predictor.ModelManifest != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ModelManifestKey, *predictor.ModelManifest))
+	}
 	if predictor.SignatureKey != nil {
 		sb.WriteString(fmt.Sprintf("%s: %s\n", SignatureKeyKey, *predictor.SignatureKey))
 	}
@@ -319,10 +427,18 @@ func (predictor *Predictor) UserConfigStr() string {
 		d, _ := yaml.Marshal(&predictor.Env)
 		sb.WriteString(s.Indent(string(d), "  "))
 	}
+	if predictor.Runtime != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", RuntimeKey))
+		sb.WriteString(s.Indent(predictor.Runtime.UserConfigStr(), "  "))
+	}
 	return sb.String()
 }
 
 func (predictor *Predictor) Validate(projectFileMap map[string][]byte) error {
+	if err := predictor.Runtime.Validate(predictor.Type); err != nil {
+		return err
+	}
+
 	switch predictor.Type {
 	case PythonPredictorType:
 		if err := predictor.PythonValidate(); err != nil {
@@ -339,7 +455,7 @@ func (predictor *Predictor) Validate(projectFileMap map[string][]byte) error {
 	}
 
 	if _, ok := projectFileMap[predictor.Path]; !ok {
-		return errors.Wrap(ErrorImplDoesNotExist(predictor.Path), PathKey)
+		return cr.WithKey(errors.Wrap(ErrorImplDoesNotExist(predictor.Path), PathKey), PathKey)
 	}
 
 	if predictor.PythonPath != nil {
@@ -353,47 +469,72 @@ func (predictor *Predictor) Validate(projectFileMap map[string][]byte) error {
 
 func (predictor *Predictor) TensorFlowValidate() error {
 	if predictor.Model == nil {
-		return ErrorFieldMustBeDefinedForPredictorType(ModelKey, TensorFlowPredictorType)
+		return cr.WithKey(ErrorFieldMustBeDefinedForPredictorType(ModelKey, TensorFlowPredictorType), ModelKey)
 	}
 
 	model := *predictor.Model
 
 	awsClient, err := aws.NewFromS3Path(model, false)
 	if err != nil {
-		return err
+		return cr.WithKey(err, ModelKey)
 	}
 	if strings.HasSuffix(model, ".zip") {
 		if ok, err := awsClient.IsS3PathFile(model); err != nil || !ok {
-			return errors.Wrap(ErrorExternalNotFound(model), ModelKey)
+			return cr.WithKey(errors.Wrap(ErrorExternalNotFound(model), ModelKey), ModelKey)
 		}
 	} else {
 		path, err := GetTFServingExportFromS3Path(model, awsClient)
 		if path == "" || err != nil {
-			return errors.Wrap(ErrorInvalidTensorFlowDir(model), ModelKey)
+			return cr.WithKey(errors.Wrap(ErrorInvalidTensorFlowDir(model), ModelKey), ModelKey)
 		}
 		predictor.Model = pointer.String(path)
 	}
 
+	if err := predictor.validateModelManifest(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 func (predictor *Predictor) ONNXValidate() error {
 	if predictor.Model == nil {
-		return ErrorFieldMustBeDefinedForPredictorType(ModelKey, ONNXPredictorType)
+		return cr.WithKey(ErrorFieldMustBeDefinedForPredictorType(ModelKey, ONNXPredictorType), ModelKey)
 	}
 
 	model := *predictor.Model
 
 	awsClient, err := aws.NewFromS3Path(model, false)
 	if err != nil {
-		return err
+		return cr.WithKey(err, ModelKey)
 	}
 	if ok, err := awsClient.IsS3PathFile(model); err != nil || !ok {
-		return errors.Wrap(ErrorExternalNotFound(model), ModelKey)
+		return cr.WithKey(errors.Wrap(ErrorExternalNotFound(model), ModelKey), ModelKey)
 	}
 
 	if predictor.SignatureKey != nil {
-		return ErrorFieldNotSupportedByPredictorType(SignatureKeyKey, ONNXPredictorType)
+		return cr.WithKey(ErrorFieldNotSupportedByPredictorType(SignatureKeyKey, ONNXPredictorType), SignatureKeyKey)
+	}
+
+	if err := predictor.validateModelManifest(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (predictor *Predictor) validateModelManifest() error {
+	if predictor.ModelManifest == nil {
+		return nil
+	}
+
+	manifest := *predictor.ModelManifest
+	awsClient, err := aws.NewFromS3Path(manifest, false)
+	if err != nil {
+		return cr.WithKey(err, ModelManifestKey)
+	}
+	if ok, err := awsClient.IsS3PathFile(manifest); err != nil || !ok {
+		return cr.WithKey(errors.Wrap(ErrorExternalNotFound(manifest), ModelManifestKey), ModelManifestKey)
 	}
 
 	return nil
@@ -401,11 +542,15 @@ func (predictor *Predictor) ONNXValidate() error {
 
 func (predictor *Predictor) PythonValidate() error {
 	if predictor.SignatureKey != nil {
-		return ErrorFieldNotSupportedByPredictorType(SignatureKeyKey, PythonPredictorType)
+		return cr.WithKey(ErrorFieldNotSupportedByPredictorType(SignatureKeyKey, PythonPredictorType), SignatureKeyKey)
 	}
 
 	if predictor.Model != nil {
-		return ErrorFieldNotSupportedByPredictorType(ModelKey, PythonPredictorType)
+		return cr.WithKey(ErrorFieldNotSupportedByPredictorType(ModelKey, PythonPredictorType), ModelKey)
+	}
+
+	if predictor.ModelManifest != nil {
+		return cr.WithKey(ErrorFieldNotSupportedByPredictorType(ModelManifestKey, PythonPredictorType), ModelManifestKey)
 	}
 
 	return nil
@@ -417,11 +562,15 @@ func (api *API) Validate(deploymentName string, projectFileMap map[string][]byte
 	}
 
 	if err := api.Predictor.Validate(projectFileMap); err != nil {
-		return errors.Wrap(err, Identify(api), PredictorKey)
+		return AttachSourcePosition(api, errors.Wrap(err, Identify(api), PredictorKey), PredictorKey)
 	}
 
 	if err := api.Compute.Validate(); err != nil {
-		return errors.Wrap(err, Identify(api), ComputeKey)
+		return AttachSourcePosition(api, errors.Wrap(err, Identify(api), ComputeKey), ComputeKey)
+	}
+
+	if err := api.PythonPackageIndex.Validate(); err != nil {
+		return AttachSourcePosition(api, errors.Wrap(err, Identify(api), PythonPackageIndexKey), PythonPackageIndexKey)
 	}
 
 	return nil
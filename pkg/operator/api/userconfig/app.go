@@ -21,7 +21,9 @@ import (
 )
 
 type App struct {
-	Name string `json:"name" yaml:"name"`
+	Name    string   `json:"name" yaml:"name"`
+	JWTAuth *JWTAuth `json:"jwt_auth" yaml:"jwt_auth"` // applies to the deployment's APIs which don't configure their own
+	Usage   *Usage   `json:"usage" yaml:"usage"`
 }
 
 var appValidation = &cr.StructValidation{
@@ -35,6 +37,8 @@ var appValidation = &cr.StructValidation{
 				DNS1123:                    true,
 			},
 		},
+		jwtAuthValidation,
+		usageValidation,
 		typeFieldValidation,
 	},
 }
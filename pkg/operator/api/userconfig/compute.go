@@ -129,15 +129,15 @@ func (ac *APICompute) UserConfigStr() string {
 
 func (ac *APICompute) Validate() error {
 	if ac.MinReplicas > ac.MaxReplicas {
-		return ErrorMinReplicasGreaterThanMax(ac.MinReplicas, ac.MaxReplicas)
+		return cr.WithKey(ErrorMinReplicasGreaterThanMax(ac.MinReplicas, ac.MaxReplicas), MinReplicasKey)
 	}
 
 	if ac.InitReplicas > ac.MaxReplicas {
-		return ErrorInitReplicasGreaterThanMax(ac.InitReplicas, ac.MaxReplicas)
+		return cr.WithKey(ErrorInitReplicasGreaterThanMax(ac.InitReplicas, ac.MaxReplicas), InitReplicasKey)
 	}
 
 	if ac.InitReplicas < ac.MinReplicas {
-		return ErrorInitReplicasLessThanMin(ac.InitReplicas, ac.MinReplicas)
+		return cr.WithKey(ErrorInitReplicasLessThanMin(ac.InitReplicas, ac.MinReplicas), InitReplicasKey)
 	}
 
 	return nil
@@ -22,6 +22,7 @@ import (
 	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/zip"
 	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
 )
@@ -46,11 +47,21 @@ func (config *Config) Validate(projectBytes []byte) error {
 	}
 
 	if config.APIs != nil {
+		for _, api := range config.APIs {
+			if api.JWTAuth == nil {
+				api.JWTAuth = config.App.JWTAuth
+			}
+		}
+
 		if err := config.APIs.Validate(config.App.Name, projectFileMap); err != nil {
 			return err
 		}
 	}
 
+	if err := config.App.Usage.Validate(config.APIs); err != nil {
+		return errors.Wrap(err, resource.AppType.String())
+	}
+
 	return nil
 }
 
@@ -67,20 +78,22 @@ func New(filePath string, configBytes []byte) (*Config, error) {
 		return nil, errors.Wrap(ErrorMalformedConfig(), filePath)
 	}
 
+	// positions are best-effort; errors are still reported without them
+	positions, _ := cr.ReadYAMLPositions(filePath, configBytes)
+
 	config := &Config{}
 	for i, data := range configDataSlice {
 		kindInterface, ok := data[KindKey]
 		if !ok {
-			return nil, errors.Wrap(configreader.ErrorMustBeDefined(), identify(filePath, resource.UnknownType, "", i), KindKey)
+			return nil, positions.Attach(errors.Wrap(configreader.ErrorMustBeDefined(), identify(filePath, resource.UnknownType, "", i), KindKey), s.Int(i))
 		}
 		kindStr, ok := kindInterface.(string)
 		if !ok {
-			return nil, errors.Wrap(configreader.ErrorInvalidPrimitiveType(kindInterface, configreader.PrimTypeString), identify(filePath, resource.UnknownType, "", i), KindKey)
+			return nil, positions.Attach(errors.Wrap(configreader.ErrorInvalidPrimitiveType(kindInterface, configreader.PrimTypeString), identify(filePath, resource.UnknownType, "", i), KindKey), s.Int(i), KindKey)
 		}
 
 		var errs []error
 		resourceType := resource.TypeFromKindString(kindStr)
-		var newResource Resource
 		switch resourceType {
 		case resource.AppType:
 			if config.App != nil {
@@ -90,23 +103,19 @@ func New(filePath string, configBytes []byte) (*Config, error) {
 			errs = cr.Struct(app, data, appValidation)
 			config.App = app
 		case resource.APIType:
-			newResource = &API{}
-			errs = cr.Struct(newResource, data, apiValidation)
-			if !errors.HasErrors(errs) {
-				config.APIs = append(config.APIs, newResource.(*API))
+			apis, err := newAPIs(filePath, data, i, positions)
+			if err != nil {
+				return nil, err
 			}
+			config.APIs = append(config.APIs, apis...)
+			continue
 		default:
-			return nil, errors.Wrap(resource.ErrorUnknownKind(kindStr), identify(filePath, resource.UnknownType, "", i))
+			return nil, positions.Attach(errors.Wrap(resource.ErrorUnknownKind(kindStr), identify(filePath, resource.UnknownType, "", i)), s.Int(i), KindKey)
 		}
 
 		if errors.HasErrors(errs) {
 			name, _ := data[NameKey].(string)
-			return nil, errors.Wrap(errors.FirstError(errs...), identify(filePath, resourceType, name, i))
-		}
-
-		if newResource != nil {
-			newResource.SetIndex(i)
-			newResource.SetFilePath(filePath)
+			return nil, positions.Attach(errors.Wrap(errors.FirstError(errs...), identify(filePath, resourceType, name, i)), s.Int(i))
 		}
 	}
 
@@ -117,6 +126,37 @@ func New(filePath string, configBytes []byte) (*Config, error) {
 	return config, nil
 }
 
+// newAPIs parses an api entry, which generates multiple apis if it declares a matrix
+func newAPIs(filePath string, data map[string]interface{}, index int, positions *cr.YAMLPositions) (APIs, error) {
+	nameTemplate := ""
+	if _, ok := data[MatrixKey]; ok {
+		nameTemplate, _ = data[NameKey].(string)
+	}
+
+	apisData, err := expandMatrix(data)
+	if err != nil {
+		return nil, positions.Attach(errors.Wrap(err, identify(filePath, resource.APIType, nameTemplate, index)), s.Int(index))
+	}
+
+	apis := make(APIs, len(apisData))
+	for i, apiData := range apisData {
+		api := &API{}
+		errs := cr.Struct(api, apiData, apiValidation)
+		if errors.HasErrors(errs) {
+			name, _ := apiData[NameKey].(string)
+			return nil, positions.Attach(errors.Wrap(errors.FirstError(errs...), identifyGenerated(filePath, resource.APIType, name, nameTemplate, index)), s.Int(index))
+		}
+
+		api.SetIndex(index)
+		api.SetFilePath(filePath)
+		api.SetSourcePositions(positions)
+		api.SetNameTemplate(nameTemplate)
+		apis[i] = api
+	}
+
+	return apis, nil
+}
+
 func ReadConfigFile(filePath string, relativePath string) (*Config, error) {
 	configBytes, err := files.ReadFileBytesErrPath(filePath, relativePath)
 	if err != nil {
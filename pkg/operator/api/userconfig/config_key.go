@@ -23,18 +23,49 @@ const (
 	KindKey    = "kind"
 
 	// API
-	ModelKey        = "model"
-	TypeKey         = "type"
-	PathKey         = "path"
-	PredictorKey    = "predictor"
-	EndpointKey     = "endpoint"
-	SignatureKeyKey = "signature_key"
-	TrackerKey      = "tracker"
-	ModelTypeKey    = "model_type"
-	KeyKey          = "key"
-	ConfigKey       = "config"
-	PythonPathKey   = "python_path"
-	EnvKey          = "env"
+	ModelKey           = "model"
+	TypeKey            = "type"
+	PathKey            = "path"
+	PredictorKey       = "predictor"
+	EndpointKey        = "endpoint"
+	SignatureKeyKey    = "signature_key"
+	TrackerKey         = "tracker"
+	ModelTypeKey       = "model_type"
+	ModelManifestKey   = "model_manifest"
+	KeyKey             = "key"
+	ConfigKey          = "config"
+	PythonPathKey      = "python_path"
+	EnvKey             = "env"
+	EgressKey          = "egress"
+	SecurityOptOutsKey = "security_opt_outs"
+
+	PythonPackageIndexKey = "python_package_index"
+
+	// JWT auth
+	JWTAuthKey        = "jwt_auth"
+	IssuerKey         = "issuer"
+	JWKSURLKey        = "jwks_url"
+	AudiencesKey      = "audiences"
+	RequiredClaimsKey = "required_claims"
+	ForwardClaimsKey  = "forward_claims"
+
+	// Matrix
+	MatrixKey = "matrix"
+
+	// Usage
+	UsageKey          = "usage"
+	ConsumerHeaderKey = "consumer_header"
+	ConsumerClaimKey  = "consumer_claim"
+	ConsumersKey      = "consumers"
+
+	// Predictor runtime
+	RuntimeKey                = "runtime"
+	IntraOpThreadsKey         = "intra_op_threads"
+	InterOpThreadsKey         = "inter_op_threads"
+	GPUMemoryFractionKey      = "gpu_memory_fraction"
+	GraphOptimizationLevelKey = "graph_optimization_level"
+	ExecutionModeKey          = "execution_mode"
+	ExecutionProvidersKey     = "execution_providers"
 
 	// Compute
 	ComputeKey              = "compute"
@@ -18,8 +18,10 @@ package userconfig
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/maps"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
@@ -50,6 +52,20 @@ const (
 	ErrFieldMustBeDefinedForPredictorType
 	ErrFieldNotSupportedByPredictorType
 	ErrDuplicateEndpoints
+	ErrInvalidEgressDestination
+	ErrInvalidSecurityOptOut
+	ErrInvalidExecutionProvider
+	ErrInvalidForwardHeader
+	ErrDuplicateForwardHeader
+	ErrInvalidConsumerHeader
+	ErrConsumerClaimWithoutJWTAuth
+	ErrInvalidMatrix
+	ErrInvalidMatrixParam
+	ErrUndefinedMatrixParam
+	ErrDuplicateMatrixName
+	ErrTooManyMatrixAPIs
+	ErrInvalidConsumer
+	ErrConsumerHeaderWithoutConsumers
 )
 
 var errorKinds = []string{
@@ -75,9 +91,23 @@ var errorKinds = []string{
 	"err_field_must_be_defined_for_predictor_type",
 	"err_field_not_supported_by_predictor_type",
 	"err_duplicate_endpoints",
+	"err_invalid_egress_destination",
+	"err_invalid_security_opt_out",
+	"err_invalid_execution_provider",
+	"err_invalid_forward_header",
+	"err_duplicate_forward_header",
+	"err_invalid_consumer_header",
+	"err_consumer_claim_without_jwt_auth",
+	"err_invalid_matrix",
+	"err_invalid_matrix_param",
+	"err_undefined_matrix_param",
+	"err_duplicate_matrix_name",
+	"err_too_many_matrix_apis",
+	"err_invalid_consumer",
+	"err_consumer_header_without_consumers",
 }
 
-var _ = [1]int{}[int(ErrDuplicateEndpoints)-(len(errorKinds)-1)] // Ensure list length matches
+var _ = [1]int{}[int(ErrConsumerHeaderWithoutConsumers)-(len(errorKinds)-1)] // Ensure list length matches
 
 func (t ErrorKind) String() string {
 	return errorKinds[t]
@@ -125,15 +155,26 @@ func (e Error) Error() string {
 func ErrorDuplicateResourceName(resources ...Resource) error {
 	filePaths := strset.New()
 	resourceTypes := strset.New()
+	nameTemplates := strset.New()
 
 	for _, res := range resources {
 		resourceTypes.Add(res.GetResourceType().Plural())
 		filePaths.Add(res.GetFilePath())
+		if res.GetNameTemplate() != "" {
+			nameTemplates.Add(res.GetNameTemplate())
+		}
+	}
+
+	definedIn := s.StrsAnd(filePaths.Slice())
+	if len(nameTemplates) > 0 {
+		templates := nameTemplates.Slice()
+		sort.Strings(templates)
+		definedIn += ", generated by " + s.UserStrsAnd(templates)
 	}
 
 	return errors.WithStack(Error{
 		Kind:    ErrDuplicateResourceName,
-		message: fmt.Sprintf("name %s must be unique across %s (defined in %s)", s.UserStr(resources[0].GetName()), s.StrsAnd(resourceTypes.Slice()), s.StrsAnd(filePaths.Slice())),
+		message: fmt.Sprintf("name %s must be unique across %s (defined in %s)", s.UserStr(resources[0].GetName()), s.StrsAnd(resourceTypes.Slice()), definedIn),
 	})
 }
 
@@ -201,6 +242,13 @@ func ErrorSpecifyOneModelFormatFoundMultiple(found []string, vals ...string) err
 	})
 }
 
+func ErrorSpecifyOnlyOne(vals ...string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrSpecifyOnlyOne,
+		message: fmt.Sprintf("please specify exactly one of %s", s.UserStrsOr(vals)),
+	})
+}
+
 func ErrorOneOfPrerequisitesNotDefined(argName string, prerequisites ...string) error {
 	message := fmt.Sprintf("%s specified without specifying %s", s.UserStr(argName), s.UserStrsOr(prerequisites))
 
@@ -299,3 +347,109 @@ func ErrorDuplicateEndpoints(endpoint string, apiNames ...string) error {
 		message: fmt.Sprintf("multiple APIs specify the same endpoint (endpoint %s is used by the %s APIs)", s.UserStr(endpoint), s.UserStrsAnd(apiNames)),
 	})
 }
+
+func ErrorInvalidEgressDestination(destination string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidEgressDestination,
+		message: fmt.Sprintf("%s is not a valid egress destination (specify an IPv4 CIDR block, e.g. 10.0.0.0/16, or a hostname, e.g. pypi.org); the instance metadata endpoint (%s) cannot be allowed", s.UserStr(destination), InstanceMetadataCIDR),
+	})
+}
+
+func ErrorInvalidSecurityOptOut(optOut string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidSecurityOptOut,
+		message: fmt.Sprintf("invalid security opt-out %s (valid opt-outs are %s)", s.UserStr(optOut), s.UserStrsOr(SecurityOptOuts)),
+	})
+}
+
+func ErrorInvalidExecutionProvider(provider string) error {
+	providers := maps.StrMapKeys(ONNXExecutionProviders)
+	sort.Strings(providers)
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidExecutionProvider,
+		message: fmt.Sprintf("invalid execution provider %s (valid execution providers are %s)", s.UserStr(provider), s.UserStrsOr(providers)),
+	})
+}
+
+func ErrorInvalidForwardHeader(header string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidForwardHeader,
+		message: fmt.Sprintf("claims can't be forwarded as the %s header (header names may only contain letters, numbers and dashes, and can't be one of %s)", s.UserStr(header), s.StrsOr(ReservedForwardHeaders)),
+	})
+}
+
+func ErrorDuplicateForwardHeader(header string, claims ...string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrDuplicateForwardHeader,
+		message: fmt.Sprintf("the %s claims are all forwarded as the %s header", s.UserStrsAnd(claims), s.UserStr(header)),
+	})
+}
+
+func ErrorInvalidConsumerHeader(header string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidConsumerHeader,
+		message: fmt.Sprintf("%s is not a valid header name (header names may only contain letters, numbers and dashes)", s.UserStr(header)),
+	})
+}
+
+func ErrorConsumerClaimWithoutJWTAuth(apiName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrConsumerClaimWithoutJWTAuth,
+		message: fmt.Sprintf("consumers can only be identified by a claim if every api verifies its tokens, but the %s api doesn't have %s configured (set %s on the deployment or the api, or use %s instead)", s.UserStr(apiName), JWTAuthKey, JWTAuthKey, ConsumerHeaderKey),
+	})
+}
+
+func ErrorInvalidMatrix() error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidMatrix,
+		message: "must be either a map of parameters to lists of values, or a list of parameter sets (maps of parameters to values)",
+	})
+}
+
+func ErrorInvalidMatrixParam(param string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidMatrixParam,
+		message: fmt.Sprintf("%s is not a valid parameter name (parameter names may only contain letters, numbers and underscores)", s.UserStr(param)),
+	})
+}
+
+func ErrorUndefinedMatrixParam(param string, params []string) error {
+	if len(params) == 0 {
+		return errors.WithStack(Error{
+			Kind:    ErrUndefinedMatrixParam,
+			message: fmt.Sprintf("%s is not defined in the %s (no parameters are defined)", s.UserStr(param), MatrixKey),
+		})
+	}
+	return errors.WithStack(Error{
+		Kind:    ErrUndefinedMatrixParam,
+		message: fmt.Sprintf("%s is not defined in the %s (defined parameters: %s)", s.UserStr(param), MatrixKey, s.UserStrsAnd(params)),
+	})
+}
+
+func ErrorDuplicateMatrixName(name string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrDuplicateMatrixName,
+		message: fmt.Sprintf("multiple apis generated by the %s are named %s (the name must include the parameters which differ between them, e.g. iris-{{region}})", MatrixKey, s.UserStr(name)),
+	})
+}
+
+func ErrorTooManyMatrixAPIs(maxAPIs int) error {
+	return errors.WithStack(Error{
+		Kind:    ErrTooManyMatrixAPIs,
+		message: fmt.Sprintf("the %s generates more than %d apis (use fewer parameter values, or split it into multiple entries)", MatrixKey, maxAPIs),
+	})
+}
+
+func ErrorInvalidConsumer(consumer string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidConsumer,
+		message: fmt.Sprintf("%s is not a valid consumer (consumers must be non-empty ascii strings of at most %d characters, and can't be %s)", s.UserStr(consumer), _maxConsumerLength, s.UserStr(UnidentifiedConsumer)),
+	})
+}
+
+func ErrorConsumerHeaderWithoutConsumers() error {
+	return errors.WithStack(Error{
+		Kind:    ErrConsumerHeaderWithoutConsumers,
+		message: fmt.Sprintf("%s must be specified when consumers are identified by %s, since the header is set by the client (requests from other consumers are recorded as %s)", ConsumersKey, ConsumerHeaderKey, s.UserStr(UnidentifiedConsumer)),
+	})
+}
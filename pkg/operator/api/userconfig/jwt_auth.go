@@ -0,0 +1,150 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/maps"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+)
+
+// JWTAuth requires requests to carry a valid JSON Web Token (verified by the API's istio sidecar)
+type JWTAuth struct {
+	Issuer         string            `json:"issuer" yaml:"issuer"`
+	JWKSURL        string            `json:"jwks_url" yaml:"jwks_url"`
+	Audiences      []string          `json:"audiences" yaml:"audiences"`
+	RequiredClaims map[string]string `json:"required_claims" yaml:"required_claims"`
+	ForwardClaims  map[string]string `json:"forward_claims" yaml:"forward_claims"` // claim -> request header
+}
+
+// headers which are set by the client, istio or cortex, and can't be overwritten with a claim
+var ReservedForwardHeaders = []string{"authorization", "host", "content-type", "content-length", "x-request-id"}
+
+var _headerNameRegex = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+var jwtAuthValidation = &cr.StructFieldValidation{
+	StructField: "JWTAuth",
+	StructValidation: &cr.StructValidation{
+		DefaultNil:        true,
+		AllowExplicitNull: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "Issuer",
+				StringValidation: &cr.StringValidation{
+					Required: true,
+				},
+			},
+			{
+				StructField: "JWKSURL",
+				StringValidation: &cr.StringValidation{
+					Required:  true,
+					Validator: validateJWKSURL,
+				},
+			},
+			{
+				StructField: "Audiences",
+				StringListValidation: &cr.StringListValidation{
+					Default:      []string{},
+					AllowEmpty:   true,
+					DisallowDups: true,
+				},
+			},
+			{
+				StructField: "RequiredClaims",
+				StringMapValidation: &cr.StringMapValidation{
+					Default:    map[string]string{},
+					AllowEmpty: true,
+				},
+			},
+			{
+				StructField: "ForwardClaims",
+				StringMapValidation: &cr.StringMapValidation{
+					Default:    map[string]string{},
+					AllowEmpty: true,
+					Validator:  validateForwardClaims,
+				},
+			},
+		},
+	},
+}
+
+func validateJWKSURL(jwksURL string) (string, error) {
+	parsed, err := urls.Parse(jwksURL)
+	if err != nil {
+		return "", err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", urls.ErrorInvalidURL(jwksURL)
+	}
+	return jwksURL, nil
+}
+
+func validateForwardClaims(forwardClaims map[string]string) (map[string]string, error) {
+	headers := map[string]string{} // lowercase header -> claim
+	for _, claim := range sortedKeys(forwardClaims) {
+		header := forwardClaims[claim]
+		if !_headerNameRegex.MatchString(header) {
+			return nil, ErrorInvalidForwardHeader(header)
+		}
+		for _, reserved := range ReservedForwardHeaders {
+			if strings.ToLower(header) == reserved {
+				return nil, ErrorInvalidForwardHeader(header)
+			}
+		}
+		if dupClaim, ok := headers[strings.ToLower(header)]; ok {
+			return nil, ErrorDuplicateForwardHeader(header, dupClaim, claim)
+		}
+		headers[strings.ToLower(header)] = claim
+	}
+	return forwardClaims, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := maps.StrMapKeys(m)
+	sort.Strings(keys)
+	return keys
+}
+
+func (jwtAuth *JWTAuth) UserConfigStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", IssuerKey, jwtAuth.Issuer))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", JWKSURLKey, jwtAuth.JWKSURL))
+	if len(jwtAuth.Audiences) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", AudiencesKey))
+		for _, audience := range jwtAuth.Audiences {
+			sb.WriteString(fmt.Sprintf("  - %s\n", audience))
+		}
+	}
+	if len(jwtAuth.RequiredClaims) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", RequiredClaimsKey))
+		for _, claim := range sortedKeys(jwtAuth.RequiredClaims) {
+			sb.WriteString(fmt.Sprintf("  %s: %s\n", claim, jwtAuth.RequiredClaims[claim]))
+		}
+	}
+	if len(jwtAuth.ForwardClaims) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", ForwardClaimsKey))
+		for _, claim := range sortedKeys(jwtAuth.ForwardClaims) {
+			sb.WriteString(fmt.Sprintf("  %s: %s\n", claim, jwtAuth.ForwardClaims[claim]))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/cast"
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// e.g. {{region}}
+var _matrixPlaceholderRegex = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+var _matrixParamRegex = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// MaxMatrixAPIs is the maximum number of apis which a single matrix can generate (a typo in a large cartesian product can
+// otherwise generate an unbounded number of apis)
+const MaxMatrixAPIs = 100
+
+// expandMatrix returns the config data of the apis which are generated by the api's matrix (with the parameters substituted
+// into every string), or the api's config data if it doesn't declare a matrix; errors point to the keys of the template
+func expandMatrix(apiData map[string]interface{}) ([]map[string]interface{}, error) {
+	matrixData, ok := apiData[MatrixKey]
+	if !ok {
+		return []map[string]interface{}{apiData}, nil
+	}
+
+	paramSets, err := matrixParamSets(matrixData)
+	if err != nil {
+		return nil, errors.Wrap(cr.WithKey(err, MatrixKey), MatrixKey)
+	}
+
+	template := make(map[string]interface{}, len(apiData)-1)
+	for key, value := range apiData {
+		if key != MatrixKey {
+			template[key] = value
+		}
+	}
+
+	expanded := make([]map[string]interface{}, len(paramSets))
+	names := map[string]bool{}
+	for i, params := range paramSets {
+		substituted, err := substituteMatrixParams(template, params)
+		if err != nil {
+			return nil, err
+		}
+		expanded[i] = substituted.(map[string]interface{})
+
+		if name, ok := expanded[i][NameKey].(string); ok {
+			if names[name] {
+				return nil, errors.Wrap(cr.WithKey(ErrorDuplicateMatrixName(name), NameKey), NameKey)
+			}
+			names[name] = true
+		}
+	}
+
+	return expanded, nil
+}
+
+// matrixParamSets returns the matrix's parameter sets, either from a list of parameter sets, or from the cartesian product of
+// each parameter's values (in which case the last parameter in alphabetical order varies fastest)
+func matrixParamSets(matrixData interface{}) ([]map[string]interface{}, error) {
+	if paramSetsData, ok := cast.InterfaceToInterfaceSlice(matrixData); ok {
+		if len(paramSetsData) == 0 {
+			return nil, cr.ErrorCannotBeEmpty()
+		}
+		if len(paramSetsData) > MaxMatrixAPIs {
+			return nil, ErrorTooManyMatrixAPIs(MaxMatrixAPIs)
+		}
+		paramSets := make([]map[string]interface{}, len(paramSetsData))
+		for i, paramSetData := range paramSetsData {
+			params, ok := cast.InterfaceToStrInterfaceMap(paramSetData)
+			if !ok {
+				return nil, errors.Wrap(cr.WithIndex(ErrorInvalidMatrix(), i), s.Index(i))
+			}
+			for _, param := range sortedParams(params) {
+				if err := validateMatrixParam(param, params[param]); err != nil {
+					return nil, errors.Wrap(cr.WithIndex(err, i), s.Index(i))
+				}
+			}
+			paramSets[i] = params
+		}
+		return paramSets, nil
+	}
+
+	paramValuesData, ok := cast.InterfaceToStrInterfaceMap(matrixData)
+	if !ok {
+		return nil, ErrorInvalidMatrix()
+	}
+	if len(paramValuesData) == 0 {
+		return nil, cr.ErrorCannotBeEmpty()
+	}
+
+	paramSets := []map[string]interface{}{{}}
+	for _, param := range sortedParams(paramValuesData) {
+		values, ok := cast.InterfaceToInterfaceSlice(paramValuesData[param])
+		if !ok {
+			return nil, errors.Wrap(cr.WithKey(ErrorInvalidMatrix(), param), param)
+		}
+		if len(values) == 0 {
+			return nil, errors.Wrap(cr.WithKey(cr.ErrorCannotBeEmpty(), param), param)
+		}
+		for i, value := range values {
+			if err := validateMatrixParam(param, value); err != nil {
+				return nil, errors.Wrap(cr.WithKey(errors.Wrap(cr.WithIndex(err, i), s.Index(i)), param), param)
+			}
+		}
+
+		// checked before the parameter sets are built, so that they are never larger than the limit
+		if len(paramSets)*len(values) > MaxMatrixAPIs {
+			return nil, ErrorTooManyMatrixAPIs(MaxMatrixAPIs)
+		}
+
+		var nextParamSets []map[string]interface{}
+		for _, paramSet := range paramSets {
+			for _, value := range values {
+				nextParamSet := make(map[string]interface{}, len(paramSet)+1)
+				for k, v := range paramSet {
+					nextParamSet[k] = v
+				}
+				nextParamSet[param] = value
+				nextParamSets = append(nextParamSets, nextParamSet)
+			}
+		}
+		paramSets = nextParamSets
+	}
+
+	return paramSets, nil
+}
+
+func validateMatrixParam(param string, value interface{}) error {
+	if !_matrixParamRegex.MatchString(param) {
+		return ErrorInvalidMatrixParam(param)
+	}
+	if !cast.IsScalarType(value) {
+		return cr.ErrorInvalidPrimitiveType(value, cr.PrimTypeScalars...)
+	}
+	return nil
+}
+
+func sortedParams(params map[string]interface{}) []string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// substituteMatrixParams returns a copy of value with the parameters substituted into every string (map keys are unchanged)
+func substituteMatrixParams(value interface{}, params map[string]interface{}) (interface{}, error) {
+	switch typedValue := value.(type) {
+	case string:
+		return substituteMatrixParamsStr(typedValue, params)
+
+	case map[string]interface{}:
+		substituted := make(map[string]interface{}, len(typedValue))
+		for _, key := range sortedParams(typedValue) {
+			subValue, err := substituteMatrixParams(typedValue[key], params)
+			if err != nil {
+				return nil, errors.Wrap(cr.WithKey(err, key), key)
+			}
+			substituted[key] = subValue
+		}
+		return substituted, nil
+
+	case map[interface{}]interface{}:
+		keys := make([]interface{}, 0, len(typedValue))
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return s.ObjFlatNoQuotes(keys[i]) < s.ObjFlatNoQuotes(keys[j]) })
+
+		substituted := make(map[interface{}]interface{}, len(typedValue))
+		for _, key := range keys {
+			subValue, err := substituteMatrixParams(typedValue[key], params)
+			if err != nil {
+				keyStr := s.ObjFlatNoQuotes(key)
+				return nil, errors.Wrap(cr.WithKey(err, keyStr), keyStr)
+			}
+			substituted[key] = subValue
+		}
+		return substituted, nil
+
+	case []interface{}:
+		substituted := make([]interface{}, len(typedValue))
+		for i, item := range typedValue {
+			subItem, err := substituteMatrixParams(item, params)
+			if err != nil {
+				return nil, errors.Wrap(cr.WithIndex(err, i), s.Index(i))
+			}
+			substituted[i] = subItem
+		}
+		return substituted, nil
+	}
+
+	return value, nil
+}
+
+// a string which only consists of a placeholder is replaced by the parameter's value (e.g. to set an integer field)
+func substituteMatrixParamsStr(str string, params map[string]interface{}) (interface{}, error) {
+	matches := _matrixPlaceholderRegex.FindAllStringSubmatchIndex(str, -1)
+	if len(matches) == 0 {
+		return str, nil
+	}
+
+	var sb strings.Builder
+	prevEnd := 0
+	for _, match := range matches {
+		param := str[match[2]:match[3]]
+		value, ok := params[param]
+		if !ok {
+			return nil, ErrorUndefinedMatrixParam(param, sortedParams(params))
+		}
+		if len(matches) == 1 && match[0] == 0 && match[1] == len(str) {
+			return value, nil
+		}
+		sb.WriteString(str[prevEnd:match[0]])
+		sb.WriteString(fmt.Sprint(value))
+		prevEnd = match[1]
+	}
+	sb.WriteString(str[prevEnd:])
+
+	return sb.String(), nil
+}
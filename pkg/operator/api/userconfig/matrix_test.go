@@ -0,0 +1,165 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+func TestMatrixProduct(t *testing.T) {
+	config, err := New("cortex.yaml", []byte(`- kind: deployment
+  name: translator
+
+- kind: api
+  name: translator-{{region}}-{{ lang }}
+  matrix:
+    region: [us, eu]
+    lang: [en, fr]
+  predictor:
+    type: python
+    path: predictor.py
+    config:
+      model: s3://models/{{region}}/{{lang}}
+      tags: ["{{lang}}"]
+`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"translator-us-en", "translator-eu-en", "translator-us-fr", "translator-eu-fr"}, config.APIs.Names())
+
+	api := config.APIs[3]
+	require.Equal(t, "s3://models/eu/fr", api.Predictor.Config["model"])
+	require.Equal(t, []interface{}{"fr"}, api.Predictor.Config["tags"])
+	require.Equal(t, 1, api.Index)
+	require.Equal(t, `cortex.yaml: api: translator-eu-fr (generated by "translator-{{region}}-{{ lang }}")`, Identify(api))
+}
+
+func TestMatrixParamSets(t *testing.T) {
+	config, err := New("cortex.yaml", []byte(`- kind: deployment
+  name: translator
+
+- kind: api
+  name: translator-{{region}}
+  matrix:
+    - region: us
+      replicas: 3
+    - region: eu
+      replicas: 1
+  predictor:
+    type: python
+    path: predictor.py
+  compute:
+    min_replicas: "{{replicas}}"
+`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"translator-us", "translator-eu"}, config.APIs.Names())
+	require.Equal(t, int32(3), config.APIs[0].Compute.MinReplicas)
+	require.Equal(t, int32(1), config.APIs[1].Compute.MinReplicas)
+}
+
+func TestMatrixErrors(t *testing.T) {
+	_, err := New("cortex.yaml", []byte(`- kind: deployment
+  name: translator
+
+- kind: api
+  name: translator
+  matrix:
+    region: [us, eu]
+  predictor:
+    type: python
+    path: predictor.py
+`))
+	require.Error(t, err)
+	require.Equal(t, &cr.SourcePosition{FilePath: "cortex.yaml", Line: 5, Column: 9}, cr.GetSourcePosition(err))
+
+	_, err = New("cortex.yaml", []byte(`- kind: deployment
+  name: translator
+
+- kind: api
+  name: translator-{{region}}
+  matrix:
+    region: [us, eu]
+  predictor:
+    type: python
+    path: predictor.py
+    env:
+      MODEL: s3://models/{{regoin}}
+`))
+	require.Error(t, err)
+	require.Equal(t, &cr.SourcePosition{FilePath: "cortex.yaml", Line: 12, Column: 14}, cr.GetSourcePosition(err))
+
+	_, err = New("cortex.yaml", []byte(`- kind: deployment
+  name: translator
+
+- kind: api
+  name: translator-{{region}}
+  matrix:
+    region: [us, [eu]]
+  predictor:
+    type: python
+    path: predictor.py
+`))
+	require.Error(t, err)
+	require.Equal(t, []string{"matrix", "region", "1"}, cr.KeyPath(err))
+	require.Equal(t, 7, cr.GetSourcePosition(err).Line)
+}
+
+func TestMatrixTooManyAPIs(t *testing.T) {
+	matrixConfig := func(matrix string) []byte {
+		return []byte(`- kind: deployment
+  name: translator
+
+- kind: api
+  name: translator-{{a}}-{{b}}
+  matrix:
+` + matrix + `
+  predictor:
+    type: python
+    path: predictor.py
+`)
+	}
+
+	values := func(n int) string {
+		strs := make([]string, n)
+		for i := range strs {
+			strs[i] = fmt.Sprint(i)
+		}
+		return "[" + strings.Join(strs, ", ") + "]"
+	}
+
+	config, err := New("cortex.yaml", matrixConfig("    a: "+values(10)+"\n    b: "+values(10)))
+	require.NoError(t, err)
+	require.Len(t, config.APIs, MaxMatrixAPIs)
+
+	_, err = New("cortex.yaml", matrixConfig("    a: "+values(11)+"\n    b: "+values(10)))
+	require.Equal(t, ErrTooManyMatrixAPIs, errors.Cause(err).(Error).Kind)
+	require.Equal(t, []string{"matrix"}, cr.KeyPath(err))
+	require.Equal(t, 6, cr.GetSourcePosition(err).Line)
+
+	paramSets := make([]string, MaxMatrixAPIs+1)
+	for i := range paramSets {
+		paramSets[i] = fmt.Sprintf("    - {a: %d, b: %d}", i, i)
+	}
+	_, err = New("cortex.yaml", matrixConfig(strings.Join(paramSets, "\n")))
+	require.Equal(t, ErrTooManyMatrixAPIs, errors.Cause(err).(Error).Kind)
+	require.Equal(t, []string{"matrix"}, cr.KeyPath(err))
+}
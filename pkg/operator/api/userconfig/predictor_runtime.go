@@ -0,0 +1,174 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// PredictorRuntime tunes the serving runtime (TensorFlow Serving or ONNX Runtime); unset fields use the runtime's defaults
+type PredictorRuntime struct {
+	IntraOpThreads         *int32   `json:"intra_op_threads" yaml:"intra_op_threads"`
+	InterOpThreads         *int32   `json:"inter_op_threads" yaml:"inter_op_threads"`
+	GPUMemoryFraction      *float64 `json:"gpu_memory_fraction" yaml:"gpu_memory_fraction"`
+	GraphOptimizationLevel *string  `json:"graph_optimization_level" yaml:"graph_optimization_level"`
+	ExecutionMode          *string  `json:"execution_mode" yaml:"execution_mode"`
+	ExecutionProviders     []string `json:"execution_providers" yaml:"execution_providers"`
+}
+
+var ONNXGraphOptimizationLevels = []string{"disable_all", "basic", "extended", "all"}
+
+var ONNXExecutionModes = []string{"sequential", "parallel"}
+
+// ONNXExecutionProviders maps the execution provider names in the API configuration to their ONNX Runtime names
+var ONNXExecutionProviders = map[string]string{
+	"cpu":      "CPUExecutionProvider",
+	"cuda":     "CUDAExecutionProvider",
+	"tensorrt": "TensorrtExecutionProvider",
+	"dnnl":     "DnnlExecutionProvider",
+	"openvino": "OpenVINOExecutionProvider",
+}
+
+var predictorRuntimeValidation = &cr.StructFieldValidation{
+	StructField: "Runtime",
+	StructValidation: &cr.StructValidation{
+		DefaultNil: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "IntraOpThreads",
+				Int32PtrValidation: &cr.Int32PtrValidation{
+					GreaterThan: pointer.Int32(0),
+				},
+			},
+			{
+				StructField: "InterOpThreads",
+				Int32PtrValidation: &cr.Int32PtrValidation{
+					GreaterThan: pointer.Int32(0),
+				},
+			},
+			{
+				StructField: "GPUMemoryFraction",
+				Float64PtrValidation: &cr.Float64PtrValidation{
+					GreaterThan:       pointer.Float64(0),
+					LessThanOrEqualTo: pointer.Float64(1),
+				},
+			},
+			{
+				StructField: "GraphOptimizationLevel",
+				StringPtrValidation: &cr.StringPtrValidation{
+					AllowedValues: ONNXGraphOptimizationLevels,
+				},
+			},
+			{
+				StructField: "ExecutionMode",
+				StringPtrValidation: &cr.StringPtrValidation{
+					AllowedValues: ONNXExecutionModes,
+				},
+			},
+			{
+				StructField: "ExecutionProviders",
+				StringListValidation: &cr.StringListValidation{
+					AllowExplicitNull: true,
+					DisallowDups:      true,
+					Validator:         validateONNXExecutionProviders,
+				},
+			},
+		},
+	},
+}
+
+func validateONNXExecutionProviders(providers []string) ([]string, error) {
+	for _, provider := range providers {
+		if _, ok := ONNXExecutionProviders[provider]; !ok {
+			return nil, ErrorInvalidExecutionProvider(provider)
+		}
+	}
+	return providers, nil
+}
+
+// Validate rejects runtime options which don't apply to the predictor type
+func (runtime *PredictorRuntime) Validate(predictorType PredictorType) error {
+	if runtime == nil {
+		return nil
+	}
+
+	if predictorType == PythonPredictorType {
+		return cr.WithKey(ErrorFieldNotSupportedByPredictorType(RuntimeKey, predictorType), RuntimeKey)
+	}
+
+	unsupportedKey := ""
+	switch predictorType {
+	case TensorFlowPredictorType:
+		if runtime.GraphOptimizationLevel != nil {
+			unsupportedKey = GraphOptimizationLevelKey
+		} else if runtime.ExecutionMode != nil {
+			unsupportedKey = ExecutionModeKey
+		} else if runtime.ExecutionProviders != nil {
+			unsupportedKey = ExecutionProvidersKey
+		}
+	case ONNXPredictorType:
+		if runtime.GPUMemoryFraction != nil {
+			unsupportedKey = GPUMemoryFractionKey
+		}
+	}
+
+	if unsupportedKey != "" {
+		return cr.WithKey(cr.WithKey(ErrorFieldNotSupportedByPredictorType(unsupportedKey, predictorType), unsupportedKey), RuntimeKey)
+	}
+
+	return nil
+}
+
+// ONNXExecutionProviderNames returns the ONNX Runtime names of the configured execution providers, in order of preference
+func (runtime *PredictorRuntime) ONNXExecutionProviderNames() []string {
+	names := make([]string, len(runtime.ExecutionProviders))
+	for i, provider := range runtime.ExecutionProviders {
+		names[i] = ONNXExecutionProviders[provider]
+	}
+	return names
+}
+
+func (runtime *PredictorRuntime) UserConfigStr() string {
+	var sb strings.Builder
+	if runtime.IntraOpThreads != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", IntraOpThreadsKey, s.Int32(*runtime.IntraOpThreads)))
+	}
+	if runtime.InterOpThreads != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", InterOpThreadsKey, s.Int32(*runtime.InterOpThreads)))
+	}
+	if runtime.GPUMemoryFraction != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", GPUMemoryFractionKey, s.Float64(*runtime.GPUMemoryFraction)))
+	}
+	if runtime.GraphOptimizationLevel != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", GraphOptimizationLevelKey, *runtime.GraphOptimizationLevel))
+	}
+	if runtime.ExecutionMode != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ExecutionModeKey, *runtime.ExecutionMode))
+	}
+	if len(runtime.ExecutionProviders) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", ExecutionProvidersKey))
+		for _, provider := range runtime.ExecutionProviders {
+			sb.WriteString(fmt.Sprintf("  - %s\n", provider))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+)
+
+func TestPredictorRuntimeValidate(t *testing.T) {
+	tfRuntime := &PredictorRuntime{IntraOpThreads: pointer.Int32(4), GPUMemoryFraction: pointer.Float64(0.5)}
+	require.NoError(t, tfRuntime.Validate(TensorFlowPredictorType))
+
+	err := tfRuntime.Validate(ONNXPredictorType)
+	require.EqualError(t, err, "gpu_memory_fraction is not a supported field for the onnx predictor type")
+	require.Equal(t, []string{RuntimeKey, GPUMemoryFractionKey}, cr.KeyPath(err))
+
+	err = tfRuntime.Validate(PythonPredictorType)
+	require.EqualError(t, err, "runtime is not a supported field for the python predictor type")
+	require.Equal(t, []string{RuntimeKey}, cr.KeyPath(err))
+
+	onnxRuntime := &PredictorRuntime{ExecutionProviders: []string{"cuda"}}
+	require.NoError(t, onnxRuntime.Validate(ONNXPredictorType))
+
+	err = onnxRuntime.Validate(TensorFlowPredictorType)
+	require.Error(t, err)
+	require.Equal(t, []string{RuntimeKey, ExecutionProvidersKey}, cr.KeyPath(err))
+
+	var nilRuntime *PredictorRuntime
+	require.NoError(t, nilRuntime.Validate(PythonPredictorType))
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strings"
 
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
 )
@@ -31,12 +32,19 @@ type Resource interface {
 	SetIndex(int)
 	GetFilePath() string
 	SetFilePath(string)
+	GetSourcePositions() *cr.YAMLPositions
+	SetSourcePositions(*cr.YAMLPositions)
+	GetNameTemplate() string
+	SetNameTemplate(string)
 }
 
 type ResourceFields struct {
 	Name     string `json:"name" yaml:"name"`
 	Index    int    `json:"index" yaml:"-"`
 	FilePath string `json:"file_path" yaml:"-"`
+
+	sourcePositions *cr.YAMLPositions
+	nameTemplate    string // the name of the config entry which the resource was generated from (if it declares a matrix)
 }
 
 func (resourceFields *ResourceFields) GetName() string {
@@ -59,6 +67,22 @@ func (resourceFields *ResourceFields) SetFilePath(filePath string) {
 	resourceFields.FilePath = filePath
 }
 
+func (resourceFields *ResourceFields) GetSourcePositions() *cr.YAMLPositions {
+	return resourceFields.sourcePositions
+}
+
+func (resourceFields *ResourceFields) SetSourcePositions(positions *cr.YAMLPositions) {
+	resourceFields.sourcePositions = positions
+}
+
+func (resourceFields *ResourceFields) GetNameTemplate() string {
+	return resourceFields.nameTemplate
+}
+
+func (resourceFields *ResourceFields) SetNameTemplate(nameTemplate string) {
+	resourceFields.nameTemplate = nameTemplate
+}
+
 func (resourceFields *ResourceFields) UserConfigStr() string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("%s: %s\n", NameKey, resourceFields.Name))
@@ -66,7 +90,16 @@ func (resourceFields *ResourceFields) UserConfigStr() string {
 }
 
 func Identify(r Resource) string {
-	return identify(r.GetFilePath(), r.GetResourceType(), r.GetName(), r.GetIndex())
+	return identifyGenerated(r.GetFilePath(), r.GetResourceType(), r.GetName(), r.GetNameTemplate(), r.GetIndex())
+}
+
+// identifyGenerated also identifies the config entry which the resource was generated from (if any)
+func identifyGenerated(filePath string, resourceType resource.Type, name string, nameTemplate string, index int) string {
+	str := identify(filePath, resourceType, name, index)
+	if nameTemplate != "" {
+		str += " (generated by " + s.UserStr(nameTemplate) + ")"
+	}
+	return str
 }
 
 func identify(filePath string, resourceType resource.Type, name string, index int) string {
@@ -89,6 +122,15 @@ func identify(filePath string, resourceType resource.Type, name string, index in
 	return str + resourceTypeStr
 }
 
+// AttachSourcePosition points err to the resource's keys in its config file (or to the resource's name if no keys are provided)
+func AttachSourcePosition(r Resource, err error, keys ...string) error {
+	if len(keys) == 0 && len(cr.KeyPath(err)) == 0 {
+		keys = []string{NameKey}
+	}
+	keyPathPrefix := append([]string{s.Int(r.GetIndex())}, keys...)
+	return r.GetSourcePositions().Attach(err, keyPathPrefix...)
+}
+
 func FindDuplicateResourceName(resources ...Resource) []Resource {
 	names := make(map[string][]Resource)
 	for _, r := range resources {
@@ -0,0 +1,51 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+)
+
+// Security opt-outs relax individual parts of the restricted API pod security profile
+const (
+	RunAsRootOptOut              = "run_as_root"
+	WritableRootFilesystemOptOut = "writable_root_filesystem"
+	DefaultCapabilitiesOptOut    = "default_capabilities"
+	PrivilegeEscalationOptOut    = "privilege_escalation"
+	UnconfinedSeccompOptOut      = "unconfined_seccomp"
+)
+
+var SecurityOptOuts = []string{
+	RunAsRootOptOut,
+	WritableRootFilesystemOptOut,
+	DefaultCapabilitiesOptOut,
+	PrivilegeEscalationOptOut,
+	UnconfinedSeccompOptOut,
+}
+
+func validateSecurityOptOuts(optOuts []string) ([]string, error) {
+	for _, optOut := range optOuts {
+		if !slices.HasString(SecurityOptOuts, optOut) {
+			return nil, ErrorInvalidSecurityOptOut(optOut)
+		}
+	}
+	return optOuts, nil
+}
+
+func (api *API) HasSecurityOptOut(optOut string) bool {
+	return slices.HasString(api.SecurityOptOuts, optOut)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// Usage identifies the consumer of each request, so that the deployment's request counts, errors and latency are also recorded per consumer
+type Usage struct {
+	ConsumerHeader *string  `json:"consumer_header" yaml:"consumer_header"`
+	ConsumerClaim  *string  `json:"consumer_claim" yaml:"consumer_claim"`
+	Consumers      []string `json:"consumers" yaml:"consumers"` // requests from other consumers are recorded as unidentified
+}
+
+// UnidentifiedConsumer is recorded for requests which don't identify their consumer, or whose consumer isn't in the allow-list
+const UnidentifiedConsumer = "unidentified"
+
+// each consumer is a CloudWatch dimension value
+const _maxConsumerLength = 255
+
+var usageValidation = &cr.StructFieldValidation{
+	StructField: "Usage",
+	StructValidation: &cr.StructValidation{
+		DefaultNil:        true,
+		AllowExplicitNull: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "ConsumerHeader",
+				StringPtrValidation: &cr.StringPtrValidation{
+					Validator: validateConsumerHeader,
+				},
+			},
+			{
+				StructField:         "ConsumerClaim",
+				StringPtrValidation: &cr.StringPtrValidation{},
+			},
+			{
+				StructField: "Consumers",
+				StringListValidation: &cr.StringListValidation{
+					AllowExplicitNull: true,
+					DisallowDups:      true,
+					Validator:         validateConsumers,
+				},
+			},
+		},
+	},
+}
+
+func validateConsumerHeader(header string) (string, error) {
+	if !_headerNameRegex.MatchString(header) {
+		return "", ErrorInvalidConsumerHeader(header)
+	}
+	return header, nil
+}
+
+func validateConsumers(consumers []string) ([]string, error) {
+	for _, consumer := range consumers {
+		if consumer == "" || consumer == UnidentifiedConsumer || len(consumer) > _maxConsumerLength || !isASCII(consumer) {
+			return nil, ErrorInvalidConsumer(consumer)
+		}
+	}
+	return consumers, nil
+}
+
+func isASCII(str string) bool {
+	for _, r := range str {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks that the consumer is identified in exactly one way, and that consumer claims come from verified tokens
+func (usage *Usage) Validate(apis APIs) error {
+	if usage == nil {
+		return nil
+	}
+
+	if (usage.ConsumerHeader == nil) == (usage.ConsumerClaim == nil) {
+		return errors.Wrap(ErrorSpecifyOnlyOne(ConsumerHeaderKey, ConsumerClaimKey), UsageKey)
+	}
+
+	// request headers are set by the client, so without an allow-list any client could create an unbounded number of consumers
+	// (each of which is billed as a separate set of CloudWatch metrics)
+	if usage.ConsumerHeader != nil && usage.Consumers == nil {
+		return errors.Wrap(ErrorConsumerHeaderWithoutConsumers(), UsageKey)
+	}
+
+	if usage.ConsumerClaim != nil {
+		for _, api := range apis {
+			if api.JWTAuth == nil {
+				return errors.Wrap(ErrorConsumerClaimWithoutJWTAuth(api.Name), UsageKey, ConsumerClaimKey)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (usage *Usage) UserConfigStr() string {
+	var sb strings.Builder
+	if usage.ConsumerHeader != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ConsumerHeaderKey, *usage.ConsumerHeader))
+	}
+	if usage.ConsumerClaim != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ConsumerClaimKey, *usage.ConsumerClaim))
+	}
+	if usage.Consumers != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ConsumersKey, s.ObjFlatNoQuotes(usage.Consumers)))
+	}
+	return sb.String()
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+func usageConfig(usage string) []byte {
+	return []byte(`- kind: deployment
+  name: metered
+  usage:
+` + usage + `
+
+- kind: api
+  name: iris
+  predictor:
+    type: python
+    path: predictor.py
+`)
+}
+
+func TestUsageConsumers(t *testing.T) {
+	config, err := New("cortex.yaml", usageConfig("    consumer_header: X-Team\n    consumers: [team-a, team-b]"))
+	require.NoError(t, err)
+	require.NoError(t, config.App.Usage.Validate(config.APIs))
+	require.Equal(t, []string{"team-a", "team-b"}, config.App.Usage.Consumers)
+
+	config, err = New("cortex.yaml", usageConfig("    consumer_header: X-Team"))
+	require.NoError(t, err)
+	err = config.App.Usage.Validate(config.APIs)
+	require.Equal(t, ErrConsumerHeaderWithoutConsumers, errors.Cause(err).(Error).Kind)
+
+	for _, consumers := range []string{`[""]`, "[unidentified]", "[" + strings.Repeat("x", 256) + "]", "[équipe]"} {
+		_, err = New("cortex.yaml", usageConfig("    consumer_header: X-Team\n    consumers: "+consumers))
+		require.Error(t, err, consumers)
+		require.Equal(t, ErrInvalidConsumer, errors.Cause(err).(Error).Kind, consumers)
+	}
+
+	_, err = New("cortex.yaml", usageConfig("    consumer_header: X-Team\n    consumers: [team-a, team-a]"))
+	require.Error(t, err)
+}
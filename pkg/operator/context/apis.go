@@ -36,6 +36,9 @@ func getAPIs(config *userconfig.Config, deploymentVersion string, projectID stri
 		buf.WriteString(s.Obj(apiConfig.Tracker))
 		buf.WriteString(deploymentVersion)
 		buf.WriteString(s.Obj(apiConfig.Predictor))
+		buf.WriteString(s.Obj(apiConfig.PythonPackageIndex))
+		buf.WriteString(s.Obj(apiConfig.JWTAuth))
+		buf.WriteString(s.Obj(config.App.Usage)) // the APIs record usage metrics
 		buf.WriteString(projectID)
 
 		id := hash.Bytes(buf.Bytes())
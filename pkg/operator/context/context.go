@@ -17,6 +17,7 @@ limitations under the License.
 package context
 
 import (
+	gocontext "context"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -24,12 +25,14 @@ import (
 
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	"github.com/cortexlabs/cortex/pkg/lib/zip"
 	"github.com/cortexlabs/cortex/pkg/operator/api/context"
 	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
 )
 
 func New(
+	goCtx gocontext.Context,
 	userconf *userconfig.Config,
 	projectBytes []byte,
 	ignoreCache bool,
@@ -41,7 +44,7 @@ func New(
 
 	ctx.App = getApp(userconf.App)
 
-	deploymentVersion, err := getOrSetDeploymentVersion(ctx.App.Name, ignoreCache)
+	deploymentVersion, err := getOrSetDeploymentVersion(goCtx, ctx.App.Name, ignoreCache)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +74,14 @@ func New(
 
 	ctx.ProjectID = projectID
 	ctx.ProjectKey = filepath.Join(consts.ProjectsDir, ctx.ProjectID+".zip")
-	if err = config.AWS.UploadBytesToS3(projectBytes, ctx.ProjectKey); err != nil {
+
+	projectFileMap, err := zip.UnzipMemToMem(projectBytes)
+	if err != nil {
+		return nil, err
+	}
+	_, ctx.HasRequirements = projectFileMap["requirements.txt"]
+
+	if err = config.AWS.WithContext(goCtx).UploadBytesToS3(projectBytes, ctx.ProjectKey); err != nil {
 		return nil, err
 	}
 
@@ -111,11 +121,11 @@ func calculateID(ctx *context.Context) string {
 	return hash.String(strings.Join(ids, ""))
 }
 
-func DownloadContext(ctxID string, appName string) (*context.Context, error) {
+func DownloadContext(goCtx gocontext.Context, ctxID string, appName string) (*context.Context, error) {
 	s3Key := ctxKey(ctxID, appName)
 	var ctx context.Context
 
-	if err := config.AWS.ReadMsgpackFromS3(&ctx, s3Key); err != nil {
+	if err := config.AWS.WithContext(goCtx).ReadMsgpackFromS3(&ctx, s3Key); err != nil {
 		return nil, err
 	}
 
@@ -17,6 +17,7 @@ limitations under the License.
 package context
 
 import (
+	gocontext "context"
 	"path/filepath"
 	"time"
 
@@ -26,7 +27,9 @@ import (
 	"github.com/cortexlabs/cortex/pkg/operator/config"
 )
 
-func getOrSetDeploymentVersion(appName string, ignoreCache bool) (string, error) {
+func getOrSetDeploymentVersion(goCtx gocontext.Context, appName string, ignoreCache bool) (string, error) {
+	awsClient := config.AWS.WithContext(goCtx)
+
 	deploymentVersionFileKey := filepath.Join(
 		consts.AppsDir,
 		appName,
@@ -35,7 +38,7 @@ func getOrSetDeploymentVersion(appName string, ignoreCache bool) (string, error)
 
 	if ignoreCache {
 		deploymentVersion := libtime.Timestamp(time.Now())
-		err := config.AWS.UploadStringToS3(deploymentVersion, deploymentVersionFileKey)
+		err := awsClient.UploadStringToS3(deploymentVersion, deploymentVersionFileKey)
 		if err != nil {
 			if aws.IsNoSuchBucketErr(err) {
 				return "", aws.ErrorBucketInaccessible(config.AWS.Bucket)
@@ -45,7 +48,7 @@ func getOrSetDeploymentVersion(appName string, ignoreCache bool) (string, error)
 		return deploymentVersion, nil
 	}
 
-	deploymentVersion, err := config.AWS.ReadStringFromS3(deploymentVersionFileKey)
+	deploymentVersion, err := awsClient.ReadStringFromS3(deploymentVersionFileKey)
 	if err != nil {
 		if !aws.IsNoSuchKeyErr(err) {
 			if aws.IsNoSuchBucketErr(err) {
@@ -54,7 +57,7 @@ func getOrSetDeploymentVersion(appName string, ignoreCache bool) (string, error)
 			return "", err
 		}
 		deploymentVersion = libtime.Timestamp(time.Now())
-		err := config.AWS.UploadStringToS3(deploymentVersion, deploymentVersionFileKey)
+		err := awsClient.UploadStringToS3(deploymentVersion, deploymentVersionFileKey)
 		if err != nil {
 			if aws.IsNoSuchBucketErr(err) {
 				return "", aws.ErrorBucketInaccessible(config.AWS.Bucket)
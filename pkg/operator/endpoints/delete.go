@@ -32,13 +32,35 @@ func Delete(w http.ResponseWriter, r *http.Request) {
 
 	keepCache := getOptionalBoolQParam("keepCache", false, r)
 
-	wasDeployed := workloads.DeleteApp(appName, keepCache)
+	goCtx, cancel := detachedContext(_deleteTimeout)
+	defer cancel()
 
+	override, err := checkFreeze(r, appName, "delete")
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	freezeMessage, err := recordFreezeOverride(goCtx, override)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	wasDeployed, err := workloads.DeleteApp(goCtx, appName, keepCache)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
 	if !wasDeployed {
 		RespondError(w, ErrorAppNotDeployed(appName))
 		return
 	}
 
-	response := schema.DeleteResponse{Message: ResDeploymentDeleted(appName)}
+	message := ResDeploymentDeleted(appName)
+	if freezeMessage != "" {
+		message = freezeMessage + "\n" + message
+	}
+	response := schema.DeleteResponse{Message: message}
 	Respond(w, response)
 }
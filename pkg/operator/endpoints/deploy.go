@@ -63,13 +63,23 @@ func Deploy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, err := ocontext.New(userconf, projectBytes, ignoreCache)
+	goCtx, cancel := requestContext(r, _deployTimeout)
+	defer cancel()
+
+	// the override is only recorded once the deployment has been validated and is about to be applied
+	override, err := checkFreeze(r, userconf.App.Name, "deploy")
 	if err != nil {
 		RespondError(w, err)
 		return
 	}
 
-	err = workloads.PopulateWorkloadIDs(ctx)
+	ctx, err := ocontext.New(goCtx, userconf, projectBytes, ignoreCache)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	err = workloads.PopulateWorkloadIDs(goCtx, ctx)
 	if err != nil {
 		RespondError(w, err)
 		return
@@ -82,13 +92,13 @@ func Deploy(w http.ResponseWriter, r *http.Request) {
 		fullCtxMatch = true
 	}
 
-	err = workloads.ValidateDeploy(ctx)
+	err = workloads.ValidateDeploy(goCtx, ctx)
 	if err != nil {
 		RespondError(w, err)
 		return
 	}
 
-	deploymentStatus, err := workloads.GetDeploymentStatus(ctx.App.Name)
+	deploymentStatus, err := workloads.GetDeploymentStatus(goCtx, ctx.App.Name)
 	if err != nil {
 		RespondError(w, err)
 		return
@@ -109,19 +119,29 @@ func Deploy(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	err = config.AWS.UploadMsgpackToS3(ctx, ctx.Key)
+	// once the deployment starts being applied, finish applying it even if the client disconnects
+	commitCtx, cancelCommit := detachedContext(_deployTimeout)
+	defer cancelCommit()
+
+	freezeMessage, err := recordFreezeOverride(commitCtx, override)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	err = config.AWS.WithContext(commitCtx).UploadMsgpackToS3(ctx, ctx.Key)
 	if err != nil {
 		RespondError(w, err, ctx.App.Name, "upload context")
 		return
 	}
 
-	err = workloads.Run(ctx)
+	err = workloads.Run(commitCtx, ctx)
 	if err != nil {
 		RespondError(w, err)
 		return
 	}
 
-	apisBaseURL, err := workloads.APIsBaseURL()
+	apisBaseURL, err := workloads.APIsBaseURL(commitCtx)
 	if err != nil {
 		RespondError(w, err)
 		return
@@ -134,6 +154,9 @@ func Deploy(w http.ResponseWriter, r *http.Request) {
 	} else {
 		baseMessage, updatingAPIs = apiDiffMessage(existingCtx, ctx, apisBaseURL)
 	}
+	if freezeMessage != "" {
+		baseMessage = freezeMessage + "\n" + baseMessage
+	}
 
 	Respond(w, schema.DeployResponse{
 		Context:     ctx,
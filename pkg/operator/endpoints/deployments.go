@@ -25,11 +25,14 @@ import (
 )
 
 func GetDeployments(w http.ResponseWriter, r *http.Request) {
+	goCtx, cancel := requestContext(r, _getTimeout)
+	defer cancel()
+
 	currentContexts := workloads.CurrentContexts()
 	deployments := make([]schema.Deployment, len(currentContexts))
 	for i, ctx := range currentContexts {
 		deployments[i].Name = ctx.App.Name
-		status, _ := workloads.GetDeploymentStatus(ctx.App.Name)
+		status, _ := workloads.GetDeploymentStatus(goCtx, ctx.App.Name)
 		deployments[i].Status = status
 		deployments[i].LastUpdated = time.Unix(ctx.CreatedEpoch, 0)
 	}
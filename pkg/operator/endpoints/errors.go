@@ -19,6 +19,7 @@ package endpoints
 import (
 	"fmt"
 
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 )
@@ -40,6 +41,9 @@ const (
 	ErrAnyQueryParamRequired
 	ErrAnyPathParamRequired
 	ErrPending
+	ErrRequestCanceled
+	ErrOperationTimedOut
+	ErrDeploymentFrozen
 )
 
 var (
@@ -58,10 +62,13 @@ var (
 		"err_any_query_param_required",
 		"err_any_path_param_required",
 		"err_pending",
+		"err_request_canceled",
+		"err_operation_timed_out",
+		"err_deployment_frozen",
 	}
 )
 
-var _ = [1]int{}[int(ErrPending)-(len(errorKinds)-1)] // Ensure list length matches
+var _ = [1]int{}[int(ErrDeploymentFrozen)-(len(errorKinds)-1)] // Ensure list length matches
 
 func (t ErrorKind) String() string {
 	return errorKinds[t]
@@ -130,7 +137,7 @@ func ErrorAuthAPIError() error {
 func ErrorAuthInvalid() error {
 	return errors.WithStack(Error{
 		Kind:    ErrAuthInvalid,
-		message: "invalid AWS credentials; run `cortex configure` to configure your CLI with credentials for any IAM user in the same AWS account as the operator",
+		message: "invalid AWS credentials, or the CLI environment is configured for a different cluster; run `cortex configure` to configure your CLI with the cluster's name and credentials for any IAM user in the same AWS account as the operator",
 	})
 }
 
@@ -196,3 +203,28 @@ func ErrorPending() error {
 		message: "pending",
 	})
 }
+
+func ErrorRequestCanceled() error {
+	return errors.WithStack(Error{
+		Kind:    ErrRequestCanceled,
+		message: "the request was canceled",
+	})
+}
+
+func ErrorOperationTimedOut() error {
+	return errors.WithStack(Error{
+		Kind:    ErrOperationTimedOut,
+		message: "the operator timed out while waiting for a response from kubernetes or aws; please try again",
+	})
+}
+
+func ErrorDeploymentFrozen(appName string, periods []clusterconfig.FreezePeriod) error {
+	periodStrs := make([]string, len(periods))
+	for i := range periods {
+		periodStrs[i] = periods[i].UserFacingString()
+	}
+	return errors.WithStack(Error{
+		Kind:    ErrDeploymentFrozen,
+		message: fmt.Sprintf("%s deployment can't be modified during the %s freeze window; if this is an emergency fix, override the freeze with --override-freeze \"<reason>\" (the override will be recorded)", appName, s.StrsAnd(periodStrs)),
+	})
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	gocontext "context"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/random"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// Freeze windows which start within this duration are included in the cluster info
+const _upcomingFreezesDuration = 30 * 24 * time.Hour
+
+// freezeOverride is recorded in the bucket whenever a frozen deployment is modified
+type freezeOverride struct {
+	AppName       string                       `json:"app_name"`
+	Operation     string                       `json:"operation"`
+	Reason        string                       `json:"reason"`
+	Caller        string                       `json:"caller"`
+	Time          time.Time                    `json:"time"`
+	FreezePeriods []clusterconfig.FreezePeriod `json:"freeze_periods"`
+}
+
+func ResFreezeOverridden(periods []clusterconfig.FreezePeriod, reason string) string {
+	names := make([]string, len(periods))
+	for i := range periods {
+		names[i] = periods[i].Name
+	}
+	return fmt.Sprintf("overriding %s freeze window (reason: %s)", strings.Join(names, ", "), reason)
+}
+
+// checkFreeze returns an error if the deployment is frozen and the freeze wasn't overridden; if it was overridden, the override is
+// returned (it should be recorded with recordFreezeOverride once the operation has been validated)
+func checkFreeze(r *http.Request, appName string, operation string) (*freezeOverride, error) {
+	now := time.Now()
+	periods := clusterconfig.ActiveFreezePeriods(config.Cluster.FreezeWindows, now, appName)
+	if len(periods) == 0 {
+		return nil, nil
+	}
+
+	reason := strings.TrimSpace(getOptionalQParam("overrideFreeze", r))
+	if reason == "" {
+		return nil, ErrorDeploymentFrozen(appName, periods)
+	}
+
+	return &freezeOverride{
+		AppName:       appName,
+		Operation:     operation,
+		Reason:        reason,
+		Caller:        getCaller(r),
+		Time:          now.UTC(),
+		FreezePeriods: periods,
+	}, nil
+}
+
+// recordFreezeOverride records the override in the bucket and returns a message for the user (or an empty string if override is nil)
+func recordFreezeOverride(goCtx gocontext.Context, override *freezeOverride) (string, error) {
+	if override == nil {
+		return "", nil
+	}
+
+	if err := config.AWS.WithContext(goCtx).UploadJSONToS3(override, freezeOverrideKey(override)); err != nil {
+		return "", errors.Wrap(err, "record freeze override", override.AppName)
+	}
+	log.Printf("%s %s during freeze (caller: %s, reason: %s)", override.Operation, override.AppName, override.Caller, override.Reason)
+
+	return ResFreezeOverridden(override.FreezePeriods, override.Reason), nil
+}
+
+// the random suffix keeps overrides which are requested within the same second from overwriting each other
+func freezeOverrideKey(override *freezeOverride) string {
+	fileName := fmt.Sprintf("%s-%s-%s.json", override.Time.Format("2006-01-02-15-04-05"), override.Operation, random.LowercaseString(8))
+	return filepath.Join(consts.FreezeOverridesDir, override.AppName, fileName)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	gocontext "context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+func TestCheckFreeze(t *testing.T) {
+	originalCluster := config.Cluster
+	defer func() { config.Cluster = originalCluster }()
+
+	config.Cluster = &clusterconfig.InternalConfig{
+		Config: clusterconfig.Config{
+			FreezeWindows: []*clusterconfig.FreezeWindow{
+				{Name: "always", Start: "00:00", End: "00:00", Timezone: "UTC", Deployments: []string{"payments"}},
+			},
+		},
+	}
+
+	r := WithCaller(httptest.NewRequest("POST", "/delete?appName=payments", nil), "arn:aws:iam::123456789012:user/alice")
+	require.Equal(t, "arn:aws:iam::123456789012:user/alice", getCaller(r))
+
+	_, err := checkFreeze(r, "payments", "delete")
+	require.Equal(t, ErrDeploymentFrozen, errors.Cause(err).(Error).Kind)
+	require.Contains(t, err.Error(), "--override-freeze")
+
+	override, err := checkFreeze(r, "search", "delete")
+	require.NoError(t, err)
+	require.Nil(t, override)
+
+	message, err := recordFreezeOverride(gocontext.Background(), override)
+	require.NoError(t, err)
+	require.Empty(t, message)
+
+	r = httptest.NewRequest("POST", "/delete?appName=payments&overrideFreeze=%20", nil)
+	require.Equal(t, "unknown", getCaller(r))
+	_, err = checkFreeze(r, "payments", "delete")
+	require.Equal(t, ErrDeploymentFrozen, errors.Cause(err).(Error).Kind)
+
+	r = WithCaller(httptest.NewRequest("POST", "/deploy?overrideFreeze=hotfix", nil), "arn:aws:iam::123456789012:user/alice")
+	override, err = checkFreeze(r, "payments", "deploy")
+	require.NoError(t, err)
+	require.Equal(t, "hotfix", override.Reason)
+	require.Equal(t, "arn:aws:iam::123456789012:user/alice", override.Caller)
+	require.Equal(t, "deploy", override.Operation)
+	require.Len(t, override.FreezePeriods, 1)
+
+	// overrides within the same second are recorded separately
+	require.NotEqual(t, freezeOverrideKey(override), freezeOverrideKey(override))
+	require.Regexp(t, `^freeze_overrides/payments/\d{4}(-\d{2}){5}-deploy-[a-z0-9]{8}\.json$`, freezeOverrideKey(override))
+}
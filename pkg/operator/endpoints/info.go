@@ -19,16 +19,33 @@ package endpoints
 import (
 	"net/http"
 	"os"
+	"time"
 
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
+	"github.com/cortexlabs/cortex/pkg/operator/workloads"
 )
 
 func Info(w http.ResponseWriter, r *http.Request) {
+	goCtx, cancel := requestContext(r, _getTimeout)
+	defer cancel()
+
+	imageCache, err := workloads.GetImageCacheStatuses(goCtx)
+	if err != nil {
+		// the rest of the cluster info is still useful
+		errors.PrintError(err, "get image cache statuses")
+	}
+
+	now := time.Now()
 	response := schema.InfoResponse{
 		MaskedAWSAccessKeyID: s.MaskString(os.Getenv("AWS_ACCESS_KEY_ID"), 4),
 		ClusterConfig:        config.Cluster,
+		ActiveFreezes:        clusterconfig.ActiveFreezePeriods(config.Cluster.FreezeWindows, now, ""),
+		UpcomingFreezes:      clusterconfig.UpcomingFreezePeriods(config.Cluster.FreezeWindows, now, _upcomingFreezesDuration),
+		ImageCache:           imageCache,
 	}
 	Respond(w, response)
 }
@@ -45,6 +45,12 @@ func ReadLogs(w http.ResponseWriter, r *http.Request) {
 	resourceName := getOptionalQParam("resourceName", r)
 	resourceType := getOptionalQParam("resourceType", r)
 
+	filterPattern, err := workloads.LogFilterPattern(r.URL.Query()["filter"], getOptionalQParam("level", r))
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
 	podLabels := map[string]string{
 		"appName":      appName,
 		"userFacing":   "true",
@@ -53,12 +59,14 @@ func ReadLogs(w http.ResponseWriter, r *http.Request) {
 
 	if workloadID != "" {
 		podLabels["workloadID"] = workloadID
-		readLogs(w, r, podLabels, appName)
+		readLogs(w, r, podLabels, filterPattern, appName)
 		return
 	}
 
 	if resourceID != "" {
-		workloadID, err = workloads.GetLatestWorkloadID(resourceID, appName)
+		goCtx, cancel := requestContext(r, _getTimeout)
+		workloadID, err = workloads.GetLatestWorkloadID(goCtx, resourceID, appName)
+		cancel()
 		if err != nil {
 			RespondError(w, err)
 			return
@@ -69,7 +77,7 @@ func ReadLogs(w http.ResponseWriter, r *http.Request) {
 		}
 
 		podLabels["workloadID"] = workloadID
-		readLogs(w, r, podLabels, appName)
+		readLogs(w, r, podLabels, filterPattern, appName)
 		return
 	}
 
@@ -89,7 +97,7 @@ func ReadLogs(w http.ResponseWriter, r *http.Request) {
 		} else {
 			podLabels["workloadID"] = res.GetWorkloadID()
 		}
-		readLogs(w, r, podLabels, appName)
+		readLogs(w, r, podLabels, filterPattern, appName)
 		return
 	}
 
@@ -102,7 +110,7 @@ func ReadLogs(w http.ResponseWriter, r *http.Request) {
 		} else {
 			podLabels["workloadID"] = res.GetWorkloadID()
 		}
-		readLogs(w, r, podLabels, appName)
+		readLogs(w, r, podLabels, filterPattern, appName)
 		return
 	}
 
@@ -114,7 +122,7 @@ func ReadLogs(w http.ResponseWriter, r *http.Request) {
 	workloadIDs = slices.UniqueStrings(workloadIDs)
 	if len(workloadIDs) == 1 {
 		podLabels["workloadID"] = workloadIDs[0]
-		readLogs(w, r, podLabels, appName)
+		readLogs(w, r, podLabels, filterPattern, appName)
 		return
 	}
 
@@ -122,7 +130,7 @@ func ReadLogs(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
-func readLogs(w http.ResponseWriter, r *http.Request, podLabels map[string]string, appName string) {
+func readLogs(w http.ResponseWriter, r *http.Request, podLabels map[string]string, filterPattern string, appName string) {
 	upgrader := websocket.Upgrader{}
 	socket, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -131,5 +139,5 @@ func readLogs(w http.ResponseWriter, r *http.Request, podLabels map[string]strin
 	}
 	defer socket.Close()
 
-	workloads.ReadLogs(appName, podLabels, socket)
+	workloads.ReadLogs(appName, podLabels, filterPattern, socket)
 }
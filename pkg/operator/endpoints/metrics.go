@@ -46,7 +46,10 @@ func GetMetrics(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	apiMetrics, err := workloads.GetMetrics(ctx, apiName)
+	goCtx, cancel := requestContext(r, _getTimeout)
+	defer cancel()
+
+	apiMetrics, err := workloads.GetMetrics(goCtx, ctx, apiName)
 	if err != nil {
 		RespondError(w, err)
 		return
@@ -0,0 +1,36 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/operator/workloads"
+)
+
+func GetNodes(w http.ResponseWriter, r *http.Request) {
+	goCtx, cancel := requestContext(r, _getTimeout)
+	defer cancel()
+
+	response, err := workloads.GetNodes(goCtx)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	Respond(w, response)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/workloads"
+)
+
+func GetOverview(w http.ResponseWriter, r *http.Request) {
+	goCtx, cancel := requestContext(r, _getTimeout)
+	defer cancel()
+
+	apisBaseURL, err := workloads.APIsBaseURL(goCtx)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	currentContexts := workloads.CurrentContexts()
+	deployments := make([]schema.DeploymentOverview, len(currentContexts))
+	for i, ctx := range currentContexts {
+		deployments[i].Name = ctx.App.Name
+		status, err := workloads.GetDeploymentStatus(goCtx, ctx.App.Name)
+		if err != nil {
+			RespondError(w, err)
+			return
+		}
+		deployments[i].Status = status
+		deployments[i].LastUpdated = time.Unix(ctx.CreatedEpoch, 0)
+
+		dataStatuses, err := workloads.GetCurrentDataStatuses(goCtx, ctx)
+		if err != nil {
+			RespondError(w, err)
+			return
+		}
+
+		_, apiGroupStatuses, err := workloads.GetCurrentAPIAndGroupStatuses(goCtx, dataStatuses, ctx)
+		if err != nil {
+			RespondError(w, err)
+			return
+		}
+
+		for _, api := range ctx.APIs {
+			deployments[i].APIs = append(deployments[i].APIs, schema.APIOverview{
+				Name:        api.Name,
+				GroupStatus: apiGroupStatuses[api.Name],
+				Compute:     api.Compute,
+				Endpoint:    urls.Join(apisBaseURL, *api.Endpoint),
+			})
+		}
+		sort.Slice(deployments[i].APIs, func(a, b int) bool {
+			return deployments[i].APIs[a].Name < deployments[i].APIs[b].Name
+		})
+	}
+
+	sort.Slice(deployments, func(a, b int) bool {
+		return deployments[a].Name < deployments[b].Name
+	})
+
+	clusterResources, err := workloads.GetClusterResources(goCtx)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	response := schema.GetOverviewResponse{
+		Deployments:      deployments,
+		ClusterResources: clusterResources,
+		APIsBaseURL:      apisBaseURL,
+	}
+
+	Respond(w, response)
+}
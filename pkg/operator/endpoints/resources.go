@@ -36,19 +36,22 @@ func GetResources(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dataStatuses, err := workloads.GetCurrentDataStatuses(ctx)
+	goCtx, cancel := requestContext(r, _getTimeout)
+	defer cancel()
+
+	dataStatuses, err := workloads.GetCurrentDataStatuses(goCtx, ctx)
 	if err != nil {
 		RespondError(w, err)
 		return
 	}
 
-	apiStatuses, apiGroupStatuses, err := workloads.GetCurrentAPIAndGroupStatuses(dataStatuses, ctx)
+	apiStatuses, apiGroupStatuses, err := workloads.GetCurrentAPIAndGroupStatuses(goCtx, dataStatuses, ctx)
 	if err != nil {
 		RespondError(w, err)
 		return
 	}
 
-	apisBaseURL, err := workloads.APIsBaseURL()
+	apisBaseURL, err := workloads.APIsBaseURL(goCtx)
 	if err != nil {
 		RespondError(w, err)
 		return
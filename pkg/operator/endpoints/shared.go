@@ -17,17 +17,31 @@ limitations under the License.
 package endpoints
 
 import (
+	gocontext "context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
 	"github.com/gorilla/mux"
 )
 
+// Deadlines for the work done by each endpoint (including all of its requests to kubernetes and aws)
+const (
+	_deployTimeout = 5 * time.Minute
+	_deleteTimeout = 2 * time.Minute
+	_getTimeout    = 1 * time.Minute
+)
+
+// Non-standard status code (originally from nginx) for requests which the client canceled before a response was sent
+const _statusClientClosedRequest = 499
+
 func ResDeploymentDeleted(appName string) string {
 	return fmt.Sprintf("deleting %s deployment", appName)
 }
@@ -69,9 +83,19 @@ func RespondErrorCode(w http.ResponseWriter, code int, err error, strs ...string
 	err = errors.Wrap(err, strs...)
 	errors.PrintError(err)
 
+	// the original error is logged above, but it's not helpful to the user (e.g. "context deadline exceeded")
+	if errors.IsCanceled(err) {
+		code = _statusClientClosedRequest
+		err = ErrorRequestCanceled()
+	} else if errors.IsTimeout(err) {
+		code = http.StatusGatewayTimeout
+		err = ErrorOperationTimedOut()
+	}
+
 	w.WriteHeader(code)
 	response := schema.ErrorResponse{
-		Error: err.Error(),
+		Error:          err.Error(),
+		SourcePosition: cr.GetSourcePosition(err),
 	}
 	json.NewEncoder(w).Encode(response)
 }
@@ -84,6 +108,34 @@ func RecoverAndRespond(w http.ResponseWriter, strs ...string) {
 	}
 }
 
+type callerContextKey struct{}
+
+// WithCaller returns a shallow copy of r whose context identifies the authenticated caller (e.g. by IAM ARN)
+func WithCaller(r *http.Request, caller string) *http.Request {
+	return r.WithContext(gocontext.WithValue(r.Context(), callerContextKey{}, caller))
+}
+
+func getCaller(r *http.Request) string {
+	if caller, ok := r.Context().Value(callerContextKey{}).(string); ok {
+		return caller
+	}
+	return "unknown"
+}
+
+// requestContext returns a context which is done when the client disconnects, or after timeout
+// (the kubernetes clients for the context are built once, and shared by the request's operations)
+func requestContext(r *http.Request, timeout time.Duration) (gocontext.Context, gocontext.CancelFunc) {
+	goCtx, cancel := gocontext.WithTimeout(r.Context(), timeout)
+	return k8s.ContextWithClientCache(goCtx), cancel
+}
+
+// detachedContext returns a context which is only done after timeout; it is used for operations which modify the cluster,
+// so that a client disconnecting can't leave them partially applied
+func detachedContext(timeout time.Duration) (gocontext.Context, gocontext.CancelFunc) {
+	goCtx, cancel := gocontext.WithTimeout(gocontext.Background(), timeout)
+	return k8s.ContextWithClientCache(goCtx), cancel
+}
+
 func getRequiredPathParam(paramName string, r *http.Request) (string, error) {
 	param := mux.Vars(r)[paramName]
 	if param == "" {
@@ -0,0 +1,53 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+)
+
+func respondErrorResponse(t *testing.T, err error) (int, string) {
+	recorder := httptest.NewRecorder()
+	RespondError(recorder, err)
+
+	var response schema.ErrorResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	return recorder.Code, response.Error
+}
+
+func TestRespondErrorCanceledAndTimedOut(t *testing.T) {
+	code, message := respondErrorResponse(t, errors.Wrap(gocontext.Canceled, "app", "upload context"))
+	require.Equal(t, _statusClientClosedRequest, code)
+	require.Equal(t, ErrorRequestCanceled().Error(), message)
+
+	code, message = respondErrorResponse(t, errors.Wrap(gocontext.DeadlineExceeded, "app"))
+	require.Equal(t, http.StatusGatewayTimeout, code)
+	require.Equal(t, ErrorOperationTimedOut().Error(), message)
+
+	code, message = respondErrorResponse(t, ErrorAppNotDeployed("app"))
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Equal(t, "app is not deployed", message)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/workloads"
+)
+
+// usage is read from the recorded metrics, so it is also available for deployments which have since been deleted
+func GetUsage(w http.ResponseWriter, r *http.Request) {
+	appName, err := getRequiredQueryParam("appName", r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	start, end, err := workloads.UsageRange(getOptionalQParam("start", r), getOptionalQParam("end", r))
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	groupBy := []string{workloads.UsageGroupByConsumer}
+	if groupByStr := getOptionalQParam("groupBy", r); groupByStr != "" {
+		groupBy = strings.Split(groupByStr, ",")
+	}
+	if err := workloads.ValidateUsageGroupBy(groupBy); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	goCtx, cancel := requestContext(r, _getTimeout)
+	defer cancel()
+
+	rows, err := workloads.GetUsage(goCtx, appName, start, end, groupBy)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	Respond(w, schema.GetUsageResponse{
+		Start:   start.Format(workloads.UsageDateFormat),
+		End:     end.Format(workloads.UsageDateFormat),
+		GroupBy: groupBy,
+		Rows:    rows,
+	})
+}
@@ -17,9 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"encoding/base64"
 	"log"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
@@ -57,7 +59,10 @@ func main() {
 	router.HandleFunc("/deploy", endpoints.Deploy).Methods("POST")
 	router.HandleFunc("/delete", endpoints.Delete).Methods("POST")
 	router.HandleFunc("/deployments", endpoints.GetDeployments).Methods("GET")
+	router.HandleFunc("/overview", endpoints.GetOverview).Methods("GET")
+	router.HandleFunc("/nodes", endpoints.GetNodes).Methods("GET")
 	router.HandleFunc("/metrics", endpoints.GetMetrics).Methods("GET")
+	router.HandleFunc("/usage", endpoints.GetUsage).Methods("GET")
 	router.HandleFunc("/resources", endpoints.GetResources).Methods("GET")
 	router.HandleFunc("/logs/read", endpoints.ReadLogs)
 
@@ -88,19 +93,48 @@ func authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 
-		if !strings.HasPrefix(authHeader, "CortexAWS") {
-			endpoints.RespondError(w, endpoints.ErrorAuthHeaderMissing())
-			return
-		}
+		var userAccountID string
+		var caller string
+		var validCreds bool
+		var err error
+
+		switch {
+		case strings.HasPrefix(authHeader, consts.AuthHeaderSignedPrefix):
+			presignedURL, decodeErr := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(authHeader, consts.AuthHeaderSignedPrefix))
+			if decodeErr != nil {
+				endpoints.RespondError(w, endpoints.ErrorAuthHeaderMalformed())
+				return
+			}
+			var identity *aws.CallerIdentity
+			identity, validCreds, err = aws.VerifyPresignedGetCallerIdentity(string(presignedURL), config.Cluster.ClusterName)
+			if aws.IsInvalidSignedIdentityRequestErr(err) {
+				endpoints.RespondErrorCode(w, http.StatusForbidden, err)
+				return
+			}
+			if identity != nil {
+				userAccountID = identity.AccountID
+				caller = identity.ARN
+			}
+
+		case strings.HasPrefix(authHeader, consts.AuthHeaderLegacyPrefix):
+			// Deprecated: the legacy auth header contains the user's secret access key, and is only accepted until all CLIs send signed identity requests
+			parts := strings.Split(strings.TrimPrefix(authHeader, consts.AuthHeaderLegacyPrefix), "|")
+			if len(parts) != 2 {
+				endpoints.RespondError(w, endpoints.ErrorAuthHeaderMalformed())
+				return
+			}
+			accessKeyID, secretAccessKey := parts[0], parts[1]
+			userAccountID, validCreds, err = aws.AccountID(accessKeyID, secretAccessKey, *config.Cluster.Region)
+			caller = "access key id " + accessKeyID
+			if err == nil && validCreds {
+				warnLegacyAuthHeader(accessKeyID)
+			}
 
-		parts := strings.Split(authHeader[10:], "|")
-		if len(parts) != 2 {
-			endpoints.RespondError(w, endpoints.ErrorAuthHeaderMalformed())
+		default:
+			endpoints.RespondError(w, endpoints.ErrorAuthHeaderMissing())
 			return
 		}
 
-		accessKeyID, secretAccessKey := parts[0], parts[1]
-		userAccountID, validCreds, err := aws.AccountID(accessKeyID, secretAccessKey, *config.Cluster.Region)
 		if err != nil {
 			endpoints.RespondError(w, endpoints.ErrorAuthAPIError())
 			return
@@ -114,10 +148,25 @@ func authMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, endpoints.WithCaller(r, caller))
 	})
 }
 
+var _legacyAuthAccessKeyIDs = strset.New()
+var _legacyAuthMutex sync.Mutex
+
+// Warns once per access key ID (the secret access key is never logged)
+func warnLegacyAuthHeader(accessKeyID string) {
+	_legacyAuthMutex.Lock()
+	defer _legacyAuthMutex.Unlock()
+
+	if _legacyAuthAccessKeyIDs.Has(accessKeyID) {
+		return
+	}
+	_legacyAuthAccessKeyIDs.Add(accessKeyID)
+	log.Printf("warning: access key ID %s authenticated with the deprecated %s auth header, which includes its secret access key; update the CLI to authenticate with signed identity requests", accessKeyID, strings.TrimSpace(consts.AuthHeaderLegacyPrefix))
+}
+
 func apiVersionCheckMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/info" {
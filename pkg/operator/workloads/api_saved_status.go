@@ -17,6 +17,7 @@ limitations under the License.
 package workloads
 
 import (
+	gocontext "context"
 	"time"
 
 	kcore "k8s.io/api/core/v1"
@@ -31,13 +32,13 @@ import (
 	ocontext "github.com/cortexlabs/cortex/pkg/operator/context"
 )
 
-func uploadAPISavedStatus(savedStatus *resource.APISavedStatus) error {
+func uploadAPISavedStatus(goCtx gocontext.Context, savedStatus *resource.APISavedStatus) error {
 	if isAPISavedStatusCached(savedStatus) {
 		return nil
 	}
 
 	key := ocontext.StatusKey(savedStatus.ResourceID, savedStatus.WorkloadID, savedStatus.AppName)
-	err := config.AWS.UploadJSONToS3(savedStatus, key)
+	err := config.AWS.WithContext(goCtx).UploadJSONToS3(savedStatus, key)
 	if err != nil {
 		return errors.Wrap(err, "upload api saved status", savedStatus.AppName, savedStatus.ResourceID, savedStatus.WorkloadID)
 	}
@@ -45,28 +46,28 @@ func uploadAPISavedStatus(savedStatus *resource.APISavedStatus) error {
 	return nil
 }
 
-func uploadAPISavedStatuses(savedStatuses []*resource.APISavedStatus) error {
+func uploadAPISavedStatuses(goCtx gocontext.Context, savedStatuses []*resource.APISavedStatus) error {
 	fns := make([]func() error, len(savedStatuses))
 	for i, savedStatus := range savedStatuses {
-		fns[i] = uploadAPISavedStatusFunc(savedStatus)
+		fns[i] = uploadAPISavedStatusFunc(goCtx, savedStatus)
 	}
 	return parallel.RunFirstErr(fns...)
 }
 
-func uploadAPISavedStatusFunc(savedStatus *resource.APISavedStatus) func() error {
+func uploadAPISavedStatusFunc(goCtx gocontext.Context, savedStatus *resource.APISavedStatus) func() error {
 	return func() error {
-		return uploadAPISavedStatus(savedStatus)
+		return uploadAPISavedStatus(goCtx, savedStatus)
 	}
 }
 
-func getAPISavedStatus(resourceID string, workloadID string, appName string) (*resource.APISavedStatus, error) {
+func getAPISavedStatus(goCtx gocontext.Context, resourceID string, workloadID string, appName string) (*resource.APISavedStatus, error) {
 	if cachedSavedStatus, ok := getCachedAPISavedStatus(resourceID, workloadID, appName); ok {
 		return cachedSavedStatus, nil
 	}
 
 	key := ocontext.StatusKey(resourceID, workloadID, appName)
 	var savedStatus resource.APISavedStatus
-	err := config.AWS.ReadJSONFromS3(&savedStatus, key)
+	err := config.AWS.WithContext(goCtx).ReadJSONFromS3(&savedStatus, key)
 	if aws.IsNoSuchKeyErr(err) {
 		return nil, nil
 	}
@@ -77,7 +78,7 @@ func getAPISavedStatus(resourceID string, workloadID string, appName string) (*r
 	return &savedStatus, nil
 }
 
-func calculateAPISavedStatuses(podList []kcore.Pod, appName string) ([]*resource.APISavedStatus, error) {
+func calculateAPISavedStatuses(goCtx gocontext.Context, podList []kcore.Pod, appName string) ([]*resource.APISavedStatus, error) {
 	podMap := make(map[string]map[string][]kcore.Pod)
 	for _, pod := range podList {
 		resourceID := pod.Labels["resourceID"]
@@ -91,7 +92,7 @@ func calculateAPISavedStatuses(podList []kcore.Pod, appName string) ([]*resource
 	var savedStatuses []*resource.APISavedStatus
 	for resourceID := range podMap {
 		for workloadID, pods := range podMap[resourceID] {
-			savedStatus, err := getAPISavedStatus(resourceID, workloadID, appName)
+			savedStatus, err := getAPISavedStatus(goCtx, resourceID, workloadID, appName)
 			if err != nil {
 				return nil, err
 			}
@@ -132,7 +133,7 @@ func updateAPISavedStatusStartTime(savedStatus *resource.APISavedStatus, pods []
 	}
 }
 
-func updateAPISavedStatuses(allPods []kcore.Pod) error {
+func updateAPISavedStatuses(goCtx gocontext.Context, allPods []kcore.Pod) error {
 	podMap := make(map[string][]kcore.Pod)
 	for _, pod := range allPods {
 		appName := pod.Labels["appName"]
@@ -141,19 +142,19 @@ func updateAPISavedStatuses(allPods []kcore.Pod) error {
 
 	var allSavedStatuses []*resource.APISavedStatus
 	for appName, podList := range podMap {
-		savedStatuses, err := calculateAPISavedStatuses(podList, appName)
+		savedStatuses, err := calculateAPISavedStatuses(goCtx, podList, appName)
 		if err != nil {
 			return err
 		}
 		allSavedStatuses = append(allSavedStatuses, savedStatuses...)
 	}
 
-	err := uploadAPISavedStatuses(allSavedStatuses)
+	err := uploadAPISavedStatuses(goCtx, allSavedStatuses)
 	if err != nil {
 		return err
 	}
 
-	err = updateFinishedAPISavedStatuses(allSavedStatuses)
+	err = updateFinishedAPISavedStatuses(goCtx, allSavedStatuses)
 	if err != nil {
 		return err
 	}
@@ -161,7 +162,7 @@ func updateAPISavedStatuses(allPods []kcore.Pod) error {
 	return nil
 }
 
-func updateFinishedAPISavedStatuses(allSavedStatuses []*resource.APISavedStatus) error {
+func updateFinishedAPISavedStatuses(goCtx gocontext.Context, allSavedStatuses []*resource.APISavedStatus) error {
 	staleSavedStatuses := getStaleAPISavedStatuses(allSavedStatuses)
 	for _, savedStatus := range staleSavedStatuses {
 		if savedStatus.End == nil {
@@ -169,7 +170,7 @@ func updateFinishedAPISavedStatuses(allSavedStatuses []*resource.APISavedStatus)
 		}
 	}
 
-	err := uploadAPISavedStatuses(staleSavedStatuses)
+	err := uploadAPISavedStatuses(goCtx, staleSavedStatuses)
 	if err != nil {
 		if !aws.IsGenericNotFoundErr(err) {
 			return err
@@ -17,6 +17,10 @@ limitations under the License.
 package workloads
 
 import (
+	gocontext "context"
+	"regexp"
+	"strconv"
+	"sync"
 	"time"
 
 	kapps "k8s.io/api/apps/v1"
@@ -32,15 +36,16 @@ import (
 )
 
 func GetCurrentAPIAndGroupStatuses(
+	goCtx gocontext.Context,
 	dataStatuses map[string]*resource.DataStatus,
 	ctx *context.Context,
 ) (map[string]*resource.APIStatus, map[string]*resource.APIGroupStatus, error) {
-	deployments, err := apiDeploymentMap(ctx.App.Name)
+	deployments, err := apiDeploymentMap(goCtx, ctx.App.Name)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	apiStatuses, err := getCurrentAPIStatuses(dataStatuses, deployments, ctx)
+	apiStatuses, err := getCurrentAPIStatuses(goCtx, dataStatuses, deployments, ctx)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -54,12 +59,18 @@ func GetCurrentAPIAndGroupStatuses(
 }
 
 func getCurrentAPIStatuses(
+	goCtx gocontext.Context,
 	dataStatuses map[string]*resource.DataStatus,
 	deployments map[string]*kapps.Deployment, // api.Name -> deployment
 	ctx *context.Context,
 ) (map[string]*resource.APIStatus, error) {
 
-	podList, err := config.Kubernetes.ListPodsByLabels(map[string]string{
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	podList, err := k8sClient.ListPodsByLabels(map[string]string{
 		"workloadType": workloadTypeAPI,
 		"appName":      ctx.App.Name,
 		"userFacing":   "true",
@@ -72,7 +83,7 @@ func getCurrentAPIStatuses(
 
 	currentResourceWorkloadIDs := ctx.APIResourceWorkloadIDs()
 
-	savedStatuses, err := calculateAPISavedStatuses(podList, ctx.App.Name)
+	savedStatuses, err := calculateAPISavedStatuses(goCtx, podList, ctx.App.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -126,11 +137,140 @@ func getCurrentAPIStatuses(
 		}
 	}
 
-	setInsufficientComputeAPIStatusCodes(apiStatuses, ctx)
+	setInsufficientComputeAPIStatusCodes(goCtx, apiStatuses, ctx)
+
+	for resourceID := range currentAPIResourceIDs {
+		apiStatus := apiStatuses[resourceID]
+		if apiStatus.Code == resource.StatusUpdating || apiStatus.Code == resource.StatusPending {
+			apiStatus.DownloadProgress = getDownloadProgress(k8sClient, podList, resourceID)
+		}
+	}
 
 	return apiStatuses, nil
 }
 
+// Matches the progress lines logged by the downloader (see size_progress_str() in transfer.py)
+var downloadProgressRegex = regexp.MustCompile(`downloading (.+) \(([0-9.]+)/([0-9.]+) (B|KB|MB|GB)\)$`)
+
+var downloadProgressUnits = map[string]float64{
+	"B":  1,
+	"KB": 1 << 10,
+	"MB": 1 << 20,
+	"GB": 1 << 30,
+}
+
+const (
+	// the downloader logs its progress every 10 seconds, so fetching it more often doesn't help
+	_downloadProgressCacheTTL = 5 * time.Second
+	// the number of downloading replicas whose logs are fetched per API, since any one of them is representative
+	_maxDownloadProgressLogFetches = 3
+)
+
+type downloadProgressCacheEntry struct {
+	progress  *resource.DownloadProgress
+	fetchedAt time.Time
+}
+
+// the most recent download progress of each downloading replica (pod name -> progress), so that frequent status requests
+// (e.g. `cortex get --watch` from several clients) don't each fetch the downloaders' logs
+var _downloadProgressCache = struct {
+	m map[string]downloadProgressCacheEntry
+	sync.Mutex
+}{m: map[string]downloadProgressCacheEntry{}}
+
+// Returns the download progress of an initializing replica, or nil if no replica is downloading
+func getDownloadProgress(k8sClient *k8s.Client, podList []kcore.Pod, resourceID string) *resource.DownloadProgress {
+	numFetches := 0
+
+	for _, pod := range podList {
+		if pod.Labels["resourceID"] != resourceID || k8s.GetPodStatus(&pod) != k8s.PodStatusInitializing {
+			continue
+		}
+
+		isDownloading := false
+		for _, containerStatus := range pod.Status.InitContainerStatuses {
+			if containerStatus.Name == downloaderInitContainerName && containerStatus.State.Running != nil {
+				isDownloading = true
+			}
+		}
+		if !isDownloading {
+			continue
+		}
+
+		if progress, ok := cachedDownloadProgress(pod.Name, time.Now()); ok {
+			if progress != nil {
+				return progress
+			}
+			continue
+		}
+
+		if numFetches >= _maxDownloadProgressLogFetches {
+			continue
+		}
+		numFetches++
+
+		// Progress is best-effort, so errors fetching logs are not surfaced
+		lastLine, err := k8sClient.GetPodLastLogLine(pod.Name, downloaderInitContainerName)
+		if err != nil {
+			continue
+		}
+		progress := parseDownloadProgress(lastLine)
+		cacheDownloadProgress(pod.Name, progress, time.Now())
+		if progress != nil {
+			return progress
+		}
+	}
+
+	return nil
+}
+
+func cachedDownloadProgress(podName string, now time.Time) (*resource.DownloadProgress, bool) {
+	_downloadProgressCache.Lock()
+	defer _downloadProgressCache.Unlock()
+
+	entry, ok := _downloadProgressCache.m[podName]
+	if !ok || now.Sub(entry.fetchedAt) > _downloadProgressCacheTTL {
+		return nil, false
+	}
+	return entry.progress, true
+}
+
+// cacheDownloadProgress caches the pod's progress (which may be nil), and drops expired entries
+func cacheDownloadProgress(podName string, progress *resource.DownloadProgress, now time.Time) {
+	_downloadProgressCache.Lock()
+	defer _downloadProgressCache.Unlock()
+
+	for cachedPodName, entry := range _downloadProgressCache.m {
+		if now.Sub(entry.fetchedAt) > _downloadProgressCacheTTL {
+			delete(_downloadProgressCache.m, cachedPodName)
+		}
+	}
+	_downloadProgressCache.m[podName] = downloadProgressCacheEntry{progress: progress, fetchedAt: now}
+}
+
+func parseDownloadProgress(logLine string) *resource.DownloadProgress {
+	match := downloadProgressRegex.FindStringSubmatch(logLine)
+	if match == nil {
+		return nil
+	}
+
+	downloaded, err := strconv.ParseFloat(match[2], 64)
+	if err != nil {
+		return nil
+	}
+	total, err := strconv.ParseFloat(match[3], 64)
+	if err != nil {
+		return nil
+	}
+	unit := downloadProgressUnits[match[4]]
+
+	return &resource.DownloadProgress{
+		ItemName:        match[1],
+		DownloadedBytes: int64(downloaded * unit),
+		TotalBytes:      int64(total * unit),
+	}
+}
+
 func getReplicaCountsMap(
 	podList []kcore.Pod,
 	deployments map[string]*kapps.Deployment, // api.Name -> deployment
@@ -171,7 +311,7 @@ func getReplicaCountsMap(
 				replicaCounts.ReadyStaleCompute++
 			}
 		}
-		if podStatus == k8s.PodStatusFailed || podStatus == k8s.PodStatusKilled || podStatus == k8s.PodStatusKilledOOM {
+		if podStatus == k8s.PodStatusFailed || podStatus == k8s.PodStatusKilled || podStatus == k8s.PodStatusKilledOOM || podStatus == k8s.PodStatusConfigError {
 			if computeMatches {
 				replicaCounts.FailedUpdatedCompute++
 			} else {
@@ -196,8 +336,13 @@ func getReplicaCountsMap(
 	return replicaCountsMap, podStatusMap
 }
 
-func numUpdatedReadyReplicas(ctx *context.Context, api *context.API) (int32, error) {
-	podList, err := config.Kubernetes.ListPodsByLabels(map[string]string{
+func numUpdatedReadyReplicas(goCtx gocontext.Context, ctx *context.Context, api *context.API) (int32, error) {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return 0, err
+	}
+
+	podList, err := k8sClient.ListPodsByLabels(map[string]string{
 		"workloadType": workloadTypeAPI,
 		"appName":      ctx.App.Name,
 		"resourceID":   api.ID,
@@ -227,6 +372,12 @@ func apiStatusCode(apiStatus *resource.APIStatus) resource.StatusCode {
 	}
 
 	if apiStatus.FailedUpdatedCompute > 0 {
+		for _, podStatus := range apiStatus.PodStatuses {
+			if podStatus == k8s.PodStatusConfigError {
+				return resource.StatusConfigError
+			}
+		}
+
 		for _, podStatus := range apiStatus.PodStatuses {
 			if podStatus == k8s.PodStatusKilledOOM {
 				return resource.StatusKilledOOM
@@ -303,9 +454,19 @@ func getAPIGroupStatuses(
 	for apiName, apiStatuses := range statusMap {
 		groupedReplicaCounts := getGroupedReplicaCounts(apiStatuses, ctx)
 
+		var downloadProgress *resource.DownloadProgress
+		if ctxAPI := ctx.APIs[apiName]; ctxAPI != nil {
+			for _, apiStatus := range apiStatuses {
+				if apiStatus.ResourceID == ctxAPI.ID {
+					downloadProgress = apiStatus.DownloadProgress
+				}
+			}
+		}
+
 		apiGroupStatuses[apiName] = &resource.APIGroupStatus{
 			APIName:              apiName,
 			ActiveStatus:         getActiveAPIStatus(apiStatuses, ctx),
+			DownloadProgress:     downloadProgress,
 			Code:                 apiGroupStatusCode(apiStatuses, groupedReplicaCounts, ctx),
 			GroupedReplicaCounts: groupedReplicaCounts,
 		}
@@ -423,8 +584,13 @@ func getRequestedReplicasFromDeployment(api *context.API, k8sDeployment *kapps.D
 	return getRequestedReplicas(api, k8sRequested, hpa)
 }
 
-func setInsufficientComputeAPIStatusCodes(apiStatuses map[string]*resource.APIStatus, ctx *context.Context) error {
-	stalledPods, err := config.Kubernetes.StalledPods()
+func setInsufficientComputeAPIStatusCodes(goCtx gocontext.Context, apiStatuses map[string]*resource.APIStatus, ctx *context.Context) error {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return err
+	}
+
+	stalledPods, err := k8sClient.StalledPods()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,106 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
+)
+
+func TestParseDownloadProgress(t *testing.T) {
+	for _, tc := range []struct {
+		logLine  string
+		expected *resource.DownloadProgress
+	}{
+		{
+			logLine:  "2019-12-01 10:00:00.000000:cortex:pid-1:INFO:downloading model (512/2048 B)",
+			expected: &resource.DownloadProgress{ItemName: "model", DownloadedBytes: 512, TotalBytes: 2048},
+		},
+		{
+			logLine:  "downloading model (1.5/4.0 KB)",
+			expected: &resource.DownloadProgress{ItemName: "model", DownloadedBytes: 1536, TotalBytes: 4096},
+		},
+		{
+			logLine:  "downloading onnx model (10.0/20.5 MB)",
+			expected: &resource.DownloadProgress{ItemName: "onnx model", DownloadedBytes: 10 << 20, TotalBytes: 20.5 * (1 << 20)},
+		},
+		{
+			logLine:  "downloading model (0.0/2.0 GB)",
+			expected: &resource.DownloadProgress{ItemName: "model", DownloadedBytes: 0, TotalBytes: 2 << 30},
+		},
+		{
+			logLine:  "downloading model (2.0/2.0 GB)",
+			expected: &resource.DownloadProgress{ItemName: "model", DownloadedBytes: 2 << 30, TotalBytes: 2 << 30},
+		},
+		{logLine: ""},
+		{logLine: "downloading model"},
+		{logLine: "downloading model (1.0/2.0 TB)"},
+		{logLine: "downloading model (1.0/2.0 MB) and more"},
+		{logLine: "downloading model (1.0-2.0 MB)"},
+		{logLine: "downloading model (1.0/ MB)"},
+		{logLine: "downloading  (1.0/2.0 MB)"},
+		{logLine: "downloading model (1.2.3/4.0 MB)"},
+		{logLine: "downloading model (1.0/4.0.1 MB)"},
+		{logLine: "model is downloaded"},
+	} {
+		require.Equal(t, tc.expected, parseDownloadProgress(tc.logLine), tc.logLine)
+	}
+}
+
+func TestDownloadProgressCache(t *testing.T) {
+	_downloadProgressCache.Lock()
+	prevCache := _downloadProgressCache.m
+	_downloadProgressCache.m = map[string]downloadProgressCacheEntry{}
+	_downloadProgressCache.Unlock()
+	defer func() {
+		_downloadProgressCache.Lock()
+		_downloadProgressCache.m = prevCache
+		_downloadProgressCache.Unlock()
+	}()
+
+	start := time.Now()
+	progress := &resource.DownloadProgress{ItemName: "model", DownloadedBytes: 1, TotalBytes: 2}
+
+	_, ok := cachedDownloadProgress("pod-a", start)
+	require.False(t, ok)
+
+	cacheDownloadProgress("pod-a", progress, start)
+	cacheDownloadProgress("pod-b", nil, start)
+
+	cached, ok := cachedDownloadProgress("pod-a", start.Add(time.Second))
+	require.True(t, ok)
+	require.Equal(t, progress, cached)
+
+	// replicas whose logs didn't contain progress are cached too, so that their logs aren't fetched again right away
+	cached, ok = cachedDownloadProgress("pod-b", start.Add(time.Second))
+	require.True(t, ok)
+	require.Nil(t, cached)
+
+	_, ok = cachedDownloadProgress("pod-a", start.Add(_downloadProgressCacheTTL+time.Second))
+	require.False(t, ok)
+
+	// expired entries are dropped when new ones are added
+	cacheDownloadProgress("pod-c", nil, start.Add(_downloadProgressCacheTTL+time.Second))
+	_downloadProgressCache.Lock()
+	require.Len(t, _downloadProgressCache.m, 1)
+	require.Contains(t, _downloadProgressCache.m, "pod-c")
+	_downloadProgressCache.Unlock()
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package workloads
 
 import (
+	gocontext "context"
 	"encoding/base64"
 	"fmt"
 	"path"
@@ -32,6 +33,7 @@ import (
 	intstr "k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	"github.com/cortexlabs/cortex/pkg/operator/api/context"
@@ -77,15 +79,20 @@ func extractAPIWorkloads(ctx *context.Context) []Workload {
 	return workloads
 }
 
-func (aw *APIWorkload) Start(ctx *context.Context) error {
+func (aw *APIWorkload) Start(goCtx gocontext.Context, ctx *context.Context) error {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return err
+	}
+
 	api := ctx.APIs.OneByID(aw.GetSingleResourceID())
 
 	k8sDeloymentName := internalAPIName(api.Name, ctx.App.Name)
-	k8sDeloyment, err := config.Kubernetes.GetDeployment(k8sDeloymentName)
+	k8sDeloyment, err := k8sClient.GetDeployment(k8sDeloymentName)
 	if err != nil {
 		return err
 	}
-	hpa, err := config.Kubernetes.GetHPA(k8sDeloymentName)
+	hpa, err := k8sClient.GetHPA(k8sDeloymentName)
 	if err != nil {
 		return err
 	}
@@ -104,27 +111,42 @@ func (aw *APIWorkload) Start(ctx *context.Context) error {
 		return errors.New(api.Name, "unknown model format encountered") // unexpected
 	}
 
-	_, err = config.Kubernetes.ApplyService(serviceSpec(ctx, api))
+	_, err = k8sClient.ApplyService(serviceSpec(ctx, api))
+	if err != nil {
+		return err
+	}
+
+	_, err = k8sClient.ApplyVirtualService(virtualServiceSpec(ctx, api))
+	if err != nil {
+		return err
+	}
+
+	err = applyMTLS(goCtx, ctx, api)
+	if err != nil {
+		return err
+	}
+
+	err = applyJWTAuth(goCtx, ctx, api)
 	if err != nil {
 		return err
 	}
 
-	_, err = config.Kubernetes.ApplyVirtualService(virtualServiceSpec(ctx, api))
+	err = applyNetworkPolicy(goCtx, ctx, api)
 	if err != nil {
 		return err
 	}
 
 	if k8sDeloyment != nil && k8sDeloyment.Status.ReadyReplicas == 0 {
-		config.Kubernetes.DeleteDeployment(k8sDeloymentName)
+		k8sClient.DeleteDeployment(k8sDeloymentName)
 	}
 
-	_, err = config.Kubernetes.ApplyDeployment(deploymentSpec)
+	_, err = k8sClient.ApplyDeployment(deploymentSpec)
 	if err != nil {
 		return err
 	}
 
 	// Delete HPA while updating replicas to avoid unwanted autoscaling
-	_, err = config.Kubernetes.DeleteHPA(k8sDeloymentName)
+	_, err = k8sClient.DeleteHPA(k8sDeloymentName)
 	if err != nil {
 		return err
 	}
@@ -132,11 +154,16 @@ func (aw *APIWorkload) Start(ctx *context.Context) error {
 	return nil
 }
 
-func (aw *APIWorkload) IsSucceeded(ctx *context.Context) (bool, error) {
+func (aw *APIWorkload) IsSucceeded(goCtx gocontext.Context, ctx *context.Context) (bool, error) {
 	api := ctx.APIs.OneByID(aw.GetSingleResourceID())
 	k8sDeloymentName := internalAPIName(api.Name, ctx.App.Name)
 
-	k8sDeployment, err := config.Kubernetes.GetDeployment(k8sDeloymentName)
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return false, err
+	}
+
+	k8sDeployment, err := k8sClient.GetDeployment(k8sDeloymentName)
 	if err != nil {
 		return false, err
 	}
@@ -148,7 +175,7 @@ func (aw *APIWorkload) IsSucceeded(ctx *context.Context) (bool, error) {
 		return false, nil
 	}
 
-	updatedReplicas, err := numUpdatedReadyReplicas(ctx, api)
+	updatedReplicas, err := numUpdatedReadyReplicas(goCtx, ctx, api)
 	if err != nil {
 		return false, err
 	}
@@ -159,11 +186,16 @@ func (aw *APIWorkload) IsSucceeded(ctx *context.Context) (bool, error) {
 	return true, nil
 }
 
-func (aw *APIWorkload) IsRunning(ctx *context.Context) (bool, error) {
+func (aw *APIWorkload) IsRunning(goCtx gocontext.Context, ctx *context.Context) (bool, error) {
 	api := ctx.APIs.OneByID(aw.GetSingleResourceID())
 	k8sDeloymentName := internalAPIName(api.Name, ctx.App.Name)
 
-	k8sDeployment, err := config.Kubernetes.GetDeployment(k8sDeloymentName)
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return false, err
+	}
+
+	k8sDeployment, err := k8sClient.GetDeployment(k8sDeloymentName)
 	if err != nil {
 		return false, err
 	}
@@ -175,7 +207,7 @@ func (aw *APIWorkload) IsRunning(ctx *context.Context) (bool, error) {
 		return false, nil
 	}
 
-	updatedReplicas, err := numUpdatedReadyReplicas(ctx, api)
+	updatedReplicas, err := numUpdatedReadyReplicas(goCtx, ctx, api)
 	if err != nil {
 		return false, err
 	}
@@ -186,11 +218,16 @@ func (aw *APIWorkload) IsRunning(ctx *context.Context) (bool, error) {
 	return false, nil
 }
 
-func (aw *APIWorkload) IsStarted(ctx *context.Context) (bool, error) {
+func (aw *APIWorkload) IsStarted(goCtx gocontext.Context, ctx *context.Context) (bool, error) {
 	api := ctx.APIs.OneByID(aw.GetSingleResourceID())
 	k8sDeloymentName := internalAPIName(api.Name, ctx.App.Name)
 
-	k8sDeployment, err := config.Kubernetes.GetDeployment(k8sDeloymentName)
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return false, err
+	}
+
+	k8sDeployment, err := k8sClient.GetDeployment(k8sDeloymentName)
 	if err != nil {
 		return false, err
 	}
@@ -205,14 +242,19 @@ func (aw *APIWorkload) IsStarted(ctx *context.Context) (bool, error) {
 	return true, nil
 }
 
-func (aw *APIWorkload) CanRun(ctx *context.Context) (bool, error) {
-	return areAllDataDependenciesSucceeded(ctx, aw.GetResourceIDs())
+func (aw *APIWorkload) CanRun(goCtx gocontext.Context, ctx *context.Context) (bool, error) {
+	return areAllDataDependenciesSucceeded(goCtx, ctx, aw.GetResourceIDs())
 }
 
-func (aw *APIWorkload) IsFailed(ctx *context.Context) (bool, error) {
+func (aw *APIWorkload) IsFailed(goCtx gocontext.Context, ctx *context.Context) (bool, error) {
 	api := ctx.APIs.OneByID(aw.GetSingleResourceID())
 
-	pods, err := config.Kubernetes.ListPodsByLabels(map[string]string{
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return false, err
+	}
+
+	pods, err := k8sClient.ListPodsByLabels(map[string]string{
 		"appName":      ctx.App.Name,
 		"workloadType": workloadTypeAPI,
 		"apiName":      api.Name,
@@ -226,7 +268,7 @@ func (aw *APIWorkload) IsFailed(ctx *context.Context) (bool, error) {
 
 	for _, pod := range pods {
 		podStatus := k8s.GetPodStatus(&pod)
-		if podStatus == k8s.PodStatusFailed || podStatus == k8s.PodStatusKilled || podStatus == k8s.PodStatusKilledOOM {
+		if podStatus == k8s.PodStatusFailed || podStatus == k8s.PodStatusKilled || podStatus == k8s.PodStatusKilledOOM || podStatus == k8s.PodStatusConfigError {
 			return true, nil
 		}
 	}
@@ -235,8 +277,13 @@ func (aw *APIWorkload) IsFailed(ctx *context.Context) (bool, error) {
 }
 
 type downloadContainerConfig struct {
-	DownloadArgs []downloadContainerArg `json:"download_args"`
-	LastLog      string                 `json:"last_log"` // string to log at the conclusion of the downloader (if "" nothing will be logged)
+	DownloadArgs        []downloadContainerArg `json:"download_args"`
+	LastLog             string                 `json:"last_log"`              // string to log at the conclusion of the downloader (if "" nothing will be logged)
+	Parallelism         int                    `json:"parallelism"`           // number of concurrent ranged GET requests
+	PartSizeBytes       int64                  `json:"part_size_bytes"`       // size of each ranged GET request
+	MaxRetries          int                    `json:"max_retries"`           // number of times to retry each part (with exponential backoff)
+	TimeoutSeconds      int                    `json:"timeout_seconds"`       // overall deadline for all downloads (0 means no deadline)
+	StallTimeoutSeconds int                    `json:"stall_timeout_seconds"` // a part is retried if no bytes are received for this long
 }
 
 type downloadContainerArg struct {
@@ -247,10 +294,41 @@ type downloadContainerArg struct {
 	TFModelVersionRename string `json:"tf_model_version_rename"` // e.g. passing in /mnt/model/1 will rename /mnt/model/* to /mnt/model/1 only if there is one item in /mnt/model/
 	HideFromLog          bool   `json:"hide_from_log"`           // if true, don't log where the file is being downloaded from
 	HideUnzippingLog     bool   `json:"hide_unzipping_log"`      // if true, don't log when unzipping
+	Manifest             string `json:"manifest"`                // S3 path to a JSON file mapping relative file paths to sha256 checksums (if "" only S3 ETags are verified)
 }
 
 const downloaderLastLog = "pulling the %s serving image"
 
+func projectDownloadContainerArg(ctx *context.Context) downloadContainerArg {
+	return downloadContainerArg{
+		From:             config.AWS.S3Path(ctx.ProjectKey),
+		To:               path.Join(consts.EmptyDirMountPath, "project"),
+		Unzip:            true,
+		ItemName:         "the project code",
+		HideFromLog:      true,
+		HideUnzippingLog: true,
+	}
+}
+
+func modelManifest(api *context.API) string {
+	if api.Predictor.ModelManifest == nil {
+		return ""
+	}
+	return *api.Predictor.ModelManifest
+}
+
+func newDownloadContainerConfig(lastLog string, downloadArgs ...downloadContainerArg) downloadContainerConfig {
+	return downloadContainerConfig{
+		DownloadArgs:        downloadArgs,
+		LastLog:             lastLog,
+		Parallelism:         8,
+		PartSizeBytes:       64 * 1024 * 1024,
+		MaxRetries:          5,
+		TimeoutSeconds:      60 * 60,
+		StallTimeoutSeconds: 2 * 60,
+	}
+}
+
 func tfAPISpec(
 	ctx *context.Context,
 	api *context.API,
@@ -271,35 +349,25 @@ func tfAPISpec(
 		tfServingResourceList[kcore.ResourceMemory] = *q2
 	}
 
-	servingImage := config.Cluster.ImageTFServe
+	servingImage := apiServingImage(api)
 	if api.Compute.GPU > 0 {
-		servingImage = config.Cluster.ImageTFServeGPU
 		tfServingResourceList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
 		tfServingLimitsList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
 	}
 
 	tensorflowModel := *ctx.APIs[api.Name].Predictor.Model
 
-	downloadConfig := downloadContainerConfig{
-		LastLog: fmt.Sprintf(downloaderLastLog, "tensorflow"),
-		DownloadArgs: []downloadContainerArg{
-			{
-				From:             config.AWS.S3Path(ctx.ProjectKey),
-				To:               path.Join(consts.EmptyDirMountPath, "project"),
-				Unzip:            true,
-				ItemName:         "the project code",
-				HideFromLog:      true,
-				HideUnzippingLog: true,
-			},
-			{
-				From:                 tensorflowModel,
-				To:                   path.Join(consts.EmptyDirMountPath, "model"),
-				Unzip:                strings.HasSuffix(tensorflowModel, ".zip"),
-				ItemName:             "the model",
-				TFModelVersionRename: path.Join(consts.EmptyDirMountPath, "model", "1"),
-			},
+	downloadConfig := newDownloadContainerConfig(fmt.Sprintf(downloaderLastLog, "tensorflow"),
+		projectDownloadContainerArg(ctx),
+		downloadContainerArg{
+			From:                 tensorflowModel,
+			To:                   path.Join(consts.EmptyDirMountPath, "model"),
+			Unzip:                strings.HasSuffix(tensorflowModel, ".zip"),
+			ItemName:             "the model",
+			TFModelVersionRename: path.Join(consts.EmptyDirMountPath, "model", "1"),
+			Manifest:             modelManifest(api),
 		},
-	}
+	)
 
 	envVars := []kcore.EnvVar{}
 
@@ -319,7 +387,16 @@ func tfAPISpec(
 				},
 			},
 		},
+		kcore.EnvVar{
+			Name:  "CORTEX_EGRESS_ALLOWLIST",
+			Value: egressAllowListStr(ctx, api),
+		},
+		kcore.EnvVar{
+			Name:  "CORTEX_LOG_FORMAT",
+			Value: "json",
+		},
 	)
+	envVars = append(envVars, apiSecurityEnvVars(api)...)
 
 	if api.Predictor.PythonPath != nil {
 		envVars = append(envVars, kcore.EnvVar{
@@ -355,9 +432,7 @@ func tfAPISpec(
 				"userFacing":   "true",
 				"logGroupName": ctx.LogGroupName(api.Name),
 			},
-			Annotations: map[string]string{
-				"traffic.sidecar.istio.io/excludeOutboundIPRanges": "0.0.0.0/0",
-			},
+			Annotations: apiPodAnnotations(api, tfServingPortStr),
 			K8sPodSpec: kcore.PodSpec{
 				RestartPolicy: "Always",
 				InitContainers: []kcore.Container{
@@ -368,8 +443,9 @@ func tfAPISpec(
 						Args: []string{
 							"--download=" + downloadArgsStr,
 						},
-						EnvFrom:      baseEnvVars(),
-						VolumeMounts: defaultVolumeMounts(),
+						EnvFrom:         baseEnvVars(),
+						VolumeMounts:    apiVolumeMounts(),
+						SecurityContext: apiContainerSecurityContext(api),
 					},
 				},
 				Containers: []kcore.Container{
@@ -387,9 +463,10 @@ func tfAPISpec(
 							"--cache-dir=" + consts.ContextCacheDir,
 							"--project-dir=" + path.Join(consts.EmptyDirMountPath, "project"),
 						},
-						Env:          envVars,
-						EnvFrom:      baseEnvVars(),
-						VolumeMounts: defaultVolumeMounts(),
+						Env:             append(envVars, pythonPackageIndexEnvVars(api)...),
+						EnvFrom:         baseEnvVars(),
+						VolumeMounts:    apiVolumeMounts(),
+						SecurityContext: apiContainerSecurityContext(api),
 						ReadinessProbe: &kcore.Probe{
 							InitialDelaySeconds: 5,
 							TimeoutSeconds:      5,
@@ -398,7 +475,7 @@ func tfAPISpec(
 							FailureThreshold:    2,
 							Handler: kcore.Handler{
 								Exec: &kcore.ExecAction{
-									Command: []string{"/bin/bash", "-c", "/bin/ps aux | grep \"api.py\" && test -f /health_check.txt"},
+									Command: []string{"/bin/bash", "-c", "/bin/ps aux | grep \"api.py\" && test -f /tmp/health_check.txt"},
 								},
 							},
 						},
@@ -415,13 +492,14 @@ func tfAPISpec(
 						Name:            tfServingContainerName,
 						Image:           servingImage,
 						ImagePullPolicy: kcore.PullAlways,
-						Args: []string{
+						Args: append([]string{
 							"--port=" + tfServingPortStr,
 							"--model_base_path=" + path.Join(consts.EmptyDirMountPath, "model"),
-						},
-						Env:          envVars,
-						EnvFrom:      baseEnvVars(),
-						VolumeMounts: defaultVolumeMounts(),
+						}, tfServingRuntimeArgs(api)...),
+						Env:             envVars,
+						EnvFrom:         baseEnvVars(),
+						VolumeMounts:    apiVolumeMounts(),
+						SecurityContext: apiContainerSecurityContext(api),
 						ReadinessProbe: &kcore.Probe{
 							InitialDelaySeconds: 5,
 							TimeoutSeconds:      5,
@@ -451,7 +529,8 @@ func tfAPISpec(
 					"workload": "true",
 				},
 				Tolerations:        tolerations,
-				Volumes:            defaultVolumes(),
+				Volumes:            apiVolumes(),
+				SecurityContext:    apiPodSecurityContext(api),
 				ServiceAccountName: "default",
 			},
 		},
@@ -465,7 +544,7 @@ func pythonAPISpec(
 	workloadID string,
 	desiredReplicas int32,
 ) *kapps.Deployment {
-	servingImage := config.Cluster.ImagePythonServe
+	servingImage := apiServingImage(api)
 	resourceList := kcore.ResourceList{}
 	resourceLimitsList := kcore.ResourceList{}
 	resourceList[kcore.ResourceCPU] = api.Compute.CPU.Quantity
@@ -475,24 +554,13 @@ func pythonAPISpec(
 	}
 
 	if api.Compute.GPU > 0 {
-		servingImage = config.Cluster.ImagePythonServeGPU
 		resourceList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
 		resourceLimitsList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
 	}
 
-	downloadConfig := downloadContainerConfig{
-		LastLog: fmt.Sprintf(downloaderLastLog, "python"),
-		DownloadArgs: []downloadContainerArg{
-			{
-				From:             config.AWS.S3Path(ctx.ProjectKey),
-				To:               path.Join(consts.EmptyDirMountPath, "project"),
-				Unzip:            true,
-				ItemName:         "the project code",
-				HideFromLog:      true,
-				HideUnzippingLog: true,
-			},
-		},
-	}
+	downloadConfig := newDownloadContainerConfig(fmt.Sprintf(downloaderLastLog, "python"),
+		projectDownloadContainerArg(ctx),
+	)
 
 	downloadArgsBytes, _ := json.Marshal(downloadConfig)
 	downloadArgsStr := base64.URLEncoding.EncodeToString(downloadArgsBytes)
@@ -515,7 +583,17 @@ func pythonAPISpec(
 				},
 			},
 		},
+		kcore.EnvVar{
+			Name:  "CORTEX_EGRESS_ALLOWLIST",
+			Value: egressAllowListStr(ctx, api),
+		},
+		kcore.EnvVar{
+			Name:  "CORTEX_LOG_FORMAT",
+			Value: "json",
+		},
 	)
+	envVars = append(envVars, apiSecurityEnvVars(api)...)
+	envVars = append(envVars, pythonPackageIndexEnvVars(api)...)
 
 	if api.Predictor.PythonPath != nil {
 		envVars = append(envVars, kcore.EnvVar{
@@ -549,9 +627,7 @@ func pythonAPISpec(
 				"userFacing":   "true",
 				"logGroupName": ctx.LogGroupName(api.Name),
 			},
-			Annotations: map[string]string{
-				"traffic.sidecar.istio.io/excludeOutboundIPRanges": "0.0.0.0/0",
-			},
+			Annotations: apiPodAnnotations(api),
 			K8sPodSpec: kcore.PodSpec{
 				RestartPolicy: "Always",
 				InitContainers: []kcore.Container{
@@ -562,8 +638,9 @@ func pythonAPISpec(
 						Args: []string{
 							"--download=" + downloadArgsStr,
 						},
-						EnvFrom:      baseEnvVars(),
-						VolumeMounts: defaultVolumeMounts(),
+						EnvFrom:         baseEnvVars(),
+						VolumeMounts:    apiVolumeMounts(),
+						SecurityContext: apiContainerSecurityContext(api),
 					},
 				},
 				Containers: []kcore.Container{
@@ -579,9 +656,10 @@ func pythonAPISpec(
 							"--cache-dir=" + consts.ContextCacheDir,
 							"--project-dir=" + path.Join(consts.EmptyDirMountPath, "project"),
 						},
-						Env:          envVars,
-						EnvFrom:      baseEnvVars(),
-						VolumeMounts: defaultVolumeMounts(),
+						Env:             envVars,
+						EnvFrom:         baseEnvVars(),
+						VolumeMounts:    apiVolumeMounts(),
+						SecurityContext: apiContainerSecurityContext(api),
 						ReadinessProbe: &kcore.Probe{
 							InitialDelaySeconds: 5,
 							TimeoutSeconds:      5,
@@ -590,7 +668,7 @@ func pythonAPISpec(
 							FailureThreshold:    2,
 							Handler: kcore.Handler{
 								Exec: &kcore.ExecAction{
-									Command: []string{"/bin/bash", "-c", "/bin/ps aux | grep \"api.py\" && test -f /health_check.txt"},
+									Command: []string{"/bin/bash", "-c", "/bin/ps aux | grep \"api.py\" && test -f /tmp/health_check.txt"},
 								},
 							},
 						},
@@ -609,7 +687,8 @@ func pythonAPISpec(
 					"workload": "true",
 				},
 				Tolerations:        tolerations,
-				Volumes:            defaultVolumes(),
+				Volumes:            apiVolumes(),
+				SecurityContext:    apiPodSecurityContext(api),
 				ServiceAccountName: "default",
 			},
 		},
@@ -623,7 +702,7 @@ func onnxAPISpec(
 	workloadID string,
 	desiredReplicas int32,
 ) *kapps.Deployment {
-	servingImage := config.Cluster.ImageONNXServe
+	servingImage := apiServingImage(api)
 	resourceList := kcore.ResourceList{}
 	resourceLimitsList := kcore.ResourceList{}
 	resourceList[kcore.ResourceCPU] = api.Compute.CPU.Quantity
@@ -633,29 +712,19 @@ func onnxAPISpec(
 	}
 
 	if api.Compute.GPU > 0 {
-		servingImage = config.Cluster.ImageONNXServeGPU
 		resourceList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
 		resourceLimitsList["nvidia.com/gpu"] = *kresource.NewQuantity(api.Compute.GPU, kresource.DecimalSI)
 	}
 
-	downloadConfig := downloadContainerConfig{
-		LastLog: fmt.Sprintf(downloaderLastLog, "onnx"),
-		DownloadArgs: []downloadContainerArg{
-			{
-				From:             config.AWS.S3Path(ctx.ProjectKey),
-				To:               path.Join(consts.EmptyDirMountPath, "project"),
-				Unzip:            true,
-				ItemName:         "the project code",
-				HideFromLog:      true,
-				HideUnzippingLog: true,
-			},
-			{
-				From:     *ctx.APIs[api.Name].Predictor.Model,
-				To:       path.Join(consts.EmptyDirMountPath, "model"),
-				ItemName: "the model",
-			},
+	downloadConfig := newDownloadContainerConfig(fmt.Sprintf(downloaderLastLog, "onnx"),
+		projectDownloadContainerArg(ctx),
+		downloadContainerArg{
+			From:     *ctx.APIs[api.Name].Predictor.Model,
+			To:       path.Join(consts.EmptyDirMountPath, "model"),
+			ItemName: "the model",
+			Manifest: modelManifest(api),
 		},
-	}
+	)
 
 	envVars := []kcore.EnvVar{}
 
@@ -675,7 +744,18 @@ func onnxAPISpec(
 				},
 			},
 		},
+		kcore.EnvVar{
+			Name:  "CORTEX_EGRESS_ALLOWLIST",
+			Value: egressAllowListStr(ctx, api),
+		},
+		kcore.EnvVar{
+			Name:  "CORTEX_LOG_FORMAT",
+			Value: "json",
+		},
 	)
+	envVars = append(envVars, apiSecurityEnvVars(api)...)
+	envVars = append(envVars, pythonPackageIndexEnvVars(api)...)
+	envVars = append(envVars, onnxRuntimeEnvVars(api)...)
 
 	if api.Predictor.PythonPath != nil {
 		envVars = append(envVars, kcore.EnvVar{
@@ -711,9 +791,7 @@ func onnxAPISpec(
 				"userFacing":   "true",
 				"logGroupName": ctx.LogGroupName(api.Name),
 			},
-			Annotations: map[string]string{
-				"traffic.sidecar.istio.io/excludeOutboundIPRanges": "0.0.0.0/0",
-			},
+			Annotations: apiPodAnnotations(api),
 			K8sPodSpec: kcore.PodSpec{
 				InitContainers: []kcore.Container{
 					{
@@ -723,8 +801,9 @@ func onnxAPISpec(
 						Args: []string{
 							"--download=" + downloadArgsStr,
 						},
-						EnvFrom:      baseEnvVars(),
-						VolumeMounts: defaultVolumeMounts(),
+						EnvFrom:         baseEnvVars(),
+						VolumeMounts:    apiVolumeMounts(),
+						SecurityContext: apiContainerSecurityContext(api),
 					},
 				},
 				Containers: []kcore.Container{
@@ -741,9 +820,10 @@ func onnxAPISpec(
 							"--cache-dir=" + consts.ContextCacheDir,
 							"--project-dir=" + path.Join(consts.EmptyDirMountPath, "project"),
 						},
-						Env:          envVars,
-						EnvFrom:      baseEnvVars(),
-						VolumeMounts: defaultVolumeMounts(),
+						Env:             envVars,
+						EnvFrom:         baseEnvVars(),
+						VolumeMounts:    apiVolumeMounts(),
+						SecurityContext: apiContainerSecurityContext(api),
 						ReadinessProbe: &kcore.Probe{
 							InitialDelaySeconds: 5,
 							TimeoutSeconds:      5,
@@ -752,7 +832,7 @@ func onnxAPISpec(
 							FailureThreshold:    2,
 							Handler: kcore.Handler{
 								Exec: &kcore.ExecAction{
-									Command: []string{"/bin/bash", "-c", "/bin/ps aux | grep \"api.py\" && test -f /health_check.txt"},
+									Command: []string{"/bin/bash", "-c", "/bin/ps aux | grep \"api.py\" && test -f /tmp/health_check.txt"},
 								},
 							},
 						},
@@ -771,7 +851,8 @@ func onnxAPISpec(
 					"workload": "true",
 				},
 				Tolerations:        tolerations,
-				Volumes:            defaultVolumes(),
+				Volumes:            apiVolumes(),
+				SecurityContext:    apiPodSecurityContext(api),
 				ServiceAccountName: "default",
 			},
 		},
@@ -796,6 +877,140 @@ func virtualServiceSpec(ctx *context.Context, api *context.API) *kunstructured.U
 	})
 }
 
+func destinationRuleSpec(ctx *context.Context, api *context.API) *kunstructured.Unstructured {
+	return k8s.DestinationRule(&k8s.DestinationRuleSpec{
+		Name:      internalAPIName(api.Name, ctx.App.Name),
+		Namespace: consts.K8sNamespace,
+		Host:      internalAPIName(api.Name, ctx.App.Name) + "." + consts.K8sNamespace + ".svc.cluster.local",
+		TLSMode:   "ISTIO_MUTUAL",
+		Labels: map[string]string{
+			"appName":      ctx.App.Name,
+			"workloadType": workloadTypeAPI,
+			"apiName":      api.Name,
+		},
+	})
+}
+
+func authenticationPolicySpec(ctx *context.Context, api *context.API) *kunstructured.Unstructured {
+	mtlsMode := "STRICT"
+	if config.Cluster.MTLS == clusterconfig.MTLSPermissive {
+		mtlsMode = "PERMISSIVE"
+	}
+
+	var jwt *k8s.JWTSpec
+	if api.JWTAuth != nil {
+		jwt = &k8s.JWTSpec{
+			Issuer:    api.JWTAuth.Issuer,
+			JWKSURI:   api.JWTAuth.JWKSURL,
+			Audiences: api.JWTAuth.Audiences,
+		}
+	}
+
+	return k8s.AuthenticationPolicy(&k8s.AuthenticationPolicySpec{
+		Name:        internalAPIName(api.Name, ctx.App.Name),
+		Namespace:   consts.K8sNamespace,
+		ServiceName: internalAPIName(api.Name, ctx.App.Name),
+		MTLSMode:    mtlsMode,
+		JWT:         jwt,
+		Labels: map[string]string{
+			"appName":      ctx.App.Name,
+			"workloadType": workloadTypeAPI,
+			"apiName":      api.Name,
+		},
+	})
+}
+
+func authorizationPolicySpec(ctx *context.Context, api *context.API) *kunstructured.Unstructured {
+	return k8s.AuthorizationPolicy(&k8s.AuthorizationPolicySpec{
+		Name:      internalAPIName(api.Name, ctx.App.Name),
+		Namespace: consts.K8sNamespace,
+		Selector: map[string]string{
+			"appName":      ctx.App.Name,
+			"workloadType": workloadTypeAPI,
+			"apiName":      api.Name,
+		},
+		RequestPrincipals: []string{api.JWTAuth.Issuer + "/*"},
+		RequiredClaims:    api.JWTAuth.RequiredClaims,
+		Labels: map[string]string{
+			"appName":      ctx.App.Name,
+			"workloadType": workloadTypeAPI,
+			"apiName":      api.Name,
+		},
+	})
+}
+
+// Creates the peer authentication policy and destination rule for the API's service if mutual TLS is enabled, otherwise removes them
+func applyMTLS(goCtx gocontext.Context, ctx *context.Context, api *context.API) error {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return err
+	}
+
+	name := internalAPIName(api.Name, ctx.App.Name)
+
+	if config.Cluster.MTLS == clusterconfig.MTLSDisabled {
+		if _, err := k8sClient.DeleteAuthenticationPolicy(name, consts.K8sNamespace); err != nil {
+			return err
+		}
+		if _, err := k8sClient.DeleteDestinationRule(name, consts.K8sNamespace); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if _, err := k8sClient.ApplyAuthenticationPolicy(authenticationPolicySpec(ctx, api)); err != nil {
+		return err
+	}
+	if _, err := k8sClient.ApplyDestinationRule(destinationRuleSpec(ctx, api)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Creates the authorization policy which requires a JWT (and the API's required claims) if the API is configured with jwt_auth,
+// otherwise removes it (the token itself is verified via the authentication policy's origins)
+func applyJWTAuth(goCtx gocontext.Context, ctx *context.Context, api *context.API) error {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return err
+	}
+
+	if api.JWTAuth == nil {
+		_, err := k8sClient.DeleteAuthorizationPolicy(internalAPIName(api.Name, ctx.App.Name), consts.K8sNamespace)
+		return err
+	}
+
+	_, err = k8sClient.ApplyAuthorizationPolicy(authorizationPolicySpec(ctx, api))
+	return err
+}
+
+// excludedInboundPorts are ports which are only used within the pod (e.g. by readiness probes or between containers),
+// and should not be intercepted by the istio sidecar
+func apiPodAnnotations(api *context.API, excludedInboundPorts ...string) map[string]string {
+	annotations := map[string]string{}
+
+	if isAPIPodSecurityEnforced(api, userconfig.UnconfinedSeccompOptOut) {
+		annotations["seccomp.security.alpha.kubernetes.io/pod"] = "runtime/default"
+	}
+
+	if config.Cluster.MTLS != clusterconfig.MTLSDisabled {
+		annotations["sidecar.istio.io/inject"] = "true"
+		// traffic to the aws services bypasses the sidecar, which allows the downloader init container to fetch the model from s3
+		// before the sidecar has started (traffic within the cluster still goes through the sidecar)
+		if awsCIDRs, err := awsServiceCIDRs(); err == nil {
+			annotations["traffic.sidecar.istio.io/excludeOutboundIPRanges"] = strings.Join(awsCIDRs, ",")
+		}
+		if len(excludedInboundPorts) > 0 {
+			annotations["traffic.sidecar.istio.io/excludeInboundPorts"] = strings.Join(excludedInboundPorts, ",")
+		}
+		if api.JWTAuth != nil {
+			annotations["sidecar.istio.io/statsInclusionRegexps"] = strings.Join(_envoyStatsInclusionRegexps, ",")
+		}
+	}
+
+	return annotations
+}
+
 func serviceSpec(ctx *context.Context, api *context.API) *kcore.Service {
 	return k8s.Service(&k8s.ServiceSpec{
 		Name:       internalAPIName(api.Name, ctx.App.Name),
@@ -835,51 +1050,13 @@ func doesAPIComputeNeedsUpdating(api *context.API, k8sDeployment *kapps.Deployme
 	return false
 }
 
-func deleteOldAPIs(ctx *context.Context) {
-	virtualServices, _ := config.Kubernetes.ListVirtualServicesByLabels(consts.K8sNamespace, map[string]string{
-		"appName":      ctx.App.Name,
-		"workloadType": workloadTypeAPI,
-	})
-	for _, virtualService := range virtualServices {
-		if _, ok := ctx.APIs[virtualService.GetLabels()["apiName"]]; !ok {
-			config.Kubernetes.DeleteVirtualService(virtualService.GetName(), consts.K8sNamespace)
-		}
-	}
-
-	services, _ := config.Kubernetes.ListServicesByLabels(map[string]string{
-		"appName":      ctx.App.Name,
-		"workloadType": workloadTypeAPI,
-	})
-	for _, service := range services {
-		if _, ok := ctx.APIs[service.Labels["apiName"]]; !ok {
-			config.Kubernetes.DeleteService(service.Name)
-		}
-	}
-
-	deployments, _ := config.Kubernetes.ListDeploymentsByLabels(map[string]string{
-		"appName":      ctx.App.Name,
-		"workloadType": workloadTypeAPI,
-	})
-	for _, deployment := range deployments {
-		if _, ok := ctx.APIs[deployment.Labels["apiName"]]; !ok {
-			config.Kubernetes.DeleteDeployment(deployment.Name)
-		}
-	}
-
-	hpas, _ := config.Kubernetes.ListHPAsByLabels(map[string]string{
-		"appName":      ctx.App.Name,
-		"workloadType": workloadTypeAPI,
-	})
-	for _, hpa := range hpas {
-		if _, ok := ctx.APIs[hpa.Labels["apiName"]]; !ok {
-			config.Kubernetes.DeleteHPA(hpa.Name)
-		}
+func apiDeploymentMap(goCtx gocontext.Context, appName string) (map[string]*kapps.Deployment, error) {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return nil, err
 	}
-}
 
-// This returns map apiName -> deployment (not internalName -> deployment)
-func apiDeploymentMap(appName string) (map[string]*kapps.Deployment, error) {
-	deploymentList, err := config.Kubernetes.ListDeploymentsByLabels(map[string]string{
+	deploymentList, err := k8sClient.ListDeploymentsByLabels(map[string]string{
 		"appName":      appName,
 		"workloadType": workloadTypeAPI,
 	})
@@ -904,8 +1081,13 @@ func internalAPIName(apiName string, appName string) string {
 	return appName + "----" + apiName
 }
 
-func APIsBaseURL() (string, error) {
-	service, err := config.IstioKubernetes.GetService("apis-ingressgateway")
+func APIsBaseURL(goCtx gocontext.Context) (string, error) {
+	istioK8sClient, err := config.IstioKubernetes.WithContext(goCtx)
+	if err != nil {
+		return "", err
+	}
+
+	service, err := istioK8sClient.GetService("apis-ingressgateway")
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,149 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+func setTestClusterMTLS(mtls string) func() {
+	prevCluster := config.Cluster
+	config.Cluster = &clusterconfig.InternalConfig{
+		Config: clusterconfig.Config{
+			MTLS: mtls,
+		},
+	}
+
+	_awsServiceCIDRs.Lock()
+	prevCIDRs, prevLastUpdated := _awsServiceCIDRs.cidrs, _awsServiceCIDRs.lastUpdated
+	_awsServiceCIDRs.cidrs = []string{"52.216.0.0/15", "54.231.0.0/17"}
+	_awsServiceCIDRs.lastUpdated = time.Now()
+	_awsServiceCIDRs.Unlock()
+
+	return func() {
+		config.Cluster = prevCluster
+		_awsServiceCIDRs.Lock()
+		_awsServiceCIDRs.cidrs, _awsServiceCIDRs.lastUpdated = prevCIDRs, prevLastUpdated
+		_awsServiceCIDRs.Unlock()
+	}
+}
+
+func testMTLSContext(jwtAuth *userconfig.JWTAuth) (*context.Context, *context.API) {
+	api := &context.API{
+		API: &userconfig.API{
+			ResourceFields: userconfig.ResourceFields{Name: "iris"},
+			JWTAuth:        jwtAuth,
+		},
+	}
+	ctx := &context.Context{
+		App:  &context.App{App: &userconfig.App{Name: "app"}},
+		APIs: context.APIs{"iris": api},
+	}
+	return ctx, api
+}
+
+func TestAuthenticationPolicySpec(t *testing.T) {
+	for _, mtls := range []string{clusterconfig.MTLSStrict, clusterconfig.MTLSPermissive} {
+		restore := setTestClusterMTLS(mtls)
+		ctx, api := testMTLSContext(nil)
+
+		policy := authenticationPolicySpec(ctx, api)
+		require.Equal(t, "app----iris", policy.GetName())
+		require.Equal(t, "cortex", policy.GetNamespace())
+
+		spec := policy.Object["spec"].(map[string]interface{})
+		require.Equal(t, []map[string]interface{}{{"name": "app----iris"}}, spec["targets"])
+		expectedMode := map[string]string{clusterconfig.MTLSStrict: "STRICT", clusterconfig.MTLSPermissive: "PERMISSIVE"}[mtls]
+		require.Equal(t, []map[string]interface{}{{"mtls": map[string]interface{}{"mode": expectedMode}}}, spec["peers"], mtls)
+		require.NotContains(t, spec, "origins")
+		require.NotContains(t, spec, "principalBinding")
+
+		restore()
+	}
+}
+
+func TestAuthenticationPolicySpecJWT(t *testing.T) {
+	defer setTestClusterMTLS(clusterconfig.MTLSStrict)()
+
+	ctx, api := testMTLSContext(&userconfig.JWTAuth{
+		Issuer:    "https://auth.example.com/",
+		JWKSURL:   "https://auth.example.com/.well-known/jwks.json",
+		Audiences: []string{"iris"},
+	})
+
+	spec := authenticationPolicySpec(ctx, api).Object["spec"].(map[string]interface{})
+	require.Equal(t, []map[string]interface{}{{"jwt": map[string]interface{}{
+		"issuer":    "https://auth.example.com/",
+		"jwksUri":   "https://auth.example.com/.well-known/jwks.json",
+		"audiences": []string{"iris"},
+	}}}, spec["origins"])
+	require.Equal(t, "USE_ORIGIN", spec["principalBinding"])
+}
+
+func TestDestinationRuleSpec(t *testing.T) {
+	defer setTestClusterMTLS(clusterconfig.MTLSStrict)()
+
+	ctx, api := testMTLSContext(nil)
+
+	destinationRule := destinationRuleSpec(ctx, api)
+	require.Equal(t, "app----iris", destinationRule.GetName())
+	require.Equal(t, "cortex", destinationRule.GetNamespace())
+
+	host, _, _ := kunstructured.NestedString(destinationRule.Object, "spec", "host")
+	require.Equal(t, "app----iris.cortex.svc.cluster.local", host)
+	tlsMode, _, _ := kunstructured.NestedString(destinationRule.Object, "spec", "trafficPolicy", "tls", "mode")
+	require.Equal(t, "ISTIO_MUTUAL", tlsMode)
+}
+
+func TestAPIPodAnnotations(t *testing.T) {
+	restore := setTestClusterMTLS(clusterconfig.MTLSDisabled)
+	_, api := testMTLSContext(nil)
+	require.Empty(t, apiPodAnnotations(api, "8889"))
+	restore()
+
+	defer setTestClusterMTLS(clusterconfig.MTLSStrict)()
+
+	require.Equal(t, map[string]string{
+		"sidecar.istio.io/inject":                          "true",
+		"traffic.sidecar.istio.io/excludeOutboundIPRanges": "52.216.0.0/15,54.231.0.0/17",
+	}, apiPodAnnotations(api))
+
+	require.Equal(t, map[string]string{
+		"sidecar.istio.io/inject":                          "true",
+		"traffic.sidecar.istio.io/excludeOutboundIPRanges": "52.216.0.0/15,54.231.0.0/17",
+		"traffic.sidecar.istio.io/excludeInboundPorts":     "8889,8890",
+	}, apiPodAnnotations(api, "8889", "8890"))
+
+	config.Cluster.APIPodSecurity = pointer.String(clusterconfig.APIPodSecurityRestricted)
+	_, api = testMTLSContext(&userconfig.JWTAuth{Issuer: "https://auth.example.com/"})
+	annotations := apiPodAnnotations(api)
+	require.Equal(t, "runtime/default", annotations["seccomp.security.alpha.kubernetes.io/pod"])
+	require.Contains(t, annotations, "sidecar.istio.io/statsInclusionRegexps")
+
+	api.SecurityOptOuts = []string{userconfig.UnconfinedSeccompOptOut}
+	require.NotContains(t, apiPodAnnotations(api), "seccomp.security.alpha.kubernetes.io/pod")
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	gocontext "context"
+
+	kcore "k8s.io/api/core/v1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const gpuResourceName kcore.ResourceName = "nvidia.com/gpu"
+
+// GetClusterResources sums the requests of all pods scheduled on worker nodes, and the allocatable resources of the worker nodes
+func GetClusterResources(goCtx gocontext.Context) (schema.ClusterResources, error) {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return schema.ClusterResources{}, err
+	}
+
+	nodes, pods, err := listWorkerNodesAndPods(k8sClient)
+	if err != nil {
+		return schema.ClusterResources{}, err
+	}
+
+	clusterResources := schema.ClusterResources{
+		NumNodes: len(nodes),
+	}
+	for _, totals := range nodeResourceTotals(nodes, pods) {
+		addResourceTotals(&clusterResources.Capacity, totals.Allocatable)
+		addResourceTotals(&clusterResources.Requested, totals.Requested)
+	}
+	return clusterResources, nil
+}
+
+// listWorkerNodesAndPods lists the worker nodes, and the pods in all namespaces which haven't terminated
+func listWorkerNodesAndPods(k8sClient *k8s.Client) ([]kcore.Node, []kcore.Pod, error) {
+	nodes, err := k8sClient.ListNodes(&kmeta.ListOptions{
+		LabelSelector: k8s.LabelSelector(map[string]string{
+			"workload": "true",
+		}),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pods, err := k8sClient.ListPodsInAllNamespaces(&kmeta.ListOptions{
+		FieldSelector: "status.phase!=Succeeded,status.phase!=Failed",
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nodes, pods, nil
+}
+
+type nodeResources struct {
+	Allocatable kcore.ResourceList
+	Requested   kcore.ResourceList // the sum of the requests of the pods scheduled on the node
+}
+
+// nodeResourceTotals returns the allocatable and requested resources of each node, by node name (pods which aren't scheduled on one of the nodes are ignored)
+func nodeResourceTotals(nodes []kcore.Node, pods []kcore.Pod) map[string]nodeResources {
+	totals := make(map[string]nodeResources, len(nodes))
+	for _, node := range nodes {
+		totals[node.Name] = nodeResources{
+			Allocatable: node.Status.Allocatable,
+			Requested:   kcore.ResourceList{},
+		}
+	}
+
+	for _, pod := range pods {
+		nodeTotals, ok := totals[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+		for name, quantity := range k8s.PodRequests(&pod) {
+			total := nodeTotals.Requested[name]
+			total.Add(quantity)
+			nodeTotals.Requested[name] = total
+		}
+	}
+
+	return totals
+}
+
+func addResourceTotals(totals *schema.ResourceTotals, resources kcore.ResourceList) {
+	if cpu, ok := resources[kcore.ResourceCPU]; ok {
+		totals.CPU.Add(cpu)
+	}
+	if mem, ok := resources[kcore.ResourceMemory]; ok {
+		totals.Mem.Add(mem)
+	}
+	if gpu, ok := resources[gpuResourceName]; ok {
+		totals.GPU += gpu.Value()
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+	kclientrest "k8s.io/client-go/rest"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// setTestK8sHandler points config.Kubernetes to an API server which responds with handler
+func setTestK8sHandler(t *testing.T, handler http.HandlerFunc) func() {
+	server := httptest.NewServer(handler)
+
+	prevKubernetes := config.Kubernetes
+	client, err := k8s.NewFromRestConfig(consts.K8sNamespace, &kclientrest.Config{Host: server.URL})
+	require.NoError(t, err)
+	config.Kubernetes = client
+
+	return func() {
+		config.Kubernetes = prevKubernetes
+		server.Close()
+	}
+}
+
+func TestGetClusterResources(t *testing.T) {
+	gpuNode := testNode("node-gpu", "i-gpu", "4", "16Gi", nil)
+	gpuNode.Status.Allocatable[gpuResourceName] = kresource.MustParse("1")
+	nodes := []kcore.Node{
+		testNode("node-a", "i-a", "2", "8Gi", nil),
+		gpuNode,
+	}
+
+	gpuPod := testAPIPod("gpu", "node-gpu", "1", "4Gi")
+	gpuPod.Spec.Containers[0].Resources.Requests[gpuResourceName] = kresource.MustParse("1")
+	systemPod := kcore.Pod{Spec: kcore.PodSpec{
+		NodeName: "node-a",
+		Containers: []kcore.Container{{Resources: kcore.ResourceRequirements{Requests: kcore.ResourceList{
+			kcore.ResourceCPU: kresource.MustParse("200m"),
+		}}}},
+	}}
+	pods := []kcore.Pod{
+		testAPIPod("iris", "node-a", "1", "1Gi"),
+		gpuPod,
+		systemPod,
+		testAPIPod("operator", "node-operator", "1", "1Gi"), // not a worker node
+		testAPIPod("pending", "", "1", "1Gi"),
+	}
+
+	var nodesQuery, podsQuery string
+	defer setTestK8sHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/nodes":
+			nodesQuery = r.URL.Query().Get("labelSelector")
+			writeJSON(w, http.StatusOK, kcore.NodeList{Items: nodes})
+		case "/api/v1/pods":
+			podsQuery = r.URL.Query().Get("fieldSelector")
+			writeJSON(w, http.StatusOK, kcore.PodList{Items: pods})
+		default:
+			writeStatus(w, http.StatusNotFound, "NotFound")
+		}
+	})()
+
+	clusterResources, err := GetClusterResources(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "workload=true", nodesQuery)
+	require.Equal(t, "status.phase!=Succeeded,status.phase!=Failed", podsQuery)
+
+	require.Equal(t, 2, clusterResources.NumNodes)
+	require.Equal(t, "6", clusterResources.Capacity.CPU.String())
+	require.Equal(t, "24Gi", clusterResources.Capacity.Mem.String())
+	require.Equal(t, int64(1), clusterResources.Capacity.GPU)
+
+	// the api pods' requests include the istio sidecar (100m CPU and 128Mi memory)
+	require.Equal(t, "2400m", clusterResources.Requested.CPU.String())
+	require.Equal(t, "5376Mi", clusterResources.Requested.Mem.String())
+	require.Equal(t, int64(1), clusterResources.Requested.GPU)
+
+	// the requests of each node are the same as in cortex cluster nodes
+	nodesResponse := nodesResponse(nodes, pods, nil)
+	require.Equal(t, "1300m", nodesResponse.Nodes[0].Requested.CPU.String())
+	require.Equal(t, "1100m", nodesResponse.Nodes[1].Requested.CPU.String())
+}
+
+func TestGetClusterResourcesError(t *testing.T) {
+	defer setTestK8sHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/pods" {
+			writeStatus(w, http.StatusForbidden, "Forbidden")
+			return
+		}
+		writeJSON(w, http.StatusOK, kcore.NodeList{})
+	})()
+
+	_, err := GetClusterResources(context.Background())
+	require.Error(t, err)
+}
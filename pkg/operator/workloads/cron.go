@@ -17,6 +17,7 @@ limitations under the License.
 package workloads
 
 import (
+	gocontext "context"
 	"time"
 
 	kcore "k8s.io/api/core/v1"
@@ -31,6 +32,7 @@ import (
 
 const (
 	_cronInterval      = 5 * time.Second
+	_cronTimeout       = 5 * time.Minute // bounds a single run, so that a hung request can't stall the cron indefinitely
 	_telemetryInterval = 1 * time.Hour
 )
 
@@ -54,18 +56,37 @@ func cronRunner() {
 }
 
 func runCronNow() {
-	cronChannel <- struct{}{}
+	select {
+	case cronChannel <- struct{}{}:
+	default: // a run is already pending
+	}
 }
 
 func runCron() {
 	defer reportAndRecover("cron failed")
 
-	if err := UpdateWorkflows(); err != nil {
+	goCtx, cancel := gocontext.WithTimeout(gocontext.Background(), _cronTimeout)
+	defer cancel()
+	goCtx = k8s.ContextWithClientCache(goCtx) // the tasks below share a kubernetes client
+
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		telemetry.Error(err)
+		errors.PrintError(err)
+		return
+	}
+
+	if err := UpdateWorkflows(goCtx); err != nil {
 		telemetry.Error(err)
 		errors.PrintError(err)
 	}
 
-	apiPods, err := config.Kubernetes.ListPodsByLabels(map[string]string{
+	if err := updateImagePrepullers(goCtx); err != nil {
+		telemetry.Error(err)
+		errors.PrintError(err)
+	}
+
+	apiPods, err := k8sClient.ListPodsByLabels(map[string]string{
 		"workloadType": workloadTypeAPI,
 		"userFacing":   "true",
 	})
@@ -75,12 +96,27 @@ func runCron() {
 		errors.PrintError(err)
 	}
 
-	if err := updateAPISavedStatuses(apiPods); err != nil {
+	if err := updateAPISavedStatuses(goCtx, apiPods); err != nil {
 		telemetry.Error(err)
 		errors.PrintError(err)
 	}
 
-	failedPods, err := config.Kubernetes.ListPods(&kmeta.ListOptions{
+	if err := updateAuthRejectionMetrics(goCtx, apiPods); err != nil {
+		telemetry.Error(err)
+		errors.PrintError(err)
+	}
+
+	if err := exportUsage(goCtx); err != nil {
+		telemetry.Error(err)
+		errors.PrintError(err)
+	}
+
+	if err := refreshNetworkPolicies(goCtx); err != nil {
+		telemetry.Error(err)
+		errors.PrintError(err)
+	}
+
+	failedPods, err := k8sClient.ListPods(&kmeta.ListOptions{
 		FieldSelector: "status.phase=Failed",
 	})
 
@@ -89,24 +125,29 @@ func runCron() {
 		errors.PrintError(err)
 	}
 
-	deleteEvictedPods(failedPods)
+	deleteEvictedPods(k8sClient, failedPods)
 
-	if err := updateDataWorkloadErrors(failedPods); err != nil {
+	if err := updateDataWorkloadErrors(goCtx, failedPods); err != nil {
 		telemetry.Error(err)
 		errors.PrintError(err)
 	}
 
 	if time.Since(_lastTelemetryCron) >= _telemetryInterval {
 		_lastTelemetryCron = time.Now()
-		if err := telemetryCron(); err != nil {
+		if err := telemetryCron(goCtx); err != nil {
 			telemetry.Error(err)
 			errors.PrintError(err)
 		}
 	}
 }
 
-func telemetryCron() error {
-	nodes, err := config.Kubernetes.ListNodes(nil)
+func telemetryCron(goCtx gocontext.Context) error {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return err
+	}
+
+	nodes, err := k8sClient.ListNodes(nil)
 	if err != nil {
 		return err
 	}
@@ -148,7 +189,7 @@ func reportAndRecover(strs ...string) error {
 	return nil
 }
 
-func deleteEvictedPods(failedPods []kcore.Pod) {
+func deleteEvictedPods(k8sClient *k8s.Client, failedPods []kcore.Pod) {
 	evictedPods := []kcore.Pod{}
 	for _, pod := range failedPods {
 		if pod.Status.Reason == k8s.ReasonEvicted {
@@ -170,7 +211,7 @@ func deleteEvictedPods(failedPods []kcore.Pod) {
 					continue
 				}
 			}
-			_, err := config.Kubernetes.DeletePod(pod.Name)
+			_, err := k8sClient.DeletePod(pod.Name)
 			if err != nil {
 				errors.PrintError(err)
 			}
@@ -17,6 +17,7 @@ limitations under the License.
 package workloads
 
 import (
+	gocontext "context"
 	"fmt"
 	"sync"
 
@@ -51,13 +52,13 @@ func CurrentContexts() []*context.Context {
 	return ctxs
 }
 
-func setCurrentContext(ctx *context.Context) error {
+func setCurrentContext(goCtx gocontext.Context, ctx *context.Context) error {
 	currentCtxs.Lock()
 	defer currentCtxs.Unlock()
 
 	currentCtxs.m[ctx.App.Name] = ctx
 
-	err := updateContextConfigMap()
+	err := updateContextConfigMap(goCtx)
 	if err != nil {
 		return err
 	}
@@ -65,13 +66,13 @@ func setCurrentContext(ctx *context.Context) error {
 	return nil
 }
 
-func deleteCurrentContext(appName string) error {
+func deleteCurrentContext(goCtx gocontext.Context, appName string) error {
 	currentCtxs.Lock()
 	defer currentCtxs.Unlock()
 
 	delete(currentCtxs.m, appName)
 
-	err := updateContextConfigMap()
+	err := updateContextConfigMap(goCtx)
 	if err != nil {
 		return err
 	}
@@ -79,7 +80,7 @@ func deleteCurrentContext(appName string) error {
 	return nil
 }
 
-func updateContextConfigMap() error {
+func updateContextConfigMap(goCtx gocontext.Context) error {
 	configMapData := make(map[string]string, len(currentCtxs.m))
 	for appName, ctx := range currentCtxs.m {
 		configMapData[appName] = ctx.ID
@@ -91,7 +92,12 @@ func updateContextConfigMap() error {
 		Data:      configMapData,
 	})
 
-	_, err := config.Kubernetes.ApplyConfigMap(configMap)
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return err
+	}
+
+	_, err = k8sClient.ApplyConfigMap(configMap)
 	if err != nil {
 		return err
 	}
@@ -99,8 +105,13 @@ func updateContextConfigMap() error {
 	return nil
 }
 
-func reloadCurrentContexts() error {
-	configMap, err := config.Kubernetes.GetConfigMap(configMapName)
+func reloadCurrentContexts(goCtx gocontext.Context) error {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return err
+	}
+
+	configMap, err := k8sClient.GetConfigMap(configMapName)
 	if err != nil {
 		return err
 	}
@@ -109,10 +120,10 @@ func reloadCurrentContexts() error {
 	}
 
 	for appName, ctxID := range configMap.Data {
-		ctx, err := ocontext.DownloadContext(ctxID, appName)
+		ctx, err := ocontext.DownloadContext(goCtx, ctxID, appName)
 		if err != nil {
 			fmt.Printf("Deleting stale workflow: %s\n", appName)
-			DeleteApp(appName, true)
+			DeleteApp(goCtx, appName, true)
 		} else if ctx != nil {
 			currentCtxs.m[appName] = ctx
 		}
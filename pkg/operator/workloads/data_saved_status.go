@@ -17,6 +17,7 @@ limitations under the License.
 package workloads
 
 import (
+	gocontext "context"
 	"time"
 
 	kcore "k8s.io/api/core/v1"
@@ -33,13 +34,13 @@ import (
 	ocontext "github.com/cortexlabs/cortex/pkg/operator/context"
 )
 
-func uploadDataSavedStatus(savedStatus *resource.DataSavedStatus) error {
+func uploadDataSavedStatus(goCtx gocontext.Context, savedStatus *resource.DataSavedStatus) error {
 	if isDataSavedStatusCached(savedStatus) {
 		return nil
 	}
 
 	key := ocontext.StatusKey(savedStatus.ResourceID, savedStatus.WorkloadID, savedStatus.AppName)
-	err := config.AWS.UploadJSONToS3(savedStatus, key)
+	err := config.AWS.WithContext(goCtx).UploadJSONToS3(savedStatus, key)
 	if err != nil {
 		return errors.Wrap(err, "upload data saved status", savedStatus.AppName, savedStatus.ResourceID, savedStatus.WorkloadID)
 	}
@@ -47,28 +48,28 @@ func uploadDataSavedStatus(savedStatus *resource.DataSavedStatus) error {
 	return nil
 }
 
-func uploadDataSavedStatuses(savedStatuses []*resource.DataSavedStatus) error {
+func uploadDataSavedStatuses(goCtx gocontext.Context, savedStatuses []*resource.DataSavedStatus) error {
 	fns := make([]func() error, len(savedStatuses))
 	for i, savedStatus := range savedStatuses {
-		fns[i] = uploadDataSavedStatusFunc(savedStatus)
+		fns[i] = uploadDataSavedStatusFunc(goCtx, savedStatus)
 	}
 	return parallel.RunFirstErr(fns...)
 }
 
-func uploadDataSavedStatusFunc(savedStatus *resource.DataSavedStatus) func() error {
+func uploadDataSavedStatusFunc(goCtx gocontext.Context, savedStatus *resource.DataSavedStatus) func() error {
 	return func() error {
-		return uploadDataSavedStatus(savedStatus)
+		return uploadDataSavedStatus(goCtx, savedStatus)
 	}
 }
 
-func getDataSavedStatus(resourceID string, workloadID string, appName string) (*resource.DataSavedStatus, error) {
+func getDataSavedStatus(goCtx gocontext.Context, resourceID string, workloadID string, appName string) (*resource.DataSavedStatus, error) {
 	if cachedSavedStatus, ok := getCachedDataSavedStatus(resourceID, workloadID, appName); ok {
 		return cachedSavedStatus, nil
 	}
 
 	key := ocontext.StatusKey(resourceID, workloadID, appName)
 	var savedStatus resource.DataSavedStatus
-	err := config.AWS.ReadJSONFromS3(&savedStatus, key)
+	err := config.AWS.WithContext(goCtx).ReadJSONFromS3(&savedStatus, key)
 	if aws.IsNoSuchKeyErr(err) {
 		return nil, nil
 	}
@@ -79,12 +80,12 @@ func getDataSavedStatus(resourceID string, workloadID string, appName string) (*
 	return &savedStatus, nil
 }
 
-func getDataSavedStatuses(resourceWorkloadIDs map[string]string, appName string) (map[string]*resource.DataSavedStatus, error) {
+func getDataSavedStatuses(goCtx gocontext.Context, resourceWorkloadIDs map[string]string, appName string) (map[string]*resource.DataSavedStatus, error) {
 	savedStatuses := make([]*resource.DataSavedStatus, len(resourceWorkloadIDs))
 	fns := make([]func() error, len(resourceWorkloadIDs))
 	i := 0
 	for resourceID, workloadID := range resourceWorkloadIDs {
-		fns[i] = getDataSavedStatusFunc(resourceID, workloadID, appName, savedStatuses, i)
+		fns[i] = getDataSavedStatusFunc(goCtx, resourceID, workloadID, appName, savedStatuses, i)
 		i++
 	}
 	err := parallel.RunFirstErr(fns...)
@@ -106,9 +107,9 @@ func getDataSavedStatuses(resourceWorkloadIDs map[string]string, appName string)
 	return savedStatusMap, err
 }
 
-func getDataSavedStatusFunc(resourceID string, workloadID string, appName string, savedStatuses []*resource.DataSavedStatus, i int) func() error {
+func getDataSavedStatusFunc(goCtx gocontext.Context, resourceID string, workloadID string, appName string, savedStatuses []*resource.DataSavedStatus, i int) func() error {
 	return func() error {
-		savedStatus, err := getDataSavedStatus(resourceID, workloadID, appName)
+		savedStatus, err := getDataSavedStatus(goCtx, resourceID, workloadID, appName)
 		if err != nil {
 			return err
 		}
@@ -117,9 +118,9 @@ func getDataSavedStatusFunc(resourceID string, workloadID string, appName string
 	}
 }
 
-func updateKilledDataSavedStatuses(ctx *context.Context) error {
+func updateKilledDataSavedStatuses(goCtx gocontext.Context, ctx *context.Context) error {
 	resourceWorkloadIDs := ctx.DataResourceWorkloadIDs()
-	savedStatuses, err := getDataSavedStatuses(resourceWorkloadIDs, ctx.App.Name)
+	savedStatuses, err := getDataSavedStatuses(goCtx, resourceWorkloadIDs, ctx.App.Name)
 	if err != nil {
 		return err
 	}
@@ -133,14 +134,14 @@ func updateKilledDataSavedStatuses(ctx *context.Context) error {
 		}
 	}
 
-	err = uploadDataSavedStatuses(savedStatusesToUpdate)
+	err = uploadDataSavedStatuses(goCtx, savedStatusesToUpdate)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func updateDataWorkloadErrors(failedPods []kcore.Pod) error {
+func updateDataWorkloadErrors(goCtx gocontext.Context, failedPods []kcore.Pod) error {
 	checkedWorkloadIDs := strset.New()
 	nowTime := pointer.Time(time.Now())
 
@@ -163,7 +164,7 @@ func updateDataWorkloadErrors(failedPods []kcore.Pod) error {
 		}
 		checkedWorkloadIDs.Add(workloadID)
 
-		savedWorkload, err := getSavedBaseWorkload(workloadID, appName)
+		savedWorkload, err := getSavedBaseWorkload(goCtx, workloadID, appName)
 		if err != nil {
 			return err
 		}
@@ -176,7 +177,7 @@ func updateDataWorkloadErrors(failedPods []kcore.Pod) error {
 			resourceWorkloadIDs[resource.ID] = workloadID
 		}
 
-		savedStatuses, err := getDataSavedStatuses(resourceWorkloadIDs, appName)
+		savedStatuses, err := getDataSavedStatuses(goCtx, resourceWorkloadIDs, appName)
 		if err != nil {
 			return err
 		}
@@ -215,7 +216,7 @@ func updateDataWorkloadErrors(failedPods []kcore.Pod) error {
 			}
 		}
 
-		err = uploadDataSavedStatuses(savedStatusesToUpload)
+		err = uploadDataSavedStatuses(goCtx, savedStatusesToUpload)
 		if err != nil {
 			return err
 		}
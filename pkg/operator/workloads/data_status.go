@@ -17,16 +17,18 @@ limitations under the License.
 package workloads
 
 import (
+	gocontext "context"
+
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	"github.com/cortexlabs/cortex/pkg/operator/api/context"
 	"github.com/cortexlabs/cortex/pkg/operator/api/resource"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
 )
 
-func GetCurrentDataStatuses(ctx *context.Context) (map[string]*resource.DataStatus, error) {
+func GetCurrentDataStatuses(goCtx gocontext.Context, ctx *context.Context) (map[string]*resource.DataStatus, error) {
 	dataStatuses := make(map[string]*resource.DataStatus)
 	dataResourceWorkloadIDs := ctx.DataResourceWorkloadIDs()
-	dataSavedStatuses, err := getDataSavedStatuses(dataResourceWorkloadIDs, ctx.App.Name)
+	dataSavedStatuses, err := getDataSavedStatuses(goCtx, dataResourceWorkloadIDs, ctx.App.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +59,7 @@ func GetCurrentDataStatuses(ctx *context.Context) (map[string]*resource.DataStat
 	}
 
 	setSkippedDataStatusCodes(dataStatuses, ctx)
-	setInsufficientComputeDataStatusCodes(dataStatuses, ctx)
+	setInsufficientComputeDataStatusCodes(goCtx, dataStatuses, ctx)
 
 	return dataStatuses, nil
 }
@@ -121,8 +123,13 @@ func setSkippedDataStatusCodes(dataStatuses map[string]*resource.DataStatus, ctx
 	return
 }
 
-func setInsufficientComputeDataStatusCodes(dataStatuses map[string]*resource.DataStatus, ctx *context.Context) error {
-	stalledPods, err := config.Kubernetes.StalledPods()
+func setInsufficientComputeDataStatusCodes(goCtx gocontext.Context, dataStatuses map[string]*resource.DataStatus, ctx *context.Context) error {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return err
+	}
+
+	stalledPods, err := k8sClient.StalledPods()
 	if err != nil {
 		return err
 	}
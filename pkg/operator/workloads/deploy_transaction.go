@@ -0,0 +1,560 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	gocontext "context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	kapps "k8s.io/api/apps/v1"
+	kautoscaling "k8s.io/api/autoscaling/v2beta2"
+	kcore "k8s.io/api/core/v1"
+	knetworking "k8s.io/api/networking/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+/*
+Deploys are applied as a transaction:
+1. Stage: snapshot the Kubernetes resources of every API which the deploy will modify (or delete)
+2. Apply each step, retrying transient errors
+3. If a step can't be applied, roll back that step and all of the steps before it (in reverse order),
+   restoring the snapshotted resources and the previous context
+*/
+
+var (
+	_deployStepRetries     = 3               // number of times a step which fails with a transient error is retried
+	_deployStepRetryDelay  = 1 * time.Second // doubled after each retry
+	_deployRollbackTimeout = 5 * time.Minute // rollbacks don't use the deploy's context, since it may be the reason the deploy failed
+	_workflowMutex         sync.Mutex        // prevents the cron from starting workloads while a deploy is being applied
+)
+
+type deployStep struct {
+	description         string // e.g. "apply the iris api" (used in error messages)
+	apply               func(goCtx gocontext.Context) error
+	rollback            func(goCtx gocontext.Context) error // undoes apply, including a partial apply (nil if there is nothing to undo)
+	rollbackDescription string                              // e.g. "restored the previous iris api"
+}
+
+type rollbackReport struct {
+	rolledBack []string // rollback descriptions of the steps which were rolled back
+	failed     []string // rollback descriptions (and errors) of the steps which couldn't be rolled back
+}
+
+func runDeployTransaction(goCtx gocontext.Context, steps []deployStep) error {
+	for i, step := range steps {
+		err := retryTransientErrors(goCtx, step.apply)
+		if err != nil {
+			report := rollBackDeploySteps(steps[:i+1])
+			return ErrorDeployRolledBack(step.description, err, report.rolledBack, report.failed)
+		}
+	}
+	return nil
+}
+
+// rollBackDeploySteps undoes steps in reverse order; a step which fails to roll back doesn't prevent the others from being rolled back
+func rollBackDeploySteps(steps []deployStep) rollbackReport {
+	goCtx, cancel := gocontext.WithTimeout(gocontext.Background(), _deployRollbackTimeout)
+	defer cancel()
+	goCtx = k8s.ContextWithClientCache(goCtx)
+
+	var report rollbackReport
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		if step.rollback == nil {
+			continue
+		}
+		err := retryTransientErrors(goCtx, step.rollback)
+		if err != nil {
+			report.failed = append(report.failed, fmt.Sprintf("%s: %s", step.rollbackDescription, err.Error()))
+			continue
+		}
+		report.rolledBack = append(report.rolledBack, step.rollbackDescription)
+	}
+	return report
+}
+
+func retryTransientErrors(goCtx gocontext.Context, fn func(gocontext.Context) error) error {
+	delay := _deployStepRetryDelay
+	for attempt := 0; ; attempt++ {
+		err := fn(goCtx)
+		if err == nil || attempt >= _deployStepRetries || goCtx.Err() != nil || !isTransientErr(err) {
+			return err
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-goCtx.Done():
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// isTransientErr returns whether a request to Kubernetes may succeed if it's retried
+func isTransientErr(err error) bool {
+	if errors.IsCanceled(err) {
+		return false
+	}
+
+	cause := errors.Cause(err)
+	if kerrors.IsConflict(cause) || kerrors.IsServerTimeout(cause) || kerrors.IsTimeout(cause) ||
+		kerrors.IsTooManyRequests(cause) || kerrors.IsServiceUnavailable(cause) || kerrors.IsInternalError(cause) {
+		return true
+	}
+
+	// the request didn't reach the API server, or the connection was interrupted
+	if _, ok := cause.(*url.Error); ok {
+		return true
+	}
+
+	return false
+}
+
+// deploySteps stages all of the changes required to deploy ctx (which replaces prevCtx, if not nil)
+func deploySteps(goCtx gocontext.Context, ctx *context.Context, prevCtx *context.Context) ([]deployStep, error) {
+	var steps []deployStep
+
+	existingAPINames, err := deployedAPINames(goCtx, ctx.App.Name)
+	if err != nil {
+		return nil, err
+	}
+	var deletedAPINames []string
+	for apiName := range existingAPINames {
+		if _, ok := ctx.APIs[apiName]; !ok {
+			deletedAPINames = append(deletedAPINames, apiName)
+		}
+	}
+	sort.Strings(deletedAPINames)
+
+	for _, apiName := range deletedAPINames {
+		snapshot, err := snapshotAPI(goCtx, ctx.App.Name, apiName)
+		if err != nil {
+			return nil, err
+		}
+		apiName := apiName
+		steps = append(steps, deployStep{
+			description: fmt.Sprintf("delete the %s api", apiName),
+			apply: func(goCtx gocontext.Context) error {
+				return deleteAPIResources(goCtx, ctx.App.Name, apiName)
+			},
+			rollback:            snapshot.restore,
+			rollbackDescription: fmt.Sprintf("restored the deleted %s api (%s)", apiName, snapshot.kindsStr()),
+		})
+	}
+
+	apiWorkloads := extractAPIWorkloads(ctx)
+	sort.Slice(apiWorkloads, func(i, j int) bool {
+		return apiName(ctx, apiWorkloads[i]) < apiName(ctx, apiWorkloads[j])
+	})
+
+	for _, workload := range apiWorkloads {
+		isStarted, err := workload.IsStarted(goCtx, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if isStarted {
+			continue
+		}
+
+		apiName := apiName(ctx, workload)
+		snapshot, err := snapshotAPI(goCtx, ctx.App.Name, apiName)
+		if err != nil {
+			return nil, err
+		}
+
+		rollbackDescription := fmt.Sprintf("restored the previous %s api (%s)", apiName, snapshot.kindsStr())
+		if snapshot.isEmpty() {
+			rollbackDescription = fmt.Sprintf("deleted the new %s api", apiName)
+		}
+
+		workload := workload
+		steps = append(steps, deployStep{
+			description: fmt.Sprintf("apply the %s api", apiName),
+			apply: func(goCtx gocontext.Context) error {
+				return workload.Start(goCtx, ctx)
+			},
+			rollback:            snapshot.restore,
+			rollbackDescription: rollbackDescription,
+		})
+	}
+
+	// latest workload IDs are only used to reuse workload IDs in later deploys, so they don't need to be rolled back
+	resourceWorkloadIDs := ctx.ComputedResourceResourceWorkloadIDs()
+	steps = append(steps, deployStep{
+		description: "save the latest workload ids",
+		apply: func(goCtx gocontext.Context) error {
+			return uploadLatestWorkloadIDs(goCtx, resourceWorkloadIDs, ctx.App.Name)
+		},
+	})
+
+	contextStep := deployStep{
+		description: "update the deployment's context",
+		apply: func(goCtx gocontext.Context) error {
+			return setCurrentContext(goCtx, ctx)
+		},
+	}
+	if prevCtx != nil {
+		contextStep.rollback = func(goCtx gocontext.Context) error {
+			return setCurrentContext(goCtx, prevCtx)
+		}
+		contextStep.rollbackDescription = "restored the previous context"
+	} else {
+		contextStep.rollback = func(goCtx gocontext.Context) error {
+			return deleteCurrentContext(goCtx, ctx.App.Name)
+		}
+		contextStep.rollbackDescription = "removed the new context"
+	}
+	steps = append(steps, contextStep)
+
+	return steps, nil
+}
+
+func apiName(ctx *context.Context, workload Workload) string {
+	return ctx.APIs.OneByID(workload.GetSingleResourceID()).Name
+}
+
+// deployedAPINames returns the names of all APIs in the deployment which have Kubernetes resources
+func deployedAPINames(goCtx gocontext.Context, appName string) (strset.Set, error) {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := map[string]string{
+		"appName":      appName,
+		"workloadType": workloadTypeAPI,
+	}
+
+	apiNames := strset.New()
+
+	virtualServices, err := k8sClient.ListVirtualServicesByLabels(consts.K8sNamespace, labels)
+	if err != nil {
+		return nil, err
+	}
+	for _, virtualService := range virtualServices {
+		apiNames.Add(virtualService.GetLabels()["apiName"])
+	}
+
+	destinationRules, err := k8sClient.ListDestinationRulesByLabels(consts.K8sNamespace, labels)
+	if err != nil {
+		return nil, err
+	}
+	for _, destinationRule := range destinationRules {
+		apiNames.Add(destinationRule.GetLabels()["apiName"])
+	}
+
+	authenticationPolicies, err := k8sClient.ListAuthenticationPoliciesByLabels(consts.K8sNamespace, labels)
+	if err != nil {
+		return nil, err
+	}
+	for _, authenticationPolicy := range authenticationPolicies {
+		apiNames.Add(authenticationPolicy.GetLabels()["apiName"])
+	}
+
+	authorizationPolicies, err := k8sClient.ListAuthorizationPoliciesByLabels(consts.K8sNamespace, labels)
+	if err != nil {
+		return nil, err
+	}
+	for _, authorizationPolicy := range authorizationPolicies {
+		apiNames.Add(authorizationPolicy.GetLabels()["apiName"])
+	}
+
+	networkPolicies, err := k8sClient.ListNetworkPoliciesByLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+	for _, networkPolicy := range networkPolicies {
+		apiNames.Add(networkPolicy.Labels["apiName"])
+	}
+
+	services, err := k8sClient.ListServicesByLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+	for _, service := range services {
+		apiNames.Add(service.Labels["apiName"])
+	}
+
+	deployments, err := k8sClient.ListDeploymentsByLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments {
+		apiNames.Add(deployment.Labels["apiName"])
+	}
+
+	hpas, err := k8sClient.ListHPAsByLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+	for _, hpa := range hpas {
+		apiNames.Add(hpa.Labels["apiName"])
+	}
+
+	apiNames.Remove("")
+	return apiNames, nil
+}
+
+func deleteAPIResources(goCtx gocontext.Context, appName string, apiName string) error {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return err
+	}
+
+	name := internalAPIName(apiName, appName)
+
+	if _, err := k8sClient.DeleteVirtualService(name, consts.K8sNamespace); err != nil {
+		return err
+	}
+	if _, err := k8sClient.DeleteDestinationRule(name, consts.K8sNamespace); err != nil {
+		return err
+	}
+	if _, err := k8sClient.DeleteAuthenticationPolicy(name, consts.K8sNamespace); err != nil {
+		return err
+	}
+	if _, err := k8sClient.DeleteAuthorizationPolicy(name, consts.K8sNamespace); err != nil {
+		return err
+	}
+	if _, err := k8sClient.DeleteNetworkPolicy(name); err != nil {
+		return err
+	}
+	if _, err := k8sClient.DeleteService(name); err != nil {
+		return err
+	}
+	if _, err := k8sClient.DeleteDeployment(name); err != nil {
+		return err
+	}
+	if _, err := k8sClient.DeleteHPA(name); err != nil {
+		return err
+	}
+	return nil
+}
+
+// apiSnapshot holds an API's Kubernetes resources before they were modified by a deploy (nil if the resource didn't exist)
+type apiSnapshot struct {
+	appName              string
+	apiName              string
+	virtualService       *kunstructured.Unstructured
+	destinationRule      *kunstructured.Unstructured
+	authenticationPolicy *kunstructured.Unstructured
+	authorizationPolicy  *kunstructured.Unstructured
+	networkPolicy        *knetworking.NetworkPolicy
+	service              *kcore.Service
+	deployment           *kapps.Deployment
+	hpa                  *kautoscaling.HorizontalPodAutoscaler
+}
+
+func snapshotAPI(goCtx gocontext.Context, appName string, apiName string) (*apiSnapshot, error) {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	name := internalAPIName(apiName, appName)
+	snapshot := &apiSnapshot{
+		appName: appName,
+		apiName: apiName,
+	}
+
+	if snapshot.virtualService, err = k8sClient.GetVirtualService(name, consts.K8sNamespace); err != nil {
+		return nil, err
+	}
+	if snapshot.destinationRule, err = k8sClient.GetDestinationRule(name, consts.K8sNamespace); err != nil {
+		return nil, err
+	}
+	if snapshot.authenticationPolicy, err = k8sClient.GetAuthenticationPolicy(name, consts.K8sNamespace); err != nil {
+		return nil, err
+	}
+	if snapshot.authorizationPolicy, err = k8sClient.GetAuthorizationPolicy(name, consts.K8sNamespace); err != nil {
+		return nil, err
+	}
+	if snapshot.networkPolicy, err = k8sClient.GetNetworkPolicy(name); err != nil {
+		return nil, err
+	}
+	if snapshot.service, err = k8sClient.GetService(name); err != nil {
+		return nil, err
+	}
+	if snapshot.deployment, err = k8sClient.GetDeployment(name); err != nil {
+		return nil, err
+	}
+	if snapshot.hpa, err = k8sClient.GetHPA(name); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+func (snapshot *apiSnapshot) isEmpty() bool {
+	return len(snapshot.kinds()) == 0
+}
+
+func (snapshot *apiSnapshot) kinds() []string {
+	var kinds []string
+	if snapshot.deployment != nil {
+		kinds = append(kinds, "deployment")
+	}
+	if snapshot.service != nil {
+		kinds = append(kinds, "service")
+	}
+	if snapshot.virtualService != nil {
+		kinds = append(kinds, "virtual service")
+	}
+	if snapshot.destinationRule != nil {
+		kinds = append(kinds, "destination rule")
+	}
+	if snapshot.authenticationPolicy != nil {
+		kinds = append(kinds, "authentication policy")
+	}
+	if snapshot.authorizationPolicy != nil {
+		kinds = append(kinds, "authorization policy")
+	}
+	if snapshot.networkPolicy != nil {
+		kinds = append(kinds, "network policy")
+	}
+	if snapshot.hpa != nil {
+		kinds = append(kinds, "hpa")
+	}
+	return kinds
+}
+
+func (snapshot *apiSnapshot) kindsStr() string {
+	return strings.Join(snapshot.kinds(), ", ")
+}
+
+// restore applies the snapshotted resources, and deletes the API's resources which didn't exist when the snapshot was taken
+func (snapshot *apiSnapshot) restore(goCtx gocontext.Context) error {
+	if snapshot.isEmpty() {
+		return deleteAPIResources(goCtx, snapshot.appName, snapshot.apiName)
+	}
+
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return err
+	}
+
+	name := internalAPIName(snapshot.apiName, snapshot.appName)
+
+	if snapshot.deployment != nil {
+		deployment := snapshot.deployment.DeepCopy()
+		clearServerFields(&deployment.ObjectMeta)
+		deployment.Status = kapps.DeploymentStatus{}
+		if _, err := k8sClient.ApplyDeployment(deployment); err != nil {
+			return err
+		}
+	} else if _, err := k8sClient.DeleteDeployment(name); err != nil {
+		return err
+	}
+
+	if snapshot.service != nil {
+		service := snapshot.service.DeepCopy()
+		clearServerFields(&service.ObjectMeta)
+		service.Spec.ClusterIP = "" // kept if the service still exists, otherwise a new IP is allocated
+		service.Status = kcore.ServiceStatus{}
+		if _, err := k8sClient.ApplyService(service); err != nil {
+			return err
+		}
+	} else if _, err := k8sClient.DeleteService(name); err != nil {
+		return err
+	}
+
+	if snapshot.virtualService != nil {
+		virtualService := snapshot.virtualService.DeepCopy()
+		clearServerFields(virtualService)
+		if _, err := k8sClient.ApplyVirtualService(virtualService); err != nil {
+			return err
+		}
+	} else if _, err := k8sClient.DeleteVirtualService(name, consts.K8sNamespace); err != nil {
+		return err
+	}
+
+	if snapshot.destinationRule != nil {
+		destinationRule := snapshot.destinationRule.DeepCopy()
+		clearServerFields(destinationRule)
+		if _, err := k8sClient.ApplyDestinationRule(destinationRule); err != nil {
+			return err
+		}
+	} else if _, err := k8sClient.DeleteDestinationRule(name, consts.K8sNamespace); err != nil {
+		return err
+	}
+
+	if snapshot.authenticationPolicy != nil {
+		authenticationPolicy := snapshot.authenticationPolicy.DeepCopy()
+		clearServerFields(authenticationPolicy)
+		if _, err := k8sClient.ApplyAuthenticationPolicy(authenticationPolicy); err != nil {
+			return err
+		}
+	} else if _, err := k8sClient.DeleteAuthenticationPolicy(name, consts.K8sNamespace); err != nil {
+		return err
+	}
+
+	if snapshot.authorizationPolicy != nil {
+		authorizationPolicy := snapshot.authorizationPolicy.DeepCopy()
+		clearServerFields(authorizationPolicy)
+		if _, err := k8sClient.ApplyAuthorizationPolicy(authorizationPolicy); err != nil {
+			return err
+		}
+	} else if _, err := k8sClient.DeleteAuthorizationPolicy(name, consts.K8sNamespace); err != nil {
+		return err
+	}
+
+	if snapshot.networkPolicy != nil {
+		networkPolicy := snapshot.networkPolicy.DeepCopy()
+		clearServerFields(&networkPolicy.ObjectMeta)
+		if _, err := k8sClient.ApplyNetworkPolicy(networkPolicy); err != nil {
+			return err
+		}
+	} else if _, err := k8sClient.DeleteNetworkPolicy(name); err != nil {
+		return err
+	}
+
+	if snapshot.hpa != nil {
+		hpa := snapshot.hpa.DeepCopy()
+		clearServerFields(&hpa.ObjectMeta)
+		hpa.Status = kautoscaling.HorizontalPodAutoscalerStatus{}
+		if _, err := k8sClient.ApplyHPA(hpa); err != nil {
+			return err
+		}
+	} else if _, err := k8sClient.DeleteHPA(name); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// clearServerFields removes the metadata which is set by the API server, so that a snapshotted resource can be re-applied
+func clearServerFields(obj kmeta.Object) {
+	obj.SetResourceVersion("")
+	obj.SetUID("")
+	obj.SetSelfLink("")
+	obj.SetGeneration(0)
+	obj.SetCreationTimestamp(kmeta.Time{})
+	obj.SetManagedFields(nil)
+}
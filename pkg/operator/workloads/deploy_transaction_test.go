@@ -0,0 +1,220 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	gocontext "context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	kapps "k8s.io/api/apps/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+func init() {
+	_deployStepRetryDelay = time.Millisecond
+}
+
+// fakeDeploy records the order in which steps are applied and rolled back
+type fakeDeploy struct {
+	events []string
+}
+
+func (fake *fakeDeploy) step(name string, applyErrs ...error) deployStep {
+	attempt := 0
+	return deployStep{
+		description: "apply " + name,
+		apply: func(goCtx gocontext.Context) error {
+			fake.events = append(fake.events, "apply "+name)
+			if attempt < len(applyErrs) {
+				attempt++
+				return applyErrs[attempt-1]
+			}
+			return nil
+		},
+		rollback: func(goCtx gocontext.Context) error {
+			fake.events = append(fake.events, "rollback "+name)
+			return nil
+		},
+		rollbackDescription: "restored " + name,
+	}
+}
+
+func TestRunDeployTransactionSucceeds(t *testing.T) {
+	fake := &fakeDeploy{}
+	err := runDeployTransaction(gocontext.Background(), []deployStep{fake.step("a"), fake.step("b"), fake.step("c")})
+	require.NoError(t, err)
+	require.Equal(t, []string{"apply a", "apply b", "apply c"}, fake.events)
+}
+
+func TestRunDeployTransactionRollsBackEachStep(t *testing.T) {
+	names := []string{"a", "b", "c", "d"}
+
+	for failedIndex := range names {
+		fake := &fakeDeploy{}
+		var steps []deployStep
+		for i, name := range names {
+			if i == failedIndex {
+				steps = append(steps, fake.step(name, errors.New("injected failure")))
+			} else {
+				steps = append(steps, fake.step(name))
+			}
+		}
+
+		err := runDeployTransaction(gocontext.Background(), steps)
+		require.Error(t, err, "failed step %d", failedIndex)
+
+		var expectedEvents []string
+		var expectedRolledBack []string
+		for i := 0; i <= failedIndex; i++ {
+			expectedEvents = append(expectedEvents, "apply "+names[i])
+		}
+		for i := failedIndex; i >= 0; i-- {
+			expectedEvents = append(expectedEvents, "rollback "+names[i])
+			expectedRolledBack = append(expectedRolledBack, "restored "+names[i])
+		}
+		require.Equal(t, expectedEvents, fake.events, "failed step %d", failedIndex)
+
+		require.Contains(t, err.Error(), fmt.Sprintf("unable to apply %s: injected failure", names[failedIndex]))
+		require.Contains(t, err.Error(), "the deployment was rolled back:\n  "+strings.Join(expectedRolledBack, "\n  "))
+		require.NotContains(t, err.Error(), "unable to roll back")
+	}
+}
+
+func TestRunDeployTransactionRetriesTransientErrors(t *testing.T) {
+	fake := &fakeDeploy{}
+	transientErr := errors.WithStack(kerrors.NewServiceUnavailable("unavailable"))
+	conflictErr := errors.WithStack(kerrors.NewConflict(schema.GroupResource{Resource: "deployments"}, "api", errors.New("modified")))
+
+	err := runDeployTransaction(gocontext.Background(), []deployStep{fake.step("a", transientErr, conflictErr), fake.step("b")})
+	require.NoError(t, err)
+	require.Equal(t, []string{"apply a", "apply a", "apply a", "apply b"}, fake.events)
+}
+
+func TestRunDeployTransactionGivesUpOnTransientErrors(t *testing.T) {
+	fake := &fakeDeploy{}
+	transientErr := errors.WithStack(kerrors.NewTooManyRequests("slow down", 1))
+	applyErrs := make([]error, _deployStepRetries+1)
+	for i := range applyErrs {
+		applyErrs[i] = transientErr
+	}
+
+	err := runDeployTransaction(gocontext.Background(), []deployStep{fake.step("a"), fake.step("b", applyErrs...)})
+	require.Error(t, err)
+	require.Equal(t, []string{"apply a", "apply b", "apply b", "apply b", "apply b", "rollback b", "rollback a"}, fake.events)
+}
+
+func TestRunDeployTransactionDoesNotRetryPermanentErrors(t *testing.T) {
+	fake := &fakeDeploy{}
+	permanentErr := errors.WithStack(kerrors.NewBadRequest("invalid spec"))
+
+	err := runDeployTransaction(gocontext.Background(), []deployStep{fake.step("a", permanentErr)})
+	require.Error(t, err)
+	require.Equal(t, []string{"apply a", "rollback a"}, fake.events)
+}
+
+func TestRunDeployTransactionReportsFailedRollbacks(t *testing.T) {
+	fake := &fakeDeploy{}
+	stepB := fake.step("b")
+	stepB.rollback = func(goCtx gocontext.Context) error {
+		fake.events = append(fake.events, "rollback b")
+		return errors.New("injected rollback failure")
+	}
+	stepNoRollback := fake.step("workload ids")
+	stepNoRollback.rollback = nil
+
+	err := runDeployTransaction(gocontext.Background(), []deployStep{fake.step("a"), stepB, stepNoRollback, fake.step("c", errors.New("injected failure"))})
+	require.Error(t, err)
+
+	// a failed rollback doesn't prevent earlier steps from being rolled back
+	require.Equal(t, []string{"apply a", "apply b", "apply workload ids", "apply c", "rollback c", "rollback b", "rollback a"}, fake.events)
+	require.Contains(t, err.Error(), "the deployment was rolled back:\n  restored c\n  restored a")
+	require.Contains(t, err.Error(), "unable to roll back (run `cortex deploy` again, or `cortex delete` to remove the deployment):\n  restored b: injected rollback failure")
+	require.NotContains(t, err.Error(), "workload ids")
+}
+
+func TestRunDeployTransactionRollsBackAfterCancellation(t *testing.T) {
+	fake := &fakeDeploy{}
+	goCtx, cancel := gocontext.WithCancel(gocontext.Background())
+
+	stepB := fake.step("b")
+	stepB.apply = func(goCtx gocontext.Context) error {
+		fake.events = append(fake.events, "apply b")
+		cancel()
+		return errors.WithStack(&url.Error{Op: "Put", URL: "https://kubernetes", Err: goCtx.Err()})
+	}
+	var rollbackCtxErr error
+	stepA := fake.step("a")
+	stepA.rollback = func(rollbackCtx gocontext.Context) error {
+		fake.events = append(fake.events, "rollback a")
+		rollbackCtxErr = rollbackCtx.Err()
+		return nil
+	}
+
+	err := runDeployTransaction(goCtx, []deployStep{stepA, stepB, fake.step("c")})
+	require.Error(t, err)
+	require.Equal(t, []string{"apply a", "apply b", "rollback b", "rollback a"}, fake.events)
+	require.NoError(t, rollbackCtxErr) // the rollback isn't canceled along with the deploy
+}
+
+func TestIsTransientErr(t *testing.T) {
+	require.True(t, isTransientErr(errors.WithStack(kerrors.NewInternalError(errors.New("etcd")))))
+	require.True(t, isTransientErr(errors.WithStack(kerrors.NewServerTimeout(schema.GroupResource{Resource: "services"}, "create", 1))))
+	require.True(t, isTransientErr(errors.WithStack(&url.Error{Op: "Get", URL: "https://kubernetes", Err: errors.New("connection refused")})))
+	require.False(t, isTransientErr(errors.WithStack(&url.Error{Op: "Get", URL: "https://kubernetes", Err: gocontext.Canceled})))
+	require.False(t, isTransientErr(errors.WithStack(kerrors.NewNotFound(schema.GroupResource{Resource: "services"}, "api"))))
+	require.False(t, isTransientErr(errors.WithStack(kerrors.NewForbidden(schema.GroupResource{Resource: "services"}, "api", errors.New("rbac")))))
+	require.False(t, isTransientErr(errors.New("invalid spec")))
+}
+
+func TestClearServerFields(t *testing.T) {
+	deployment := &kapps.Deployment{
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:              "api",
+			Labels:            map[string]string{"apiName": "api"},
+			ResourceVersion:   "123",
+			UID:               "uid",
+			Generation:        4,
+			CreationTimestamp: kmeta.Now(),
+		},
+	}
+	clearServerFields(&deployment.ObjectMeta)
+	require.Equal(t, kmeta.ObjectMeta{Name: "api", Labels: map[string]string{"apiName": "api"}}, deployment.ObjectMeta)
+
+	virtualService := &kunstructured.Unstructured{}
+	virtualService.SetName("api")
+	virtualService.SetResourceVersion("123")
+	virtualService.SetUID("uid")
+	clearServerFields(virtualService)
+	require.Equal(t, "api", virtualService.GetName())
+	require.Equal(t, "", virtualService.GetResourceVersion())
+	require.Equal(t, "", string(virtualService.GetUID()))
+}
+
+func TestErrorDeployRolledBackNothingApplied(t *testing.T) {
+	err := ErrorDeployRolledBack("apply the iris api", errors.New("forbidden"), nil, nil)
+	require.Equal(t, "unable to apply the iris api: forbidden\n\nno changes had been applied, so there was nothing to roll back", err.Error())
+}
@@ -18,7 +18,10 @@ package workloads
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 )
@@ -34,6 +37,16 @@ const (
 	ErrAPIInitializing
 	ErrNoAvailableNodeComputeLimit
 	ErrDuplicateEndpointOtherDeployment
+	ErrUnableToResolveEgressHost
+	ErrPackageIndexSecretNotFound
+	ErrPackageIndexSecretMissingKey
+	ErrDeployRolledBack
+	ErrInvalidLogFilter
+	ErrInvalidLogLevel
+	ErrJWTAuthRequiresMTLS
+	ErrInvalidUsageDate
+	ErrInvalidUsageRange
+	ErrInvalidUsageGroupBy
 )
 
 var errorKinds = []string{
@@ -45,9 +58,19 @@ var errorKinds = []string{
 	"err_api_initializing",
 	"err_no_available_node_compute_limit",
 	"err_duplicate_endpoint_other_deployment",
+	"err_unable_to_resolve_egress_host",
+	"err_package_index_secret_not_found",
+	"err_package_index_secret_missing_key",
+	"err_deploy_rolled_back",
+	"err_invalid_log_filter",
+	"err_invalid_log_level",
+	"err_jwt_auth_requires_mtls",
+	"err_invalid_usage_date",
+	"err_invalid_usage_range",
+	"err_invalid_usage_group_by",
 }
 
-var _ = [1]int{}[int(ErrDuplicateEndpointOtherDeployment)-(len(errorKinds)-1)] // Ensure list length matches
+var _ = [1]int{}[int(ErrInvalidUsageGroupBy)-(len(errorKinds)-1)] // Ensure list length matches
 
 func (t ErrorKind) String() string {
 	return errorKinds[t]
@@ -144,3 +167,85 @@ func ErrorDuplicateEndpointOtherDeployment(appName string, apiName string) error
 		message: fmt.Sprintf("endpoint is already in use by an API named %s in the %s deployment", s.UserStr(apiName), s.UserStr(appName)),
 	})
 }
+
+func ErrorUnableToResolveEgressHost(host string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrUnableToResolveEgressHost,
+		message: fmt.Sprintf("unable to resolve egress hostname %s to an IPv4 address", s.UserStr(host)),
+	})
+}
+
+func ErrorPackageIndexSecretNotFound(secretName string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPackageIndexSecretNotFound,
+		message: fmt.Sprintf("secret %s does not exist in the %s namespace (it can be created with `kubectl create secret generic %s --namespace=%s --from-literal=%s=<username> --from-literal=%s=<password>`)", s.UserStr(secretName), consts.K8sNamespace, secretName, consts.K8sNamespace, clusterconfig.PythonPackageIndexUsernameSecretKey, clusterconfig.PythonPackageIndexPasswordSecretKey),
+	})
+}
+
+func ErrorPackageIndexSecretMissingKey(secretName string, key string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrPackageIndexSecretMissingKey,
+		message: fmt.Sprintf("secret %s does not contain the %s key", s.UserStr(secretName), s.UserStr(key)),
+	})
+}
+
+func ErrorDeployRolledBack(stepDescription string, err error, rolledBack []string, rollbackFailed []string) error {
+	message := fmt.Sprintf("unable to %s: %s", stepDescription, err.Error())
+
+	if len(rolledBack) == 0 && len(rollbackFailed) == 0 {
+		message += "\n\nno changes had been applied, so there was nothing to roll back"
+	}
+	if len(rolledBack) > 0 {
+		message += "\n\nthe deployment was rolled back:\n  " + strings.Join(rolledBack, "\n  ")
+	}
+	if len(rollbackFailed) > 0 {
+		message += "\n\nunable to roll back (run `cortex deploy` again, or `cortex delete` to remove the deployment):\n  " + strings.Join(rollbackFailed, "\n  ")
+	}
+
+	return errors.WithStack(Error{
+		Kind:    ErrDeployRolledBack,
+		message: message,
+	})
+}
+
+func ErrorInvalidLogFilter(filter string, reason string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidLogFilter,
+		message: fmt.Sprintf("invalid log filter %s: %s (filters look like request_id=7f3a or level=ERROR)", s.UserStr(filter), reason),
+	})
+}
+
+func ErrorInvalidLogLevel(level string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidLogLevel,
+		message: fmt.Sprintf("invalid log level %s; valid levels are %s", s.UserStr(level), s.StrsOr(LogLevels)),
+	})
+}
+
+func ErrorJWTAuthRequiresMTLS() error {
+	return errors.WithStack(Error{
+		Kind:    ErrJWTAuthRequiresMTLS,
+		message: fmt.Sprintf("jwt authentication is enforced by the apis' istio sidecars, which aren't injected when %s is %s (set %s to %s or %s in your cluster configuration and run `cortex cluster update`)", clusterconfig.MTLSKey, clusterconfig.MTLSDisabled, clusterconfig.MTLSKey, clusterconfig.MTLSStrict, clusterconfig.MTLSPermissive),
+	})
+}
+
+func ErrorInvalidUsageDate(date string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidUsageDate,
+		message: fmt.Sprintf("invalid date %s (dates look like 2020-01-31)", s.UserStr(date)),
+	})
+}
+
+func ErrorInvalidUsageRange(reason string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidUsageRange,
+		message: "invalid date range: " + reason,
+	})
+}
+
+func ErrorInvalidUsageGroupBy(groupBy string) error {
+	return errors.WithStack(Error{
+		Kind:    ErrInvalidUsageGroupBy,
+		message: fmt.Sprintf("usage can't be grouped by %s; valid groupings are %s", s.UserStr(groupBy), s.StrsOr(UsageGroupBys)),
+	})
+}
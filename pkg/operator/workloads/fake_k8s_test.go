@@ -0,0 +1,299 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kclientrest "k8s.io/client-go/rest"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// fakeK8s is an in-memory Kubernetes API server which supports getting, creating, updating and deleting objects by name
+type fakeK8s struct {
+	sync.Mutex
+	objects  map[string]map[string]interface{} // object path -> object
+	failures map[string]int                    // "METHOD resource" -> status code to respond with
+	requests []string                          // "METHOD resource" of each request
+}
+
+func newFakeK8s(t *testing.T) (*fakeK8s, func()) {
+	fake := &fakeK8s{
+		objects:  map[string]map[string]interface{}{},
+		failures: map[string]int{},
+	}
+	server := httptest.NewServer(fake)
+
+	prevKubernetes := config.Kubernetes
+	client, err := k8s.NewFromRestConfig(consts.K8sNamespace, &kclientrest.Config{Host: server.URL})
+	require.NoError(t, err)
+	config.Kubernetes = client
+
+	return fake, func() {
+		config.Kubernetes = prevKubernetes
+		server.Close()
+	}
+}
+
+// resource returns the resource type of an object path, e.g. "deployments"
+func resourceOfPath(objectPath string) string {
+	return path.Base(path.Dir(objectPath))
+}
+
+func (fake *fakeK8s) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fake.Lock()
+	defer fake.Unlock()
+
+	objectPath := r.URL.Path
+	var body map[string]interface{}
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		bodyBytes, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(bodyBytes, &body)
+		if r.Method == http.MethodPost {
+			objectPath = path.Join(objectPath, body["metadata"].(map[string]interface{})["name"].(string))
+		}
+	}
+
+	request := r.Method + " " + resourceOfPath(objectPath)
+	fake.requests = append(fake.requests, request)
+	if code, ok := fake.failures[request]; ok {
+		writeStatus(w, code, "InternalError")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		obj, ok := fake.objects[objectPath]
+		if !ok {
+			writeStatus(w, http.StatusNotFound, "NotFound")
+			return
+		}
+		writeJSON(w, http.StatusOK, obj)
+	case http.MethodPost, http.MethodPut:
+		fake.objects[objectPath] = body
+		writeJSON(w, http.StatusOK, body)
+	case http.MethodDelete:
+		if _, ok := fake.objects[objectPath]; !ok {
+			writeStatus(w, http.StatusNotFound, "NotFound")
+			return
+		}
+		delete(fake.objects, objectPath)
+		writeStatus(w, http.StatusOK, "")
+	default:
+		writeStatus(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(obj)
+}
+
+func writeStatus(w http.ResponseWriter, code int, reason string) {
+	status := "Success"
+	if code >= 300 {
+		status = "Failure"
+	}
+	writeJSON(w, code, map[string]interface{}{
+		"kind":       "Status",
+		"apiVersion": "v1",
+		"status":     status,
+		"reason":     reason,
+		"code":       code,
+	})
+}
+
+func (fake *fakeK8s) names(resource string) []string {
+	fake.Lock()
+	defer fake.Unlock()
+
+	var names []string
+	for objectPath := range fake.objects {
+		if resourceOfPath(objectPath) == resource {
+			names = append(names, path.Base(objectPath))
+		}
+	}
+	return names
+}
+
+func (fake *fakeK8s) label(resource string, name string, key string) string {
+	fake.Lock()
+	defer fake.Unlock()
+
+	for objectPath, obj := range fake.objects {
+		if resourceOfPath(objectPath) == resource && path.Base(objectPath) == name {
+			labels, _ := obj["metadata"].(map[string]interface{})["labels"].(map[string]interface{})
+			value, _ := labels[key].(string)
+			return value
+		}
+	}
+	return ""
+}
+
+var _apiResources = []string{"deployments", "services", "virtualservices", "destinationrules", "policies", "authorizationpolicies", "networkpolicies", "horizontalpodautoscalers"}
+
+// applyTestAPI creates all of an API's resources, with a "version" label
+func applyTestAPI(t *testing.T, apiName string, version string, resources ...string) {
+	k8sClient := config.Kubernetes
+	name := internalAPIName(apiName, "app")
+	labels := map[string]string{"appName": "app", "apiName": apiName, "version": version}
+
+	for _, resource := range resources {
+		var err error
+		switch resource {
+		case "deployments":
+			_, err = k8sClient.ApplyDeployment(k8s.Deployment(&k8s.DeploymentSpec{Name: name, Labels: labels, Selector: map[string]string{"apiName": apiName}}))
+		case "services":
+			_, err = k8sClient.ApplyService(k8s.Service(&k8s.ServiceSpec{Name: name, Namespace: consts.K8sNamespace, Labels: labels}))
+		case "virtualservices":
+			_, err = k8sClient.ApplyVirtualService(k8s.VirtualService(&k8s.VirtualServiceSpec{Name: name, Namespace: consts.K8sNamespace, Labels: labels}))
+		case "destinationrules":
+			_, err = k8sClient.ApplyDestinationRule(k8s.DestinationRule(&k8s.DestinationRuleSpec{Name: name, Namespace: consts.K8sNamespace, Labels: labels}))
+		case "policies":
+			_, err = k8sClient.ApplyAuthenticationPolicy(k8s.AuthenticationPolicy(&k8s.AuthenticationPolicySpec{Name: name, Namespace: consts.K8sNamespace, Labels: labels}))
+		case "authorizationpolicies":
+			_, err = k8sClient.ApplyAuthorizationPolicy(k8s.AuthorizationPolicy(&k8s.AuthorizationPolicySpec{Name: name, Namespace: consts.K8sNamespace, Labels: labels}))
+		case "networkpolicies":
+			_, err = k8sClient.ApplyNetworkPolicy(k8s.NetworkPolicy(&k8s.NetworkPolicySpec{Name: name, Namespace: consts.K8sNamespace, Labels: labels}))
+		case "horizontalpodautoscalers":
+			_, err = k8sClient.ApplyHPA(k8s.HPA(&k8s.HPASpec{DeploymentName: name, MinReplicas: 1, MaxReplicas: 2, TargetCPUUtilization: 90, Labels: labels}))
+		}
+		require.NoError(t, err, resource)
+	}
+}
+
+func TestAPISnapshotRestore(t *testing.T) {
+	fake, closeFake := newFakeK8s(t)
+	defer closeFake()
+	goCtx := context.Background()
+
+	// the previous version of the api didn't have an hpa or network policy
+	applyTestAPI(t, "api", "previous", "deployments", "services", "virtualservices", "destinationrules", "policies")
+	snapshot, err := snapshotAPI(goCtx, "app", "api")
+	require.NoError(t, err)
+	require.Equal(t, "deployment, service, virtual service, destination rule, authentication policy", snapshot.kindsStr())
+
+	applyTestAPI(t, "api", "new", _apiResources...)
+
+	require.NoError(t, snapshot.restore(goCtx))
+	for _, resource := range []string{"deployments", "services", "virtualservices", "destinationrules", "policies"} {
+		require.Equal(t, "previous", fake.label(resource, internalAPIName("api", "app"), "version"), resource)
+	}
+	require.Empty(t, fake.names("authorizationpolicies"))
+	require.Empty(t, fake.names("networkpolicies"))
+	require.Empty(t, fake.names("horizontalpodautoscalers"))
+}
+
+func TestAPISnapshotRestoreNewAPI(t *testing.T) {
+	fake, closeFake := newFakeK8s(t)
+	defer closeFake()
+	goCtx := context.Background()
+
+	snapshot, err := snapshotAPI(goCtx, "app", "api")
+	require.NoError(t, err)
+	require.True(t, snapshot.isEmpty())
+
+	applyTestAPI(t, "api", "new", _apiResources...)
+
+	require.NoError(t, snapshot.restore(goCtx))
+	for _, resource := range _apiResources {
+		require.Empty(t, fake.names(resource), resource)
+	}
+}
+
+func TestDeleteAPIResources(t *testing.T) {
+	fake, closeFake := newFakeK8s(t)
+	defer closeFake()
+	goCtx := context.Background()
+
+	applyTestAPI(t, "api", "previous", _apiResources...)
+	applyTestAPI(t, "other", "previous", "deployments")
+	snapshot, err := snapshotAPI(goCtx, "app", "api")
+	require.NoError(t, err)
+
+	require.NoError(t, deleteAPIResources(goCtx, "app", "api"))
+	for _, resource := range _apiResources {
+		require.NotContains(t, fake.names(resource), internalAPIName("api", "app"), resource)
+	}
+	require.Equal(t, []string{internalAPIName("other", "app")}, fake.names("deployments"))
+
+	require.NoError(t, snapshot.restore(goCtx))
+	for _, resource := range _apiResources {
+		require.Equal(t, "previous", fake.label(resource, internalAPIName("api", "app"), "version"), resource)
+	}
+}
+
+// each of the requests made while restoring a snapshot fails the restore if it fails permanently, and is retried if it fails transiently
+func TestAPISnapshotRestoreFailures(t *testing.T) {
+	var requests []string
+	for _, resource := range []string{"deployments", "services", "virtualservices", "destinationrules", "policies"} {
+		requests = append(requests, http.MethodGet+" "+resource, http.MethodPut+" "+resource)
+	}
+	// these didn't exist in the snapshot, so they are deleted rather than updated
+	requests = append(requests, http.MethodDelete+" authorizationpolicies", http.MethodDelete+" networkpolicies", http.MethodDelete+" horizontalpodautoscalers")
+
+	for _, request := range requests {
+		func() {
+			fake, closeFake := newFakeK8s(t)
+			defer closeFake()
+			goCtx := context.Background()
+
+			applyTestAPI(t, "api", "previous", "deployments", "services", "virtualservices", "destinationrules", "policies")
+			snapshot, err := snapshotAPI(goCtx, "app", "api")
+			require.NoError(t, err)
+			applyTestAPI(t, "api", "new", _apiResources...)
+
+			fake.Lock()
+			fake.failures[request] = http.StatusForbidden
+			fake.Unlock()
+			require.Error(t, retryTransientErrors(goCtx, snapshot.restore), request)
+
+			fake.Lock()
+			fake.failures[request] = http.StatusServiceUnavailable
+			numRequests := len(fake.requests)
+			fake.Unlock()
+			require.Error(t, retryTransientErrors(goCtx, snapshot.restore), request) // still failing after all retries
+
+			fake.Lock()
+			var numFailedAttempts int
+			for _, r := range fake.requests[numRequests:] {
+				if r == request {
+					numFailedAttempts++
+				}
+			}
+			delete(fake.failures, request)
+			fake.Unlock()
+			require.Equal(t, _deployStepRetries+1, numFailedAttempts, request)
+
+			require.NoError(t, retryTransientErrors(goCtx, snapshot.restore), request)
+			require.Equal(t, "previous", fake.label("deployments", internalAPIName("api", "app"), "version"), request)
+			require.Empty(t, fake.names("horizontalpodautoscalers"), request)
+		}()
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package workloads
 
 import (
+	gocontext "context"
 	"time"
 
 	kapps "k8s.io/api/apps/v1"
@@ -47,10 +48,15 @@ func extractHPAWorkloads(ctx *context.Context) []Workload {
 	return workloads
 }
 
-func (hw *HPAWorkload) Start(ctx *context.Context) error {
+func (hw *HPAWorkload) Start(goCtx gocontext.Context, ctx *context.Context) error {
 	api := ctx.APIs.OneByID(hw.APIID)
 
-	_, err := config.Kubernetes.ApplyHPA(hpaSpec(ctx, api))
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return err
+	}
+
+	_, err = k8sClient.ApplyHPA(hpaSpec(ctx, api))
 	if err != nil {
 		return err
 	}
@@ -58,11 +64,16 @@ func (hw *HPAWorkload) Start(ctx *context.Context) error {
 	return nil
 }
 
-func (hw *HPAWorkload) IsSucceeded(ctx *context.Context) (bool, error) {
+func (hw *HPAWorkload) IsSucceeded(goCtx gocontext.Context, ctx *context.Context) (bool, error) {
 	api := ctx.APIs.OneByID(hw.APIID)
 	k8sDeloymentName := internalAPIName(api.Name, ctx.App.Name)
 
-	hpa, err := config.Kubernetes.GetHPA(k8sDeloymentName)
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return false, err
+	}
+
+	hpa, err := k8sClient.GetHPA(k8sDeloymentName)
 	if err != nil {
 		return false, err
 	}
@@ -70,19 +81,24 @@ func (hw *HPAWorkload) IsSucceeded(ctx *context.Context) (bool, error) {
 	return k8s.IsHPAUpToDate(hpa, api.Compute.MinReplicas, api.Compute.MaxReplicas, api.Compute.TargetCPUUtilization), nil
 }
 
-func (hw *HPAWorkload) IsRunning(ctx *context.Context) (bool, error) {
+func (hw *HPAWorkload) IsRunning(goCtx gocontext.Context, ctx *context.Context) (bool, error) {
 	return false, nil
 }
 
-func (hw *HPAWorkload) IsStarted(ctx *context.Context) (bool, error) {
-	return hw.IsSucceeded(ctx)
+func (hw *HPAWorkload) IsStarted(goCtx gocontext.Context, ctx *context.Context) (bool, error) {
+	return hw.IsSucceeded(goCtx, ctx)
 }
 
-func (hw *HPAWorkload) CanRun(ctx *context.Context) (bool, error) {
+func (hw *HPAWorkload) CanRun(goCtx gocontext.Context, ctx *context.Context) (bool, error) {
 	api := ctx.APIs.OneByID(hw.APIID)
 	k8sDeloymentName := internalAPIName(api.Name, ctx.App.Name)
 
-	k8sDeployment, err := config.Kubernetes.GetDeployment(k8sDeloymentName)
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return false, err
+	}
+
+	k8sDeployment, err := k8sClient.GetDeployment(k8sDeloymentName)
 	if err != nil {
 		return false, err
 	}
@@ -94,7 +110,7 @@ func (hw *HPAWorkload) CanRun(ctx *context.Context) (bool, error) {
 		return false, nil
 	}
 
-	updatedReplicas, err := numUpdatedReadyReplicas(ctx, api)
+	updatedReplicas, err := numUpdatedReadyReplicas(goCtx, ctx, api)
 	if err != nil {
 		return false, err
 	}
@@ -115,7 +131,7 @@ func (hw *HPAWorkload) CanRun(ctx *context.Context) (bool, error) {
 	return false, nil
 }
 
-func (hw *HPAWorkload) IsFailed(ctx *context.Context) (bool, error) {
+func (hw *HPAWorkload) IsFailed(goCtx gocontext.Context, ctx *context.Context) (bool, error) {
 	return false, nil
 }
 
@@ -0,0 +1,287 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	gocontext "context"
+	"fmt"
+	"sort"
+	"strings"
+
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// The pre-puller of GPU images only runs on GPU nodes, so that CPU nodes don't store images which they can't use
+const (
+	_imagePrepullerName        = "image-prepuller"
+	_imagePrepullerGPUName     = "image-prepuller-gpu"
+	_gpuNodeLabel              = "nvidia.com/gpu"
+	_prepulledImagesAnnotation = "cortex.dev/images"
+)
+
+// pre-puller name -> images of the most recently applied pre-puller, so that the pre-pullers are only updated when the images change
+var _appliedPrepullerImages = map[string]string{}
+
+// apiServingImage returns the image of the container which serves the API's predictions
+func apiServingImage(api *context.API) string {
+	gpu := api.Compute.GPU > 0
+	switch api.Predictor.Type {
+	case userconfig.TensorFlowPredictorType:
+		if gpu {
+			return config.Cluster.ImageTFServeGPU
+		}
+		return config.Cluster.ImageTFServe
+	case userconfig.ONNXPredictorType:
+		if gpu {
+			return config.Cluster.ImageONNXServeGPU
+		}
+		return config.Cluster.ImageONNXServe
+	default:
+		if gpu {
+			return config.Cluster.ImagePythonServeGPU
+		}
+		return config.Cluster.ImagePythonServe
+	}
+}
+
+// apiImages returns all of the images which are pulled to start a replica of the API
+func apiImages(api *context.API) []string {
+	images := []string{config.Cluster.ImageDownloader}
+	if api.Predictor.Type == userconfig.TensorFlowPredictorType {
+		images = append(images, config.Cluster.ImageTFAPI)
+	}
+	return append(images, apiServingImage(api))
+}
+
+// prepullerImages returns the sorted images of the APIs which run on any worker node, and of the APIs which only run on GPU nodes
+func prepullerImages(ctxs []*context.Context) ([]string, []string) {
+	images := strset.New()
+	gpuImages := strset.New()
+	for _, ctx := range ctxs {
+		for _, api := range ctx.APIs {
+			if api.Compute.GPU > 0 {
+				gpuImages.Add(apiImages(api)...)
+			} else {
+				images.Add(apiImages(api)...)
+			}
+		}
+	}
+	// GPU nodes also run the pre-puller of the other images
+	gpuImages.Subtract(images)
+
+	imagesSlice := images.Slice()
+	sort.Strings(imagesSlice)
+	gpuImagesSlice := gpuImages.Slice()
+	sort.Strings(gpuImagesSlice)
+	return imagesSlice, gpuImagesSlice
+}
+
+func imagePrepullerSpec(name string, images []string, nodeSelector map[string]string) *kapps.DaemonSet {
+	resourceList := kcore.ResourceList{
+		kcore.ResourceCPU:    k8s.CPU("10m"),
+		kcore.ResourceMemory: k8s.Mem("10Mi"),
+	}
+
+	// each image is pulled by an init container which exits immediately, so the pod is ready once all of the images are cached
+	initContainers := make([]kcore.Container, len(images))
+	for i, image := range images {
+		initContainers[i] = kcore.Container{
+			Name:            fmt.Sprintf("image-%d", i),
+			Image:           image,
+			ImagePullPolicy: kcore.PullAlways,
+			Command:         []string{"/bin/sh", "-c", "true"},
+			Resources: kcore.ResourceRequirements{
+				Requests: resourceList,
+			},
+		}
+	}
+
+	return k8s.DaemonSet(&k8s.DaemonSetSpec{
+		Name: name,
+		Labels: map[string]string{
+			"workloadType": workloadTypeImagePrepuller,
+		},
+		Annotations: map[string]string{
+			_prepulledImagesAnnotation: strings.Join(images, ","),
+		},
+		PodSpec: k8s.PodSpec{
+			Labels: map[string]string{
+				"workloadType": workloadTypeImagePrepuller,
+				"prepuller":    name,
+			},
+			Annotations: map[string]string{
+				"sidecar.istio.io/inject": "false",
+			},
+			K8sPodSpec: kcore.PodSpec{
+				InitContainers: initContainers,
+				Containers: []kcore.Container{
+					{
+						Name:            "pause",
+						Image:           config.Cluster.ImageDownloader,
+						ImagePullPolicy: kcore.PullIfNotPresent,
+						Command:         []string{"/bin/sh", "-c", "sleep infinity"},
+						Resources: kcore.ResourceRequirements{
+							Requests: resourceList,
+						},
+					},
+				},
+				NodeSelector:                  nodeSelector,
+				Tolerations:                   tolerations,
+				TerminationGracePeriodSeconds: pointer.Int64(0),
+				ServiceAccountName:            "default",
+			},
+		},
+		Namespace: consts.K8sNamespace,
+	})
+}
+
+// updateImagePrepullers keeps the images of the current APIs cached on the worker nodes (or removes the pre-pullers if pre-pulling is disabled)
+func updateImagePrepullers(goCtx gocontext.Context) error {
+	var images, gpuImages []string
+	if config.Cluster.PrepullImages {
+		images, gpuImages = prepullerImages(CurrentContexts())
+	}
+
+	if err := updateImagePrepuller(goCtx, _imagePrepullerName, images, map[string]string{
+		"workload": "true",
+	}); err != nil {
+		return err
+	}
+
+	return updateImagePrepuller(goCtx, _imagePrepullerGPUName, gpuImages, map[string]string{
+		"workload":    "true",
+		_gpuNodeLabel: "true",
+	})
+}
+
+func updateImagePrepuller(goCtx gocontext.Context, name string, images []string, nodeSelector map[string]string) error {
+	imagesStr := strings.Join(images, ",")
+	if appliedImagesStr, ok := _appliedPrepullerImages[name]; ok && appliedImagesStr == imagesStr {
+		return nil
+	}
+
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return err
+	}
+
+	if len(images) == 0 {
+		if _, err := k8sClient.DeleteDaemonSet(name); err != nil {
+			return err
+		}
+	} else {
+		if _, err := k8sClient.ApplyDaemonSet(imagePrepullerSpec(name, images, nodeSelector)); err != nil {
+			return err
+		}
+	}
+
+	_appliedPrepullerImages[name] = imagesStr
+	return nil
+}
+
+// GetImageCacheStatuses returns how many of the images of the current APIs are cached on each worker node
+func GetImageCacheStatuses(goCtx gocontext.Context) ([]schema.NodeImageCache, error) {
+	if !config.Cluster.PrepullImages {
+		return nil, nil
+	}
+
+	images, gpuImages := prepullerImages(CurrentContexts())
+	if len(images) == 0 && len(gpuImages) == 0 {
+		return nil, nil
+	}
+
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := k8sClient.ListNodes(&kmeta.ListOptions{
+		LabelSelector: k8s.LabelSelector(map[string]string{
+			"workload": "true",
+		}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := k8sClient.ListPodsByLabel("workloadType", workloadTypeImagePrepuller)
+	if err != nil {
+		return nil, err
+	}
+
+	return imageCacheStatuses(nodes, pods, images, gpuImages), nil
+}
+
+func imageCacheStatuses(nodes []kcore.Node, pods []kcore.Pod, images []string, gpuImages []string) []schema.NodeImageCache {
+	cachedImages := map[string]strset.Set{} // node name -> images which were pulled by a pre-puller on the node
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if _, ok := cachedImages[pod.Spec.NodeName]; !ok {
+			cachedImages[pod.Spec.NodeName] = strset.New()
+		}
+		for _, containerStatus := range pod.Status.InitContainerStatuses {
+			if containerStatus.State.Terminated != nil && containerStatus.State.Terminated.ExitCode == 0 {
+				cachedImages[pod.Spec.NodeName].Add(initContainerImage(&pod, containerStatus.Name))
+			}
+		}
+	}
+
+	statuses := make([]schema.NodeImageCache, 0, len(nodes))
+	for _, node := range nodes {
+		expectedImages := strset.New(images...)
+		if node.Labels[_gpuNodeLabel] == "true" {
+			expectedImages.Add(gpuImages...)
+		}
+
+		status := schema.NodeImageCache{
+			NodeName: node.Name,
+			Images:   len(expectedImages),
+		}
+		if nodeCachedImages, ok := cachedImages[node.Name]; ok {
+			status.CachedImages = len(strset.Intersection(expectedImages, nodeCachedImages))
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].NodeName < statuses[j].NodeName
+	})
+	return statuses
+}
+
+// initContainerImage returns the image in the pod spec of the init container, since the image of a container status may have been resolved to a digest
+func initContainerImage(pod *kcore.Pod, containerName string) string {
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == containerName {
+			return container.Image
+		}
+	}
+	return ""
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	gocontext "context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kcore "k8s.io/api/core/v1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+func setTestClusterImages(prepullImages bool) func() {
+	prevCluster := config.Cluster
+	config.Cluster = &clusterconfig.InternalConfig{
+		Config: clusterconfig.Config{
+			PrepullImages:       prepullImages,
+			ImageDownloader:     "downloader",
+			ImageTFAPI:          "tf-api",
+			ImageTFServe:        "tf-serve",
+			ImageTFServeGPU:     "tf-serve-gpu",
+			ImagePythonServe:    "python-serve",
+			ImagePythonServeGPU: "python-serve-gpu",
+			ImageONNXServe:      "onnx-serve",
+			ImageONNXServeGPU:   "onnx-serve-gpu",
+		},
+	}
+	return func() {
+		config.Cluster = prevCluster
+	}
+}
+
+func testImagesContext(predictorTypes map[string]userconfig.PredictorType, gpus map[string]int64) *context.Context {
+	ctx := &context.Context{APIs: context.APIs{}}
+	for apiName, predictorType := range predictorTypes {
+		ctx.APIs[apiName] = &context.API{
+			API: &userconfig.API{
+				Predictor: &userconfig.Predictor{Type: predictorType},
+				Compute:   &userconfig.APICompute{GPU: gpus[apiName]},
+			},
+		}
+	}
+	return ctx
+}
+
+func testPrepullerPod(nodeName string, images []string, pulled int) kcore.Pod {
+	pod := kcore.Pod{
+		ObjectMeta: kmeta.ObjectMeta{
+			Labels: map[string]string{"workloadType": workloadTypeImagePrepuller},
+		},
+		Spec: kcore.PodSpec{NodeName: nodeName},
+	}
+	for i, image := range images {
+		name := "image-" + image
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, kcore.Container{Name: name, Image: image})
+		state := kcore.ContainerState{Waiting: &kcore.ContainerStateWaiting{Reason: "PodInitializing"}}
+		if i < pulled {
+			state = kcore.ContainerState{Terminated: &kcore.ContainerStateTerminated{ExitCode: 0}}
+		}
+		pod.Status.InitContainerStatuses = append(pod.Status.InitContainerStatuses, kcore.ContainerStatus{Name: name, Image: "docker.io/" + image + "@sha256:1234", State: state})
+	}
+	return pod
+}
+
+func TestPrepullerImages(t *testing.T) {
+	defer setTestClusterImages(true)()
+
+	images, gpuImages := prepullerImages(nil)
+	require.Empty(t, images)
+	require.Empty(t, gpuImages)
+
+	ctxs := []*context.Context{
+		testImagesContext(map[string]userconfig.PredictorType{
+			"tf":     userconfig.TensorFlowPredictorType,
+			"python": userconfig.PythonPredictorType,
+		}, nil),
+		testImagesContext(map[string]userconfig.PredictorType{
+			"tf":   userconfig.TensorFlowPredictorType,
+			"onnx": userconfig.ONNXPredictorType,
+		}, map[string]int64{"tf": 1, "onnx": 1}),
+	}
+
+	images, gpuImages = prepullerImages(ctxs)
+	require.Equal(t, []string{"downloader", "python-serve", "tf-api", "tf-serve"}, images)
+	// the downloader and tf-api images are already pulled on all worker nodes
+	require.Equal(t, []string{"onnx-serve-gpu", "tf-serve-gpu"}, gpuImages)
+}
+
+func TestImageCacheStatuses(t *testing.T) {
+	nodes := []kcore.Node{
+		testNode("node-b", "i-b", "2", "8Gi", map[string]string{"workload": "true", _gpuNodeLabel: "true"}),
+		testNode("node-a", "i-a", "2", "8Gi", map[string]string{"workload": "true"}),
+		testNode("node-c", "i-c", "2", "8Gi", map[string]string{"workload": "true"}),
+	}
+
+	images := []string{"downloader", "python-serve"}
+	gpuImages := []string{"python-serve-gpu"}
+
+	terminatingPod := testPrepullerPod("node-c", images, 2)
+	terminatingPod.DeletionTimestamp = &kmeta.Time{}
+
+	pods := []kcore.Pod{
+		testPrepullerPod("node-a", images, 2),
+		testPrepullerPod("node-b", images, 2),
+		testPrepullerPod("node-b", gpuImages, 0),
+		terminatingPod,
+		testPrepullerPod("", images, 0),
+	}
+
+	require.Equal(t, []schema.NodeImageCache{
+		{NodeName: "node-a", Images: 2, CachedImages: 2},
+		{NodeName: "node-b", Images: 3, CachedImages: 2},
+		{NodeName: "node-c", Images: 2, CachedImages: 0},
+	}, imageCacheStatuses(nodes, pods, images, gpuImages))
+
+	// a previously pre-pulled image which is no longer used isn't counted
+	require.Equal(t, 1, imageCacheStatuses(nodes, pods, []string{"downloader"}, nil)[0].CachedImages)
+}
+
+func TestUpdateImagePrepullers(t *testing.T) {
+	fake, closeFake := newFakeK8s(t)
+	defer closeFake()
+	defer func() {
+		_appliedPrepullerImages = map[string]string{}
+	}()
+	goCtx := gocontext.Background()
+
+	restoreCluster := setTestClusterImages(true)
+	defer restoreCluster()
+
+	setTestCurrentContexts(testImagesContext(map[string]userconfig.PredictorType{
+		"python": userconfig.PythonPredictorType,
+	}, nil))
+	defer setTestCurrentContexts()
+
+	require.NoError(t, updateImagePrepullers(goCtx))
+	require.Equal(t, []string{_imagePrepullerName}, fake.names("daemonsets"))
+
+	// the pre-puller isn't updated while the images are unchanged
+	requests := len(fake.requests)
+	require.NoError(t, updateImagePrepullers(goCtx))
+	require.Len(t, fake.requests, requests)
+
+	setTestCurrentContexts(testImagesContext(map[string]userconfig.PredictorType{
+		"python": userconfig.PythonPredictorType,
+	}, map[string]int64{"python": 1}))
+	require.NoError(t, updateImagePrepullers(goCtx))
+	require.Equal(t, []string{_imagePrepullerGPUName}, fake.names("daemonsets"))
+
+	config.Cluster.PrepullImages = false
+	require.NoError(t, updateImagePrepullers(goCtx))
+	require.Empty(t, fake.names("daemonsets"))
+}
+
+func setTestCurrentContexts(ctxs ...*context.Context) {
+	currentCtxs.Lock()
+	defer currentCtxs.Unlock()
+	currentCtxs.m = make(map[string]*context.Context)
+	for i, ctx := range ctxs {
+		currentCtxs.m[fmt.Sprintf("app-%d", i)] = ctx
+	}
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"bufio"
+	gocontext "context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	_authMetricsInterval = 1 * time.Minute
+	_envoyStatsPort      = 15090
+	_envoyStatsTimeout   = 5 * time.Second
+)
+
+// only the counters which are needed for the auth rejection metrics are kept by the sidecar (the stat names are matched before envoy extracts their tags)
+var _envoyStatsInclusionRegexps = []string{
+	`http\..*_` + defaultPortStr + `\.downstream_rq_4xx`,
+	`cluster\.inbound\|` + defaultPortStr + `\|.*\.upstream_rq_4xx`,
+}
+
+var _lastAuthMetricsCron time.Time
+
+// the sidecar counters of each API pod as of the previous scrape (pod UID -> counters)
+var _sidecarCounters = map[string]sidecarCounters{}
+
+type sidecarCounters struct {
+	downstream4xx float64 // 4xx responses sent to clients by the sidecar, including those from the API container
+	upstream4xx   float64 // 4xx responses received from the API container
+}
+
+func validateJWTAuth(ctx *context.Context) error {
+	if config.Cluster.MTLS != clusterconfig.MTLSDisabled {
+		return nil
+	}
+	for _, api := range ctx.APIs {
+		if api.JWTAuth != nil {
+			return errors.Wrap(ErrorJWTAuthRequiresMTLS(), userconfig.Identify(api), userconfig.JWTAuthKey)
+		}
+	}
+	return nil
+}
+
+// updateAuthRejectionMetrics publishes the requests which were rejected by the sidecars of APIs with jwt_auth (and therefore
+// never reached the API container) as 4XX status codes, alongside the status codes which the APIs report themselves
+func updateAuthRejectionMetrics(goCtx gocontext.Context, apiPods []kcore.Pod) error {
+	if time.Since(_lastAuthMetricsCron) < _authMetricsInterval {
+		return nil
+	}
+	_lastAuthMetricsCron = time.Now()
+
+	currentPodUIDs := map[string]bool{}
+	var errs []error
+
+	for _, pod := range apiPods {
+		if pod.Status.Phase != kcore.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+
+		ctx := CurrentContext(pod.Labels["appName"])
+		if ctx == nil {
+			continue
+		}
+		api := ctx.APIs[pod.Labels["apiName"]]
+		if api == nil || api.JWTAuth == nil || api.ID != pod.Labels["resourceID"] {
+			continue
+		}
+
+		podUID := string(pod.UID)
+		currentPodUIDs[podUID] = true
+
+		counters, err := scrapeSidecarCounters(goCtx, pod.Status.PodIP)
+		if err != nil {
+			errs = append(errs, errors.Wrap(err, pod.Name))
+			continue
+		}
+
+		prevCounters, ok := _sidecarCounters[podUID]
+		_sidecarCounters[podUID] = counters
+		if !ok {
+			continue // the first scrape of each pod (e.g. after the operator restarts) is only used as the baseline
+		}
+
+		rejected := int64(counters.downstream4xx-prevCounters.downstream4xx) - int64(counters.upstream4xx-prevCounters.upstream4xx)
+		if counters.downstream4xx < prevCounters.downstream4xx || counters.upstream4xx < prevCounters.upstream4xx {
+			rejected = int64(counters.downstream4xx) - int64(counters.upstream4xx) // the sidecar restarted
+		}
+		if rejected <= 0 {
+			continue
+		}
+
+		if err := publishStatusCodeMetric(ctx.App.Name, api, "4XX", rejected); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for podUID := range _sidecarCounters {
+		if !currentPodUIDs[podUID] {
+			delete(_sidecarCounters, podUID)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Wrap(errors.FirstError(errs...), "updating auth rejection metrics")
+	}
+	return nil
+}
+
+func scrapeSidecarCounters(goCtx gocontext.Context, podIP string) (sidecarCounters, error) {
+	goCtx, cancel := gocontext.WithTimeout(goCtx, _envoyStatsTimeout)
+	defer cancel()
+
+	url := fmt.Sprintf("http://%s/stats/prometheus", net.JoinHostPort(podIP, s.Int(_envoyStatsPort)))
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return sidecarCounters{}, errors.WithStack(err)
+	}
+
+	res, err := http.DefaultClient.Do(req.WithContext(goCtx))
+	if err != nil {
+		return sidecarCounters{}, errors.WithStack(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return sidecarCounters{}, errors.New(url, res.Status)
+	}
+
+	return parseSidecarCounters(res.Body)
+}
+
+// parseSidecarCounters reads the inbound 4xx counters from envoy's prometheus-formatted stats
+func parseSidecarCounters(stats io.Reader) (sidecarCounters, error) {
+	var counters sidecarCounters
+
+	scanner := bufio.NewScanner(stats)
+	for scanner.Scan() {
+		name, labels, value, ok := parsePrometheusSample(scanner.Text())
+		if !ok || labels["envoy_response_code_class"] != "4" {
+			continue
+		}
+
+		switch name {
+		case "envoy_http_downstream_rq_xx":
+			if strings.HasSuffix(labels["envoy_http_conn_manager_prefix"], "_"+defaultPortStr) {
+				counters.downstream4xx += value
+			}
+		case "envoy_cluster_upstream_rq_xx":
+			if strings.HasPrefix(labels["cluster_name"], "inbound|"+defaultPortStr+"|") {
+				counters.upstream4xx += value
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return sidecarCounters{}, errors.WithStack(err)
+	}
+
+	return counters, nil
+}
+
+// parses a line such as `envoy_cluster_upstream_rq_xx{envoy_response_code_class="4",cluster_name="inbound|8888|http|api"} 12`
+func parsePrometheusSample(line string) (string, map[string]string, float64, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil, 0, false
+	}
+
+	labels := map[string]string{}
+	name := line
+	rest := ""
+
+	if i := strings.Index(line, "{"); i >= 0 {
+		j := strings.LastIndex(line, "}")
+		if j < i {
+			return "", nil, 0, false
+		}
+		name = line[:i]
+		for _, pair := range strings.Split(line[i+1:j], ",") {
+			keyValue := strings.SplitN(pair, "=", 2)
+			if len(keyValue) != 2 {
+				continue
+			}
+			labels[strings.TrimSpace(keyValue[0])] = strings.Trim(strings.TrimSpace(keyValue[1]), `"`)
+		}
+		rest = line[j+1:]
+	} else {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return "", nil, 0, false
+		}
+		name = fields[0]
+		rest = strings.Join(fields[1:], " ")
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "", nil, 0, false
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return "", nil, 0, false
+	}
+
+	return name, labels, value, true
+}
+
+// publishStatusCodeMetric sends a StatusCode count to the node's statsd agent, with the same dimensions that the APIs use
+func publishStatusCodeMetric(appName string, api *context.API, code string, count int64) error {
+	hostIP := os.Getenv("HOST_IP")
+	if hostIP == "" {
+		return errors.New("unable to publish metrics: HOST_IP is not set") // unexpected
+	}
+
+	conn, err := net.Dial("udp", net.JoinHostPort(hostIP, s.Int(_statsdPort)))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer conn.Close()
+
+	// dogstatsd format, which the cloudwatch agent converts to dimensions
+	tags := []string{"AppName:" + appName, "APIName:" + api.Name, "APIID:" + api.ID, "Code:" + code}
+	if _, err := fmt.Fprintf(conn, "StatusCode:%d|c|#%s", count, strings.Join(tags, ",")); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const _testEnvoyStats = `# TYPE envoy_http_downstream_rq_xx counter
+envoy_http_downstream_rq_xx{envoy_response_code_class="2",envoy_http_conn_manager_prefix="10.0.1.5_8888"} 40
+envoy_http_downstream_rq_xx{envoy_response_code_class="4",envoy_http_conn_manager_prefix="10.0.1.5_8888"} 12
+envoy_http_downstream_rq_xx{envoy_response_code_class="4",envoy_http_conn_manager_prefix="10.0.1.5_15090"} 7
+# TYPE envoy_cluster_upstream_rq_xx counter
+envoy_cluster_upstream_rq_xx{envoy_response_code_class="4",cluster_name="inbound|8888|http|api-app.cortex.svc.cluster.local"} 3
+envoy_cluster_upstream_rq_xx{envoy_response_code_class="4",cluster_name="outbound|8888||api-app.cortex.svc.cluster.local"} 5
+envoy_cluster_upstream_rq_xx{envoy_response_code_class="5",cluster_name="inbound|8888|http|api-app.cortex.svc.cluster.local"} 1
+`
+
+func TestParseSidecarCounters(t *testing.T) {
+	counters, err := parseSidecarCounters(strings.NewReader(_testEnvoyStats))
+	require.NoError(t, err)
+	require.Equal(t, sidecarCounters{downstream4xx: 12, upstream4xx: 3}, counters)
+}
+
+func TestParsePrometheusSample(t *testing.T) {
+	name, labels, value, ok := parsePrometheusSample(`envoy_cluster_upstream_rq_xx{envoy_response_code_class="4",cluster_name="inbound|8888|http|api"} 12`)
+	require.True(t, ok)
+	require.Equal(t, "envoy_cluster_upstream_rq_xx", name)
+	require.Equal(t, map[string]string{"envoy_response_code_class": "4", "cluster_name": "inbound|8888|http|api"}, labels)
+	require.Equal(t, float64(12), value)
+
+	name, labels, value, ok = parsePrometheusSample("envoy_server_uptime 3600")
+	require.True(t, ok)
+	require.Equal(t, "envoy_server_uptime", name)
+	require.Empty(t, labels)
+	require.Equal(t, float64(3600), value)
+
+	for _, line := range []string{"", "# TYPE envoy_server_uptime gauge", "envoy_server_uptime", `envoy_x{a="b"} NaNx`} {
+		_, _, _, ok = parsePrometheusSample(line)
+		require.False(t, ok, line)
+	}
+}
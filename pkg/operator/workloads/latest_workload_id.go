@@ -17,6 +17,8 @@ limitations under the License.
 package workloads
 
 import (
+	gocontext "context"
+
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/parallel"
@@ -25,13 +27,13 @@ import (
 	ocontext "github.com/cortexlabs/cortex/pkg/operator/context"
 )
 
-func uploadLatestWorkloadID(resourceID string, workloadID string, appName string) error {
+func uploadLatestWorkloadID(goCtx gocontext.Context, resourceID string, workloadID string, appName string) error {
 	if isLatestWorkloadIDCached(resourceID, workloadID, appName) {
 		return nil
 	}
 
 	key := ocontext.LatestWorkloadIDKey(resourceID, appName)
-	err := config.AWS.UploadStringToS3(workloadID, key)
+	err := config.AWS.WithContext(goCtx).UploadStringToS3(workloadID, key)
 	if err != nil {
 		return errors.Wrap(err, "upload latest workload ID", appName, resourceID, workloadID)
 	}
@@ -39,33 +41,33 @@ func uploadLatestWorkloadID(resourceID string, workloadID string, appName string
 	return nil
 }
 
-func uploadLatestWorkloadIDs(resourceWorkloadIDs map[string]string, appName string) error {
+func uploadLatestWorkloadIDs(goCtx gocontext.Context, resourceWorkloadIDs map[string]string, appName string) error {
 	fns := make([]func() error, len(resourceWorkloadIDs))
 	i := 0
 	for resourceID, workloadID := range resourceWorkloadIDs {
-		fns[i] = uploadLatestWorkloadIDFunc(resourceID, workloadID, appName)
+		fns[i] = uploadLatestWorkloadIDFunc(goCtx, resourceID, workloadID, appName)
 		i++
 	}
 	return parallel.RunFirstErr(fns...)
 }
 
-func uploadLatestWorkloadIDFunc(resourceID string, workloadID string, appName string) func() error {
+func uploadLatestWorkloadIDFunc(goCtx gocontext.Context, resourceID string, workloadID string, appName string) func() error {
 	return func() error {
-		return uploadLatestWorkloadID(resourceID, workloadID, appName)
+		return uploadLatestWorkloadID(goCtx, resourceID, workloadID, appName)
 	}
 }
 
-func GetLatestWorkloadID(resourceID string, appName string) (string, error) {
-	return getSavedLatestWorkloadID(resourceID, appName)
+func GetLatestWorkloadID(goCtx gocontext.Context, resourceID string, appName string) (string, error) {
+	return getSavedLatestWorkloadID(goCtx, resourceID, appName)
 }
 
-func getSavedLatestWorkloadID(resourceID string, appName string) (string, error) {
+func getSavedLatestWorkloadID(goCtx gocontext.Context, resourceID string, appName string) (string, error) {
 	if cachedWorkloadID, ok := getCachedLatestWorkloadID(resourceID, appName); ok {
 		return cachedWorkloadID, nil
 	}
 
 	key := ocontext.LatestWorkloadIDKey(resourceID, appName)
-	workloadID, err := config.AWS.ReadStringFromS3(key)
+	workloadID, err := config.AWS.WithContext(goCtx).ReadStringFromS3(key)
 	if aws.IsNoSuchKeyErr(err) {
 		cacheEmptyLatestWorkloadID(resourceID, appName)
 		return "", nil
@@ -77,12 +79,12 @@ func getSavedLatestWorkloadID(resourceID string, appName string) (string, error)
 	return workloadID, nil
 }
 
-func getSavedLatestWorkloadIDs(resourceIDs strset.Set, appName string) (map[string]string, error) {
+func getSavedLatestWorkloadIDs(goCtx gocontext.Context, resourceIDs strset.Set, appName string) (map[string]string, error) {
 	resourceIDList := resourceIDs.Slice()
 	workloadIDList := make([]string, len(resourceIDList))
 	fns := make([]func() error, len(resourceIDList))
 	for i, resourceID := range resourceIDList {
-		fns[i] = getSavedLatestWorkloadIDFunc(resourceID, appName, workloadIDList, i)
+		fns[i] = getSavedLatestWorkloadIDFunc(goCtx, resourceID, appName, workloadIDList, i)
 	}
 	err := parallel.RunFirstErr(fns...)
 	if err != nil {
@@ -96,9 +98,9 @@ func getSavedLatestWorkloadIDs(resourceIDs strset.Set, appName string) (map[stri
 	return workloadIDMap, nil
 }
 
-func getSavedLatestWorkloadIDFunc(resourceID string, appName string, workloadIDs []string, i int) func() error {
+func getSavedLatestWorkloadIDFunc(goCtx gocontext.Context, resourceID string, appName string, workloadIDs []string, i int) func() error {
 	return func() error {
-		workloadID, err := getSavedLatestWorkloadID(resourceID, appName)
+		workloadID, err := getSavedLatestWorkloadID(goCtx, resourceID, appName)
 		if err != nil {
 			return err
 		}
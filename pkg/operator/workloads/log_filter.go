@@ -0,0 +1,100 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/regex"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+// LogLevels are the levels of structured log records, from least to most severe
+var LogLevels = []string{"DEBUG", "INFO", "WARNING", "ERROR", "CRITICAL"}
+
+// LogFilterPattern translates `key=value` filters and a minimum level into a CloudWatch filter pattern which matches structured log records (an empty pattern matches all records)
+func LogFilterPattern(filters []string, minLevel string) (string, error) {
+	var conditions []string
+
+	for _, filter := range filters {
+		condition, err := logFilterCondition(filter)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	if minLevel != "" {
+		levels, err := logLevelsAtLeast(minLevel)
+		if err != nil {
+			return "", err
+		}
+		var levelConditions []string
+		for _, level := range levels {
+			levelConditions = append(levelConditions, fmt.Sprintf(`$.level = "%s"`, level))
+		}
+		conditions = append(conditions, "("+strings.Join(levelConditions, " || ")+")")
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "{ " + strings.Join(conditions, " && ") + " }", nil
+}
+
+func logFilterCondition(filter string) (string, error) {
+	parts := strings.SplitN(filter, "=", 2)
+	if len(parts) != 2 {
+		return "", ErrorInvalidLogFilter(filter, "it must be in the form key=value")
+	}
+	key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+
+	// nested fields can be selected with dots (e.g. user.id)
+	for _, keyPart := range strings.Split(key, ".") {
+		if keyPart == "" || !regex.IsAlphaNumericDashUnderscore(keyPart) {
+			return "", ErrorInvalidLogFilter(filter, "keys can only contain letters, numbers, dashes, underscores and dots")
+		}
+	}
+	if value == "" {
+		return "", ErrorInvalidLogFilter(filter, "the value cannot be empty")
+	}
+	if strings.ContainsAny(value, `"\`) {
+		return "", ErrorInvalidLogFilter(filter, "the value cannot contain quotes or backslashes")
+	}
+
+	// the value may have been logged as a number or as a string
+	if _, ok := s.ParseFloat64(value); ok {
+		return fmt.Sprintf(`($.%s = %s || $.%s = "%s")`, key, value, key, value), nil
+	}
+	return fmt.Sprintf(`$.%s = "%s"`, key, value), nil
+}
+
+// logLevelsAtLeast returns minLevel and all of the more severe levels
+func logLevelsAtLeast(minLevel string) ([]string, error) {
+	normalizedLevel := strings.ToUpper(strings.TrimSpace(minLevel))
+	if normalizedLevel == "WARN" {
+		normalizedLevel = "WARNING"
+	}
+
+	for i, level := range LogLevels {
+		if level == normalizedLevel {
+			return LogLevels[i:], nil
+		}
+	}
+	return nil, ErrorInvalidLogLevel(minLevel)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogFilterPattern(t *testing.T) {
+	for _, c := range []struct {
+		filters  []string
+		level    string
+		expected string
+	}{
+		{
+			expected: "",
+		},
+		{
+			filters:  []string{"request_id=7f3a"},
+			expected: `{ $.request_id = "7f3a" }`,
+		},
+		{
+			filters:  []string{" customer.id = 123 ", "region=us-*"},
+			expected: `{ ($.customer.id = 123 || $.customer.id = "123") && $.region = "us-*" }`,
+		},
+		{
+			level:    "error",
+			expected: `{ ($.level = "ERROR" || $.level = "CRITICAL") }`,
+		},
+		{
+			filters:  []string{"replica=api-5d8f"},
+			level:    "Warn",
+			expected: `{ $.replica = "api-5d8f" && ($.level = "WARNING" || $.level = "ERROR" || $.level = "CRITICAL") }`,
+		},
+		{
+			level:    "critical",
+			expected: `{ ($.level = "CRITICAL") }`,
+		},
+	} {
+		pattern, err := LogFilterPattern(c.filters, c.level)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, pattern)
+	}
+}
+
+func TestLogFilterPatternErrors(t *testing.T) {
+	for _, filter := range []string{"request_id", "=7f3a", "request_id=", "request id=7f3a", "a..b=1", "$.a=1", `a=say "hi"`, `a=b\c`, "a[0]=1"} {
+		_, err := LogFilterPattern([]string{filter}, "")
+		require.Error(t, err, filter)
+	}
+
+	_, err := LogFilterPattern(nil, "fatal")
+	require.Error(t, err)
+}
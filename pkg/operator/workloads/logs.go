@@ -17,7 +17,6 @@ limitations under the License.
 package workloads
 
 import (
-	"encoding/json"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -74,10 +73,10 @@ func (c *eventCache) Add(eventID string) {
 	c.eventQueue.PushRight(eventID)
 }
 
-func ReadLogs(appName string, podLabels map[string]string, socket *websocket.Conn) {
+func ReadLogs(appName string, podLabels map[string]string, filterPattern string, socket *websocket.Conn) {
 	podCheckCancel := make(chan struct{})
 	defer close(podCheckCancel)
-	go StreamFromCloudWatch(podCheckCancel, appName, podLabels, socket)
+	go StreamFromCloudWatch(podCheckCancel, appName, podLabels, filterPattern, socket)
 	pumpStdin(socket)
 	podCheckCancel <- struct{}{}
 }
@@ -92,11 +91,8 @@ func pumpStdin(socket *websocket.Conn) {
 	}
 }
 
-type FluentdLog struct {
-	Log string `json:"log"`
-}
-
-func StreamFromCloudWatch(podCheckCancel chan struct{}, appName string, podLabels map[string]string, socket *websocket.Conn) {
+// StreamFromCloudWatch writes the log records which match filterPattern (see LogFilterPattern()) to socket; each record is written as the json object which fluentd sent to CloudWatch, which the CLI formats
+func StreamFromCloudWatch(podCheckCancel chan struct{}, appName string, podLabels map[string]string, filterPattern string, socket *websocket.Conn) {
 	timer := time.NewTimer(0)
 	defer timer.Stop()
 
@@ -176,13 +172,17 @@ func StreamFromCloudWatch(podCheckCancel chan struct{}, appName string, podLabel
 
 			endTime := libtime.ToMillis(time.Now())
 
-			logEventsOutput, err := config.AWS.CloudWatchLogsClient.FilterLogEvents(&cloudwatchlogs.FilterLogEventsInput{
+			filterLogEventsInput := &cloudwatchlogs.FilterLogEventsInput{
 				LogGroupName:   aws.String(logGroupName),
 				LogStreamNames: aws.StringSlice(logStreamNames.Slice()),
 				StartTime:      aws.Int64(libtime.ToMillis(lastLogTime.Add(-pollPeriod))),
 				EndTime:        aws.Int64(endTime),
 				Limit:          aws.Int64(int64(maxLogLinesPerRequest)),
-			})
+			}
+			if filterPattern != "" {
+				filterLogEventsInput.FilterPattern = aws.String(filterPattern)
+			}
+			logEventsOutput, err := config.AWS.CloudWatchLogsClient.FilterLogEvents(filterLogEventsInput)
 
 			if err != nil {
 				if !awslib.CheckErrCode(err, cloudwatchlogs.ErrCodeResourceNotFoundException) {
@@ -193,10 +193,8 @@ func StreamFromCloudWatch(podCheckCancel chan struct{}, appName string, podLabel
 
 			lastLogTimestampMillis := libtime.ToMillis(lastLogTime)
 			for _, logEvent := range logEventsOutput.Events {
-				var log FluentdLog
-				json.Unmarshal([]byte(*logEvent.Message), &log)
 				if !eventCache.Has(*logEvent.EventId) {
-					socket.WriteMessage(websocket.TextMessage, []byte(log.Log))
+					socket.WriteMessage(websocket.TextMessage, []byte(*logEvent.Message))
 					if *logEvent.Timestamp > lastLogTimestampMillis {
 						lastLogTimestampMillis = *logEvent.Timestamp
 					}
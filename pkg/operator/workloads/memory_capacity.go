@@ -17,6 +17,8 @@ limitations under the License.
 package workloads
 
 import (
+	gocontext "context"
+
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	"github.com/cortexlabs/cortex/pkg/operator/config"
@@ -27,13 +29,18 @@ import (
 const memConfigMapName = "cortex-instance-memory"
 const memConfigMapKey = "capacity"
 
-func GetMemoryCapacityFromNodes() (*kresource.Quantity, error) {
+func GetMemoryCapacityFromNodes(goCtx gocontext.Context) (*kresource.Quantity, error) {
 	opts := kmeta.ListOptions{
 		LabelSelector: k8s.LabelSelector(map[string]string{
 			"workload": "true",
 		}),
 	}
-	nodes, err := config.Kubernetes.ListNodes(&opts)
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := k8sClient.ListNodes(&opts)
 	if err != nil {
 		return nil, err
 	}
@@ -54,8 +61,13 @@ func GetMemoryCapacityFromNodes() (*kresource.Quantity, error) {
 	return minMem, nil
 }
 
-func GetMemoryCapacityFromConfigMap() (*kresource.Quantity, error) {
-	configMapData, err := config.Kubernetes.GetConfigMapData(memConfigMapName)
+func GetMemoryCapacityFromConfigMap(goCtx gocontext.Context) (*kresource.Quantity, error) {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	configMapData, err := k8sClient.GetConfigMapData(memConfigMapName)
 	if err != nil {
 		return nil, err
 	}
@@ -72,14 +84,14 @@ func GetMemoryCapacityFromConfigMap() (*kresource.Quantity, error) {
 	return &mem, nil
 }
 
-func UpdateMemoryCapacityConfigMap() (*kresource.Quantity, error) {
+func UpdateMemoryCapacityConfigMap(goCtx gocontext.Context) (*kresource.Quantity, error) {
 	memFromConfig := config.Cluster.InstanceMetadata.Memory
-	memFromNodes, err := GetMemoryCapacityFromNodes()
+	memFromNodes, err := GetMemoryCapacityFromNodes(goCtx)
 	if err != nil {
 		return nil, err
 	}
 
-	memFromConfigMap, err := GetMemoryCapacityFromConfigMap()
+	memFromConfigMap, err := GetMemoryCapacityFromConfigMap(goCtx)
 	if err != nil {
 		return nil, err
 	}
@@ -103,7 +115,12 @@ func UpdateMemoryCapacityConfigMap() (*kresource.Quantity, error) {
 			},
 		})
 
-		_, err := config.Kubernetes.ApplyConfigMap(configMap)
+		k8sClient, err := config.Kubernetes.WithContext(goCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = k8sClient.ApplyConfigMap(configMap)
 		if err != nil {
 			return nil, err
 		}
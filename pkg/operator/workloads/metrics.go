@@ -17,6 +17,7 @@ limitations under the License.
 package workloads
 
 import (
+	gocontext "context"
 	"encoding/base64"
 	"fmt"
 	"path/filepath"
@@ -36,10 +37,10 @@ import (
 	"github.com/cortexlabs/cortex/pkg/operator/config"
 )
 
-func GetMetrics(ctx *context.Context, apiName string) (schema.APIMetrics, error) {
+func GetMetrics(goCtx gocontext.Context, ctx *context.Context, apiName string) (schema.APIMetrics, error) {
 	api := ctx.APIs[apiName]
 
-	apiSavedStatus, err := getAPISavedStatus(api.ID, api.WorkloadID, ctx.App.Name)
+	apiSavedStatus, err := getAPISavedStatus(goCtx, api.ID, api.WorkloadID, ctx.App.Name)
 	if err != nil {
 		return schema.APIMetrics{}, err
 	}
@@ -63,7 +64,7 @@ func GetMetrics(ctx *context.Context, apiName string) (schema.APIMetrics, error)
 
 	requestList := []func() error{}
 	if realTimeStart.Before(realTimeEnd) {
-		requestList = append(requestList, getAPIMetricsFunc(ctx, api, 1, &realTimeStart, &realTimeEnd, &realTimeMetrics))
+		requestList = append(requestList, getAPIMetricsFunc(goCtx, ctx, api, 1, &realTimeStart, &realTimeEnd, &realTimeMetrics))
 	}
 
 	if apiStartTime.Before(realTimeStart) {
@@ -76,7 +77,7 @@ func GetMetrics(ctx *context.Context, apiName string) (schema.APIMetrics, error)
 		} else {
 			batchStart = twoWeeksAgo
 		}
-		requestList = append(requestList, getAPIMetricsFunc(ctx, api, 60*60, &batchStart, &batchEnd, &batchMetrics))
+		requestList = append(requestList, getAPIMetricsFunc(goCtx, ctx, api, 60*60, &batchStart, &batchEnd, &batchMetrics))
 	}
 
 	if len(requestList) != 0 {
@@ -90,9 +91,9 @@ func GetMetrics(ctx *context.Context, apiName string) (schema.APIMetrics, error)
 	return mergedMetrics, nil
 }
 
-func getAPIMetricsFunc(ctx *context.Context, api *context.API, period int64, startTime *time.Time, endTime *time.Time, apiMetrics *schema.APIMetrics) func() error {
+func getAPIMetricsFunc(goCtx gocontext.Context, ctx *context.Context, api *context.API, period int64, startTime *time.Time, endTime *time.Time, apiMetrics *schema.APIMetrics) func() error {
 	return func() error {
-		metricDataResults, err := queryMetrics(ctx, api, period, startTime, endTime)
+		metricDataResults, err := queryMetrics(goCtx, ctx, api, period, startTime, endTime)
 		if err != nil {
 			return err
 		}
@@ -117,12 +118,12 @@ func getAPIMetricsFunc(ctx *context.Context, api *context.API, period int64, sta
 	}
 }
 
-func queryMetrics(ctx *context.Context, api *context.API, period int64, startTime *time.Time, endTime *time.Time) ([]*cloudwatch.MetricDataResult, error) {
+func queryMetrics(goCtx gocontext.Context, ctx *context.Context, api *context.API, period int64, startTime *time.Time, endTime *time.Time) ([]*cloudwatch.MetricDataResult, error) {
 	allMetrics := getNetworkStatsDef(ctx.App.Name, api, period)
 
 	if api.Tracker != nil {
 		if api.Tracker.ModelType == userconfig.ClassificationModelType {
-			classMetrics, err := getClassesMetricDef(ctx, api, period)
+			classMetrics, err := getClassesMetricDef(goCtx, ctx, api, period)
 			if err != nil {
 				return nil, err
 			}
@@ -138,7 +139,7 @@ func queryMetrics(ctx *context.Context, api *context.API, period int64, startTim
 		StartTime:         startTime,
 		MetricDataQueries: allMetrics,
 	}
-	output, err := config.AWS.CloudWatchMetrics.GetMetricData(&metricsDataQuery)
+	output, err := config.AWS.WithContext(goCtx).CloudWatchMetrics.GetMetricData(&metricsDataQuery)
 	if err != nil {
 		return nil, err
 	}
@@ -367,9 +368,9 @@ func getNetworkStatsDef(appName string, api *context.API, period int64) []*cloud
 	return networkDataQueries
 }
 
-func getClassesMetricDef(ctx *context.Context, api *context.API, period int64) ([]*cloudwatch.MetricDataQuery, error) {
+func getClassesMetricDef(goCtx gocontext.Context, ctx *context.Context, api *context.API, period int64) ([]*cloudwatch.MetricDataQuery, error) {
 	prefix := filepath.Join(ctx.MetadataRoot, api.ID, "classes") + "/"
-	classes, err := config.AWS.ListPrefix(prefix, int64(consts.MaxClassesPerRequest))
+	classes, err := config.AWS.WithContext(goCtx).ListPrefix(prefix, int64(consts.MaxClassesPerRequest))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,441 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	gocontext "context"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	kcore "k8s.io/api/core/v1"
+	knetworking "k8s.io/api/networking/v1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	_awsServiceCIDRsTTL       = 6 * time.Hour
+	_statsdPort               = 8125
+	_egressRefreshInterval    = 10 * time.Minute
+	_resolvedEgressAddressTTL = 6 * _egressRefreshInterval
+)
+
+// the hosts which pip installs packages from when no package index is configured
+var _pypiHosts = []string{"pypi.org", "files.pythonhosted.org"}
+
+// AWS service CIDRs which API pods can always reach (S3 for the downloader and the AWS client, CloudWatch for monitoring)
+var _awsServiceCIDRs = struct {
+	cidrs       []string
+	lastUpdated time.Time
+	sync.Mutex
+}{}
+
+// the addresses which each egress hostname has recently resolved to (hostname -> CIDR -> when it was last resolved), since CDNs
+// rotate the addresses which they return, and a replica may still use a previously resolved address; addresses which haven't
+// been returned for _resolvedEgressAddressTTL are dropped
+var _resolvedEgressHosts = struct {
+	m map[string]map[string]time.Time
+	sync.Mutex
+}{m: map[string]map[string]time.Time{}}
+
+var _lastEgressRefreshCron time.Time
+var _lastEgressRefreshNodeIPs strset.Set
+
+// awsServiceCIDRs returns an error if the AWS ip ranges couldn't be fetched and haven't been fetched before, in which case the
+// network policies can't be created (rather than allowing HTTPS egress to any address)
+func awsServiceCIDRs() ([]string, error) {
+	_awsServiceCIDRs.Lock()
+	defer _awsServiceCIDRs.Unlock()
+
+	if _awsServiceCIDRs.cidrs != nil && time.Since(_awsServiceCIDRs.lastUpdated) < _awsServiceCIDRsTTL {
+		return _awsServiceCIDRs.cidrs, nil
+	}
+
+	s3CIDRs, err := aws.ServiceCIDRs("S3", "")
+	if err == nil {
+		var cloudWatchCIDRs []string
+		cloudWatchCIDRs, err = aws.ServiceCIDRs("CLOUDWATCH", *config.Cluster.Region)
+		if err == nil {
+			_awsServiceCIDRs.cidrs = append(s3CIDRs, cloudWatchCIDRs...)
+			_awsServiceCIDRs.lastUpdated = time.Now()
+			return _awsServiceCIDRs.cidrs, nil
+		}
+	}
+
+	// fall back to the previously fetched ranges if they exist
+	if _awsServiceCIDRs.cidrs != nil {
+		return _awsServiceCIDRs.cidrs, nil
+	}
+
+	return nil, errors.Wrap(err, "unable to determine the ip ranges of the aws services which apis need access to")
+}
+
+// egressDestinations returns the API's egress field, and the package indexes which pip installs requirements.txt from
+func egressDestinations(ctx *context.Context, api *context.API) []string {
+	destinations := append([]string{}, api.Egress...)
+	if !ctx.HasRequirements {
+		return destinations
+	}
+
+	index := pythonPackageIndex(api)
+	if index == nil || index.URL == nil {
+		destinations = append(destinations, _pypiHosts...)
+	}
+	if index != nil {
+		indexURLs := index.ExtraURLs
+		if index.URL != nil {
+			indexURLs = append([]string{*index.URL}, indexURLs...)
+		}
+		for _, indexURL := range indexURLs {
+			if parsedURL, err := url.Parse(indexURL); err == nil && parsedURL.Hostname() != "" {
+				destinations = append(destinations, parsedURL.Hostname())
+			}
+		}
+	}
+
+	return slices.UniqueStrings(destinations)
+}
+
+// Resolves the API's egress destinations to CIDR blocks (hostnames are resolved to /32s, and are re-resolved by the cron)
+func resolveEgressCIDRs(goCtx gocontext.Context, destinations []string) ([]string, error) {
+	cidrs := strset.New()
+	for _, destination := range destinations {
+		if userconfig.IsEgressCIDR(destination) {
+			cidrs.Add(destination)
+			continue
+		}
+
+		ips, err := net.DefaultResolver.LookupIPAddr(goCtx, destination)
+		if err != nil {
+			if goCtx.Err() != nil {
+				return nil, errors.WithStack(goCtx.Err())
+			}
+			return nil, ErrorUnableToResolveEgressHost(destination)
+		}
+		resolved := strset.New()
+		for _, ip := range ips {
+			if ip.IP.To4() != nil {
+				resolved.Add(ip.IP.String() + "/32")
+			}
+		}
+		if len(resolved) == 0 {
+			return nil, ErrorUnableToResolveEgressHost(destination)
+		}
+
+		cidrs.Merge(recordResolvedEgressHost(destination, resolved, time.Now()))
+	}
+	cidrSlice := cidrs.Slice()
+	sort.Strings(cidrSlice)
+	return cidrSlice, nil
+}
+
+// recordResolvedEgressHost records the addresses which the hostname resolved to, and returns all of its addresses which
+// haven't expired
+func recordResolvedEgressHost(hostname string, resolved strset.Set, now time.Time) strset.Set {
+	_resolvedEgressHosts.Lock()
+	defer _resolvedEgressHosts.Unlock()
+
+	if _resolvedEgressHosts.m[hostname] == nil {
+		_resolvedEgressHosts.m[hostname] = map[string]time.Time{}
+	}
+	for cidr := range resolved {
+		_resolvedEgressHosts.m[hostname][cidr] = now
+	}
+
+	cidrs := strset.New()
+	for cidr, lastResolved := range _resolvedEgressHosts.m[hostname] {
+		if now.Sub(lastResolved) > _resolvedEgressAddressTTL {
+			delete(_resolvedEgressHosts.m[hostname], cidr)
+			continue
+		}
+		cidrs.Add(cidr)
+	}
+	return cidrs
+}
+
+// pruneResolvedEgressHosts drops the addresses which have expired, and the hostnames which no longer have any addresses
+// (e.g. because the APIs which used them were deleted)
+func pruneResolvedEgressHosts(now time.Time) {
+	_resolvedEgressHosts.Lock()
+	defer _resolvedEgressHosts.Unlock()
+
+	for hostname, addresses := range _resolvedEgressHosts.m {
+		for cidr, lastResolved := range addresses {
+			if now.Sub(lastResolved) > _resolvedEgressAddressTTL {
+				delete(addresses, cidr)
+			}
+		}
+		if len(addresses) == 0 {
+			delete(_resolvedEgressHosts.m, hostname)
+		}
+	}
+}
+
+func egressIPBlock(cidr string) *knetworking.IPBlock {
+	ipBlock := &knetworking.IPBlock{CIDR: cidr}
+
+	_, ipNet, _ := net.ParseCIDR(cidr)
+	metadataIP, _, _ := net.ParseCIDR(userconfig.InstanceMetadataCIDR)
+	if ipNet != nil && ipNet.Contains(metadataIP) {
+		ipBlock.Except = []string{userconfig.InstanceMetadataCIDR}
+	}
+
+	return ipBlock
+}
+
+// egressAllowListStr is a human-readable summary of the API's egress allow-list, which is printed in the API's logs
+func egressAllowListStr(ctx *context.Context, api *context.API) string {
+	return strings.Join(append([]string{"s3", "cloudwatch"}, egressDestinations(ctx, api)...), ", ")
+}
+
+// workerNodeIPs returns the addresses of the worker nodes, whose statsd agents the APIs send metrics to
+func workerNodeIPs(k8sClient *k8s.Client) ([]string, error) {
+	nodes, err := k8sClient.ListNodes(&kmeta.ListOptions{
+		LabelSelector: k8s.LabelSelector(map[string]string{
+			"workload": "true",
+		}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ips := strset.New()
+	for _, node := range nodes {
+		for _, address := range node.Status.Addresses {
+			if address.Type == kcore.NodeInternalIP {
+				ips.Add(address.Address)
+			}
+		}
+	}
+	ipSlice := ips.Slice()
+	sort.Strings(ipSlice)
+	return ipSlice, nil
+}
+
+func networkPolicySpec(ctx *context.Context, api *context.API, awsCIDRs []string, egressCIDRs []string, nodeIPs []string) *knetworking.NetworkPolicy {
+	tcp := kcore.ProtocolTCP
+	udp := kcore.ProtocolUDP
+	dnsPort := intstr.FromInt(53)
+	httpsPort := intstr.FromInt(443)
+	statsdPort := intstr.FromInt(_statsdPort)
+	apiPort := intstr.FromInt(int(defaultPortInt32))
+	envoyStatsPort := intstr.FromInt(_envoyStatsPort)
+
+	awsPeers := make([]knetworking.NetworkPolicyPeer, len(awsCIDRs))
+	for i, cidr := range awsCIDRs {
+		awsPeers[i] = knetworking.NetworkPolicyPeer{IPBlock: egressIPBlock(cidr)}
+	}
+
+	// the statsd agents are reached via their host port, so both the node's address and the agent's pod (which the host port
+	// forwards to) are allowed
+	statsdPeers := []knetworking.NetworkPolicyPeer{
+		{
+			PodSelector: &kmeta.LabelSelector{
+				MatchLabels: map[string]string{"name": "cloudwatch-agent-statsd"},
+			},
+		},
+	}
+	for _, nodeIP := range nodeIPs {
+		statsdPeers = append(statsdPeers, knetworking.NetworkPolicyPeer{IPBlock: &knetworking.IPBlock{CIDR: nodeIP + "/32"}})
+	}
+
+	istioSystemSelector := &kmeta.LabelSelector{
+		MatchLabels: map[string]string{"name": "istio-system"},
+	}
+
+	egressRules := []knetworking.NetworkPolicyEgressRule{
+		{
+			Ports: []knetworking.NetworkPolicyPort{
+				{Protocol: &udp, Port: &dnsPort},
+				{Protocol: &tcp, Port: &dnsPort},
+			},
+		},
+		{
+			// statsd runs as a daemonset on each node's host port
+			Ports: []knetworking.NetworkPolicyPort{
+				{Protocol: &udp, Port: &statsdPort},
+			},
+			To: statsdPeers,
+		},
+		{
+			Ports: []knetworking.NetworkPolicyPort{
+				{Protocol: &tcp, Port: &httpsPort},
+			},
+			To: awsPeers,
+		},
+		{
+			// istio control plane (for the sidecar when mutual TLS is enabled)
+			To: []knetworking.NetworkPolicyPeer{
+				{NamespaceSelector: istioSystemSelector},
+			},
+		},
+	}
+
+	if len(egressCIDRs) > 0 {
+		userPeers := make([]knetworking.NetworkPolicyPeer, len(egressCIDRs))
+		for i, cidr := range egressCIDRs {
+			userPeers[i] = knetworking.NetworkPolicyPeer{IPBlock: egressIPBlock(cidr)}
+		}
+		egressRules = append(egressRules, knetworking.NetworkPolicyEgressRule{To: userPeers})
+	}
+
+	return k8s.NetworkPolicy(&k8s.NetworkPolicySpec{
+		Name:      internalAPIName(api.Name, ctx.App.Name),
+		Namespace: consts.K8sNamespace,
+		PodSelector: map[string]string{
+			"appName":      ctx.App.Name,
+			"workloadType": workloadTypeAPI,
+			"apiName":      api.Name,
+		},
+		Ingress: []knetworking.NetworkPolicyIngressRule{
+			{
+				// only the apis ingress gateway can reach the API
+				From: []knetworking.NetworkPolicyPeer{
+					{
+						NamespaceSelector: istioSystemSelector,
+						PodSelector: &kmeta.LabelSelector{
+							MatchLabels: map[string]string{"istio": "apis-ingressgateway"},
+						},
+					},
+				},
+				Ports: []knetworking.NetworkPolicyPort{
+					{Protocol: &tcp, Port: &apiPort},
+				},
+			},
+			{
+				// the operator scrapes the sidecar's request counters (for the auth rejection metrics)
+				From: []knetworking.NetworkPolicyPeer{
+					{
+						PodSelector: &kmeta.LabelSelector{
+							MatchLabels: map[string]string{"workloadType": "operator"},
+						},
+					},
+				},
+				Ports: []knetworking.NetworkPolicyPort{
+					{Protocol: &tcp, Port: &envoyStatsPort},
+				},
+			},
+		},
+		Egress: egressRules,
+		Labels: map[string]string{
+			"appName":      ctx.App.Name,
+			"workloadType": workloadTypeAPI,
+			"apiName":      api.Name,
+			"resourceID":   api.ID,
+		},
+		Annotations: map[string]string{
+			"cortex.dev/egress": egressAllowListStr(ctx, api),
+		},
+	})
+}
+
+func applyNetworkPolicy(goCtx gocontext.Context, ctx *context.Context, api *context.API) error {
+	awsCIDRs, err := awsServiceCIDRs()
+	if err != nil {
+		return err
+	}
+
+	egressCIDRs, err := resolveEgressCIDRs(goCtx, egressDestinations(ctx, api))
+	if err != nil {
+		return errors.Wrap(err, userconfig.Identify(api), userconfig.EgressKey)
+	}
+
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return err
+	}
+
+	nodeIPs, err := workerNodeIPs(k8sClient)
+	if err != nil {
+		return err
+	}
+
+	_, err = k8sClient.ApplyNetworkPolicy(networkPolicySpec(ctx, api, awsCIDRs, egressCIDRs, nodeIPs))
+	return err
+}
+
+// refreshNetworkPolicies re-resolves the egress hostnames of the running APIs (whose addresses may change after they are
+// deployed) and refreshes the AWS ip ranges, and updates the policies right away when worker nodes are added or removed
+func refreshNetworkPolicies(goCtx gocontext.Context) error {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return err
+	}
+
+	nodeIPs, err := workerNodeIPs(k8sClient)
+	if err != nil {
+		return err
+	}
+	nodesChanged := !strset.New(nodeIPs...).IsEqual(_lastEgressRefreshNodeIPs)
+
+	if !nodesChanged && time.Since(_lastEgressRefreshCron) < _egressRefreshInterval {
+		return nil
+	}
+	_lastEgressRefreshCron = time.Now()
+	_lastEgressRefreshNodeIPs = strset.New(nodeIPs...)
+
+	pruneResolvedEgressHosts(time.Now())
+
+	awsCIDRs, err := awsServiceCIDRs()
+	if err != nil {
+		return errors.Wrap(err, "refreshing network policies")
+	}
+
+	var errs []error
+	for _, ctx := range CurrentContexts() {
+		for _, api := range ctx.APIs {
+			// the policy is only updated (rather than created), since the API may be in the process of being deleted
+			existing, err := k8sClient.GetNetworkPolicy(internalAPIName(api.Name, ctx.App.Name))
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if existing == nil || existing.Labels["resourceID"] != api.ID {
+				continue
+			}
+
+			egressCIDRs, err := resolveEgressCIDRs(goCtx, egressDestinations(ctx, api))
+			if err != nil {
+				errs = append(errs, errors.Wrap(err, userconfig.Identify(api), userconfig.EgressKey))
+				continue
+			}
+
+			if _, err := k8sClient.ApplyNetworkPolicy(networkPolicySpec(ctx, api, awsCIDRs, egressCIDRs, nodeIPs)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Wrap(errors.FirstError(errs...), "refreshing network policies")
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	knetworking "k8s.io/api/networking/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+func TestEgressDestinations(t *testing.T) {
+	prevCluster := config.Cluster
+	config.Cluster = &clusterconfig.InternalConfig{}
+	defer func() {
+		config.Cluster = prevCluster
+	}()
+
+	api := &context.API{API: &userconfig.API{Egress: []string{"10.0.0.0/16", "pypi.org"}}}
+
+	require.Equal(t, []string{"10.0.0.0/16", "pypi.org"}, egressDestinations(&context.Context{}, api))
+	require.Equal(t, []string{"10.0.0.0/16", "pypi.org", "files.pythonhosted.org"}, egressDestinations(&context.Context{HasRequirements: true}, api))
+
+	config.Cluster.PythonPackageIndex = &clusterconfig.PythonPackageIndex{URL: pointer.String("https://pypi.internal.example.com/simple")}
+	require.Equal(t, []string{"10.0.0.0/16", "pypi.org", "pypi.internal.example.com"}, egressDestinations(&context.Context{HasRequirements: true}, api))
+
+	api.PythonPackageIndex = &clusterconfig.PythonPackageIndex{
+		URL:       pointer.String("https://artifactory.example.com/api/pypi/pypi/simple"),
+		ExtraURLs: []string{"https://pypi.example.com:8443/simple"},
+	}
+	require.Equal(t, []string{"10.0.0.0/16", "pypi.org", "artifactory.example.com", "pypi.example.com"}, egressDestinations(&context.Context{HasRequirements: true}, api))
+}
+
+func TestRecordResolvedEgressHost(t *testing.T) {
+	_resolvedEgressHosts.Lock()
+	prevResolved := _resolvedEgressHosts.m
+	_resolvedEgressHosts.m = map[string]map[string]time.Time{}
+	_resolvedEgressHosts.Unlock()
+	defer func() {
+		_resolvedEgressHosts.Lock()
+		_resolvedEgressHosts.m = prevResolved
+		_resolvedEgressHosts.Unlock()
+	}()
+
+	start := time.Now()
+
+	cidrs := recordResolvedEgressHost("cdn.example.com", strset.New("1.1.1.1/32"), start)
+	require.Equal(t, strset.New("1.1.1.1/32"), cidrs)
+
+	// a previously resolved address remains allowed while it's recent
+	cidrs = recordResolvedEgressHost("cdn.example.com", strset.New("2.2.2.2/32"), start.Add(_egressRefreshInterval))
+	require.Equal(t, strset.New("1.1.1.1/32", "2.2.2.2/32"), cidrs)
+
+	// and is dropped once it hasn't been returned for the TTL
+	cidrs = recordResolvedEgressHost("cdn.example.com", strset.New("2.2.2.2/32"), start.Add(_resolvedEgressAddressTTL+time.Second))
+	require.Equal(t, strset.New("2.2.2.2/32"), cidrs)
+
+	recordResolvedEgressHost("old.example.com", strset.New("3.3.3.3/32"), start)
+	pruneResolvedEgressHosts(start.Add(_resolvedEgressAddressTTL + 2*time.Second))
+	_resolvedEgressHosts.Lock()
+	require.NotContains(t, _resolvedEgressHosts.m, "old.example.com")
+	require.Contains(t, _resolvedEgressHosts.m, "cdn.example.com")
+	_resolvedEgressHosts.Unlock()
+
+	pruneResolvedEgressHosts(start.Add(3 * _resolvedEgressAddressTTL))
+	_resolvedEgressHosts.Lock()
+	require.Empty(t, _resolvedEgressHosts.m)
+	_resolvedEgressHosts.Unlock()
+}
+
+func TestNetworkPolicySpecStatsdEgress(t *testing.T) {
+	prevCluster := config.Cluster
+	config.Cluster = &clusterconfig.InternalConfig{}
+	defer func() {
+		config.Cluster = prevCluster
+	}()
+
+	ctx := &context.Context{App: &context.App{App: &userconfig.App{Name: "app"}}}
+	api := &context.API{
+		API:                    &userconfig.API{ResourceFields: userconfig.ResourceFields{Name: "iris"}},
+		ComputedResourceFields: &context.ComputedResourceFields{ResourceFields: &context.ResourceFields{ID: "1234"}},
+	}
+
+	policy := networkPolicySpec(ctx, api, []string{"52.216.0.0/15"}, nil, []string{"192.168.10.5", "192.168.20.7"})
+
+	var statsdRule *knetworking.NetworkPolicyEgressRule
+	for i, rule := range policy.Spec.Egress {
+		for _, port := range rule.Ports {
+			if port.Port != nil && port.Port.IntValue() == _statsdPort {
+				statsdRule = &policy.Spec.Egress[i]
+			}
+		}
+	}
+	require.NotNil(t, statsdRule)
+	require.Len(t, statsdRule.To, 3)
+	require.Equal(t, map[string]string{"name": "cloudwatch-agent-statsd"}, statsdRule.To[0].PodSelector.MatchLabels)
+	require.Equal(t, "192.168.10.5/32", statsdRule.To[1].IPBlock.CIDR)
+	require.Equal(t, "192.168.20.7/32", statsdRule.To[2].IPBlock.CIDR)
+}
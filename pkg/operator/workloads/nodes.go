@@ -0,0 +1,269 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	gocontext "context"
+	"path"
+	"sort"
+
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// Node labels which identify the instance type and availability zone (the current labels are checked before the deprecated ones)
+var (
+	_instanceTypeLabels = []string{"node.kubernetes.io/instance-type", "beta.kubernetes.io/instance-type"}
+	_zoneLabels         = []string{"topology.kubernetes.io/zone", "failure-domain.beta.kubernetes.io/zone"}
+)
+
+// Label of the nodes in the spot node group
+const (
+	_lifecycleLabel     = "lifecycle"
+	_spotLifecycleValue = "Ec2Spot"
+)
+
+// GetNodes describes each worker node, the API replicas scheduled on it, and the largest API replica which would still fit on it
+func GetNodes(goCtx gocontext.Context) (schema.GetNodesResponse, error) {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return schema.GetNodesResponse{}, err
+	}
+
+	nodes, pods, err := listWorkerNodesAndPods(k8sClient)
+	if err != nil {
+		return schema.GetNodesResponse{}, err
+	}
+
+	instanceIDs := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		if instanceID := nodeInstanceID(&node); instanceID != "" {
+			instanceIDs = append(instanceIDs, instanceID)
+		}
+	}
+
+	spotInstanceIDs, err := config.AWS.WithContext(goCtx).SpotInstanceIDs(instanceIDs)
+	if err != nil {
+		// e.g. if the operator isn't allowed to describe instances; the node group labels are used instead
+		errors.PrintError(err, "describe worker instances")
+		spotInstanceIDs = nil
+	}
+
+	return nodesResponse(nodes, pods, spotInstanceIDs), nil
+}
+
+// nodesResponse builds the response from the worker nodes and all pods; if spotInstanceIDs is nil, nodes in the spot node group are considered to be spot instances
+func nodesResponse(nodes []kcore.Node, pods []kcore.Pod, spotInstanceIDs strset.Set) schema.GetNodesResponse {
+	response := schema.GetNodesResponse{}
+	overhead := apiReplicaOverhead(pods)
+	resourceTotals := nodeResourceTotals(nodes, pods)
+
+	nodeInfos := make(map[string]*schema.NodeInfo, len(nodes))
+	for _, node := range nodes {
+		nodeInfo := &schema.NodeInfo{
+			Name:             node.Name,
+			InstanceType:     firstLabel(&node, _instanceTypeLabels),
+			AvailabilityZone: firstLabel(&node, _zoneLabels),
+			Created:          node.CreationTimestamp.Time,
+		}
+		if spotInstanceIDs != nil {
+			nodeInfo.Spot = spotInstanceIDs.Has(nodeInstanceID(&node))
+		} else {
+			nodeInfo.Spot = node.Labels[_lifecycleLabel] == _spotLifecycleValue
+		}
+		addResourceTotals(&nodeInfo.Allocatable, resourceTotals[node.Name].Allocatable)
+		addResourceTotals(&nodeInfo.Requested, resourceTotals[node.Name].Requested)
+		nodeInfos[node.Name] = nodeInfo
+	}
+
+	var pendingPods []kcore.Pod
+	nodePods := make(map[string][]kcore.Pod, len(nodes))
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
+			if pod.Labels["workloadType"] == workloadTypeAPI {
+				pendingPods = append(pendingPods, pod)
+			}
+			continue
+		}
+
+		if _, ok := nodeInfos[pod.Spec.NodeName]; ok && pod.Labels["workloadType"] == workloadTypeAPI {
+			nodePods[pod.Spec.NodeName] = append(nodePods[pod.Spec.NodeName], pod)
+		}
+	}
+
+	for _, node := range nodes {
+		nodeInfo := nodeInfos[node.Name]
+		nodeInfo.APIReplicas = groupAPIReplicas(nodePods[nodeInfo.Name])
+
+		free := subtractResourceTotals(nodeInfo.Allocatable, nodeInfo.Requested)
+		nodeInfo.LargestReplica = subtractResourceTotals(free, overhead)
+
+		response.Headroom.CPU.Add(free.CPU)
+		response.Headroom.Mem.Add(free.Mem)
+		response.Headroom.GPU += free.GPU
+
+		if response.LargestReplica == nil || isLargerReplica(nodeInfo.LargestReplica, *response.LargestReplica) {
+			largestReplica := nodeInfo.LargestReplica
+			response.LargestReplica = &largestReplica
+		}
+
+		response.Nodes = append(response.Nodes, *nodeInfo)
+	}
+
+	sort.Slice(response.Nodes, func(i, j int) bool {
+		return response.Nodes[i].Name < response.Nodes[j].Name
+	})
+	response.PendingReplicas = groupAPIReplicas(pendingPods)
+
+	return response
+}
+
+// apiReplicaCompute returns the compute of an API replica, as it is configured in the API's compute (i.e. excluding any sidecars)
+func apiReplicaCompute(pod *kcore.Pod) schema.ResourceTotals {
+	var compute schema.ResourceTotals
+	cpu, mem, gpu := APIPodCompute(pod.Spec.Containers)
+	if cpu != nil {
+		compute.CPU = cpu.Quantity
+	}
+	if mem != nil {
+		compute.Mem = mem.Quantity
+	}
+	compute.GPU = gpu
+	return compute
+}
+
+// apiReplicaOverhead returns the largest difference between the requests of an API pod and its API compute (e.g. the requests of the istio sidecar)
+func apiReplicaOverhead(pods []kcore.Pod) schema.ResourceTotals {
+	var overhead schema.ResourceTotals
+	for _, pod := range pods {
+		if pod.Labels["workloadType"] != workloadTypeAPI {
+			continue
+		}
+
+		var requests schema.ResourceTotals
+		addResourceTotals(&requests, k8s.PodRequests(&pod))
+		podOverhead := subtractResourceTotals(requests, apiReplicaCompute(&pod))
+
+		if podOverhead.CPU.Cmp(overhead.CPU) > 0 {
+			overhead.CPU = podOverhead.CPU
+		}
+		if podOverhead.Mem.Cmp(overhead.Mem) > 0 {
+			overhead.Mem = podOverhead.Mem
+		}
+		if podOverhead.GPU > overhead.GPU {
+			overhead.GPU = podOverhead.GPU
+		}
+	}
+	return overhead
+}
+
+// groupAPIReplicas counts the replicas of each API (and compute, since replicas of an API can differ while it's updating)
+func groupAPIReplicas(pods []kcore.Pod) []schema.APIReplicas {
+	replicasByKey := map[string]*schema.APIReplicas{}
+	var keys []string
+	for _, pod := range pods {
+		key := pod.Labels["appName"] + "/" + pod.Labels["apiName"] + "/" + APIPodComputeID(pod.Spec.Containers)
+		if replicas, ok := replicasByKey[key]; ok {
+			replicas.Count++
+			continue
+		}
+		replicasByKey[key] = &schema.APIReplicas{
+			AppName: pod.Labels["appName"],
+			APIName: pod.Labels["apiName"],
+			Count:   1,
+			Compute: apiReplicaCompute(&pod),
+			Reason:  unschedulableReason(&pod),
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	apiReplicas := make([]schema.APIReplicas, 0, len(keys))
+	for _, key := range keys {
+		apiReplicas = append(apiReplicas, *replicasByKey[key])
+	}
+	return apiReplicas
+}
+
+// unschedulableReason returns the scheduler's explanation of why a pod hasn't been scheduled (e.g. "0/2 nodes are available: 2 Insufficient cpu.")
+func unschedulableReason(pod *kcore.Pod) string {
+	if pod.Spec.NodeName != "" {
+		return ""
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == kcore.PodScheduled && condition.Status == kcore.ConditionFalse {
+			return condition.Message
+		}
+	}
+	return ""
+}
+
+// subtractResourceTotals returns totals - subtrahend, where each resource is at least 0
+func subtractResourceTotals(totals schema.ResourceTotals, subtrahend schema.ResourceTotals) schema.ResourceTotals {
+	difference := schema.ResourceTotals{
+		CPU: totals.CPU.DeepCopy(),
+		Mem: totals.Mem.DeepCopy(),
+		GPU: totals.GPU - subtrahend.GPU,
+	}
+	difference.CPU.Sub(subtrahend.CPU)
+	difference.Mem.Sub(subtrahend.Mem)
+
+	if difference.CPU.Sign() < 0 {
+		difference.CPU = kresource.Quantity{}
+	}
+	if difference.Mem.Sign() < 0 {
+		difference.Mem = kresource.Quantity{}
+	}
+	if difference.GPU < 0 {
+		difference.GPU = 0
+	}
+	return difference
+}
+
+// isLargerReplica compares replicas by GPU, then CPU, then memory (since that's the order of their scarcity on most instances)
+func isLargerReplica(replica schema.ResourceTotals, other schema.ResourceTotals) bool {
+	if replica.GPU != other.GPU {
+		return replica.GPU > other.GPU
+	}
+	if cmp := replica.CPU.Cmp(other.CPU); cmp != 0 {
+		return cmp > 0
+	}
+	return replica.Mem.Cmp(other.Mem) > 0
+}
+
+func firstLabel(node *kcore.Node, labels []string) string {
+	for _, label := range labels {
+		if value := node.Labels[label]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// nodeInstanceID returns the EC2 instance ID of a node, based on its provider ID (e.g. aws:///us-west-2a/i-0123456789abcdef0)
+func nodeInstanceID(node *kcore.Node) string {
+	if node.Spec.ProviderID == "" {
+		return ""
+	}
+	return path.Base(node.Spec.ProviderID)
+}
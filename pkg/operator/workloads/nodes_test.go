@@ -0,0 +1,122 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+)
+
+func testNode(name string, instanceID string, cpu string, mem string, labels map[string]string) kcore.Node {
+	return kcore.Node{
+		ObjectMeta: kmeta.ObjectMeta{Name: name, Labels: labels},
+		Spec:       kcore.NodeSpec{ProviderID: "aws:///us-west-2a/" + instanceID},
+		Status: kcore.NodeStatus{
+			Allocatable: kcore.ResourceList{
+				kcore.ResourceCPU:    kresource.MustParse(cpu),
+				kcore.ResourceMemory: kresource.MustParse(mem),
+			},
+		},
+	}
+}
+
+func testAPIPod(apiName string, nodeName string, cpu string, mem string) kcore.Pod {
+	return kcore.Pod{
+		ObjectMeta: kmeta.ObjectMeta{
+			Labels: map[string]string{"workloadType": workloadTypeAPI, "appName": "app", "apiName": apiName},
+		},
+		Spec: kcore.PodSpec{
+			NodeName: nodeName,
+			Containers: []kcore.Container{
+				{
+					Name: apiContainerName,
+					Resources: kcore.ResourceRequirements{Requests: kcore.ResourceList{
+						kcore.ResourceCPU:    kresource.MustParse(cpu),
+						kcore.ResourceMemory: kresource.MustParse(mem),
+					}},
+				},
+				{
+					Name: "istio-proxy",
+					Resources: kcore.ResourceRequirements{Requests: kcore.ResourceList{
+						kcore.ResourceCPU:    kresource.MustParse("100m"),
+						kcore.ResourceMemory: kresource.MustParse("128Mi"),
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestNodesResponse(t *testing.T) {
+	nodes := []kcore.Node{
+		testNode("node-b", "i-b", "2", "8Gi", map[string]string{"beta.kubernetes.io/instance-type": "m5.large", _lifecycleLabel: _spotLifecycleValue}),
+		testNode("node-a", "i-a", "4", "16Gi", map[string]string{"node.kubernetes.io/instance-type": "m5.xlarge", "failure-domain.beta.kubernetes.io/zone": "us-west-2a"}),
+	}
+
+	pending := testAPIPod("big", "", "8", "1Gi")
+	pending.Status.Conditions = []kcore.PodCondition{
+		{Type: kcore.PodScheduled, Status: kcore.ConditionFalse, Message: "0/2 nodes are available: 2 Insufficient cpu."},
+	}
+	pods := []kcore.Pod{
+		testAPIPod("small", "node-a", "1", "1Gi"),
+		testAPIPod("small", "node-a", "1", "1Gi"),
+		testAPIPod("small", "node-b", "1", "1Gi"),
+		pending,
+	}
+
+	response := nodesResponse(nodes, pods, strset.New("i-a"))
+	require.Len(t, response.Nodes, 2)
+
+	nodeA := response.Nodes[0]
+	require.Equal(t, "node-a", nodeA.Name)
+	require.Equal(t, "m5.xlarge", nodeA.InstanceType)
+	require.Equal(t, "us-west-2a", nodeA.AvailabilityZone)
+	require.True(t, nodeA.Spot)
+	require.Equal(t, "2200m", nodeA.Requested.CPU.String())
+	require.Equal(t, 2, nodeA.APIReplicas[0].Count)
+	require.Equal(t, "1", nodeA.APIReplicas[0].Compute.CPU.String())
+	// 4 - 2.2 requested - 0.1 for the sidecar
+	require.Equal(t, "1700m", nodeA.LargestReplica.CPU.String())
+
+	nodeB := response.Nodes[1]
+	require.False(t, nodeB.Spot)
+	require.Equal(t, "800m", nodeB.LargestReplica.CPU.String())
+
+	require.Equal(t, "1700m", response.LargestReplica.CPU.String())
+	require.Equal(t, "2700m", response.Headroom.CPU.String())
+
+	require.Len(t, response.PendingReplicas, 1)
+	require.Equal(t, "big", response.PendingReplicas[0].APIName)
+	require.Contains(t, response.PendingReplicas[0].Reason, "Insufficient cpu")
+
+	// without instance lifecycles, the spot node group's label is used
+	response = nodesResponse(nodes, pods, nil)
+	require.False(t, response.Nodes[0].Spot)
+	require.True(t, response.Nodes[1].Spot)
+}
+
+func TestNodesResponseNoNodes(t *testing.T) {
+	response := nodesResponse(nil, nil, nil)
+	require.Empty(t, response.Nodes)
+	require.Nil(t, response.LargestReplica)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"path"
+
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	apiPodUID = int64(1000)
+	apiPodGID = int64(1000)
+)
+
+// isAPIPodSecurityEnforced returns true if the restricted pod security profile applies to the API and it has not opted out of the given setting
+func isAPIPodSecurityEnforced(api *context.API, optOut string) bool {
+	if config.Cluster.APIPodSecurityMode() != clusterconfig.APIPodSecurityRestricted {
+		return false
+	}
+	return !api.HasSecurityOptOut(optOut)
+}
+
+func apiPodSecurityContext(api *context.API) *kcore.PodSecurityContext {
+	if !isAPIPodSecurityEnforced(api, userconfig.RunAsRootOptOut) {
+		return nil
+	}
+
+	return &kcore.PodSecurityContext{
+		RunAsNonRoot: pointer.Bool(true),
+		RunAsUser:    pointer.Int64(apiPodUID),
+		RunAsGroup:   pointer.Int64(apiPodGID),
+		FSGroup:      pointer.Int64(apiPodGID),
+	}
+}
+
+func apiContainerSecurityContext(api *context.API) *kcore.SecurityContext {
+	securityContext := &kcore.SecurityContext{}
+
+	if isAPIPodSecurityEnforced(api, userconfig.WritableRootFilesystemOptOut) {
+		securityContext.ReadOnlyRootFilesystem = pointer.Bool(true)
+	}
+	if isAPIPodSecurityEnforced(api, userconfig.PrivilegeEscalationOptOut) {
+		securityContext.AllowPrivilegeEscalation = pointer.Bool(false)
+	}
+	if isAPIPodSecurityEnforced(api, userconfig.DefaultCapabilitiesOptOut) {
+		securityContext.Capabilities = &kcore.Capabilities{
+			Drop: []kcore.Capability{"ALL"},
+		}
+	}
+
+	if *securityContext == (kcore.SecurityContext{}) {
+		return nil
+	}
+	return securityContext
+}
+
+// apiSecurityEnvVars point writable locations (e.g. for pip) to the tmp volume when the root filesystem is read-only
+func apiSecurityEnvVars(api *context.API) []kcore.EnvVar {
+	if !isAPIPodSecurityEnforced(api, userconfig.WritableRootFilesystemOptOut) {
+		return nil
+	}
+
+	return []kcore.EnvVar{
+		{
+			Name:  "HOME",
+			Value: consts.TmpDirMountPath,
+		},
+		{
+			Name:  "PYTHONUSERBASE",
+			Value: path.Join(consts.TmpDirMountPath, ".local"),
+		},
+	}
+}
+
+func apiVolumes() []kcore.Volume {
+	return append(defaultVolumes(), k8s.EmptyDirVolume(consts.TmpDirVolumeName))
+}
+
+func apiVolumeMounts() []kcore.VolumeMount {
+	return append(defaultVolumeMounts(), k8s.EmptyDirVolumeMount(consts.TmpDirVolumeName, consts.TmpDirMountPath))
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+func TestAPIPodSecurity(t *testing.T) {
+	prevCluster := config.Cluster
+	defer func() {
+		config.Cluster = prevCluster
+	}()
+
+	restrictedPodSecurityContext := &kcore.PodSecurityContext{
+		RunAsNonRoot: pointer.Bool(true),
+		RunAsUser:    pointer.Int64(1000),
+		RunAsGroup:   pointer.Int64(1000),
+		FSGroup:      pointer.Int64(1000),
+	}
+	readOnlyRootFilesystem := &kcore.SecurityContext{ReadOnlyRootFilesystem: pointer.Bool(true)}
+	noPrivilegeEscalation := &kcore.SecurityContext{AllowPrivilegeEscalation: pointer.Bool(false)}
+	droppedCapabilities := &kcore.SecurityContext{Capabilities: &kcore.Capabilities{Drop: []kcore.Capability{"ALL"}}}
+	restrictedEnvVars := []kcore.EnvVar{
+		{Name: "HOME", Value: "/tmp"},
+		{Name: "PYTHONUSERBASE", Value: "/tmp/.local"},
+	}
+
+	// merges the settings of the container security contexts
+	containerSecurityContext := func(securityContexts ...*kcore.SecurityContext) *kcore.SecurityContext {
+		merged := &kcore.SecurityContext{}
+		for _, securityContext := range securityContexts {
+			if securityContext.ReadOnlyRootFilesystem != nil {
+				merged.ReadOnlyRootFilesystem = securityContext.ReadOnlyRootFilesystem
+			}
+			if securityContext.AllowPrivilegeEscalation != nil {
+				merged.AllowPrivilegeEscalation = securityContext.AllowPrivilegeEscalation
+			}
+			if securityContext.Capabilities != nil {
+				merged.Capabilities = securityContext.Capabilities
+			}
+		}
+		return merged
+	}
+
+	for _, tc := range []struct {
+		name                             string
+		apiPodSecurity                   *string
+		optOuts                          []string
+		expectedPodSecurityContext       *kcore.PodSecurityContext
+		expectedContainerSecurityContext *kcore.SecurityContext
+		expectedEnvVars                  []kcore.EnvVar
+		expectedSeccomp                  bool
+	}{
+		{
+			name:                             "restricted",
+			apiPodSecurity:                   pointer.String(clusterconfig.APIPodSecurityRestricted),
+			expectedPodSecurityContext:       restrictedPodSecurityContext,
+			expectedContainerSecurityContext: containerSecurityContext(readOnlyRootFilesystem, noPrivilegeEscalation, droppedCapabilities),
+			expectedEnvVars:                  restrictedEnvVars,
+			expectedSeccomp:                  true,
+		},
+		{
+			name:           "unrestricted",
+			apiPodSecurity: pointer.String(clusterconfig.APIPodSecurityUnrestricted),
+		},
+		{
+			// clusters which were created before api_pod_security existed
+			name: "not set",
+		},
+		{
+			name:           "unrestricted with opt-outs",
+			apiPodSecurity: pointer.String(clusterconfig.APIPodSecurityUnrestricted),
+			optOuts:        []string{userconfig.RunAsRootOptOut, userconfig.UnconfinedSeccompOptOut},
+		},
+		{
+			name:                             "run as root",
+			apiPodSecurity:                   pointer.String(clusterconfig.APIPodSecurityRestricted),
+			optOuts:                          []string{userconfig.RunAsRootOptOut},
+			expectedContainerSecurityContext: containerSecurityContext(readOnlyRootFilesystem, noPrivilegeEscalation, droppedCapabilities),
+			expectedEnvVars:                  restrictedEnvVars,
+			expectedSeccomp:                  true,
+		},
+		{
+			name:                             "writable root filesystem",
+			apiPodSecurity:                   pointer.String(clusterconfig.APIPodSecurityRestricted),
+			optOuts:                          []string{userconfig.WritableRootFilesystemOptOut},
+			expectedPodSecurityContext:       restrictedPodSecurityContext,
+			expectedContainerSecurityContext: containerSecurityContext(noPrivilegeEscalation, droppedCapabilities),
+			expectedSeccomp:                  true,
+		},
+		{
+			name:                             "privilege escalation",
+			apiPodSecurity:                   pointer.String(clusterconfig.APIPodSecurityRestricted),
+			optOuts:                          []string{userconfig.PrivilegeEscalationOptOut},
+			expectedPodSecurityContext:       restrictedPodSecurityContext,
+			expectedContainerSecurityContext: containerSecurityContext(readOnlyRootFilesystem, droppedCapabilities),
+			expectedEnvVars:                  restrictedEnvVars,
+			expectedSeccomp:                  true,
+		},
+		{
+			name:                             "default capabilities",
+			apiPodSecurity:                   pointer.String(clusterconfig.APIPodSecurityRestricted),
+			optOuts:                          []string{userconfig.DefaultCapabilitiesOptOut},
+			expectedPodSecurityContext:       restrictedPodSecurityContext,
+			expectedContainerSecurityContext: containerSecurityContext(readOnlyRootFilesystem, noPrivilegeEscalation),
+			expectedEnvVars:                  restrictedEnvVars,
+			expectedSeccomp:                  true,
+		},
+		{
+			name:                             "unconfined seccomp",
+			apiPodSecurity:                   pointer.String(clusterconfig.APIPodSecurityRestricted),
+			optOuts:                          []string{userconfig.UnconfinedSeccompOptOut},
+			expectedPodSecurityContext:       restrictedPodSecurityContext,
+			expectedContainerSecurityContext: containerSecurityContext(readOnlyRootFilesystem, noPrivilegeEscalation, droppedCapabilities),
+			expectedEnvVars:                  restrictedEnvVars,
+		},
+		{
+			name:           "all opt-outs",
+			apiPodSecurity: pointer.String(clusterconfig.APIPodSecurityRestricted),
+			optOuts:        userconfig.SecurityOptOuts,
+		},
+	} {
+		config.Cluster = &clusterconfig.InternalConfig{
+			Config: clusterconfig.Config{
+				APIPodSecurity: tc.apiPodSecurity,
+				MTLS:           clusterconfig.MTLSDisabled,
+			},
+		}
+		api := &context.API{
+			API: &userconfig.API{
+				ResourceFields:  userconfig.ResourceFields{Name: "iris"},
+				SecurityOptOuts: tc.optOuts,
+			},
+		}
+
+		require.Equal(t, tc.expectedPodSecurityContext, apiPodSecurityContext(api), tc.name)
+		require.Equal(t, tc.expectedContainerSecurityContext, apiContainerSecurityContext(api), tc.name)
+		require.Equal(t, tc.expectedEnvVars, apiSecurityEnvVars(api), tc.name)
+		if tc.expectedSeccomp {
+			require.Equal(t, map[string]string{"seccomp.security.alpha.kubernetes.io/pod": "runtime/default"}, apiPodAnnotations(api), tc.name)
+		} else {
+			require.Empty(t, apiPodAnnotations(api), tc.name)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"strings"
+
+	kcore "k8s.io/api/core/v1"
+
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+)
+
+// tfServingRuntimeArgs are appended to the TensorFlow Serving container's args
+func tfServingRuntimeArgs(api *context.API) []string {
+	runtime := api.Predictor.Runtime
+	if runtime == nil {
+		return nil
+	}
+
+	var args []string
+	if runtime.IntraOpThreads != nil {
+		args = append(args, "--tensorflow_intra_op_parallelism="+s.Int32(*runtime.IntraOpThreads))
+	}
+	if runtime.InterOpThreads != nil {
+		args = append(args, "--tensorflow_inter_op_parallelism="+s.Int32(*runtime.InterOpThreads))
+	}
+	if runtime.GPUMemoryFraction != nil {
+		args = append(args, "--per_process_gpu_memory_fraction="+s.Float64(*runtime.GPUMemoryFraction))
+	}
+	return args
+}
+
+// onnxRuntimeEnvVars configure the ONNX Runtime session options (see onnx_serve/client.py)
+func onnxRuntimeEnvVars(api *context.API) []kcore.EnvVar {
+	runtime := api.Predictor.Runtime
+	if runtime == nil {
+		return nil
+	}
+
+	var envVars []kcore.EnvVar
+	if runtime.IntraOpThreads != nil {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  "CORTEX_ONNX_INTRA_OP_THREADS",
+			Value: s.Int32(*runtime.IntraOpThreads),
+		})
+	}
+	if runtime.InterOpThreads != nil {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  "CORTEX_ONNX_INTER_OP_THREADS",
+			Value: s.Int32(*runtime.InterOpThreads),
+		})
+	}
+	if runtime.GraphOptimizationLevel != nil {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  "CORTEX_ONNX_GRAPH_OPTIMIZATION_LEVEL",
+			Value: *runtime.GraphOptimizationLevel,
+		})
+	}
+	if runtime.ExecutionMode != nil {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  "CORTEX_ONNX_EXECUTION_MODE",
+			Value: *runtime.ExecutionMode,
+		})
+	}
+	if len(runtime.ExecutionProviders) > 0 {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  "CORTEX_ONNX_EXECUTION_PROVIDERS",
+			Value: strings.Join(runtime.ONNXExecutionProviderNames(), ","),
+		})
+	}
+	return envVars
+}
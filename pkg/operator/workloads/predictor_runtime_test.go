@@ -0,0 +1,68 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+)
+
+func testRuntimeAPI(predictorType userconfig.PredictorType, runtime *userconfig.PredictorRuntime) *context.API {
+	return &context.API{
+		API: &userconfig.API{
+			Predictor: &userconfig.Predictor{Type: predictorType, Runtime: runtime},
+		},
+	}
+}
+
+func TestTFServingRuntimeArgs(t *testing.T) {
+	require.Empty(t, tfServingRuntimeArgs(testRuntimeAPI(userconfig.TensorFlowPredictorType, nil)))
+
+	api := testRuntimeAPI(userconfig.TensorFlowPredictorType, &userconfig.PredictorRuntime{
+		IntraOpThreads:    pointer.Int32(8),
+		InterOpThreads:    pointer.Int32(2),
+		GPUMemoryFraction: pointer.Float64(0.5),
+	})
+	require.Equal(t, []string{
+		"--tensorflow_intra_op_parallelism=8",
+		"--tensorflow_inter_op_parallelism=2",
+		"--per_process_gpu_memory_fraction=0.5",
+	}, tfServingRuntimeArgs(api))
+}
+
+func TestONNXRuntimeEnvVars(t *testing.T) {
+	require.Empty(t, onnxRuntimeEnvVars(testRuntimeAPI(userconfig.ONNXPredictorType, nil)))
+
+	api := testRuntimeAPI(userconfig.ONNXPredictorType, &userconfig.PredictorRuntime{
+		IntraOpThreads:         pointer.Int32(4),
+		GraphOptimizationLevel: pointer.String("extended"),
+		ExecutionMode:          pointer.String("parallel"),
+		ExecutionProviders:     []string{"tensorrt", "cuda", "cpu"},
+	})
+	require.Equal(t, []kcore.EnvVar{
+		{Name: "CORTEX_ONNX_INTRA_OP_THREADS", Value: "4"},
+		{Name: "CORTEX_ONNX_GRAPH_OPTIMIZATION_LEVEL", Value: "extended"},
+		{Name: "CORTEX_ONNX_EXECUTION_MODE", Value: "parallel"},
+		{Name: "CORTEX_ONNX_EXECUTION_PROVIDERS", Value: "TensorrtExecutionProvider,CUDAExecutionProvider,CPUExecutionProvider"},
+	}, onnxRuntimeEnvVars(api))
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	gocontext "context"
+	"strings"
+
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+// pythonPackageIndex returns the API's package index, or the cluster's if the API doesn't set one (the cluster's is
+// resolved here rather than in the API's config, so that it isn't part of the API's configuration or ID)
+func pythonPackageIndex(api *context.API) *clusterconfig.PythonPackageIndex {
+	if api.PythonPackageIndex != nil {
+		return api.PythonPackageIndex
+	}
+	return config.Cluster.PythonPackageIndex
+}
+
+// pythonPackageIndexEnvVars configure the pip install of requirements.txt (see run.sh); credentials are read from the secret by kubernetes
+func pythonPackageIndexEnvVars(api *context.API) []kcore.EnvVar {
+	index := pythonPackageIndex(api)
+	if index == nil {
+		return nil
+	}
+
+	var envVars []kcore.EnvVar
+	if index.URL != nil {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  "CORTEX_PIP_INDEX_URL",
+			Value: *index.URL,
+		})
+	}
+	if len(index.ExtraURLs) > 0 {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  "CORTEX_PIP_EXTRA_INDEX_URLS",
+			Value: strings.Join(index.ExtraURLs, " "),
+		})
+	}
+	if len(index.TrustedHosts) > 0 {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  "CORTEX_PIP_TRUSTED_HOSTS",
+			Value: strings.Join(index.TrustedHosts, " "),
+		})
+	}
+	if index.CredentialsSecret != nil {
+		envVars = append(envVars,
+			k8s.SecretKeyEnvVar("CORTEX_PIP_USERNAME", *index.CredentialsSecret, clusterconfig.PythonPackageIndexUsernameSecretKey),
+			k8s.SecretKeyEnvVar("CORTEX_PIP_PASSWORD", *index.CredentialsSecret, clusterconfig.PythonPackageIndexPasswordSecretKey),
+		)
+	}
+	return envVars
+}
+
+// validatePythonPackageIndexSecrets checks that the credentials secrets exist, since otherwise the API's containers would never start
+func validatePythonPackageIndexSecrets(goCtx gocontext.Context, ctx *context.Context) error {
+	for _, api := range ctx.APIs {
+		index := pythonPackageIndex(api)
+		if index == nil || index.CredentialsSecret == nil {
+			continue
+		}
+
+		secretName := *index.CredentialsSecret
+		k8sClient, err := config.Kubernetes.WithContext(goCtx)
+		if err != nil {
+			return err
+		}
+
+		secret, err := k8sClient.GetSecret(secretName)
+		if err != nil {
+			return err
+		}
+		if secret == nil {
+			return errors.Wrap(ErrorPackageIndexSecretNotFound(secretName), userconfig.Identify(api), userconfig.PythonPackageIndexKey, clusterconfig.CredentialsSecretKey)
+		}
+
+		for _, key := range []string{clusterconfig.PythonPackageIndexUsernameSecretKey, clusterconfig.PythonPackageIndexPasswordSecretKey} {
+			if _, ok := secret.Data[key]; !ok {
+				return errors.Wrap(ErrorPackageIndexSecretMissingKey(secretName, key), userconfig.Identify(api), userconfig.PythonPackageIndexKey, clusterconfig.CredentialsSecretKey)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	gocontext "context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kcore "k8s.io/api/core/v1"
+
+	"github.com/cortexlabs/cortex/pkg/lib/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/operator/api/context"
+	"github.com/cortexlabs/cortex/pkg/operator/api/userconfig"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+func setTestClusterPythonPackageIndex(index *clusterconfig.PythonPackageIndex) func() {
+	prevCluster := config.Cluster
+	config.Cluster = &clusterconfig.InternalConfig{
+		Config: clusterconfig.Config{
+			PythonPackageIndex: index,
+		},
+	}
+	return func() {
+		config.Cluster = prevCluster
+	}
+}
+
+func testPythonPackageIndexAPI(name string, index *clusterconfig.PythonPackageIndex) *context.API {
+	return &context.API{
+		API: &userconfig.API{
+			ResourceFields:     userconfig.ResourceFields{Name: name},
+			PythonPackageIndex: index,
+		},
+	}
+}
+
+func TestPythonPackageIndexEnvVars(t *testing.T) {
+	defer setTestClusterPythonPackageIndex(nil)()
+
+	require.Empty(t, pythonPackageIndexEnvVars(testPythonPackageIndexAPI("api", nil)))
+
+	apiIndex := &clusterconfig.PythonPackageIndex{
+		URL:               pointer.String("https://pypi.example.com/simple"),
+		ExtraURLs:         []string{"https://a.example.com/simple", "https://b.example.com/simple"},
+		TrustedHosts:      []string{"a.example.com", "b.example.com"},
+		CredentialsSecret: pointer.String("pypi-credentials"),
+	}
+	require.Equal(t, []kcore.EnvVar{
+		{Name: "CORTEX_PIP_INDEX_URL", Value: "https://pypi.example.com/simple"},
+		{Name: "CORTEX_PIP_EXTRA_INDEX_URLS", Value: "https://a.example.com/simple https://b.example.com/simple"},
+		{Name: "CORTEX_PIP_TRUSTED_HOSTS", Value: "a.example.com b.example.com"},
+		k8s.SecretKeyEnvVar("CORTEX_PIP_USERNAME", "pypi-credentials", clusterconfig.PythonPackageIndexUsernameSecretKey),
+		k8s.SecretKeyEnvVar("CORTEX_PIP_PASSWORD", "pypi-credentials", clusterconfig.PythonPackageIndexPasswordSecretKey),
+	}, pythonPackageIndexEnvVars(testPythonPackageIndexAPI("api", apiIndex)))
+
+	// the cluster's index is used by apis which don't configure one
+	defer setTestClusterPythonPackageIndex(&clusterconfig.PythonPackageIndex{
+		ExtraURLs: []string{"https://cluster.example.com/simple"},
+	})()
+	require.Equal(t, []kcore.EnvVar{
+		{Name: "CORTEX_PIP_EXTRA_INDEX_URLS", Value: "https://cluster.example.com/simple"},
+	}, pythonPackageIndexEnvVars(testPythonPackageIndexAPI("api", nil)))
+
+	require.Equal(t, []kcore.EnvVar{
+		{Name: "CORTEX_PIP_INDEX_URL", Value: "https://api.example.com/simple"},
+	}, pythonPackageIndexEnvVars(testPythonPackageIndexAPI("api", &clusterconfig.PythonPackageIndex{URL: pointer.String("https://api.example.com/simple")})))
+}
+
+func TestValidatePythonPackageIndexSecrets(t *testing.T) {
+	fake, closeFake := newFakeK8s(t)
+	defer closeFake()
+	defer setTestClusterPythonPackageIndex(nil)()
+	goCtx := gocontext.Background()
+
+	addSecret := func(name string, keys ...string) {
+		data := map[string]interface{}{}
+		for _, key := range keys {
+			data[key] = "c2VjcmV0" // "secret"
+		}
+		fake.objects["/api/v1/namespaces/cortex/secrets/"+name] = map[string]interface{}{
+			"kind":       "Secret",
+			"apiVersion": "v1",
+			"metadata":   map[string]interface{}{"name": name},
+			"data":       data,
+		}
+	}
+	addSecret("pypi-credentials", clusterconfig.PythonPackageIndexUsernameSecretKey, clusterconfig.PythonPackageIndexPasswordSecretKey)
+	addSecret("pypi-username", clusterconfig.PythonPackageIndexUsernameSecretKey)
+
+	ctxWithIndexes := func(indexes ...*clusterconfig.PythonPackageIndex) *context.Context {
+		ctx := &context.Context{APIs: context.APIs{}}
+		for i, index := range indexes {
+			name := []string{"a", "b", "c"}[i]
+			ctx.APIs[name] = testPythonPackageIndexAPI(name, index)
+		}
+		return ctx
+	}
+	indexWithSecret := func(secretName string) *clusterconfig.PythonPackageIndex {
+		return &clusterconfig.PythonPackageIndex{
+			URL:               pointer.String("https://pypi.example.com/simple"),
+			CredentialsSecret: pointer.String(secretName),
+		}
+	}
+
+	require.NoError(t, validatePythonPackageIndexSecrets(goCtx, ctxWithIndexes(nil, &clusterconfig.PythonPackageIndex{URL: pointer.String("https://pypi.example.com/simple")})))
+	require.NoError(t, validatePythonPackageIndexSecrets(goCtx, ctxWithIndexes(nil, indexWithSecret("pypi-credentials"))))
+
+	err := validatePythonPackageIndexSecrets(goCtx, ctxWithIndexes(nil, indexWithSecret("missing")))
+	require.Equal(t, ErrPackageIndexSecretNotFound, errors.Cause(err).(Error).Kind)
+
+	err = validatePythonPackageIndexSecrets(goCtx, ctxWithIndexes(indexWithSecret("pypi-username")))
+	require.Equal(t, ErrPackageIndexSecretMissingKey, errors.Cause(err).(Error).Kind)
+	require.Contains(t, err.Error(), clusterconfig.PythonPackageIndexPasswordSecretKey)
+
+	// the cluster's secret is checked for apis which don't configure an index
+	defer setTestClusterPythonPackageIndex(indexWithSecret("missing"))()
+	err = validatePythonPackageIndexSecrets(goCtx, ctxWithIndexes(indexWithSecret("pypi-credentials"), nil))
+	require.Equal(t, ErrPackageIndexSecretNotFound, errors.Cause(err).(Error).Kind)
+	require.NoError(t, validatePythonPackageIndexSecrets(goCtx, ctxWithIndexes(indexWithSecret("pypi-credentials"))))
+
+	// errors from the kubernetes api are returned
+	fake.failures["GET secrets"] = 500
+	require.Error(t, validatePythonPackageIndexSecrets(goCtx, ctxWithIndexes(indexWithSecret("pypi-credentials"))))
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package workloads
 
 import (
+	gocontext "context"
+
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/parallel"
@@ -24,13 +26,13 @@ import (
 	ocontext "github.com/cortexlabs/cortex/pkg/operator/context"
 )
 
-func uploadBaseWorkload(baseWorkload *BaseWorkload) error {
+func uploadBaseWorkload(goCtx gocontext.Context, baseWorkload *BaseWorkload) error {
 	if isBaseWorkloadCached(baseWorkload) {
 		return nil
 	}
 
 	key := ocontext.BaseWorkloadKey(baseWorkload.WorkloadID, baseWorkload.AppName)
-	err := config.AWS.UploadJSONToS3(baseWorkload, key)
+	err := config.AWS.WithContext(goCtx).UploadJSONToS3(baseWorkload, key)
 	if err != nil {
 		return errors.Wrap(err, "upload base workload", baseWorkload.AppName, baseWorkload.WorkloadID)
 	}
@@ -38,36 +40,36 @@ func uploadBaseWorkload(baseWorkload *BaseWorkload) error {
 	return nil
 }
 
-func uploadBaseWorkloads(baseWorkloads []*BaseWorkload) error {
+func uploadBaseWorkloads(goCtx gocontext.Context, baseWorkloads []*BaseWorkload) error {
 	fns := make([]func() error, len(baseWorkloads))
 	for i, baseWorkload := range baseWorkloads {
-		fns[i] = uploadBaseWorkloadFunc(baseWorkload)
+		fns[i] = uploadBaseWorkloadFunc(goCtx, baseWorkload)
 	}
 	return parallel.RunFirstErr(fns...)
 }
 
-func uploadBaseWorkloadsFromWorkloads(workloads []Workload) error {
+func uploadBaseWorkloadsFromWorkloads(goCtx gocontext.Context, workloads []Workload) error {
 	fns := make([]func() error, len(workloads))
 	for i, workload := range workloads {
-		fns[i] = uploadBaseWorkloadFunc(workload.GetBaseWorkloadPtr())
+		fns[i] = uploadBaseWorkloadFunc(goCtx, workload.GetBaseWorkloadPtr())
 	}
 	return parallel.RunFirstErr(fns...)
 }
 
-func uploadBaseWorkloadFunc(baseWorkload *BaseWorkload) func() error {
+func uploadBaseWorkloadFunc(goCtx gocontext.Context, baseWorkload *BaseWorkload) func() error {
 	return func() error {
-		return uploadBaseWorkload(baseWorkload)
+		return uploadBaseWorkload(goCtx, baseWorkload)
 	}
 }
 
-func getSavedBaseWorkload(workloadID string, appName string) (*BaseWorkload, error) {
+func getSavedBaseWorkload(goCtx gocontext.Context, workloadID string, appName string) (*BaseWorkload, error) {
 	if cachedBaseWorkload, ok := getCachedBaseWorkload(workloadID, appName); ok {
 		return cachedBaseWorkload, nil
 	}
 
 	key := ocontext.BaseWorkloadKey(workloadID, appName)
 	var baseWorkload BaseWorkload
-	err := config.AWS.ReadJSONFromS3(&baseWorkload, key)
+	err := config.AWS.WithContext(goCtx).ReadJSONFromS3(&baseWorkload, key)
 	if aws.IsNoSuchKeyErr(err) {
 		return nil, nil
 	}
@@ -78,12 +80,12 @@ func getSavedBaseWorkload(workloadID string, appName string) (*BaseWorkload, err
 	return &baseWorkload, nil
 }
 
-func getSavedBaseWorkloads(workloadIDs []string, appName string) (map[string]*BaseWorkload, error) {
+func getSavedBaseWorkloads(goCtx gocontext.Context, workloadIDs []string, appName string) (map[string]*BaseWorkload, error) {
 	baseWorkloads := make([]*BaseWorkload, len(workloadIDs))
 	fns := make([]func() error, len(workloadIDs))
 	i := 0
 	for _, workloadID := range workloadIDs {
-		fns[i] = getSavedBaseWorkloadFunc(workloadID, appName, baseWorkloads, i)
+		fns[i] = getSavedBaseWorkloadFunc(goCtx, workloadID, appName, baseWorkloads, i)
 		i++
 	}
 	err := parallel.RunFirstErr(fns...)
@@ -100,9 +102,9 @@ func getSavedBaseWorkloads(workloadIDs []string, appName string) (map[string]*Ba
 	return baseWorkloadMap, err
 }
 
-func getSavedBaseWorkloadFunc(workloadID string, appName string, baseWorkloads []*BaseWorkload, i int) func() error {
+func getSavedBaseWorkloadFunc(goCtx gocontext.Context, workloadID string, appName string, baseWorkloads []*BaseWorkload, i int) func() error {
 	return func() error {
-		baseWorkload, err := getSavedBaseWorkload(workloadID, appName)
+		baseWorkload, err := getSavedBaseWorkload(goCtx, workloadID, appName)
 		if err != nil {
 			return err
 		}
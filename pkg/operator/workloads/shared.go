@@ -17,6 +17,8 @@ limitations under the License.
 package workloads
 
 import (
+	gocontext "context"
+
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	"github.com/cortexlabs/cortex/pkg/lib/random"
@@ -32,7 +34,7 @@ func generateWorkloadID() string {
 }
 
 // Check if all resourceIDs have succeeded (only data resource types)
-func areAllDataResourcesSucceeded(ctx *context.Context, resourceIDs strset.Set) (bool, error) {
+func areAllDataResourcesSucceeded(goCtx gocontext.Context, ctx *context.Context, resourceIDs strset.Set) (bool, error) {
 	resourceWorkloadIDs := ctx.DataResourceWorkloadIDs()
 	for resourceID := range resourceIDs {
 		workloadID := resourceWorkloadIDs[resourceID]
@@ -40,7 +42,7 @@ func areAllDataResourcesSucceeded(ctx *context.Context, resourceIDs strset.Set)
 			continue
 		}
 
-		savedStatus, err := getDataSavedStatus(resourceID, workloadID, ctx.App.Name)
+		savedStatus, err := getDataSavedStatus(goCtx, resourceID, workloadID, ctx.App.Name)
 		if err != nil {
 			return false, err
 		}
@@ -54,7 +56,7 @@ func areAllDataResourcesSucceeded(ctx *context.Context, resourceIDs strset.Set)
 }
 
 // Check if any resourceIDs have succeeded (only data resource types)
-func areAnyDataResourcesFailed(ctx *context.Context, resourceIDs strset.Set) (bool, error) {
+func areAnyDataResourcesFailed(goCtx gocontext.Context, ctx *context.Context, resourceIDs strset.Set) (bool, error) {
 	resourceWorkloadIDs := ctx.DataResourceWorkloadIDs()
 	for resourceID := range resourceIDs {
 		workloadID := resourceWorkloadIDs[resourceID]
@@ -62,7 +64,7 @@ func areAnyDataResourcesFailed(ctx *context.Context, resourceIDs strset.Set) (bo
 			continue
 		}
 
-		savedStatus, err := getDataSavedStatus(resourceID, workloadID, ctx.App.Name)
+		savedStatus, err := getDataSavedStatus(goCtx, resourceID, workloadID, ctx.App.Name)
 		if err != nil {
 			return false, err
 		}
@@ -76,9 +78,9 @@ func areAnyDataResourcesFailed(ctx *context.Context, resourceIDs strset.Set) (bo
 }
 
 // Check if all dependencies of targetResourceIDs have succeeded (only data resource types)
-func areAllDataDependenciesSucceeded(ctx *context.Context, targetResourceIDs strset.Set) (bool, error) {
+func areAllDataDependenciesSucceeded(goCtx gocontext.Context, ctx *context.Context, targetResourceIDs strset.Set) (bool, error) {
 	dependencies := ctx.DirectComputedResourceDependencies(targetResourceIDs.Slice()...)
-	return areAllDataResourcesSucceeded(ctx, dependencies)
+	return areAllDataResourcesSucceeded(goCtx, ctx, dependencies)
 }
 
 func baseEnvVars() []kcore.EnvFromSource {
@@ -0,0 +1,371 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/config"
+)
+
+const (
+	UsageGroupByConsumer = "consumer"
+	UsageGroupByAPI      = "api"
+	UsageGroupByDay      = "day"
+
+	UsageDateFormat = "2006-01-02"
+
+	_usageDefaultDays     = 7
+	_usageMaxDays         = 455           // cloudwatch keeps hourly datapoints for 455 days
+	_usageExportDelay     = 1 * time.Hour // a day is exported once its late metrics have arrived
+	_usageExportInterval  = 10 * time.Minute
+	_maxMetricDataQueries = 500 // per cloudwatch.GetMetricData request
+)
+
+var UsageGroupBys = []string{UsageGroupByConsumer, UsageGroupByAPI, UsageGroupByDay}
+
+var _lastUsageExportCron time.Time
+
+var _usageExportedDays = map[string]string{} // deployment name -> the last day which was exported
+
+// usageSample is one datapoint of a per-consumer metric (either a status code count, or a latency average and its sample count)
+type usageSample struct {
+	consumer     string
+	apiName      string
+	day          string
+	code         string
+	count        float64
+	latencyAvg   float64
+	latencyCount float64
+}
+
+// UsageRange parses the start and end days of a usage query (YYYY-MM-DD, UTC, inclusive); end defaults to today and start to a week before end
+func UsageRange(startStr string, endStr string) (time.Time, time.Time, error) {
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+	if endStr != "" {
+		var err error
+		if end, err = time.Parse(UsageDateFormat, endStr); err != nil {
+			return time.Time{}, time.Time{}, ErrorInvalidUsageDate(endStr)
+		}
+	}
+
+	start := end.AddDate(0, 0, -(_usageDefaultDays - 1))
+	if startStr != "" {
+		var err error
+		if start, err = time.Parse(UsageDateFormat, startStr); err != nil {
+			return time.Time{}, time.Time{}, ErrorInvalidUsageDate(startStr)
+		}
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, ErrorInvalidUsageRange(fmt.Sprintf("the end date (%s) is before the start date (%s)", end.Format(UsageDateFormat), start.Format(UsageDateFormat)))
+	}
+	if end.Sub(start) >= _usageMaxDays*24*time.Hour {
+		return time.Time{}, time.Time{}, ErrorInvalidUsageRange(fmt.Sprintf("usage can be queried for up to %d days at a time", _usageMaxDays))
+	}
+
+	return start, end, nil
+}
+
+func ValidateUsageGroupBy(groupBy []string) error {
+	for _, field := range groupBy {
+		if !strset.New(UsageGroupBys...).Has(field) {
+			return ErrorInvalidUsageGroupBy(field)
+		}
+	}
+	return nil
+}
+
+// GetUsage returns the deployment's per-consumer usage between the start and end days (inclusive)
+func GetUsage(goCtx gocontext.Context, appName string, start time.Time, end time.Time, groupBy []string) ([]schema.UsageRow, error) {
+	statusCodeMetrics, err := listConsumerMetrics(goCtx, appName, "StatusCode")
+	if err != nil {
+		return nil, err
+	}
+	latencyMetrics, err := listConsumerMetrics(goCtx, appName, "Latency")
+	if err != nil {
+		return nil, err
+	}
+
+	metricsByID := map[string]*cloudwatch.Metric{}
+	var queries []*cloudwatch.MetricDataQuery
+	addQuery := func(id string, metric *cloudwatch.Metric, stat string) {
+		metricsByID[id] = metric
+		queries = append(queries, &cloudwatch.MetricDataQuery{
+			Id: aws.String(id),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: metric,
+				Stat:   aws.String(stat),
+				Period: aws.Int64(24 * 60 * 60),
+			},
+		})
+	}
+	for i, metric := range statusCodeMetrics {
+		addQuery(fmt.Sprintf("code_%d", i), metric, "Sum")
+	}
+	for i, metric := range latencyMetrics {
+		addQuery(fmt.Sprintf("latency_avg_%d", i), metric, "Average")
+		addQuery(fmt.Sprintf("latency_count_%d", i), metric, "SampleCount")
+	}
+
+	latencySamples := map[string]*usageSample{} // metric index + day -> sample
+	var samples []*usageSample
+
+	for len(queries) > 0 {
+		batch := queries
+		if len(batch) > _maxMetricDataQueries {
+			batch = queries[:_maxMetricDataQueries]
+		}
+		queries = queries[len(batch):]
+
+		input := &cloudwatch.GetMetricDataInput{
+			StartTime:         aws.Time(start),
+			EndTime:           aws.Time(end.AddDate(0, 0, 1)),
+			MetricDataQueries: batch,
+		}
+		err := config.AWS.WithContext(goCtx).CloudWatchMetrics.GetMetricDataPages(input, func(output *cloudwatch.GetMetricDataOutput, lastPage bool) bool {
+			for _, result := range output.MetricDataResults {
+				id := *result.Id
+				dimensions := metricDimensions(metricsByID[id])
+				for i := range result.Values {
+					if result.Values[i] == nil || i >= len(result.Timestamps) || result.Timestamps[i] == nil {
+						continue
+					}
+					day := result.Timestamps[i].UTC().Format(UsageDateFormat)
+
+					if strings.HasPrefix(id, "code_") {
+						samples = append(samples, &usageSample{
+							consumer: dimensions["Consumer"],
+							apiName:  dimensions["APIName"],
+							day:      day,
+							code:     dimensions["Code"],
+							count:    *result.Values[i],
+						})
+						continue
+					}
+
+					key := id[strings.LastIndex(id, "_")+1:] + "_" + day
+					sample, ok := latencySamples[key]
+					if !ok {
+						sample = &usageSample{consumer: dimensions["Consumer"], apiName: dimensions["APIName"], day: day}
+						latencySamples[key] = sample
+						samples = append(samples, sample)
+					}
+					if strings.HasPrefix(id, "latency_avg_") {
+						sample.latencyAvg = *result.Values[i]
+					} else {
+						sample.latencyCount = *result.Values[i]
+					}
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "querying usage metrics")
+		}
+	}
+
+	return aggregateUsage(samples, groupBy), nil
+}
+
+// listConsumerMetrics lists the deployment's metrics which have a consumer dimension (cloudwatch only lists metrics which received data in the past two weeks)
+func listConsumerMetrics(goCtx gocontext.Context, appName string, metricName string) ([]*cloudwatch.Metric, error) {
+	input := &cloudwatch.ListMetricsInput{
+		Namespace:  aws.String(config.Cluster.LogGroup),
+		MetricName: aws.String(metricName),
+		Dimensions: []*cloudwatch.DimensionFilter{
+			{Name: aws.String("AppName"), Value: aws.String(appName)},
+			{Name: aws.String("Consumer")},
+		},
+	}
+
+	var metrics []*cloudwatch.Metric
+	err := config.AWS.WithContext(goCtx).CloudWatchMetrics.ListMetricsPages(input, func(output *cloudwatch.ListMetricsOutput, lastPage bool) bool {
+		metrics = append(metrics, output.Metrics...)
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing usage metrics")
+	}
+	return metrics, nil
+}
+
+func metricDimensions(metric *cloudwatch.Metric) map[string]string {
+	dimensions := map[string]string{}
+	if metric == nil {
+		return dimensions
+	}
+	for _, dimension := range metric.Dimensions {
+		if dimension.Name != nil && dimension.Value != nil {
+			dimensions[*dimension.Name] = *dimension.Value
+		}
+	}
+	return dimensions
+}
+
+func aggregateUsage(samples []*usageSample, groupBy []string) []schema.UsageRow {
+	groupBySet := strset.New(groupBy...)
+
+	type latencyTotals struct {
+		sum   float64
+		count float64
+	}
+
+	rows := map[string]*schema.UsageRow{}
+	latencies := map[string]*latencyTotals{}
+
+	for _, sample := range samples {
+		var row schema.UsageRow
+		if groupBySet.Has(UsageGroupByConsumer) {
+			row.Consumer = sample.consumer
+		}
+		if groupBySet.Has(UsageGroupByAPI) {
+			row.APIName = sample.apiName
+		}
+		if groupBySet.Has(UsageGroupByDay) {
+			row.Day = sample.day
+		}
+		key := row.Consumer + "\n" + row.APIName + "\n" + row.Day
+
+		if _, ok := rows[key]; !ok {
+			rows[key] = &row
+			latencies[key] = &latencyTotals{}
+		}
+
+		count := int(sample.count + 0.5)
+		switch sample.code {
+		case "2XX":
+			rows[key].Code2XX += count
+		case "4XX":
+			rows[key].Code4XX += count
+		case "5XX":
+			rows[key].Code5XX += count
+		}
+		rows[key].Requests += count
+
+		latencies[key].sum += sample.latencyAvg * sample.latencyCount
+		latencies[key].count += sample.latencyCount
+	}
+
+	usageRows := make([]schema.UsageRow, 0, len(rows))
+	for key, row := range rows {
+		if latencies[key].count > 0 {
+			latency := latencies[key].sum / latencies[key].count
+			row.Latency = &latency
+		}
+		usageRows = append(usageRows, *row)
+	}
+
+	sort.Slice(usageRows, func(i, j int) bool {
+		if usageRows[i].Consumer != usageRows[j].Consumer {
+			return usageRows[i].Consumer < usageRows[j].Consumer
+		}
+		if usageRows[i].APIName != usageRows[j].APIName {
+			return usageRows[i].APIName < usageRows[j].APIName
+		}
+		return usageRows[i].Day < usageRows[j].Day
+	})
+
+	return usageRows
+}
+
+func usageExportKey(appName string, day string) string {
+	return filepath.Join(consts.UsageDir, appName, day+".csv")
+}
+
+func usageCSV(day string, rows []schema.UsageRow) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	writer.Write([]string{"day", "consumer", "api", "requests", "2xx", "4xx", "5xx", "latency_ms"})
+	for _, row := range rows {
+		latency := ""
+		if row.Latency != nil {
+			latency = s.Round(*row.Latency, 3, 0)
+		}
+		writer.Write([]string{day, row.Consumer, row.APIName, s.Int(row.Requests), s.Int(row.Code2XX), s.Int(row.Code4XX), s.Int(row.Code5XX), latency})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// exportUsage uploads each deployment's per-consumer usage for the previous day (UTC) to the cluster's bucket as CSV
+func exportUsage(goCtx gocontext.Context) error {
+	if time.Since(_lastUsageExportCron) < _usageExportInterval {
+		return nil
+	}
+	_lastUsageExportCron = time.Now()
+
+	day := time.Now().UTC().Add(-_usageExportDelay).Truncate(24*time.Hour).AddDate(0, 0, -1)
+	dayStr := day.Format(UsageDateFormat)
+
+	var errs []error
+	for _, ctx := range CurrentContexts() {
+		appName := ctx.App.Name
+		if ctx.App.Usage == nil || _usageExportedDays[appName] == dayStr {
+			continue
+		}
+
+		key := usageExportKey(appName, dayStr)
+		exists, err := config.AWS.WithContext(goCtx).IsS3File(key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !exists {
+			rows, err := GetUsage(goCtx, appName, day, day, []string{UsageGroupByConsumer, UsageGroupByAPI})
+			if err != nil {
+				errs = append(errs, errors.Wrap(err, appName))
+				continue
+			}
+			csvBytes, err := usageCSV(dayStr, rows)
+			if err != nil {
+				errs = append(errs, errors.Wrap(err, appName))
+				continue
+			}
+			if err := config.AWS.WithContext(goCtx).UploadBytesToS3(csvBytes, key); err != nil {
+				errs = append(errs, errors.Wrap(err, appName))
+				continue
+			}
+		}
+		_usageExportedDays[appName] = dayStr
+	}
+
+	if len(errs) > 0 {
+		return errors.Wrap(errors.FirstError(errs...), "exporting usage")
+	}
+	return nil
+}
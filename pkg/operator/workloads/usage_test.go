@@ -0,0 +1,77 @@
+/*
+Copyright 2019 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/operator/api/schema"
+)
+
+var _testUsageSamples = []*usageSample{
+	{consumer: "team-a", apiName: "iris", day: "2020-01-01", code: "2XX", count: 90},
+	{consumer: "team-a", apiName: "iris", day: "2020-01-01", code: "4XX", count: 10},
+	{consumer: "team-a", apiName: "iris", day: "2020-01-01", latencyAvg: 20, latencyCount: 100},
+	{consumer: "team-a", apiName: "iris", day: "2020-01-02", code: "2XX", count: 50},
+	{consumer: "team-a", apiName: "iris", day: "2020-01-02", latencyAvg: 50, latencyCount: 50},
+	{consumer: "team-b", apiName: "wine", day: "2020-01-02", code: "5XX", count: 3},
+}
+
+func TestAggregateUsage(t *testing.T) {
+	require.Equal(t, []schema.UsageRow{
+		{Consumer: "team-a", Requests: 150, Code2XX: 140, Code4XX: 10, Latency: pointer.Float64(30)},
+		{Consumer: "team-b", Requests: 3, Code5XX: 3},
+	}, aggregateUsage(_testUsageSamples, []string{UsageGroupByConsumer}))
+
+	require.Equal(t, []schema.UsageRow{
+		{APIName: "iris", Day: "2020-01-01", Requests: 100, Code2XX: 90, Code4XX: 10, Latency: pointer.Float64(20)},
+		{APIName: "iris", Day: "2020-01-02", Requests: 50, Code2XX: 50, Latency: pointer.Float64(50)},
+		{APIName: "wine", Day: "2020-01-02", Requests: 3, Code5XX: 3},
+	}, aggregateUsage(_testUsageSamples, []string{UsageGroupByAPI, UsageGroupByDay}))
+
+	require.Empty(t, aggregateUsage(nil, []string{UsageGroupByConsumer}))
+}
+
+func TestUsageRange(t *testing.T) {
+	start, end, err := UsageRange("2020-01-01", "2020-01-31")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), start)
+	require.Equal(t, time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC), end)
+
+	start, end, err = UsageRange("", "2020-01-31")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2020, 1, 25, 0, 0, 0, 0, time.UTC), start)
+
+	_, _, err = UsageRange("2020-02-01", "2020-01-31")
+	require.Error(t, err)
+	_, _, err = UsageRange("2018-01-01", "2020-01-31")
+	require.Error(t, err)
+	_, _, err = UsageRange("01/01/2020", "")
+	require.Error(t, err)
+}
+
+func TestUsageCSV(t *testing.T) {
+	csvBytes, err := usageCSV("2020-01-01", aggregateUsage(_testUsageSamples, []string{UsageGroupByConsumer, UsageGroupByAPI}))
+	require.NoError(t, err)
+	require.Equal(t, "day,consumer,api,requests,2xx,4xx,5xx,latency_ms\n"+
+		"2020-01-01,team-a,iris,150,140,10,0,30\n"+
+		"2020-01-01,team-b,wine,3,0,0,3,\n", string(csvBytes))
+}
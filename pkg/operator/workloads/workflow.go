@@ -17,8 +17,10 @@ limitations under the License.
 package workloads
 
 import (
+	gocontext "context"
 	"fmt"
 	"path/filepath"
+	"time"
 
 	kresource "k8s.io/apimachinery/pkg/api/resource"
 
@@ -56,12 +58,18 @@ var cortexMemReserve = kresource.MustParse("1200Mi")
 var nvidiaCPUReserve = kresource.MustParse("100m")
 var nvidiaMemReserve = kresource.MustParse("100Mi")
 
+const _initTimeout = 5 * time.Minute
+
 func Init() error {
-	err := reloadCurrentContexts()
+	goCtx, cancel := gocontext.WithTimeout(gocontext.Background(), _initTimeout)
+	defer cancel()
+	goCtx = k8s.ContextWithClientCache(goCtx)
+
+	err := reloadCurrentContexts(goCtx)
 	if err != nil {
 		return errors.Wrap(err, "init")
 	}
-	_, err = UpdateMemoryCapacityConfigMap()
+	_, err = UpdateMemoryCapacityConfigMap(goCtx)
 	if err != nil {
 		return errors.Wrap(err, "init")
 	}
@@ -71,9 +79,9 @@ func Init() error {
 	return nil
 }
 
-func PopulateWorkloadIDs(ctx *context.Context) error {
+func PopulateWorkloadIDs(goCtx gocontext.Context, ctx *context.Context) error {
 	resourceIDs := ctx.ComputedResourceIDs()
-	latestResourceWorkloadIDs, err := getSavedLatestWorkloadIDs(resourceIDs, ctx.App.Name)
+	latestResourceWorkloadIDs, err := getSavedLatestWorkloadIDs(goCtx, resourceIDs, ctx.App.Name)
 	if err != nil {
 		return err
 	}
@@ -93,30 +101,32 @@ func extractWorkloads(ctx *context.Context) []Workload {
 	return workloads
 }
 
-func Run(ctx *context.Context) error {
+func Run(goCtx gocontext.Context, ctx *context.Context) error {
 	if err := ctx.CheckAllWorkloadIDsPopulated(); err != nil {
 		return err
 	}
 
+	_workflowMutex.Lock()
+	defer _workflowMutex.Unlock()
+
 	prevCtx := CurrentContext(ctx.App.Name)
-	err := deleteOldDataJobs(prevCtx)
+
+	steps, err := deploySteps(goCtx, ctx, prevCtx)
 	if err != nil {
 		return err
 	}
 
-	deleteOldAPIs(ctx)
-
-	err = setCurrentContext(ctx)
+	err = runDeployTransaction(goCtx, steps)
 	if err != nil {
 		return err
 	}
 
-	resourceWorkloadIDs := ctx.ComputedResourceResourceWorkloadIDs()
-	err = uploadLatestWorkloadIDs(resourceWorkloadIDs, ctx.App.Name)
+	err = deleteOldDataJobs(goCtx, prevCtx)
 	if err != nil {
 		return err
 	}
 
+	resourceWorkloadIDs := ctx.ComputedResourceResourceWorkloadIDs()
 	uncacheDataSavedStatuses(resourceWorkloadIDs, ctx.App.Name)
 	uncacheLatestWorkloadIDs(ctx.ComputedResourceIDs(), ctx.App.Name)
 
@@ -125,17 +135,22 @@ func Run(ctx *context.Context) error {
 	return nil
 }
 
-func deleteOldDataJobs(ctx *context.Context) error {
+func deleteOldDataJobs(goCtx gocontext.Context, ctx *context.Context) error {
 	if ctx == nil {
 		return nil
 	}
 
-	jobs, _ := config.Kubernetes.ListJobsByLabel("appName", ctx.App.Name)
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return err
+	}
+
+	jobs, _ := k8sClient.ListJobsByLabel("appName", ctx.App.Name)
 	for _, job := range jobs {
-		config.Kubernetes.DeleteJob(job.Name)
+		k8sClient.DeleteJob(job.Name)
 	}
 
-	err := updateKilledDataSavedStatuses(ctx)
+	err = updateKilledDataSavedStatuses(goCtx, ctx)
 	if err != nil {
 		return err
 	}
@@ -143,50 +158,74 @@ func deleteOldDataJobs(ctx *context.Context) error {
 	return nil
 }
 
-func DeleteApp(appName string, keepCache bool) bool {
+func DeleteApp(goCtx gocontext.Context, appName string, keepCache bool) (bool, error) {
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return false, err
+	}
+
 	wasDeployed := false
 	if ctx := CurrentContext(appName); ctx != nil {
-		updateKilledDataSavedStatuses(ctx)
+		updateKilledDataSavedStatuses(goCtx, ctx)
 		wasDeployed = true
 	}
 
-	deleteCurrentContext(appName)
+	deleteCurrentContext(goCtx, appName)
 	uncacheDataSavedStatuses(nil, appName)
 	uncacheLatestWorkloadIDs(nil, appName)
 
-	virtualServices, _ := config.Kubernetes.ListVirtualServicesByLabel(consts.K8sNamespace, "appName", appName)
+	virtualServices, _ := k8sClient.ListVirtualServicesByLabel(consts.K8sNamespace, "appName", appName)
 	for _, virtualService := range virtualServices {
-		config.Kubernetes.DeleteVirtualService(virtualService.GetName(), consts.K8sNamespace)
+		k8sClient.DeleteVirtualService(virtualService.GetName(), consts.K8sNamespace)
+	}
+	destinationRules, _ := k8sClient.ListDestinationRulesByLabel(consts.K8sNamespace, "appName", appName)
+	for _, destinationRule := range destinationRules {
+		k8sClient.DeleteDestinationRule(destinationRule.GetName(), consts.K8sNamespace)
 	}
-	services, _ := config.Kubernetes.ListServicesByLabel("appName", appName)
+	authenticationPolicies, _ := k8sClient.ListAuthenticationPoliciesByLabel(consts.K8sNamespace, "appName", appName)
+	for _, authenticationPolicy := range authenticationPolicies {
+		k8sClient.DeleteAuthenticationPolicy(authenticationPolicy.GetName(), consts.K8sNamespace)
+	}
+	authorizationPolicies, _ := k8sClient.ListAuthorizationPoliciesByLabel(consts.K8sNamespace, "appName", appName)
+	for _, authorizationPolicy := range authorizationPolicies {
+		k8sClient.DeleteAuthorizationPolicy(authorizationPolicy.GetName(), consts.K8sNamespace)
+	}
+	networkPolicies, _ := k8sClient.ListNetworkPoliciesByLabel("appName", appName)
+	for _, networkPolicy := range networkPolicies {
+		k8sClient.DeleteNetworkPolicy(networkPolicy.Name)
+	}
+	services, _ := k8sClient.ListServicesByLabel("appName", appName)
 	for _, service := range services {
-		config.Kubernetes.DeleteService(service.Name)
+		k8sClient.DeleteService(service.Name)
 	}
-	hpas, _ := config.Kubernetes.ListHPAsByLabel("appName", appName)
+	hpas, _ := k8sClient.ListHPAsByLabel("appName", appName)
 	for _, hpa := range hpas {
-		config.Kubernetes.DeleteHPA(hpa.Name)
+		k8sClient.DeleteHPA(hpa.Name)
 	}
-	jobs, _ := config.Kubernetes.ListJobsByLabel("appName", appName)
+	jobs, _ := k8sClient.ListJobsByLabel("appName", appName)
 	for _, job := range jobs {
-		config.Kubernetes.DeleteJob(job.Name)
+		k8sClient.DeleteJob(job.Name)
 	}
-	deployments, _ := config.Kubernetes.ListDeploymentsByLabel("appName", appName)
+	deployments, _ := k8sClient.ListDeploymentsByLabel("appName", appName)
 	for _, deployment := range deployments {
-		config.Kubernetes.DeleteDeployment(deployment.Name)
+		k8sClient.DeleteDeployment(deployment.Name)
 	}
 
 	if !keepCache {
-		config.AWS.DeleteFromS3ByPrefix(filepath.Join(consts.AppsDir, appName), true)
+		config.AWS.WithContext(goCtx).DeleteFromS3ByPrefix(filepath.Join(consts.AppsDir, appName), true)
 	}
 
-	return wasDeployed
+	return wasDeployed, nil
 }
 
-func UpdateWorkflows() error {
+func UpdateWorkflows(goCtx gocontext.Context) error {
+	_workflowMutex.Lock()
+	defer _workflowMutex.Unlock()
+
 	currentWorkloadIDs := make(map[string]strset.Set)
 
 	for _, ctx := range CurrentContexts() {
-		err := updateWorkflow(ctx)
+		err := updateWorkflow(goCtx, ctx)
 		if err != nil {
 			return err
 		}
@@ -199,16 +238,16 @@ func UpdateWorkflows() error {
 	return nil
 }
 
-func updateWorkflow(ctx *context.Context) error {
+func updateWorkflow(goCtx gocontext.Context, ctx *context.Context) error {
 	workloads := extractWorkloads(ctx)
 
-	err := uploadBaseWorkloadsFromWorkloads(workloads)
+	err := uploadBaseWorkloadsFromWorkloads(goCtx, workloads)
 	if err != nil {
 		return err
 	}
 
 	for _, workload := range workloads {
-		isSucceeded, err := workload.IsSucceeded(ctx)
+		isSucceeded, err := workload.IsSucceeded(goCtx, ctx)
 		if err != nil {
 			return err
 		}
@@ -216,7 +255,7 @@ func updateWorkflow(ctx *context.Context) error {
 			continue
 		}
 
-		isFailed, err := workload.IsFailed(ctx)
+		isFailed, err := workload.IsFailed(goCtx, ctx)
 		if err != nil {
 			return err
 		}
@@ -224,7 +263,7 @@ func updateWorkflow(ctx *context.Context) error {
 			continue
 		}
 
-		isStarted, err := workload.IsStarted(ctx)
+		isStarted, err := workload.IsStarted(goCtx, ctx)
 		if err != nil {
 			return err
 		}
@@ -232,7 +271,7 @@ func updateWorkflow(ctx *context.Context) error {
 			continue
 		}
 
-		canRun, err := workload.CanRun(ctx)
+		canRun, err := workload.CanRun(goCtx, ctx)
 		if err != nil {
 			return err
 		}
@@ -240,7 +279,7 @@ func updateWorkflow(ctx *context.Context) error {
 			continue
 		}
 
-		err = workload.Start(ctx)
+		err = workload.Start(goCtx, ctx)
 		if err != nil {
 			return err
 		}
@@ -249,7 +288,7 @@ func updateWorkflow(ctx *context.Context) error {
 	return nil
 }
 
-func IsWorkloadEnded(appName string, workloadID string) (bool, error) {
+func IsWorkloadEnded(goCtx gocontext.Context, appName string, workloadID string) (bool, error) {
 	ctx := CurrentContext(appName)
 	if ctx == nil {
 		return false, nil
@@ -257,7 +296,7 @@ func IsWorkloadEnded(appName string, workloadID string) (bool, error) {
 
 	for _, workload := range extractWorkloads(ctx) {
 		if workload.GetWorkloadID() == workloadID {
-			isSucceeded, err := workload.IsSucceeded(ctx)
+			isSucceeded, err := workload.IsSucceeded(goCtx, ctx)
 			if err != nil {
 				return false, err
 			}
@@ -265,7 +304,7 @@ func IsWorkloadEnded(appName string, workloadID string) (bool, error) {
 				return true, nil
 			}
 
-			isFailed, err := workload.IsFailed(ctx)
+			isFailed, err := workload.IsFailed(goCtx, ctx)
 			if err != nil {
 				return false, err
 			}
@@ -280,7 +319,7 @@ func IsWorkloadEnded(appName string, workloadID string) (bool, error) {
 	return false, errors.New("workload not found in the current context")
 }
 
-func GetDeploymentStatus(appName string) (resource.DeploymentStatus, error) {
+func GetDeploymentStatus(goCtx gocontext.Context, appName string) (resource.DeploymentStatus, error) {
 	ctx := CurrentContext(appName)
 	if ctx == nil {
 		return resource.UnknownDeploymentStatus, nil
@@ -294,7 +333,7 @@ func GetDeploymentStatus(appName string) (resource.DeploymentStatus, error) {
 			continue
 		}
 
-		isSucceeded, err := workload.IsSucceeded(ctx)
+		isSucceeded, err := workload.IsSucceeded(goCtx, ctx)
 		if err != nil {
 			return resource.UnknownDeploymentStatus, err
 		}
@@ -302,7 +341,7 @@ func GetDeploymentStatus(appName string) (resource.DeploymentStatus, error) {
 			continue
 		}
 
-		isFailed, err := workload.IsFailed(ctx)
+		isFailed, err := workload.IsFailed(goCtx, ctx)
 		if err != nil {
 			return resource.UnknownDeploymentStatus, err
 		}
@@ -310,7 +349,7 @@ func GetDeploymentStatus(appName string) (resource.DeploymentStatus, error) {
 			return resource.ErrorDeploymentStatus, nil
 		}
 
-		canRun, err := workload.CanRun(ctx)
+		canRun, err := workload.CanRun(goCtx, ctx)
 		if err != nil {
 			return resource.UnknownDeploymentStatus, err
 		}
@@ -326,14 +365,22 @@ func GetDeploymentStatus(appName string) (resource.DeploymentStatus, error) {
 	return resource.UpdatedDeploymentStatus, nil
 }
 
-func ValidateDeploy(ctx *context.Context) error {
-	if err := CheckAPIEndpointCollisions(ctx); err != nil {
+func ValidateDeploy(goCtx gocontext.Context, ctx *context.Context) error {
+	if err := CheckAPIEndpointCollisions(goCtx, ctx); err != nil {
+		return err
+	}
+
+	if err := validatePythonPackageIndexSecrets(goCtx, ctx); err != nil {
+		return err
+	}
+
+	if err := validateJWTAuth(ctx); err != nil {
 		return err
 	}
 
 	maxCPU := config.Cluster.InstanceMetadata.CPU
 	maxCPU.Sub(cortexCPUReserve)
-	maxMem, err := UpdateMemoryCapacityConfigMap()
+	maxMem, err := UpdateMemoryCapacityConfigMap(goCtx)
 	if err != nil {
 		return errors.Wrap(err, "validating memory constraint")
 	}
@@ -362,13 +409,18 @@ func ValidateDeploy(ctx *context.Context) error {
 	return nil
 }
 
-func CheckAPIEndpointCollisions(ctx *context.Context) error {
+func CheckAPIEndpointCollisions(goCtx gocontext.Context, ctx *context.Context) error {
 	apiEndpoints := map[string]string{} // endpoint -> API identifiction string
 	for _, api := range ctx.APIs {
 		apiEndpoints[*api.Endpoint] = userconfig.Identify(api)
 	}
 
-	virtualServices, err := config.Kubernetes.ListVirtualServices(consts.K8sNamespace, nil)
+	k8sClient, err := config.Kubernetes.WithContext(goCtx)
+	if err != nil {
+		return err
+	}
+
+	virtualServices, err := k8sClient.ListVirtualServices(consts.K8sNamespace, nil)
 	if err != nil {
 		return err
 	}
@@ -17,6 +17,8 @@ limitations under the License.
 package workloads
 
 import (
+	gocontext "context"
+
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	"github.com/cortexlabs/cortex/pkg/operator/api/context"
 )
@@ -24,16 +26,18 @@ import (
 const (
 	workloadTypeAPI = "api"
 	workloadTypeHPA = "hpa"
+
+	workloadTypeImagePrepuller = "image-prepuller"
 )
 
 type Workload interface {
 	BaseWorkloadInterface
-	CanRun(*context.Context) (bool, error)      // All of the dependencies are satisfied and the workload can be started
-	Start(*context.Context) error               // Start the workload
-	IsStarted(*context.Context) (bool, error)   // The workload was started on the most recent deploy (might be running, succeeded, or failed). It's ok if this doesn't remain accurate across cx deploys
-	IsRunning(*context.Context) (bool, error)   // The workload is currently running
-	IsSucceeded(*context.Context) (bool, error) // The workload succeeded
-	IsFailed(*context.Context) (bool, error)    // The workload failed
+	CanRun(gocontext.Context, *context.Context) (bool, error)      // All of the dependencies are satisfied and the workload can be started
+	Start(gocontext.Context, *context.Context) error               // Start the workload
+	IsStarted(gocontext.Context, *context.Context) (bool, error)   // The workload was started on the most recent deploy (might be running, succeeded, or failed). It's ok if this doesn't remain accurate across cx deploys
+	IsRunning(gocontext.Context, *context.Context) (bool, error)   // The workload is currently running
+	IsSucceeded(gocontext.Context, *context.Context) (bool, error) // The workload succeeded
+	IsFailed(gocontext.Context, *context.Context) (bool, error)    // The workload failed
 }
 
 type BaseWorkload struct {
@@ -18,25 +18,26 @@ import base64
 import json
 
 from cortex.lib import util
-from cortex.lib.storage import S3
 from cortex.lib.log import cx_logger
+from cortex.downloader.transfer import Downloader
 
 
 def start(args):
     download_config = json.loads(base64.urlsafe_b64decode(args.download))
+    downloader = Downloader(download_config)
     for download_arg in download_config["download_args"]:
         from_path = download_arg["from"]
         to_path = download_arg["to"]
         item_name = download_arg.get("item_name", "")
-        bucket_name, prefix = S3.deconstruct_s3_path(from_path)
-        s3_client = S3(bucket_name, client_config={})
 
         if item_name != "":
             if download_arg.get("hide_from_log", False):
                 cx_logger().info("downloading {}".format(item_name))
             else:
                 cx_logger().info("downloading {} from {}".format(item_name, from_path))
-        s3_client.download(prefix, to_path)
+        downloader.download(
+            from_path, to_path, item_name=item_name, manifest=download_arg.get("manifest", "")
+        )
 
         if download_arg.get("unzip", False):
             if item_name != "" and not download_arg.get("hide_unzipping_log", False):
@@ -0,0 +1,262 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+import time
+import random
+import hashlib
+import threading
+from concurrent.futures import ThreadPoolExecutor
+
+import botocore.config
+
+from cortex.lib import util
+from cortex.lib.storage import S3
+from cortex.lib.exceptions import CortexException
+from cortex.lib.log import cx_logger
+
+READ_CHUNK_SIZE = 1024 * 1024
+PROGRESS_LOG_INTERVAL_SEC = 10
+MAX_BACKOFF_SEC = 30
+
+
+def size_progress_str(downloaded, total):
+    # the operator parses this format to report download progress in `cortex get` (see api_status.go)
+    for unit, divisor in [("GB", 1024 ** 3), ("MB", 1024 ** 2), ("KB", 1024)]:
+        if total >= divisor:
+            return "{:.1f}/{:.1f} {}".format(downloaded / divisor, total / divisor, unit)
+    return "{}/{} B".format(downloaded, total)
+
+
+class Progress:
+    def __init__(self, item_name, total):
+        self.item_name = item_name
+        self.total = total
+        self.downloaded = 0
+        self.lock = threading.Lock()
+        self.done = threading.Event()
+
+    def add(self, num_bytes):
+        with self.lock:
+            self.downloaded += num_bytes
+
+    def log(self):
+        with self.lock:
+            downloaded = self.downloaded
+        cx_logger().info(
+            "downloading {} ({})".format(self.item_name, size_progress_str(downloaded, self.total))
+        )
+
+    def log_periodically(self):
+        while not self.done.wait(PROGRESS_LOG_INTERVAL_SEC):
+            self.log()
+
+
+class Downloader:
+    def __init__(self, download_config):
+        self.parallelism = download_config.get("parallelism", 1)
+        self.part_size = download_config.get("part_size_bytes", 64 * 1024 * 1024)
+        self.max_retries = download_config.get("max_retries", 0)
+        self.stall_timeout = download_config.get("stall_timeout_seconds", 300)
+
+        timeout = download_config.get("timeout_seconds", 0)
+        self.deadline = time.time() + timeout if timeout > 0 else None
+
+    def _check_deadline(self):
+        if self.deadline is not None and time.time() > self.deadline:
+            raise CortexException("download timed out")
+
+    def _client(self, bucket_name):
+        # a stalled connection surfaces as a read timeout, which is retried like any other failure
+        client_config = {
+            "config": botocore.config.Config(
+                read_timeout=self.stall_timeout, retries={"max_attempts": 0}
+            )
+        }
+        return S3(bucket_name, client_config=client_config)
+
+    def _list_objects(self, s3_client, prefix, local_dir):
+        """Returns a list of (object, local path, path relative to the downloaded item)"""
+        if s3_client._is_s3_dir(prefix):
+            dir_name = util.trim_suffix(prefix, "/").split("/")[-1]
+            dir_prefix = util.ensure_suffix(prefix, "/")
+            objects = []
+            for obj in s3_client._get_matching_s3_objects_generator(dir_prefix):
+                if obj["Key"].endswith("/"):
+                    continue
+                rel_path = util.trim_prefix(obj["Key"], dir_prefix)
+                objects.append((obj, os.path.join(local_dir, dir_name, rel_path), rel_path))
+            return objects
+
+        head = s3_client.s3.head_object(Bucket=s3_client.bucket, Key=prefix)
+        obj = {"Key": prefix, "Size": head["ContentLength"], "ETag": head["ETag"]}
+        filename = os.path.basename(prefix)
+        return [(obj, os.path.join(local_dir, filename), filename)]
+
+    def download(self, from_path, local_dir, item_name="", manifest=""):
+        bucket_name, prefix = S3.deconstruct_s3_path(from_path)
+        s3_client = self._client(bucket_name)
+
+        try:
+            objects = self._list_objects(s3_client, prefix, local_dir)
+        except Exception as e:
+            raise CortexException(
+                'key "{}" in bucket "{}" could not be accessed; '.format(prefix, bucket_name)
+                + "it may not exist, or you may not have sufficient permissions"
+            ) from e
+
+        expected_hashes = None
+        if manifest != "":
+            manifest_bucket, manifest_key = S3.deconstruct_s3_path(manifest)
+            expected_hashes = self._client(manifest_bucket).get_json(manifest_key)
+
+        progress = Progress(item_name, sum(obj["Size"] for obj, _, _ in objects))
+        if item_name != "":
+            threading.Thread(target=progress.log_periodically, daemon=True).start()
+
+        try:
+            for obj, local_path, _ in objects:
+                util.mkdir_p(os.path.dirname(local_path))
+                with open(local_path, "wb") as f:
+                    f.truncate(obj["Size"])
+
+            parts = []
+            for obj, local_path, _ in objects:
+                for start in range(0, obj["Size"], self.part_size):
+                    end = min(start + self.part_size, obj["Size"]) - 1
+                    parts.append((obj["Key"], local_path, start, end))
+
+            with ThreadPoolExecutor(max_workers=self.parallelism) as executor:
+                futures = [
+                    executor.submit(self._download_part, s3_client, progress, *part)
+                    for part in parts
+                ]
+                try:
+                    for future in futures:
+                        future.result()  # re-raises the first error
+                except:
+                    for future in futures:
+                        future.cancel()
+                    raise
+        finally:
+            progress.done.set()
+
+        if item_name != "" and len(parts) > 0:
+            progress.log()
+
+        for obj, local_path, rel_path in objects:
+            self._verify(s3_client, obj, local_path, rel_path, expected_hashes)
+
+        if expected_hashes is not None:
+            downloaded_paths = set(rel_path for _, _, rel_path in objects)
+            for rel_path in expected_hashes:
+                if rel_path not in downloaded_paths:
+                    raise CortexException(
+                        "{} is listed in the manifest ({}) but was not found in {}".format(
+                            rel_path, manifest, from_path
+                        )
+                    )
+
+    def _download_part(self, s3_client, progress, key, local_path, start, end):
+        attempt = 0
+        while True:
+            self._check_deadline()
+            written = 0
+            try:
+                response = s3_client.s3.get_object(
+                    Bucket=s3_client.bucket, Key=key, Range="bytes={}-{}".format(start, end)
+                )
+                body = response["Body"]
+                with open(local_path, "r+b") as f:
+                    f.seek(start)
+                    while True:
+                        chunk = body.read(READ_CHUNK_SIZE)
+                        if not chunk:
+                            break
+                        f.write(chunk)
+                        written += len(chunk)
+                        progress.add(len(chunk))
+                        self._check_deadline()
+                if written != end - start + 1:
+                    raise CortexException(
+                        "received {} bytes, expected {}".format(written, end - start + 1)
+                    )
+                return
+            except Exception as e:
+                progress.add(-written)
+                if self.deadline is not None and time.time() > self.deadline:
+                    raise CortexException("download timed out") from e
+                if attempt >= self.max_retries:
+                    raise CortexException(
+                        'unable to download key "{}" in bucket "{}" after {}'.format(
+                            key, s3_client.bucket, util.pluralize(attempt + 1, "attempt", "attempts")
+                        )
+                    ) from e
+                backoff = min(2 ** attempt, MAX_BACKOFF_SEC) * (0.5 + random.random())
+                attempt += 1
+                time.sleep(backoff)
+
+    def _verify(self, s3_client, obj, local_path, rel_path, expected_hashes):
+        etag = obj["ETag"].strip('"')
+        is_multipart = "-" in etag
+        etag_part_size = obj["Size"]
+        if is_multipart:
+            head = s3_client.s3.head_object(Bucket=s3_client.bucket, Key=obj["Key"], PartNumber=1)
+            etag_part_size = head["ContentLength"]
+
+        sha256 = hashlib.sha256()
+        part_md5s = []
+        part_md5 = hashlib.md5()
+        part_bytes = 0
+        with open(local_path, "rb") as f:
+            while True:
+                chunk = f.read(min(READ_CHUNK_SIZE, etag_part_size - part_bytes) or READ_CHUNK_SIZE)
+                if not chunk:
+                    break
+                sha256.update(chunk)
+                part_md5.update(chunk)
+                part_bytes += len(chunk)
+                if part_bytes == etag_part_size:
+                    part_md5s.append(part_md5)
+                    part_md5 = hashlib.md5()
+                    part_bytes = 0
+        if part_bytes > 0 or len(part_md5s) == 0:
+            part_md5s.append(part_md5)
+
+        if is_multipart:
+            actual_etag = "{}-{}".format(
+                hashlib.md5(b"".join(md5.digest() for md5 in part_md5s)).hexdigest(),
+                len(part_md5s),
+            )
+        else:
+            actual_etag = part_md5s[0].hexdigest()
+
+        if actual_etag != etag:
+            # objects encrypted with SSE-KMS (or SSE-C) do not have an MD5-based ETag
+            head = s3_client.s3.head_object(Bucket=s3_client.bucket, Key=obj["Key"])
+            if head.get("ServerSideEncryption") != "aws:kms" and "SSECustomerAlgorithm" not in head:
+                raise CortexException(
+                    'checksum mismatch for key "{}" in bucket "{}" (the download may be corrupted)'.format(
+                        obj["Key"], s3_client.bucket
+                    )
+                )
+
+        if expected_hashes is not None and rel_path in expected_hashes:
+            if sha256.hexdigest() != expected_hashes[rel_path].lower():
+                raise CortexException(
+                    "sha256 checksum of {} does not match the manifest (the download may be corrupted)".format(
+                        rel_path
+                    )
+                )
+
@@ -15,6 +15,7 @@
 
 import os
 import base64
+import json
 import time
 
 from cortex.lib.exceptions import UserException, CortexException
@@ -49,6 +50,83 @@ def upload_class(ctx, api_name, class_name):
         raise ValueError("unable to store class {}".format(class_name)) from e
 
 
+UNIDENTIFIED_CONSUMER = "unidentified"
+
+
+def header_environ_key(header):
+    return "HTTP_" + header.upper().replace("-", "_")
+
+
+def jwt_claims(environ):
+    """returns the claims of the request's bearer token, without verifying it (tokens are verified by the istio sidecar)"""
+    authorization = environ.get("HTTP_AUTHORIZATION", "")
+    if not authorization.lower().startswith("bearer "):
+        return None
+
+    try:
+        payload = authorization[len("bearer ") :].strip().split(".")[1]
+        payload += "=" * (-len(payload) % 4)
+        return json.loads(base64.urlsafe_b64decode(payload.encode()).decode())
+    except Exception:
+        cx_logger().warn("unable to decode the request's jwt")
+        return None
+
+
+def claim_str(value):
+    if isinstance(value, str):
+        return value
+    return json.dumps(value)
+
+
+def forward_claims(api, environ):
+    """sets the API's forwarded JWT claims as request headers"""
+    jwt_auth = api.get("jwt_auth")
+    if jwt_auth is None or not jwt_auth.get("forward_claims"):
+        return
+
+    forwarded = {}  # WSGI environ key -> claim
+    for claim, header in jwt_auth["forward_claims"].items():
+        key = header_environ_key(header)
+        environ.pop(key, None)  # clients can't set these headers themselves
+        forwarded[key] = claim
+
+    claims = jwt_claims(environ)
+    if claims is None:
+        return
+
+    for key, claim in forwarded.items():
+        if claims.get(claim) is not None:
+            environ[key] = claim_str(claims[claim])
+
+
+def get_consumer(ctx, environ):
+    """identifies the consumer of the request, if the deployment records usage"""
+    usage = ctx.app.get("usage")
+    if usage is None:
+        return None
+
+    consumer = None
+    if usage.get("consumer_header") is not None:
+        consumer = environ.get(header_environ_key(usage["consumer_header"]))
+    elif usage.get("consumer_claim") is not None:
+        claims = jwt_claims(environ)
+        if claims is not None and claims.get(usage["consumer_claim"]) is not None:
+            consumer = claim_str(claims[usage["consumer_claim"]])
+
+    if not consumer:
+        return UNIDENTIFIED_CONSUMER
+
+    # each consumer is billed as a separate set of cloudwatch metrics, so only consumers in the allow-list are recorded
+    # (the allow-list is required with consumer_header, since the header is set by the client)
+    if usage.get("consumers") is not None:
+        if consumer not in usage["consumers"]:
+            return UNIDENTIFIED_CONSUMER
+        return consumer
+
+    # cloudwatch dimension values must be ascii, and at most 255 characters
+    return consumer.encode("ascii", "replace").decode()[:255]
+
+
 def api_metric_dimensions(ctx, api_name):
     api = ctx.apis[api_name]
     return [
@@ -144,12 +222,19 @@ def cache_classes(ctx, api, prediction, class_set):
         class_set.add(prediction)
 
 
-def post_request_metrics(ctx, api, response, prediction_payload, start_time, class_set):
+def post_request_metrics(
+    ctx, api, response, prediction_payload, start_time, class_set, consumer=None
+):
     api_name = api["name"]
     api_dimensions = api_metric_dimensions(ctx, api_name)
     metrics_list = []
     metrics_list += status_code_metric(api_dimensions, response.status_code)
 
+    if consumer is not None:
+        consumer_dimensions = api_dimensions + [{"Name": "Consumer", "Value": consumer}]
+        metrics_list += status_code_metric(consumer_dimensions, response.status_code)
+        metrics_list += latency_metric(consumer_dimensions, start_time)
+
     if prediction_payload is not None:
         if api.get("tracker") is not None:
             try:
@@ -0,0 +1,66 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+import socket
+import threading
+
+from cortex.lib.log import cx_logger
+
+
+_BLOCKED_CONNECTION_MESSAGE = (
+    "the connection to {} timed out; it may have been blocked by the api's egress allow-list ({}), "
+    + "in which case the destination can be added to the api's egress field"
+)
+
+
+# the api's network policy drops connections to destinations which aren't in its egress allow-list, so they time out
+# (rather than failing immediately); each destination is reported the first time a connection to it times out
+def log_blocked_connections():
+    allowlist = os.environ.get("CORTEX_EGRESS_ALLOWLIST")
+    if not allowlist:
+        return
+
+    socket.socket.connect = wrap_connect(socket.socket.connect, allowlist, lambda msg: cx_logger().warn(msg))
+
+
+def wrap_connect(connect, allowlist, log):
+    reported = set()
+    lock = threading.Lock()
+
+    def wrapped_connect(sock, address):
+        try:
+            return connect(sock, address)
+        except (socket.timeout, TimeoutError):
+            destination = format_destination(address)
+            if destination is not None:
+                with lock:
+                    is_new = destination not in reported
+                    reported.add(destination)
+                if is_new:
+                    log(_BLOCKED_CONNECTION_MESSAGE.format(destination, allowlist))
+            raise
+
+    return wrapped_connect
+
+
+def format_destination(address):
+    if not isinstance(address, tuple) or len(address) < 2:
+        return None  # e.g. unix sockets
+    host, port = address[0], address[1]
+    if host in ("localhost", "127.0.0.1", "::1"):
+        return None
+    if ":" in str(host):
+        return "[{}]:{}".format(host, port)
+    return "{}:{}".format(host, port)
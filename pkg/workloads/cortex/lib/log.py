@@ -12,8 +12,11 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+import json
 import logging
+import os
 import sys
+import threading
 import time
 
 from cortex.lib import stringify
@@ -29,15 +32,58 @@ class MyFormatter(logging.Formatter):
         return s
 
 
+# attributes of every LogRecord; any others were passed via `extra` and are included in json records
+_LOG_RECORD_ATTRS = set(logging.LogRecord("", 0, "", 0, "", (), None).__dict__.keys()) | {"message"}
+
+_request_context = threading.local()
+
+
+def set_request_id(request_id):
+    _request_context.request_id = request_id
+
+
+def clear_request_id():
+    _request_context.request_id = None
+
+
+# fluentd parses each json record into fields, and adds the api name and replica
+class JSONFormatter(logging.Formatter):
+
+    converter = dt.datetime.fromtimestamp
+
+    def format(self, record):
+        log_record = {
+            "timestamp": self.converter(record.created).strftime("%Y-%m-%d %H:%M:%S.%f"),
+            "level": record.levelname,
+            "message": record.getMessage(),
+        }
+
+        request_id = getattr(_request_context, "request_id", None)
+        if request_id is not None:
+            log_record["request_id"] = request_id
+
+        for key, value in record.__dict__.items():
+            if key not in _LOG_RECORD_ATTRS and key not in log_record:
+                log_record[key] = value
+
+        if record.exc_info:
+            log_record["exception"] = self.formatException(record.exc_info)
+
+        return json.dumps(log_record, default=str)
+
+
 current_logger = None
 
 
 def register_logger(name):
     logger = logging.getLogger(name)
     handler = logging.StreamHandler(stream=sys.stdout)
-    formatter = MyFormatter(
-        fmt="%(asctime)s:cortex:%(levelname)s:%(message)s", datefmt="%Y-%m-%d %H:%M:%S.%f"
-    )
+    if os.environ.get("CORTEX_LOG_FORMAT") == "json":
+        formatter = JSONFormatter()
+    else:
+        formatter = MyFormatter(
+            fmt="%(asctime)s:cortex:%(levelname)s:%(message)s", datefmt="%Y-%m-%d %H:%M:%S.%f"
+        )
     handler.setFormatter(formatter)
 
     logger.propagate = False
@@ -0,0 +1,57 @@
+#!/bin/bash
+
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# sourced by run.sh to point pip to the python package index from the api or cluster configuration
+
+
+# inserts the credentials from the secret into an index url (they are read from the environment so they don't appear in the process list)
+function with_pip_credentials() {
+    if [ -z "$CORTEX_PIP_USERNAME" ]; then
+        echo "$1"
+        return
+    fi
+    /usr/bin/python3.6 -c '
+import os, sys
+from urllib.parse import quote, urlsplit, urlunsplit
+url = urlsplit(sys.argv[1])
+credentials = quote(os.environ["CORTEX_PIP_USERNAME"], safe="") + ":" + quote(os.environ["CORTEX_PIP_PASSWORD"], safe="")
+print(urlunsplit(url._replace(netloc=credentials + "@" + url.netloc)))
+' "$1"
+}
+
+if [ -n "$CORTEX_PIP_INDEX_URL" ]; then
+    export PIP_INDEX_URL="$(with_pip_credentials "$CORTEX_PIP_INDEX_URL")"
+fi
+
+if [ -n "$CORTEX_PIP_EXTRA_INDEX_URLS" ]; then
+    extra_index_urls=""
+    for extra_index_url in $CORTEX_PIP_EXTRA_INDEX_URLS; do
+        extra_index_urls="$extra_index_urls $(with_pip_credentials "$extra_index_url")"
+    done
+    export PIP_EXTRA_INDEX_URL="${extra_index_urls# }"
+fi
+
+if [ -n "$CORTEX_PIP_TRUSTED_HOSTS" ]; then
+    export PIP_TRUSTED_HOST="$CORTEX_PIP_TRUSTED_HOSTS"
+fi
+
+# e.g. " (using https://pypi.example.com/simple)", for error messages
+function package_index_str() {
+    local index_urls="$(echo $CORTEX_PIP_INDEX_URL $CORTEX_PIP_EXTRA_INDEX_URLS)"
+    if [ -n "$index_urls" ]; then
+        echo " (using $index_urls)"
+    fi
+}
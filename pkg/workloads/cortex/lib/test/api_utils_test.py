@@ -0,0 +1,54 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import base64
+import json
+from types import SimpleNamespace
+
+from cortex.lib import api_utils
+
+
+def usage_ctx(usage):
+    return SimpleNamespace(app={"usage": usage})
+
+
+def bearer_environ(claims):
+    payload = base64.urlsafe_b64encode(json.dumps(claims).encode()).decode().rstrip("=")
+    return {"HTTP_AUTHORIZATION": "Bearer header." + payload + ".signature"}
+
+
+def test_get_consumer_without_usage():
+    assert api_utils.get_consumer(SimpleNamespace(app={}), {"HTTP_X_TEAM": "team-a"}) is None
+
+
+def test_get_consumer_header_allow_list():
+    ctx = usage_ctx({"consumer_header": "X-Team", "consumers": ["team-a", "team-b"]})
+
+    assert api_utils.get_consumer(ctx, {"HTTP_X_TEAM": "team-a"}) == "team-a"
+    assert api_utils.get_consumer(ctx, {"HTTP_X_TEAM": "team-b"}) == "team-b"
+    assert api_utils.get_consumer(ctx, {"HTTP_X_TEAM": "team-c"}) == "unidentified"
+    assert api_utils.get_consumer(ctx, {"HTTP_X_TEAM": ""}) == "unidentified"
+    assert api_utils.get_consumer(ctx, {}) == "unidentified"
+
+
+def test_get_consumer_claim():
+    ctx = usage_ctx({"consumer_claim": "team"})
+    assert api_utils.get_consumer(ctx, bearer_environ({"team": "team-c"})) == "team-c"
+    assert api_utils.get_consumer(ctx, bearer_environ({"sub": "alice"})) == "unidentified"
+    assert api_utils.get_consumer(ctx, {}) == "unidentified"
+    assert api_utils.get_consumer(ctx, bearer_environ({"team": "x" * 300})) == "x" * 255
+
+    ctx = usage_ctx({"consumer_claim": "team", "consumers": ["team-a"]})
+    assert api_utils.get_consumer(ctx, bearer_environ({"team": "team-a"})) == "team-a"
+    assert api_utils.get_consumer(ctx, bearer_environ({"team": "team-c"})) == "unidentified"
@@ -0,0 +1,67 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import socket
+
+from cortex.lib import egress
+
+
+def timing_out_connect(sock, address):
+    raise socket.timeout("timed out")
+
+
+def test_wrap_connect_reports_each_destination_once():
+    logs = []
+    connect = egress.wrap_connect(timing_out_connect, "s3, cloudwatch, pypi.org", logs.append)
+
+    for address in [("93.184.216.34", 443), ("93.184.216.34", 443), ("2606:2800:220:1::", 80)]:
+        try:
+            connect(None, address)
+            assert False, "the timeout should be re-raised"
+        except socket.timeout:
+            pass
+
+    assert len(logs) == 2
+    assert "93.184.216.34:443" in logs[0]
+    assert "s3, cloudwatch, pypi.org" in logs[0]
+    assert "[2606:2800:220:1::]:80" in logs[1]
+
+
+def test_wrap_connect_ignores_local_and_successful_connections():
+    logs = []
+    connect = egress.wrap_connect(timing_out_connect, "s3, cloudwatch", logs.append)
+    for address in [("localhost", 8888), ("127.0.0.1", 9000), "/tmp/socket"]:
+        try:
+            connect(None, address)
+        except socket.timeout:
+            pass
+    assert logs == []
+
+    connect = egress.wrap_connect(lambda sock, address: "connected", "s3, cloudwatch", logs.append)
+    assert connect(None, ("93.184.216.34", 443)) == "connected"
+    assert logs == []
+
+
+def test_wrap_connect_reraises_other_errors():
+    def refused_connect(sock, address):
+        raise ConnectionRefusedError()
+
+    logs = []
+    connect = egress.wrap_connect(refused_connect, "s3, cloudwatch", logs.append)
+    try:
+        connect(None, ("93.184.216.34", 443))
+        assert False, "the error should be re-raised"
+    except ConnectionRefusedError:
+        pass
+    assert logs == []
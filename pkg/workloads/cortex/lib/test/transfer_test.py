@@ -0,0 +1,187 @@
+# Copyright 2019 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import hashlib
+import io
+import os
+import tempfile
+from types import SimpleNamespace
+
+from cortex.downloader import transfer
+from cortex.lib.exceptions import CortexException
+
+
+class FakeS3:
+    """Serves a single object from memory; get_object fails with the given errors (in order) before succeeding"""
+
+    def __init__(self, data, etag, errors=None, short_reads=0, head=None):
+        self.data = data
+        self.etag = etag
+        self.errors = list(errors or [])
+        self.short_reads = short_reads
+        self.head = head or {}
+        self.get_object_calls = 0
+
+    def get_object(self, Bucket, Key, Range):
+        self.get_object_calls += 1
+        if self.errors:
+            raise self.errors.pop(0)
+        start, end = [int(i) for i in Range[len("bytes=") :].split("-")]
+        body = self.data[start : end + 1]
+        if self.short_reads > 0:
+            self.short_reads -= 1
+            body = body[:-1]
+        return {"Body": io.BytesIO(body)}
+
+    def head_object(self, Bucket, Key, PartNumber=None):
+        return self.head
+
+
+def s3_client(fake_s3):
+    return SimpleNamespace(s3=fake_s3, bucket="bucket")
+
+
+def downloader(**download_config):
+    return transfer.Downloader(download_config)
+
+
+def without_sleep(fn):
+    def wrapped():
+        sleep = transfer.time.sleep
+        transfer.time.sleep = lambda seconds: None
+        try:
+            fn()
+        finally:
+            transfer.time.sleep = sleep
+
+    wrapped.__name__ = fn.__name__
+    return wrapped
+
+
+def download_part(d, fake_s3, data):
+    with tempfile.TemporaryDirectory() as tmp_dir:
+        local_path = os.path.join(tmp_dir, "model")
+        with open(local_path, "wb") as f:
+            f.truncate(len(data))
+        progress = transfer.Progress("model", len(data))
+        d._download_part(s3_client(fake_s3), progress, "model", local_path, 0, len(data) - 1)
+        with open(local_path, "rb") as f:
+            return f.read(), progress.downloaded
+
+
+@without_sleep
+def test_download_part_retries():
+    data = b"model weights"
+    fake_s3 = FakeS3(data, "", errors=[IOError("connection reset"), IOError("read timeout")])
+
+    downloaded, progress = download_part(downloader(max_retries=2), fake_s3, data)
+    assert downloaded == data
+    assert progress == len(data)
+    assert fake_s3.get_object_calls == 3
+
+
+@without_sleep
+def test_download_part_retries_short_reads():
+    data = b"model weights"
+    fake_s3 = FakeS3(data, "", short_reads=1)
+
+    downloaded, progress = download_part(downloader(max_retries=1), fake_s3, data)
+    assert downloaded == data
+    assert progress == len(data)  # the bytes of the failed attempt are not counted
+    assert fake_s3.get_object_calls == 2
+
+
+@without_sleep
+def test_download_part_gives_up():
+    data = b"model weights"
+    fake_s3 = FakeS3(data, "", errors=[IOError("connection reset")] * 3)
+
+    try:
+        download_part(downloader(max_retries=1), fake_s3, data)
+        assert False, "the download should fail"
+    except CortexException as e:
+        assert "after 2 attempts" in str(e)
+    assert fake_s3.get_object_calls == 2
+
+
+def verify(data, etag, part_size=None, expected_hashes=None, head=None):
+    fake_s3 = FakeS3(data, etag, head=head)
+    with tempfile.TemporaryDirectory() as tmp_dir:
+        local_path = os.path.join(tmp_dir, "model")
+        with open(local_path, "wb") as f:
+            f.write(data)
+        obj = {"Key": "model", "Size": len(data), "ETag": '"{}"'.format(etag)}
+        downloader()._verify(s3_client(fake_s3), obj, local_path, "model", expected_hashes)
+
+
+def multipart_etag(data, part_size):
+    parts = [data[i : i + part_size] for i in range(0, len(data), part_size)]
+    digests = b"".join(hashlib.md5(part).digest() for part in parts)
+    return "{}-{}".format(hashlib.md5(digests).hexdigest(), len(parts))
+
+
+def raises_cortex_exception(fn, message):
+    try:
+        fn()
+    except CortexException as e:
+        assert message in str(e), str(e)
+        return
+    assert False, "expected an error containing: " + message
+
+
+def test_verify_etag():
+    data = b"model weights" * 100
+    verify(data, hashlib.md5(data).hexdigest())
+
+    raises_cortex_exception(lambda: verify(data, hashlib.md5(b"other").hexdigest()), "checksum mismatch")
+
+
+def test_verify_multipart_etag():
+    data = b"model weights" * 100
+    part_size = 512
+    head = {"ContentLength": part_size}
+    verify(data, multipart_etag(data, part_size), head=head)
+
+    corrupted = data[:-1] + b"x"
+    raises_cortex_exception(
+        lambda: verify(corrupted, multipart_etag(data, part_size), head=head), "checksum mismatch"
+    )
+
+
+def test_verify_skips_etag_of_encrypted_objects():
+    data = b"model weights"
+    verify(data, "e3b0c44298fc1c149afbf4c8996fb924", head={"ServerSideEncryption": "aws:kms"})
+    verify(data, "e3b0c44298fc1c149afbf4c8996fb924", head={"SSECustomerAlgorithm": "AES256"})
+
+
+def test_verify_sha256_manifest():
+    data = b"model weights"
+    etag = hashlib.md5(data).hexdigest()
+    sha256 = hashlib.sha256(data).hexdigest()
+
+    verify(data, etag, expected_hashes={"model": sha256})
+    verify(data, etag, expected_hashes={"model": sha256.upper()})
+    verify(data, etag, expected_hashes={"other": "0" * 64})
+
+    raises_cortex_exception(
+        lambda: verify(data, etag, expected_hashes={"model": "0" * 64}), "does not match the manifest"
+    )
+
+
+def test_size_progress_str():
+    # the operator parses this format (see api_status.go)
+    assert transfer.size_progress_str(512, 1000) == "512/1000 B"
+    assert transfer.size_progress_str(1024, 4096) == "1.0/4.0 KB"
+    assert transfer.size_progress_str(10 * 1024 ** 2, 20 * 1024 ** 2) == "10.0/20.0 MB"
+    assert transfer.size_progress_str(0, 2 * 1024 ** 3) == "0.0/2.0 GB"
@@ -12,6 +12,8 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+import os
+
 import onnxruntime as rt
 import numpy as np
 
@@ -28,7 +30,11 @@ class ONNXClient:
             model_path (string): Path to model in local file system.
         """
         self._model_path = model_path
-        session = rt.InferenceSession(model_path)
+        session = rt.InferenceSession(model_path, sess_options=session_options())
+
+        providers = execution_providers()
+        if providers is not None:
+            session.set_providers(providers)
 
         self._session = session
         self._signature = session.get_inputs()
@@ -61,6 +67,63 @@ class ONNXClient:
         return self._input_signature
 
 
+GRAPH_OPTIMIZATION_LEVELS = {
+    "disable_all": rt.GraphOptimizationLevel.ORT_DISABLE_ALL,
+    "basic": rt.GraphOptimizationLevel.ORT_ENABLE_BASIC,
+    "extended": rt.GraphOptimizationLevel.ORT_ENABLE_EXTENDED,
+    "all": rt.GraphOptimizationLevel.ORT_ENABLE_ALL,
+}
+
+EXECUTION_MODES = {
+    "sequential": rt.ExecutionMode.ORT_SEQUENTIAL,
+    "parallel": rt.ExecutionMode.ORT_PARALLEL,
+}
+
+
+def session_options():
+    """Build the session options from the predictor's runtime configuration (set by the operator via environment variables)."""
+    options = rt.SessionOptions()
+
+    if os.environ.get("CORTEX_ONNX_INTRA_OP_THREADS"):
+        options.intra_op_num_threads = int(os.environ["CORTEX_ONNX_INTRA_OP_THREADS"])
+    if os.environ.get("CORTEX_ONNX_INTER_OP_THREADS"):
+        options.inter_op_num_threads = int(os.environ["CORTEX_ONNX_INTER_OP_THREADS"])
+    if os.environ.get("CORTEX_ONNX_GRAPH_OPTIMIZATION_LEVEL"):
+        options.graph_optimization_level = GRAPH_OPTIMIZATION_LEVELS[
+            os.environ["CORTEX_ONNX_GRAPH_OPTIMIZATION_LEVEL"]
+        ]
+    if os.environ.get("CORTEX_ONNX_EXECUTION_MODE"):
+        options.execution_mode = EXECUTION_MODES[os.environ["CORTEX_ONNX_EXECUTION_MODE"]]
+
+    return options
+
+
+def execution_providers():
+    """Return the configured execution providers which are available in this image, in order of preference."""
+    if not os.environ.get("CORTEX_ONNX_EXECUTION_PROVIDERS"):
+        return None
+
+    available = rt.get_available_providers()
+    providers = []
+    for provider in os.environ["CORTEX_ONNX_EXECUTION_PROVIDERS"].split(","):
+        if provider in available:
+            providers.append(provider)
+        else:
+            cx_logger().warn(
+                "execution provider {} is not available (available providers: {})".format(
+                    provider, ", ".join(available)
+                )
+            )
+
+    if len(providers) == 0:
+        raise UserException(
+            "none of the configured execution providers are available (available providers: {})".format(
+                ", ".join(available)
+            )
+        )
+    return providers
+
+
 # https://github.com/microsoft/onnxruntime/blob/v0.4.0/onnxruntime/python/onnxruntime_pybind_mlvalue.cc
 ONNX_TO_NP_TYPE = {
     "tensor(float16)": "float16",
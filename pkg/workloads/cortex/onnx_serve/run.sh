@@ -16,7 +16,24 @@
 
 export PYTHONPATH=$PYTHONPATH:$PYTHON_PATH
 
+if [ -n "$CORTEX_EGRESS_ALLOWLIST" ]; then
+    echo "outbound network access is restricted to: $CORTEX_EGRESS_ALLOWLIST (connections to other destinations will time out; add them to the api's egress field)"
+fi
+
 if [ -f "/mnt/project/requirements.txt" ]; then
-    pip --no-cache-dir install -r /mnt/project/requirements.txt
+    source /src/cortex/lib/package_index.sh
+
+    pip_install_flags=""
+    if [ -n "$PYTHONUSERBASE" ]; then
+        # the root filesystem is read-only, so install into the user site directory
+        pip_install_flags="--user"
+    fi
+    if ! pip --no-cache-dir install $pip_install_flags -r /mnt/project/requirements.txt; then
+        error_message="failed to install the packages in requirements.txt$(package_index_str)"
+        echo "error: $error_message"
+        # the termination message is reported as a configuration error in the api's status
+        echo "configuration error: $error_message" > /dev/termination-log
+        exit 1
+    fi
 fi
 /usr/bin/python3.6 /src/cortex/onnx_serve/api.py "$@"
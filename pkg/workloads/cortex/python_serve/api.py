@@ -16,13 +16,14 @@ import os
 import sys
 import argparse
 import time
+import uuid
 
 from flask import Flask, request, jsonify, g
 from flask_api import status
 from waitress import serve
 
-from cortex.lib import util, Context, api_utils
-from cortex.lib.log import cx_logger, debug_obj, refresh_logger
+from cortex.lib import util, Context, api_utils, egress
+from cortex.lib.log import cx_logger, debug_obj, refresh_logger, set_request_id, clear_request_id
 from cortex.lib.exceptions import CortexException, UserRuntimeException
 
 app = Flask(__name__)
@@ -35,6 +36,15 @@ local_cache = {"ctx": None, "api": None, "class_set": set()}
 @app.before_request
 def before_request():
     g.start_time = time.time()
+    # istio's ingress gateway sets x-request-id, which is also included in its access logs
+    set_request_id(request.headers.get("X-Request-ID", uuid.uuid4().hex))
+    if local_cache["api"] is not None:
+        api_utils.forward_claims(local_cache["api"], request.environ)
+
+
+@app.teardown_request
+def teardown_request(exception):
+    clear_request_id()
 
 
 @app.after_request
@@ -57,7 +67,13 @@ def after_request(response):
         prediction = g.prediction
 
     api_utils.post_request_metrics(
-        ctx, api, response, prediction, g.start_time, local_cache["class_set"]
+        ctx,
+        api,
+        response,
+        prediction,
+        g.start_time,
+        local_cache["class_set"],
+        api_utils.get_consumer(ctx, request.environ),
     )
 
     return response
@@ -113,6 +129,8 @@ def exceptions(e):
 
 
 def start(args):
+    egress.log_blocked_connections()
+
     api = None
     try:
         ctx = Context(s3_path=args.context, cache_dir=args.cache_dir, workload_id=args.workload_id)
@@ -154,7 +172,7 @@ def start(args):
     waitress_kwargs["listen"] = "*:{}".format(args.port)
 
     cx_logger().info("{} api is live".format(api["name"]))
-    open("/health_check.txt", "a").close()
+    open("/tmp/health_check.txt", "a").close()
     serve(app, **waitress_kwargs)
 
 